@@ -8,12 +8,19 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // gym time zones must resolve in the alpine image
 
 	"fitslot/internal/config"
 	"fitslot/internal/db"
 	"fitslot/internal/email"
+	"fitslot/internal/gym"
 	"fitslot/internal/logger"
+	"fitslot/internal/schedule"
 	"fitslot/internal/server"
+	"fitslot/internal/user"
+	"fitslot/internal/wallet"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // @title FitSlot API
@@ -41,7 +48,7 @@ func main() {
 	defer database.Close()
 	logger.Info("Database connected")
 
-	if err := db.RunMigrations(database, "migrations"); err != nil {
+	if err := db.RunMigrations(database, "migrations", cfg.LegacyTimeZone); err != nil {
 		logger.Fatalf("Failed to run migrations: %v", err)
 	}
 	logger.Info("Migrations completed")
@@ -62,7 +69,35 @@ func main() {
 	defer cancel()
 	go emailService.Start(ctx)
 
-	srv := server.New(database, cfg, emailService)
+	// The availability cache shares Redis with the email queue but gives up
+	// quickly, since the database can always answer instead.
+	cacheRedis := redis.NewClient(&redis.Options{
+		Addr:         cfg.RedisAddr,
+		DialTimeout:  200 * time.Millisecond,
+		ReadTimeout:  200 * time.Millisecond,
+		WriteTimeout: 200 * time.Millisecond,
+		MaxRetries:   -1,
+	})
+	defer cacheRedis.Close()
+	availability := gym.NewAvailabilityCache(cacheRedis, cfg.AvailabilityCacheTTL)
+
+	gymRepo := gym.NewCachedRepository(gym.NewRepository(database), availability)
+	scheduleJob := schedule.NewJob(
+		schedule.NewService(schedule.NewRepository(database), gymRepo, availability),
+		gymRepo,
+		cfg.ScheduleInterval,
+		cfg.ScheduleHorizonWeeks,
+	)
+	go scheduleJob.Start(ctx)
+
+	walletService := wallet.NewService(wallet.NewRepository(database), user.NewRepository(database), cfg.WalletTransferDailyLimitCents)
+	reconciliationJob := wallet.NewReconciliationJob(walletService, cfg.WalletReconcileInterval)
+	go reconciliationJob.Start(ctx)
+
+	creditExpiryJob := wallet.NewCreditExpiryJob(walletService, cfg.WalletCreditExpiryInterval)
+	go creditExpiryJob.Start(ctx)
+
+	srv := server.New(database, cfg, emailService, availability)
 
 	serverErrChan := make(chan error, 1)
 	go func() {
@@ -15,52 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/gyms": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "gyms",
-                    "admin"
-                ],
-                "summary": "List gyms",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/gym.Gym"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
-            },
+        "/admin/class-types": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Admin-only: create a new gym",
+                "description": "Admin-only: add a kind of class (yoga, HIIT, boxing) that time slots can offer",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,17 +26,17 @@ const docTemplate = `{
                 ],
                 "tags": [
                     "admin",
-                    "gyms"
+                    "classes"
                 ],
-                "summary": "Create a gym",
+                "summary": "Create a class type",
                 "parameters": [
                     {
-                        "description": "Gym payload",
+                        "description": "Class type payload",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gym.CreateGymRequest"
+                            "$ref": "#/definitions/class.CreateClassTypeRequest"
                         }
                     }
                 ],
@@ -87,7 +44,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gym.Gym"
+                            "$ref": "#/definitions/class.ClassType"
                         }
                     },
                     "400": {
@@ -108,35 +65,42 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/gyms/{gymID}/bookings": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/closures/{closureID}": {
+            "delete": {
+                "description": "Admin-only: reopen the gym. Slots cancelled because of the closure stay cancelled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin",
-                    "bookings"
+                    "gyms"
                 ],
-                "summary": "List bookings by gym (admin)",
+                "summary": "Delete a gym closure",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Gym ID",
-                        "name": "gymID",
+                        "description": "Closure ID",
+                        "name": "closureID",
                         "in": "path",
                         "required": true
                     }
@@ -145,10 +109,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/booking.BookingWithDetails"
-                            }
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
@@ -169,35 +130,42 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/gyms/{gymID}/slots": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/closures/{closureID}/cancel-slots": {
+            "post": {
+                "description": "Cancel every time slot that falls into a gym closure. Active bookings are cancelled, refunded and the members are notified. Each slot is cancelled with its bookings or not at all; slots that fail are listed in failed_slot_ids and are retried by calling again.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "gyms",
-                    "admin"
+                    "admin",
+                    "gyms"
                 ],
-                "summary": "List time slots for a gym",
+                "summary": "Cancel slots inside a closure (admin)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Gym ID",
-                        "name": "gymID",
+                        "description": "Closure ID",
+                        "name": "closureID",
                         "in": "path",
                         "required": true
                     }
@@ -206,10 +174,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/gym.TimeSlotWithAvailability"
-                            }
+                            "$ref": "#/definitions/booking.CancelClosureSlotsResponse"
                         }
                     },
                     "400": {
@@ -224,6 +189,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -236,15 +207,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Admin-only: create a time slot for a gym",
+                ]
+            }
+        },
+        "/admin/exchange-rates/{base}/{quote}": {
+            "put": {
+                "description": "Stores the price of one unit of base in quote, replacing the rate of the pair in either direction. Later conversions use the new rate; past transactions keep the rate they were made at.",
                 "consumes": [
                     "application/json"
                 ],
@@ -253,32 +226,41 @@ const docTemplate = `{
                 ],
                 "tags": [
                     "admin",
-                    "gyms"
+                    "wallet"
                 ],
-                "summary": "Create a time slot",
+                "summary": "Set an exchange rate",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Gym ID",
-                        "name": "gymID",
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Base currency",
+                        "name": "base",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Time slot payload",
+                        "type": "string",
+                        "example": "KZT",
+                        "description": "Quote currency",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rate",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gym.CreateTimeSlotRequest"
+                            "$ref": "#/definitions/wallet.SetExchangeRateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gym.TimeSlot"
+                            "$ref": "#/definitions/wallet.ExchangeRate"
                         }
                     },
                     "400": {
@@ -299,53 +281,76 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/slots/{slotID}/bookings": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/gyms": {
+            "get": {
+                "description": "Cursor-paginated. from/to (RFC3339, or a local time or date read in each gym's time zone) and available_only restrict the list to gyms with a matching slot; to is exclusive.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "admin",
-                    "bookings"
+                    "gyms",
+                    "admin"
                 ],
-                "summary": "List bookings by time slot (admin)",
+                "summary": "List gyms",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting at or after this time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting before this time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only gyms with an upcoming slot that has free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at, name or rating; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Time slot ID",
-                        "name": "slotID",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/booking.BookingWithDetails"
-                            }
+                            "$ref": "#/definitions/api.Page-gym_Gym"
                         }
                     },
                     "400": {
@@ -360,24 +365,21 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/auth/login": {
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
             "post": {
-                "description": "Login with email and password to receive access/refresh tokens",
+                "description": "Admin-only: create a new gym",
                 "consumes": [
                     "application/json"
                 ],
@@ -385,25 +387,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin",
+                    "gyms"
                 ],
-                "summary": "Login",
+                "summary": "Create a gym",
                 "parameters": [
                     {
-                        "description": "Login payload",
+                        "description": "Gym payload",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.LoginRequest"
+                            "$ref": "#/definitions/gym.CreateGymRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/user.LoginResponse"
+                            "$ref": "#/definitions/gym.Gym"
                         }
                     },
                     "400": {
@@ -418,44 +421,53 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "Exchange a refresh token for a new access token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/gyms/{gymID}/bookings": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin",
+                    "bookings"
                 ],
-                "summary": "Refresh access token",
+                "summary": "List bookings by gym (admin)",
                 "parameters": [
                     {
-                        "description": "Refresh token payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.RefreshTokenRequest"
-                        }
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.RefreshTokenResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingWithDetails"
+                            }
                         }
                     },
                     "400": {
@@ -469,39 +481,54 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user (member role by default) and return access/refresh tokens",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/gyms/{gymID}/closures": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "gyms",
+                    "admin"
                 ],
-                "summary": "Register user",
+                "summary": "List gym closures",
                 "parameters": [
                     {
-                        "description": "Register payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.RegisterRequest"
-                        }
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.LoginResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gym.Closure"
+                            }
                         }
                     },
                     "400": {
@@ -510,8 +537,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -522,31 +555,55 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/bookings": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            },
+            "post": {
+                "description": "Admin-only: close the gym for a whole day (date) or a partial day (starts_at/ends_at).\nSlots inside the closure are hidden from listings and returned as affected_slots; cancel them with POST /admin/closures/{closureID}/cancel-slots.",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Add a gym closure",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Closure payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gym.CreateClosureRequest"
+                        }
+                    }
                 ],
-                "summary": "List my bookings",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/booking.Booking"
-                            }
+                            "$ref": "#/definitions/gym.CreateClosureResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "401": {
@@ -555,44 +612,67 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/bookings/{bookingID}/cancel": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/photos": {
+            "post": {
+                "description": "JPEG or PNG, up to 5 MB and 8000x8000 pixels. The type is detected from the file content. A thumbnail is generated and the photo is added after the gym's existing photos.",
+                "consumes": [
+                    "multipart/form-data"
                 ],
-                "description": "Cancel a booking owned by the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin",
+                    "gyms"
                 ],
-                "summary": "Cancel booking",
+                "summary": "Upload a gym photo",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Booking ID",
-                        "name": "bookingID",
+                        "description": "Gym ID",
+                        "name": "gymID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/booking.CancelBookingResponse"
+                            "$ref": "#/definitions/photo.Photo"
                         }
                     },
                     "400": {
@@ -619,70 +699,123 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/gyms": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/photos/order": {
+            "put": {
+                "description": "photo_ids must list every photo of the gym exactly once, in the new display order.",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "gyms",
-                    "admin"
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Reorder gym photos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New order",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/photo.ReorderPhotosRequest"
+                        }
+                    }
                 ],
-                "summary": "List gyms",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/gym.Gym"
+                                "$ref": "#/definitions/photo.Photo"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/gyms/{gymID}/slots": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/reviews": {
+            "get": {
+                "description": "Newest first. Hidden reviews are only listed on the admin route.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "gyms",
+                    "reviews",
                     "admin"
                 ],
-                "summary": "List time slots for a gym",
+                "summary": "List gym reviews",
                 "parameters": [
                     {
                         "type": "integer",
@@ -698,7 +831,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/gym.TimeSlotWithAvailability"
+                                "$ref": "#/definitions/review.Review"
                             }
                         }
                     },
@@ -726,49 +859,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/health": {
+        "/admin/gyms/{gymID}/rooms": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "system"
+                    "gyms",
+                    "admin"
                 ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.HealthResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/me": {
-            "get": {
-                "security": [
+                "summary": "List rooms of a gym",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.User"
-                        }
-                    },
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gym.Room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -780,59 +913,56 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/metrics": {
-            "get": {
-                "description": "Exposes Prometheus metrics in text format",
-                "produces": [
-                    "text/plain"
-                ],
-                "tags": [
-                    "system"
-                ],
-                "summary": "Prometheus metrics",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/slots/{slotID}/book": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            },
+            "post": {
+                "description": "Admin-only: add a room or zone (weights floor, studio, pool) with its own maximum occupancy",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Create a booking for the current user (paid with wallet or subscription)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin",
+                    "gyms"
                 ],
-                "summary": "Book a time slot",
+                "summary": "Add a room",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Time slot ID",
-                        "name": "slotID",
+                        "description": "Gym ID",
+                        "name": "gymID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Room payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gym.CreateRoomRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/booking.BookSlotResponse"
+                            "$ref": "#/definitions/gym.Room"
                         }
                     },
                     "400": {
@@ -847,8 +977,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment Required",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -871,54 +1001,82 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/subscriptions": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/schedule-templates": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin",
+                    "schedule"
+                ],
+                "summary": "List schedule templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List my subscriptions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/subscription.Subscription"
+                                "$ref": "#/definitions/schedule.Template"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Purchase a subscription plan using wallet balance",
+                ]
+            },
+            "post": {
+                "description": "Admin-only: add a weekly recurring slot (weekday 0 = Sunday) to a gym's schedule",
                 "consumes": [
                     "application/json"
                 ],
@@ -926,17 +1084,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin",
+                    "schedule"
                 ],
-                "summary": "Create subscription",
+                "summary": "Create a schedule template",
                 "parameters": [
                     {
-                        "description": "Subscription purchase payload",
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template payload",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/subscription.CreateSubscriptionRequest"
+                            "$ref": "#/definitions/schedule.CreateTemplateRequest"
                         }
                     }
                 ],
@@ -944,7 +1110,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/subscription.CreateSubscriptionResponse"
+                            "$ref": "#/definitions/schedule.Template"
                         }
                     },
                     "400": {
@@ -959,8 +1125,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment Required",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -971,65 +1143,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/subscriptions/plans": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "subscriptions"
-                ],
-                "summary": "List subscription plans",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/subscription.Plan"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
+                ]
             }
         },
-        "/test-email": {
-            "get": {
+        "/admin/gyms/{gymID}/schedule-templates/generate": {
+            "post": {
+                "description": "Admin-only: materialize slots for the next N weeks. Existing slots are skipped, so the call is safe to repeat.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "system"
+                    "admin",
+                    "schedule"
                 ],
-                "summary": "Queue a test email",
+                "summary": "Generate time slots from templates",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Recipient email",
-                        "name": "email",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 4,
+                        "description": "Horizon in weeks",
+                        "name": "weeks",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.MessageResponse"
+                            "$ref": "#/definitions/schedule.GenerateResult"
                         }
                     },
                     "400": {
@@ -1038,38 +1191,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/wallet": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "wallet"
-                ],
-                "summary": "Get wallet balance",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/wallet.Wallet"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -1080,45 +1215,92 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/wallet/topup": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/slots": {
+            "get": {
+                "description": "Cursor-paginated. Members only see upcoming slots. from/to take RFC3339, or a local time or date read in the gym's time zone; to is exclusive.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "gyms",
+                    "admin"
                 ],
-                "summary": "Top up wallet",
+                "summary": "List time slots for a gym",
                 "parameters": [
                     {
-                        "description": "Top up payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/wallet.TopUpRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/wallet.TopUpResponse"
-                        }
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
                     },
-                    "400": {
+                    {
+                        "type": "string",
+                        "description": "Class type ID or name",
+                        "name": "class_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Instructor ID",
+                        "name": "instructor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest start time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time upper bound (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only slots with free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "start_time",
+                        "description": "start_time, price or available; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_TimeSlotWithAvailability"
+                        }
+                    },
+                    "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
@@ -1130,277 +1312,5709 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/wallet/transactions": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            },
+            "post": {
+                "description": "Admin-only: create a time slot for a gym",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "admin",
+                    "gyms"
                 ],
-                "summary": "List wallet transactions",
+                "summary": "Create a time slot",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 50,
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Time slot payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gym.CreateTimeSlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gym.TimeSlot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/slots/import": {
+            "post": {
+                "description": "Admin-only: bulk-create time slots from a CSV file with columns start_time, end_time, capacity and optional price_cents and tags (separated by ';').\nWith dry_run=true every row is validated and reported but nothing is created. Otherwise the import is all-or-nothing.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Import time slots from CSV",
+                "parameters": [
                     {
                         "type": "integer",
-                        "default": 0,
-                        "description": "Offset",
-                        "name": "offset",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file (or send the CSV as the request body)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate only",
+                        "name": "dry_run",
                         "in": "query"
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gym.ImportResult"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gym.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/gym.ImportResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/staff": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "staff"
+                ],
+                "summary": "List gym staff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/wallet.Transaction"
+                                "$ref": "#/definitions/staff.StaffMember"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "something went wrong"
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Give a user a role at a gym (manager, front_desk or instructor). Gym managers can assign front_desk and instructor; only admins can assign managers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "staff"
+                ],
+                "summary": "Assign a staff role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Staff payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/staff.AssignStaffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/staff.StaffMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/gyms/{gymID}/staff/{userID}": {
+            "delete": {
+                "description": "Revoke a user's role at a gym. Only admins can remove managers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "staff"
+                ],
+                "summary": "Remove a staff member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/instructors": {
+            "post": {
+                "description": "Admin-only: add an instructor profile and the gyms they teach at",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "classes"
+                ],
+                "summary": "Create an instructor",
+                "parameters": [
+                    {
+                        "description": "Instructor payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/class.InstructorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/class.Instructor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/instructors/{instructorID}": {
+            "put": {
+                "description": "Admin-only: replace an instructor's profile and the gyms they teach at",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "classes"
+                ],
+                "summary": "Update an instructor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Instructor ID",
+                        "name": "instructorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Instructor payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/class.InstructorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/class.Instructor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/ledger/accounts": {
+            "get": {
+                "description": "Every ledger account with its balance derived from its postings. Credits are positive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "ledger"
+                ],
+                "summary": "List ledger account balances",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.AccountBalance"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/ledger/check": {
+            "get": {
+                "description": "Checks that every journal entry, and so the whole ledger, nets to zero in each currency and that every wallet balance matches its ledger account. ok is false when anything is off.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "ledger"
+                ],
+                "summary": "Check ledger invariants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.LedgerCheck"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/photos/{photoID}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Delete a gym photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Photo ID",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/reviews/{reviewID}/hide": {
+            "post": {
+                "description": "Hidden reviews disappear from public listings and no longer count towards the gym's rating.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "reviews"
+                ],
+                "summary": "Hide a review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/review.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/reviews/{reviewID}/unhide": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "reviews"
+                ],
+                "summary": "Unhide a review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/review.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/schedule-templates/{templateID}": {
+            "delete": {
+                "description": "Admin-only: stop generating slots from a template. Already generated slots are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "schedule"
+                ],
+                "summary": "Delete a schedule template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "templateID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/slots/{slotID}": {
+            "delete": {
+                "description": "Remove a slot from the schedule. If it has active bookings the request is rejected unless cancel_bookings=true, in which case every booking is cancelled, refunded and the member is notified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Delete a time slot (admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Time slot ID",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Cancel and refund active bookings",
+                        "name": "cancel_bookings",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/booking.DeleteSlotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Change slot times and/or capacity. Members with bookings are emailed about time changes. Lowering capacity below the active bookings requires overflow_policy=cancel_latest, which cancels and refunds the most recent bookings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "gyms"
+                ],
+                "summary": "Update a time slot (admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Time slot ID",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.UpdateSlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/booking.UpdateSlotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/slots/{slotID}/bookings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "bookings"
+                ],
+                "summary": "List bookings by time slot (admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Time slot ID",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingWithDetails"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users/{userID}/wallet/adjust": {
+            "post": {
+                "description": "Credits or debits the member's wallet in the currency (KZT by default) by hand, recording the reason, the reference (e.g. a support ticket) and the acting admin. A debit cannot take the balance below zero.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "wallet"
+                ],
+                "summary": "Adjust a member's wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Adjustment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.AdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users/{userID}/wallet/promo-credit": {
+            "post": {
+                "description": "Credits the member's wallet in the currency (KZT by default) with promotional credit valid for valid_for_days days, at most 366, recording the reason, an optional reference (e.g. a campaign) and the acting admin. Charges spend promotional credit before the member's own money, the credit that expires first first; it cannot be transferred.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "wallet"
+                ],
+                "summary": "Grant promotional credit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promotional credit",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.PromoCreditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/wallet/transactions/{txID}/refund": {
+            "post": {
+                "description": "Returns the amount of a booking or subscription charge to the member's wallet, recording the reason and the acting admin. A charge can be refunded once, including by the refund made when the gym cancels the booking. The part paid with promotional credit goes back into the credit it came from, which keeps its expiry; only the rest comes back as the member's own money.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "wallet"
+                ],
+                "summary": "Refund a wallet charge",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet transaction ID",
+                        "name": "txID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.RefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/wallets/reconciliation": {
+            "get": {
+                "description": "Checks every wallet against its transaction history: the balance must equal the sum of the transactions, and each transaction's balance_after must follow from the one before it. Lists the wallets that fail; ok is false when there are any. The check also runs periodically and is exported as metrics.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "wallet"
+                ],
+                "summary": "Reconcile wallets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Reconciliation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/wallets/{walletID}/reconcile": {
+            "post": {
+                "description": "Approves the correction of a wallet that failed reconciliation. A reconciliation transaction records the difference between the balance and the transaction history and starts the balance_after chain afresh; the balance and the ledger are unchanged. difference_cents must match the current difference. A wallet whose balance differs from its ledger account needs an adjustment instead (422).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "wallet"
+                ],
+                "summary": "Correct a wallet after reconciliation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet ID",
+                        "name": "walletID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Approved correction",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.ReconcileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login with email and password to receive access/refresh tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "Login payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.RefreshTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user (member role by default) and return access/refresh tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register user",
+                "parameters": [
+                    {
+                        "description": "Register payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/user.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "List my bookings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.Booking"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/bookings/{bookingID}/cancel": {
+            "post": {
+                "description": "Cancel a booking owned by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Cancel booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "bookingID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/booking.CancelBookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/class-types": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "List class types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/class.ClassType"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/exchange-rates": {
+            "get": {
+                "description": "The rates payments in another currency are converted at. A rate is the price of one unit of base in quote; the inverse pair uses its reciprocal.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.ExchangeRate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms": {
+            "get": {
+                "description": "Cursor-paginated. from/to (RFC3339, or a local time or date read in each gym's time zone) and available_only restrict the list to gyms with a matching slot; to is exclusive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms",
+                    "admin"
+                ],
+                "summary": "List gyms",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting at or after this time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting before this time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only gyms with an upcoming slot that has free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at, name or rating; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_Gym"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}": {
+            "get": {
+                "description": "The gym with its rating and photos in display order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms"
+                ],
+                "summary": "Get a gym profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/photo.GymProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}/closures": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms",
+                    "admin"
+                ],
+                "summary": "List gym closures",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gym.Closure"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}/occupancy": {
+            "get": {
+                "description": "Current headcount, based on the bookings of the slots running right now, plus the typical busyness per hour of a weekday averaged over past weeks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms"
+                ],
+                "summary": "Get live gym occupancy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 4,
+                        "description": "Weeks of history to average",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Weekday for the typical busyness, 0 = Sunday (default: today)",
+                        "name": "weekday",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gym.Occupancy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}/reviews": {
+            "get": {
+                "description": "Newest first. Hidden reviews are only listed on the admin route.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews",
+                    "admin"
+                ],
+                "summary": "List gym reviews",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/review.Review"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Rate a gym from 1 to 5 for one of your past bookings there. Each booking can be reviewed once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Review a gym",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/review.CreateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/review.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}/rooms": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms",
+                    "admin"
+                ],
+                "summary": "List rooms of a gym",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gym.Room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/gyms/{gymID}/slots": {
+            "get": {
+                "description": "Cursor-paginated. Members only see upcoming slots. from/to take RFC3339, or a local time or date read in the gym's time zone; to is exclusive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms",
+                    "admin"
+                ],
+                "summary": "List time slots for a gym",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class type ID or name",
+                        "name": "class_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Instructor ID",
+                        "name": "instructor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest start time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time upper bound (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only slots with free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "start_time",
+                        "description": "start_time, price or available; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_TimeSlotWithAvailability"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instructors": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "List instructors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only instructors teaching at this gym",
+                        "name": "gym_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/class.Instructor"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/instructors/{instructorID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get an instructor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Instructor ID",
+                        "name": "instructorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/class.Instructor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Exposes Prometheus metrics in text format",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Prometheus metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/webhook/{provider}": {
+            "post": {
+                "description": "Receives payment status changes from the provider. The request must be signed with the provider's webhook secret; for the fake provider, the X-Fake-Signature header is \"t=\u003cunix time\u003e,v1=\u003chex HMAC-SHA256 of \"\u003cunix time\u003e.\u003cbody\u003e\"\u003e\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Payment provider webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "fake",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.Event"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/gyms": {
+            "get": {
+                "description": "Same as GET /gyms, without authentication. Responses carry an ETag and may be cached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "List gyms (public)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting at or after this time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only gyms with a slot starting before this time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only gyms with an upcoming slot that has free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at, name or rating; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_Gym"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/gyms/{gymID}": {
+            "get": {
+                "description": "Same as GET /gyms/{gymID}, without authentication. Responses carry an ETag and may be cached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Get a gym profile (public)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/photo.GymProfile"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/gyms/{gymID}/slots": {
+            "get": {
+                "description": "Upcoming slots with their free places, without booking details. Takes the same filters as GET /gyms/{gymID}/slots. Responses carry an ETag and may be cached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "List upcoming time slots of a gym (public)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gym ID",
+                        "name": "gymID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class type ID or name",
+                        "name": "class_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Instructor ID",
+                        "name": "instructor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest start time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time upper bound (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only slots with free places",
+                        "name": "available_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "start_time",
+                        "description": "start_time, price or available; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_PublicTimeSlot"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slots/search": {
+            "get": {
+                "description": "Upcoming slots from every gym matching the filters, ordered by start time. from/to bound the slot start time. By default only slots with at least one free seat are returned.\nPaginated with a cursor: pass next_cursor back as cursor with the same filters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gyms"
+                ],
+                "summary": "Search slots across gyms",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Earliest start time (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest start time, exclusive (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated gym IDs",
+                        "name": "gym_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class type ID or name",
+                        "name": "class_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Minimum free seats",
+                        "name": "min_available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only gyms pricing slots in this currency; required with max_price_cents",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price in cents of currency",
+                        "name": "max_price_cents",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-gym_SlotSearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/slots/{slotID}/book": {
+            "post": {
+                "description": "Create a booking for the current user, paid with an active subscription or from the wallet in the gym's currency. Name another wallet_currency to pay from that wallet instead; the price is converted at the stored exchange rate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Book a time slot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Time slot ID",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Pay from the wallet in this currency",
+                        "name": "wallet_currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/booking.BookSlotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List my subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/subscription.Subscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Purchase a subscription plan using wallet balance. Plans are priced in KZT; set wallet_currency to pay from a wallet in another currency at the stored exchange rate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription purchase payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscription.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/subscription.CreateSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/subscriptions/plans": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscription plans",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/subscription.Plan"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/test-email": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Queue a test email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipient email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet": {
+            "get": {
+                "description": "Returns the wallet in the currency, KZT by default. A member has one wallet per currency. The balance is split into the member's own money (cash_cents) and promotional credit (promo_cents), with the time the first promotional credit expires.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get wallet balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "KZT",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/auto-topup": {
+            "get": {
+                "description": "Auto top-ups of the member's wallets, one per currency, with the declines in a row and, when declines turned it off, why.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List my auto top-ups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payment.AutoTopUp"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Tops up the wallet in the currency (KZT by default) by amount_cents from a saved payment method whenever a booking or subscription charge would leave its balance below threshold_cents, at most monthly_cap_cents per calendar month (UTC). A charge the balance does not cover waits for the top-up. Replaces the wallet's previous auto top-up and turns it back on if declines had turned it off; it is turned off after 3 declines in a row.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Turn on auto top-up",
+                "parameters": [
+                    {
+                        "description": "Auto top-up",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.AutoTopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.AutoTopUp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/auto-topup/{currency}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Turn off auto top-up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "KZT",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/payment-methods": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List my payment methods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payment.PaymentMethod"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Saves a payment method with the payment provider so that auto top-ups can be charged to it. The fake provider takes fake_success, fake_failure and fake_pending.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Save a payment method",
+                "parameters": [
+                    {
+                        "description": "Payment method",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.SavePaymentMethodRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentMethod"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/payment-methods/{methodID}": {
+            "delete": {
+                "description": "Deletes the saved payment method and the auto top-ups that use it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Delete a payment method",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment method ID",
+                        "name": "methodID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/promo-credits": {
+            "get": {
+                "description": "Promotional credit left in the member's wallets, the credit that expires first first. Charges spend it before the member's own money; what is left when it expires is taken back by a credit_expired transaction.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List my promotional credit",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.CreditLot"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/statement": {
+            "get": {
+                "description": "Statement of the wallet in the currency (KZT by default) from one date to another, both inclusive and in UTC: the opening balance, every transaction with a description and the balance after it, and the closing balance. From defaults to the first of the current month, to to today; a statement covers at most 366 days.",
+                "produces": [
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Download a wallet statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "KZT",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/topup": {
+            "post": {
+                "description": "Charges the amount through the payment provider. The wallet in the payment's currency (KZT by default) is credited once the payment succeeds: right away (200), or later through the provider's webhook when the payment is still pending (202). Answers 503 when no payment provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Top up wallet",
+                "parameters": [
+                    {
+                        "description": "Top up payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.TopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.TopUpResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/payment.TopUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/transactions": {
+            "get": {
+                "description": "Transactions of all the member's wallets, each with its wallet's currency. Transfers appear as transfer_out in the sender's list and transfer_in in the recipient's, with the other member and the note. Payments converted from another currency carry the original amount, currency and rate. Charges link to the booking or subscription they paid for and top-ups to their payment, with a description and details as metadata.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List wallet transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated transaction types, e.g. booking_payment,refund",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD (UTC)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD (UTC)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.Transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallet/transfer": {
+            "post": {
+                "description": "Moves credit from your wallet to the wallet of the member with the given email, in the same currency (KZT by default). Both wallets list the transfer in their transactions. What a member transfers out of all their wallets within the last 24 hours (a rolling window) is limited; the limit is in KZT and transfers in other currencies count at the stored rate. Promotional credit cannot be transferred, so the amount must be covered by cash.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Transfer credit to another member",
+                "parameters": [
+                    {
+                        "description": "Transfer payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wallet.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wallet.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/wallets": {
+            "get": {
+                "description": "Every wallet of the member, one per currency they have used, with the balance split into cash and promotional credit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List my wallets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wallet.Wallet"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something went wrong"
+                }
+            }
+        },
+        "api.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "api.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "api.Page-gym_Gym": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.Gym"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoic3RhcnRfdGltZSIsImsiOiIyMDI0LTA2LTAxVDA5OjAwOjAwWiIsImkiOjQyfQ"
+                }
+            }
+        },
+        "api.Page-gym_PublicTimeSlot": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.PublicTimeSlot"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoic3RhcnRfdGltZSIsImsiOiIyMDI0LTA2LTAxVDA5OjAwOjAwWiIsImkiOjQyfQ"
+                }
+            }
+        },
+        "api.Page-gym_SlotSearchResult": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.SlotSearchResult"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoic3RhcnRfdGltZSIsImsiOiIyMDI0LTA2LTAxVDA5OjAwOjAwWiIsImkiOjQyfQ"
+                }
+            }
+        },
+        "api.Page-gym_TimeSlotWithAvailability": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.TimeSlotWithAvailability"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoic3RhcnRfdGltZSIsImsiOiIyMDI0LTA2LTAxVDA5OjAwOjAwWiIsImkiOjQyfQ"
+                }
+            }
+        },
+        "booking.BookSlotResponse": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "booking": {
+                    "$ref": "#/definitions/booking.Booking"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "paid_with": {
+                    "type": "string",
+                    "example": "wallet"
+                },
+                "subscription": {
+                    "$ref": "#/definitions/subscription.Subscription"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/wallet.Transaction"
+                }
+            }
+        },
+        "booking.Booking": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paid_with": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "time_slot_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "booking.BookingWithDetails": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer"
+                },
+                "class_type_name": {
+                    "type": "string",
+                    "example": "Yoga"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "gym_location": {
+                    "type": "string"
+                },
+                "gym_name": {
+                    "type": "string"
+                },
+                "gym_timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor_name": {
+                    "type": "string"
+                },
+                "paid_with": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "time_slot_end": {
+                    "type": "string"
+                },
+                "time_slot_id": {
+                    "type": "integer"
+                },
+                "time_slot_start": {
+                    "type": "string"
+                },
+                "user_email": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "booking.CancelBookingResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Booking cancelled successfully"
+                }
+            }
+        },
+        "booking.CancelClosureSlotsResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled_bookings": {
+                    "type": "integer"
+                },
+                "cancelled_slots": {
+                    "type": "integer"
+                },
+                "closure_id": {
+                    "type": "integer"
+                },
+                "failed_slot_ids": {
+                    "description": "FailedSlotIDs are the slots left as they were, bookings included.\nCalling the endpoint again retries them.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "booking.DeleteSlotResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled_bookings": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Time slot deleted"
+                }
+            }
+        },
+        "booking.UpdateSlotRequest": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-01-20T11:00:00Z"
+                },
+                "overflow_policy": {
+                    "type": "string",
+                    "enum": [
+                        "reject",
+                        "cancel_latest"
+                    ],
+                    "example": "reject"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-01-20T10:00:00Z"
+                }
+            }
+        },
+        "booking.UpdateSlotResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled_bookings": {
+                    "type": "integer"
+                },
+                "notified_members": {
+                    "type": "integer"
+                },
+                "slot": {
+                    "$ref": "#/definitions/gym.TimeSlot"
+                }
+            }
+        },
+        "class.ClassType": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yoga"
+                }
+            }
+        },
+        "class.CreateClassTypeRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yoga"
+                }
+            }
+        },
+        "class.Instructor": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gym_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "class.InstructorRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "gym_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "gym.Closure": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "gym.CreateClosureRequest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-12-25"
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2024-12-24T18:00:00Z"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Christmas"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2024-12-24T14:00:00Z"
+                }
+            }
+        },
+        "gym.CreateClosureResponse": {
+            "type": "object",
+            "properties": {
+                "affected_slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.TimeSlot"
+                    }
+                },
+                "closure": {
+                    "$ref": "#/definitions/gym.Closure"
+                }
+            }
+        },
+        "gym.CreateGymRequest": {
+            "type": "object",
+            "required": [
+                "location",
+                "name"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "gym.CreateRoomRequest": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "name"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Studio"
+                }
+            }
+        },
+        "gym.CreateTimeSlotRequest": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "class_type_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "instructor_id": {
+                    "type": "integer"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "gym.Gym": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "gym.HourlyBusyness": {
+            "type": "object",
+            "properties": {
+                "average_headcount": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "busyness": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "hour": {
+                    "type": "integer",
+                    "example": 18
+                }
+            }
+        },
+        "gym.ImportResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "invalid_rows": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.ImportRowResult"
+                    }
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "valid_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gym.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "end_time must be after start_time"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "gym.Occupancy": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "headcount": {
+                    "type": "integer",
+                    "example": 18
+                },
+                "percent": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "source": {
+                    "type": "string",
+                    "example": "bookings"
+                },
+                "typical": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gym.HourlyBusyness"
+                    }
+                },
+                "weekday": {
+                    "type": "string",
+                    "example": "Monday"
+                },
+                "weeks": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "gym.PublicTimeSlot": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "class_type_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor_id": {
+                    "type": "integer"
+                },
+                "is_full": {
+                    "type": "boolean"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "gym.Room": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Studio"
+                }
+            }
+        },
+        "gym.SlotSearchResult": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "booked_count": {
+                    "type": "integer"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "class_type_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the gym's currency, which PriceCents is in.",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "gym_location": {
+                    "type": "string",
+                    "example": "123 Main St"
+                },
+                "gym_name": {
+                    "type": "string",
+                    "example": "Downtown Fitness"
+                },
+                "gym_timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor_id": {
+                    "type": "integer"
+                },
+                "is_full": {
+                    "type": "boolean"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "template_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gym.TimeSlot": {
+            "type": "object",
+            "properties": {
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "class_type_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor_id": {
+                    "type": "integer"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "template_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gym.TimeSlotWithAvailability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "booked_count": {
+                    "type": "integer"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "class_type_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor_id": {
+                    "type": "integer"
+                },
+                "is_full": {
+                    "type": "boolean"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "template_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.AutoTopUp": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer",
+                    "example": 1000000
+                },
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "monthly_cap_cents": {
+                    "type": "integer",
+                    "example": 3000000
+                },
+                "payment_method_id": {
+                    "type": "integer"
+                },
+                "threshold_cents": {
+                    "type": "integer",
+                    "example": 200000
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.AutoTopUpRequest": {
+            "type": "object",
+            "required": [
+                "amount_cents",
+                "monthly_cap_cents",
+                "payment_method_id"
+            ],
+            "properties": {
+                "amount_cents": {
+                    "type": "integer",
+                    "example": 1000000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "monthly_cap_cents": {
+                    "type": "integer",
+                    "example": 3000000
+                },
+                "payment_method_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "threshold_cents": {
+                    "type": "integer",
+                    "example": 200000
+                }
+            }
+        },
+        "payment.Event": {
+            "type": "object",
+            "properties": {
+                "failure_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "intent_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/payment.Status"
+                }
+            }
+        },
+        "payment.Payment": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer"
+                },
+                "auto_topup": {
+                    "type": "boolean"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_method_id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "fake"
+                },
+                "provider_payment_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payment.Status"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.PaymentMethod": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Fake card (fake_success)"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "fake"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.SavePaymentMethodRequest": {
+            "type": "object",
+            "required": [
+                "payment_method"
+            ],
+            "properties": {
+                "payment_method": {
+                    "type": "string",
+                    "example": "fake_success"
+                }
+            }
+        },
+        "payment.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed",
+                "refunded"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusSucceeded",
+                "StatusFailed",
+                "StatusRefunded"
+            ]
+        },
+        "payment.TopUpRequest": {
+            "type": "object",
+            "required": [
+                "amount_cents"
+            ],
+            "properties": {
+                "amount_cents": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "payment_method": {
+                    "description": "PaymentMethod is passed on to the provider. The fake provider takes\nfake_success (the default), fake_failure and fake_pending.",
+                    "type": "string",
+                    "example": "fake_success"
+                }
+            }
+        },
+        "payment.TopUpResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "wallet recharged"
+                },
+                "payment": {
+                    "$ref": "#/definitions/payment.Payment"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/wallet.Wallet"
+                }
+            }
+        },
+        "photo.GymProfile": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/photo.Photo"
+                    }
+                },
+                "review_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "photo.Photo": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1080
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "thumbnail_url": {
+                    "type": "string",
+                    "example": "/media/gyms/1/3f2a9c_thumb.jpg"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/media/gyms/1/3f2a9c.jpg"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1920
+                }
+            }
+        },
+        "photo.ReorderPhotosRequest": {
+            "type": "object",
+            "required": [
+                "photo_ids"
+            ],
+            "properties": {
+                "photo_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "review.CreateReviewRequest": {
+            "type": "object",
+            "required": [
+                "booking_id",
+                "rating"
+            ],
+            "properties": {
+                "booking_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Great equipment, friendly staff"
+                },
+                "rating": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "review.Review": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Great equipment, friendly staff"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "hidden_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "schedule.CreateTemplateRequest": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "duration_minutes",
+                "start_time",
+                "weekday"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 60
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "weekday": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0,
+                    "example": 1
+                }
+            }
+        },
+        "schedule.GenerateResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.Template": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "weekday": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "staff.AssignStaffRequest": {
+            "type": "object",
+            "required": [
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "front_desk"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "api.HealthResponse": {
+        "staff.StaffMember": {
             "type": "object",
             "properties": {
-                "status": {
+                "created_at": {
+                    "type": "string"
+                },
+                "gym_id": {
+                    "type": "integer"
+                },
+                "role": {
                     "type": "string",
-                    "example": "ok"
+                    "example": "front_desk"
+                },
+                "user_email": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
                 }
             }
         },
-        "api.MessageResponse": {
+        "subscription.CreateSubscriptionRequest": {
             "type": "object",
+            "required": [
+                "type"
+            ],
             "properties": {
-                "message": {
+                "gym_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "wallet_currency": {
                     "type": "string",
-                    "example": "ok"
+                    "example": "USD"
                 }
             }
         },
-        "booking.BookSlotResponse": {
+        "subscription.CreateSubscriptionResponse": {
             "type": "object",
             "properties": {
                 "amount_cents": {
-                    "type": "integer",
-                    "example": 1000
+                    "type": "integer"
                 },
-                "booking": {
-                    "$ref": "#/definitions/booking.Booking"
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
                 },
                 "paid_with": {
-                    "type": "string",
-                    "example": "wallet"
+                    "type": "string"
                 },
                 "subscription": {
                     "$ref": "#/definitions/subscription.Subscription"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/wallet.Transaction"
                 }
             }
         },
-        "booking.Booking": {
+        "subscription.Plan": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "description": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "gym_required": {
+                    "type": "boolean"
                 },
-                "status": {
+                "name": {
                     "type": "string"
                 },
-                "time_slot_id": {
+                "price_cents": {
                     "type": "integer"
                 },
-                "user_id": {
+                "type": {
+                    "type": "string"
+                },
+                "visits_limit": {
                     "type": "integer"
                 }
             }
         },
-        "booking.BookingWithDetails": {
+        "subscription.Subscription": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "gym_location": {
+                "currency": {
                     "type": "string"
                 },
-                "gym_name": {
-                    "type": "string"
+                "gym_id": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "status": {
+                "period": {
                     "type": "string"
                 },
-                "time_slot_end": {
+                "price_cents": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/subscription.SubscriptionStatus"
+                },
+                "type": {
+                    "$ref": "#/definitions/subscription.SubscriptionType"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "time_slot_id": {
+                "user_id": {
                     "type": "integer"
                 },
-                "time_slot_start": {
+                "valid_from": {
                     "type": "string"
                 },
-                "user_email": {
+                "valid_until": {
                     "type": "string"
                 },
-                "user_id": {
+                "visits_limit": {
                     "type": "integer"
                 },
-                "user_name": {
-                    "type": "string"
+                "visits_used": {
+                    "type": "integer"
                 }
             }
         },
-        "booking.CancelBookingResponse": {
+        "subscription.SubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "expired",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StatusActive",
+                "StatusExpired",
+                "StatusCanceled"
+            ]
+        },
+        "subscription.SubscriptionType": {
+            "type": "string",
+            "enum": [
+                "single_gym_lite",
+                "multi_gym_flex",
+                "unlimited_pro"
+            ],
+            "x-enum-varnames": [
+                "TypeSingleGymLite",
+                "TypeMultiGymFlex",
+                "TypeUnlimitedPro"
+            ]
+        },
+        "user.LoginRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Booking cancelled successfully"
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
                 }
             }
         },
-        "gym.CreateGymRequest": {
+        "user.LoginResponse": {
             "type": "object",
-            "required": [
-                "location",
-                "name"
-            ],
             "properties": {
-                "location": {
+                "access_token": {
                     "type": "string"
                 },
-                "name": {
+                "refresh_token": {
                     "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/user.User"
                 }
             }
         },
-        "gym.CreateTimeSlotRequest": {
+        "user.RefreshTokenRequest": {
             "type": "object",
             "required": [
-                "capacity",
-                "end_time",
-                "start_time"
+                "refresh_token"
             ],
             "properties": {
-                "capacity": {
-                    "type": "integer",
-                    "minimum": 1
-                },
-                "end_time": {
-                    "type": "string"
-                },
-                "start_time": {
+                "refresh_token": {
                     "type": "string"
                 }
             }
         },
-        "gym.Gym": {
+        "user.RefreshTokenResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "access_token": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "location": {
+                "user": {
+                    "$ref": "#/definitions/user.User"
+                }
+            }
+        },
+        "user.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
                 }
             }
         },
-        "gym.TimeSlot": {
+        "user.User": {
             "type": "object",
             "properties": {
-                "capacity": {
-                    "type": "integer"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "end_time": {
+                "email": {
                     "type": "string"
                 },
-                "gym_id": {
-                    "type": "integer"
-                },
                 "id": {
                     "type": "integer"
                 },
-                "start_time": {
+                "name": {
+                    "type": "string"
+                },
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "gym.TimeSlotWithAvailability": {
+        "wallet.AccountBalance": {
             "type": "object",
             "properties": {
-                "available": {
-                    "type": "integer"
-                },
-                "booked_count": {
-                    "type": "integer"
-                },
-                "capacity": {
+                "balance_cents": {
                     "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "end_time": {
-                    "type": "string"
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
                 },
                 "gym_id": {
                     "type": "integer"
@@ -1408,296 +7022,468 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "is_full": {
-                    "type": "boolean"
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/wallet.AccountType"
+                        }
+                    ],
+                    "example": "gym_revenue"
                 },
-                "start_time": {
-                    "type": "string"
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "subscription.CreateSubscriptionRequest": {
+        "wallet.AccountType": {
+            "type": "string",
+            "enum": [
+                "member_wallet",
+                "gym_revenue",
+                "promo_liability",
+                "refunds",
+                "cash",
+                "adjustments",
+                "fx"
+            ],
+            "x-enum-varnames": [
+                "AccountMemberWallet",
+                "AccountGymRevenue",
+                "AccountPromoLiability",
+                "AccountRefunds",
+                "AccountCash",
+                "AccountAdjustments",
+                "AccountFX"
+            ]
+        },
+        "wallet.AdjustmentRequest": {
             "type": "object",
             "required": [
+                "amount_cents",
+                "reason",
+                "reference",
                 "type"
             ],
-            "properties": {
-                "gym_id": {
-                    "type": "integer"
-                },
-                "type": {
-                    "type": "string"
-                }
-            }
-        },
-        "subscription.CreateSubscriptionResponse": {
-            "type": "object",
             "properties": {
                 "amount_cents": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 1500
                 },
-                "paid_with": {
-                    "type": "string"
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
                 },
-                "subscription": {
-                    "$ref": "#/definitions/subscription.Subscription"
+                "reason": {
+                    "type": "string",
+                    "example": "Charged twice for the 12 May class"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "SUP-1234"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "credit",
+                        "debit"
+                    ],
+                    "example": "credit"
                 }
             }
         },
-        "subscription.Plan": {
+        "wallet.ChainBreak": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "gym_required": {
-                    "type": "boolean"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "price_cents": {
+                "balance_after_cents": {
                     "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "expected_balance_cents": {
+                    "type": "integer"
                 },
-                "visits_limit": {
+                "transaction_id": {
                     "type": "integer"
                 }
             }
         },
-        "subscription.Subscription": {
+        "wallet.CreditLot": {
             "type": "object",
             "properties": {
+                "amount_cents": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "currency": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "KZT"
                 },
-                "gym_id": {
-                    "type": "integer"
+                "expires_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "period": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "example": "Welcome bonus"
                 },
-                "price_cents": {
+                "remaining_cents": {
                     "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/subscription.SubscriptionStatus"
+                "wallet_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "wallet.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
                 },
-                "type": {
-                    "$ref": "#/definitions/subscription.SubscriptionType"
+                "quote": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "470.15"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "updated_by": {
                     "type": "integer"
+                }
+            }
+        },
+        "wallet.LedgerCheck": {
+            "type": "object",
+            "properties": {
+                "ok": {
+                    "type": "boolean"
                 },
-                "valid_from": {
-                    "type": "string"
-                },
-                "valid_until": {
-                    "type": "string"
+                "totals_cents": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
                 },
-                "visits_limit": {
-                    "type": "integer"
+                "unbalanced_entries": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "visits_used": {
-                    "type": "integer"
+                "wallet_mismatches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wallet.WalletMismatch"
+                    }
                 }
             }
         },
-        "subscription.SubscriptionStatus": {
-            "type": "string",
-            "enum": [
-                "active",
-                "expired",
-                "cancelled"
-            ],
-            "x-enum-varnames": [
-                "StatusActive",
-                "StatusExpired",
-                "StatusCanceled"
-            ]
-        },
-        "subscription.SubscriptionType": {
-            "type": "string",
-            "enum": [
-                "single_gym_lite",
-                "multi_gym_flex",
-                "unlimited_pro"
-            ],
-            "x-enum-varnames": [
-                "TypeSingleGymLite",
-                "TypeMultiGymFlex",
-                "TypeUnlimitedPro"
-            ]
-        },
-        "user.LoginRequest": {
+        "wallet.PromoCreditRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "amount_cents",
+                "reason",
+                "valid_for_days"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "amount_cents": {
+                    "type": "integer",
+                    "example": 200000
                 },
-                "password": {
-                    "type": "string"
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Welcome bonus"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "WELCOME-2024"
+                },
+                "valid_for_days": {
+                    "type": "integer",
+                    "example": 30
                 }
             }
         },
-        "user.LoginResponse": {
+        "wallet.ReconcileRequest": {
             "type": "object",
+            "required": [
+                "difference_cents",
+                "reason"
+            ],
             "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "refresh_token": {
-                    "type": "string"
+                "difference_cents": {
+                    "type": "integer",
+                    "example": 5000
                 },
-                "user": {
-                    "$ref": "#/definitions/user.User"
+                "reason": {
+                    "type": "string",
+                    "example": "Balance carried over from before transactions were recorded"
                 }
             }
         },
-        "user.RefreshTokenRequest": {
+        "wallet.Reconciliation": {
             "type": "object",
-            "required": [
-                "refresh_token"
-            ],
             "properties": {
-                "refresh_token": {
+                "checked_at": {
                     "type": "string"
+                },
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wallet.WalletDiscrepancy"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                },
+                "wallets_checked": {
+                    "type": "integer"
                 }
             }
         },
-        "user.RefreshTokenResponse": {
+        "wallet.RefundRequest": {
             "type": "object",
+            "required": [
+                "reason"
+            ],
             "properties": {
-                "access_token": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "example": "Class cancelled by the gym"
                 },
-                "user": {
-                    "$ref": "#/definitions/user.User"
+                "reference": {
+                    "type": "string",
+                    "example": "SUP-1234"
                 }
             }
         },
-        "user.RegisterRequest": {
+        "wallet.SetExchangeRateRequest": {
             "type": "object",
             "required": [
-                "email",
-                "name",
-                "password"
+                "rate"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "password": {
+                "rate": {
                     "type": "string",
-                    "minLength": 6
+                    "example": "470.15"
                 }
             }
         },
-        "user.User": {
+        "wallet.Transaction": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "amount_cents": {
+                    "type": "integer"
+                },
+                "auto_topup_payment_id": {
+                    "description": "Set on a charge that started an auto top-up of the wallet.",
+                    "type": "integer"
+                },
+                "balance_after": {
+                    "type": "integer"
+                },
+                "booking_id": {
+                    "description": "What the transaction paid for or came from, a readable description\nand details such as the slot booked.",
+                    "type": "integer"
+                },
+                "counterparty_name": {
                     "type": "string"
                 },
-                "email": {
+                "counterparty_user_id": {
+                    "description": "The other member of a transfer and the sender's note.",
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "created_by": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Class booking at Downtown Gym, 20 Jan 2024 10:00"
+                },
+                "exchange_rate": {
+                    "type": "string",
+                    "example": "0.0021"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "metadata": {
+                    "type": "object"
+                },
+                "note": {
                     "type": "string"
                 },
-                "role": {
+                "original_amount_cents": {
+                    "description": "Set when the wallet paid a price in another currency: the amount in\nthat currency, signed like AmountCents, the currency and the rate of\none unit of it in the wallet's currency.",
+                    "type": "integer"
+                },
+                "original_currency": {
+                    "type": "string"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "promo_cents": {
+                    "description": "The part of the amount paid with promotional credit or, on a refund,\nreturned as promotional credit.",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Set on adjustments and refunds made by support staff.",
+                    "type": "string"
+                },
+                "reference": {
                     "type": "string"
+                },
+                "refund_of_id": {
+                    "type": "integer"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "topup, booking_payment, subscription_payment, refund, transfer_out, transfer_in, admin_adjustment, admin_refund и т.п.",
+                    "type": "string"
+                },
+                "wallet_id": {
+                    "type": "integer"
                 }
             }
         },
-        "wallet.TopUpRequest": {
+        "wallet.TransferRequest": {
             "type": "object",
             "required": [
-                "amount_cents"
+                "amount_cents",
+                "recipient_email"
             ],
             "properties": {
                 "amount_cents": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 2000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "KZT"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "For Saturday's class"
+                },
+                "recipient_email": {
+                    "type": "string",
+                    "example": "friend@example.com"
                 }
             }
         },
-        "wallet.TopUpResponse": {
+        "wallet.TransferResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "wallet recharged"
+                    "example": "transfer completed"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/wallet.Transaction"
                 },
                 "wallet": {
                     "$ref": "#/definitions/wallet.Wallet"
                 }
             }
         },
-        "wallet.Transaction": {
+        "wallet.Wallet": {
             "type": "object",
             "properties": {
-                "amount_cents": {
+                "balance_cents": {
                     "type": "integer"
                 },
-                "balance_after": {
+                "cash_cents": {
+                    "description": "The balance split into the member's own money and promotional\ncredit, with the time the first promotional credit expires.",
                     "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "type": {
-                    "description": "topup, booking_payment, subscription_payment, refund и т.п.",
+                "promo_cents": {
+                    "type": "integer"
+                },
+                "promo_expires_at": {
                     "type": "string"
                 },
-                "wallet_id": {
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "wallet.Wallet": {
+        "wallet.WalletDiscrepancy": {
             "type": "object",
             "properties": {
                 "balance_cents": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "type": "string"
+                "chain_breaks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wallet.ChainBreak"
+                    }
                 },
                 "currency": {
                     "type": "string"
                 },
-                "id": {
+                "difference_cents": {
                     "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "transactions_sum_cents": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "wallet_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "wallet.WalletMismatch": {
+            "type": "object",
+            "properties": {
+                "balance_cents": {
+                    "type": "integer"
+                },
+                "ledger_cents": {
+                    "type": "integer"
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "wallet_id": {
+                    "type": "integer"
                 }
             }
         }
@@ -3,10 +3,12 @@ package integration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -72,7 +74,7 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 	}
 
 	// Run migrations on test database
-	if err := db.RunMigrations(database, "../migrations"); err != nil {
+	if err := db.RunMigrations(database, "../migrations", "UTC"); err != nil {
 		t.Logf("Warning: Failed to run migrations: %v", err)
 	}
 
@@ -83,9 +85,19 @@ func cleanDatabase(t *testing.T, db *sqlx.DB) {
 	ctx := context.Background()
 	tables := []string{
 		"bookings",
+		"wallet_credit_lot_spends",
+		"wallet_credit_lots",
 		"wallet_transactions",
+		"payments",
+		"auto_topups",
+		"payment_methods",
+		"ledger_postings",
+		"ledger_entries",
+		"ledger_accounts",
+		"exchange_rates",
 		"subscriptions",
 		"time_slots",
+		"rooms",
 		"gyms",
 		"users",
 		"wallets",
@@ -139,24 +151,8 @@ func createTestTimeSlot(t *testing.T, db *sqlx.DB, gymID int, startTime time.Tim
 }
 
 func addWalletBalance(t *testing.T, db *sqlx.DB, userID int, amountCents int64) {
-	ctx := context.Background()
-	// Ensure wallet exists
-	_, err := db.ExecContext(ctx, `
-		INSERT INTO wallets (user_id, balance_cents, currency)
-		VALUES ($1, $2, 'KZT')
-		ON CONFLICT (user_id) DO UPDATE SET balance_cents = wallets.balance_cents + EXCLUDED.balance_cents
-	`, userID, amountCents)
-	if err != nil {
-		require.NoError(t, err)
-		return
-	}
-
-	// Insert wallet transaction
-	_, err = db.ExecContext(ctx, `
-		INSERT INTO wallet_transactions (wallet_id, amount_cents, type, balance_after, created_at)
-		SELECT id, $2, 'topup', balance_cents, NOW() FROM wallets WHERE user_id = $1
-	`, userID, amountCents)
-
+	// Top up through the repository so the ledger stays balanced
+	err := wallet.NewRepository(db).TopUp(context.Background(), userID, amountCents)
 	require.NoError(t, err)
 }
 
@@ -201,6 +197,7 @@ func TestBookingFlow(t *testing.T) {
 		walletRepo,
 		userRepo,
 		emailService,
+		nil,
 	)
 
 	handler := booking.NewHandler(bookingService)
@@ -422,6 +419,7 @@ func TestCancelBooking(t *testing.T) {
 		walletRepo,
 		userRepo,
 		emailService,
+		nil,
 	)
 
 	handler := booking.NewHandler(bookingService)
@@ -512,6 +510,230 @@ func TestCancelBooking(t *testing.T) {
 	})
 }
 
+func TestRoomCapacityUnderConcurrentBookings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cleanDatabase(t, db)
+
+	emailService := email.New("test@fitslot.com", "FitSlot", "mailhog", "1025", "", "", "localhost:6380")
+	bookingService := booking.NewService(
+		booking.NewRepository(db),
+		gym.NewRepository(db),
+		subscription.NewRepository(db),
+		wallet.NewRepository(db),
+		user.NewRepository(db),
+		emailService,
+		nil,
+	)
+
+	ctx := context.Background()
+	gymID := createTestGym(t, db, "Test Gym")
+	var roomID int
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO rooms (gym_id, name, capacity)
+		VALUES ($1, 'Studio', 3)
+		RETURNING id
+	`, gymID).Scan(&roomID)
+	require.NoError(t, err)
+
+	// Two slots share the room at the same time, each with more places
+	// than the room holds.
+	futureTime := time.Now().Add(24 * time.Hour)
+	var slotIDs []int
+	for i := 0; i < 2; i++ {
+		slotID := createTestTimeSlot(t, db, gymID, futureTime, 10)
+		_, err := db.ExecContext(ctx, `UPDATE time_slots SET room_id = $1 WHERE id = $2`, roomID, slotID)
+		require.NoError(t, err)
+		slotIDs = append(slotIDs, slotID)
+	}
+
+	const members = 10
+	userIDs := make([]int, members)
+	for i := range userIDs {
+		userIDs[i] = createTestUser(t, db, fmt.Sprintf("member%d@example.com", i), fmt.Sprintf("Member %d", i))
+		createTestSubscription(t, db, userIDs[i], gymID, nil)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		booked   int
+		roomFull int
+	)
+	for i, userID := range userIDs {
+		wg.Add(1)
+		go func(userID, slotID int) {
+			defer wg.Done()
+			_, _, _, err := bookingService.BookSlot(ctx, userID, slotID, "")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				booked++
+			case errors.Is(err, booking.ErrRoomFull):
+				roomFull++
+			default:
+				t.Errorf("unexpected booking error: %v", err)
+			}
+		}(userID, slotIDs[i%len(slotIDs)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, booked)
+	assert.Equal(t, members-3, roomFull)
+
+	var active int
+	err = db.GetContext(ctx, &active, `
+		SELECT COUNT(*) FROM bookings b
+		JOIN time_slots ts ON ts.id = b.time_slot_id
+		WHERE ts.room_id = $1 AND b.status = 'booked'
+	`, roomID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, active)
+}
+
+func TestRefundsRestorePromoCredit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cleanDatabase(t, db)
+
+	walletRepo := wallet.NewRepository(db)
+	emailService := email.New("test@fitslot.com", "FitSlot", "mailhog", "1025", "", "", "localhost:6380")
+	bookingService := booking.NewService(
+		booking.NewRepository(db),
+		gym.NewRepository(db),
+		subscription.NewRepository(db),
+		walletRepo,
+		user.NewRepository(db),
+		emailService,
+		nil,
+	)
+
+	ctx := context.Background()
+	gymID := createTestGym(t, db, "Test Gym")
+	userID := createTestUser(t, db, "member@example.com", "Member")
+	adminID := createTestUser(t, db, "support@example.com", "Support")
+
+	futureTime := time.Now().Add(24 * time.Hour)
+	var slotIDs []int
+	for i := 0; i < 2; i++ {
+		slotID := createTestTimeSlot(t, db, gymID, futureTime.Add(time.Duration(i)*2*time.Hour), 10)
+		_, err := db.ExecContext(ctx, `UPDATE time_slots SET price_cents = 100000 WHERE id = $1`, slotID)
+		require.NoError(t, err)
+		slotIDs = append(slotIDs, slotID)
+	}
+
+	// 1,000.00 of the member's own money and 1,500.00 of promotional credit.
+	addWalletBalance(t, db, userID, 100000)
+	_, err := walletRepo.GrantPromoCredit(ctx, wallet.PromoGrant{
+		UserID:      userID,
+		AmountCents: 150000,
+		Currency:    "KZT",
+		ExpiresAt:   time.Now().Add(30 * 24 * time.Hour),
+		Reason:      "Welcome bonus",
+		AdminID:     adminID,
+	})
+	require.NoError(t, err)
+
+	balance := func() (cash, promo int64) {
+		w, err := walletRepo.GetOrCreateWallet(ctx, userID, "KZT")
+		require.NoError(t, err)
+		return w.CashCents, w.PromoCents
+	}
+
+	// The first booking is paid with promotional credit only, the second
+	// with the 500.00 of credit left and 500.00 of the member's money.
+	_, _, _, err = bookingService.BookSlot(ctx, userID, slotIDs[0], "")
+	require.NoError(t, err)
+	_, _, paid, err := bookingService.BookSlot(ctx, userID, slotIDs[1], "")
+	require.NoError(t, err)
+	charge := paid.(map[string]interface{})["transaction"].(*wallet.Transaction)
+	require.NotNil(t, charge.PromoCents)
+	assert.Equal(t, int64(-50000), *charge.PromoCents)
+
+	cash, promo := balance()
+	assert.Equal(t, int64(50000), cash)
+	assert.Equal(t, int64(0), promo)
+
+	// The gym cancels the first slot: its refund is promotional credit.
+	cancelled, err := bookingService.DeleteTimeSlot(ctx, slotIDs[0], true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+
+	cash, promo = balance()
+	assert.Equal(t, int64(50000), cash)
+	assert.Equal(t, int64(100000), promo)
+
+	// Support refunds the second booking: half comes back as credit.
+	refund, err := walletRepo.RefundTransaction(ctx, wallet.TransactionRefund{
+		TransactionID: charge.ID,
+		Reason:        "Booked twice",
+		AdminID:       adminID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, refund.PromoCents)
+	assert.Equal(t, int64(50000), *refund.PromoCents)
+
+	cash, promo = balance()
+	assert.Equal(t, int64(100000), cash)
+	assert.Equal(t, int64(150000), promo)
+
+	// The booking refund points at the charge, so support cannot refund it
+	// a second time.
+	var firstCharge int
+	err = db.GetContext(ctx, &firstCharge,
+		`SELECT refund_of_id FROM wallet_transactions WHERE type = $1`, wallet.EntryRefund)
+	require.NoError(t, err)
+	_, err = walletRepo.RefundTransaction(ctx, wallet.TransactionRefund{TransactionID: firstCharge, Reason: "again", AdminID: adminID})
+	assert.ErrorIs(t, err, wallet.ErrAlreadyRefunded)
+}
+
+func TestStuckAutoTopUpDoesNotBlockTheNext(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cleanDatabase(t, db)
+
+	walletRepo := wallet.NewRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db, "member@example.com", "Member")
+	addWalletBalance(t, db, userID, 20000)
+
+	var methodID int
+	err := db.GetContext(ctx, &methodID, `
+		INSERT INTO payment_methods (user_id, provider, provider_method_id, label)
+		VALUES ($1, 'fake', 'fake_pm_stuck', 'Fake card')
+		RETURNING id
+	`, userID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO auto_topups (user_id, currency, threshold_cents, amount_cents, monthly_cap_cents, payment_method_id)
+		VALUES ($1, 'KZT', 100000, 500000, 2000000, $2)
+	`, userID, methodID)
+	require.NoError(t, err)
+
+	// An earlier top-up whose outcome never arrived.
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO payments (user_id, amount_cents, currency, status, provider, payment_method_id, auto_topup, created_at)
+		VALUES ($1, 500000, 'KZT', 'pending', 'fake', $2, TRUE, NOW() - $3 * INTERVAL '1 second')
+	`, userID, methodID, (wallet.AutoTopUpPendingTimeout + time.Minute).Seconds())
+	require.NoError(t, err)
+
+	charge := wallet.Charge{
+		UserID: userID, Kind: wallet.EntrySubscriptionCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+	}
+	var required *wallet.AutoTopUpRequired
+	_, err = walletRepo.Charge(ctx, charge)
+	require.ErrorAs(t, err, &required)
+
+	// The new top-up is recent, so it holds back another one.
+	_, err = walletRepo.Charge(ctx, charge)
+	assert.Equal(t, wallet.ErrInsufficientBalance, err)
+}
+
 func TestListMyBookings(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -534,6 +756,7 @@ func TestListMyBookings(t *testing.T) {
 		walletRepo,
 		userRepo,
 		emailService,
+		nil,
 	)
 
 	handler := booking.NewHandler(bookingService)
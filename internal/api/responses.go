@@ -11,3 +11,13 @@ type MessageResponse struct {
 type HealthResponse struct {
 	Status string `json:"status" example:"ok"`
 }
+
+// Page is the envelope of cursor-paginated listings. NextCursor is passed
+// back as the cursor query parameter to fetch the following page; it is
+// empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit" example:"20"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoic3RhcnRfdGltZSIsImsiOiIyMDI0LTA2LTAxVDA5OjAwOjAwWiIsImkiOjQyfQ"`
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuperuserRole is the global role that may manage every gym.
+const SuperuserRole = "admin"
+
+// GymRoleResolver looks up which gym a routed resource belongs to and which
+// role a user holds at that gym.
+type GymRoleResolver interface {
+	// ResolveGymID maps a route parameter such as "slotID" to its gym.
+	ResolveGymID(ctx context.Context, param string, id int) (int, error)
+	GetGymRole(ctx context.Context, userID, gymID int) (string, error)
+}
+
+// RequireGymRole authorizes a request against the gym the addressed resource
+// belongs to. The gym comes from the :gymID parameter or, failing that, from
+// the first other :xxxID parameter resolved through resolver. Global admins
+// pass unconditionally; everyone else needs one of roles at that gym.
+func RequireGymRole(resolver GymRoleResolver, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role == SuperuserRole {
+			c.Next()
+			return
+		}
+
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		gymID, ok := resolveGymID(c, resolver)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		gymRole, err := resolver.GetGymRole(c.Request.Context(), userID, gymID)
+		if err != nil || !containsRole(roles, gymRole) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Set("gym_id", gymID)
+		c.Set("gym_role", gymRole)
+
+		c.Next()
+	}
+}
+
+func resolveGymID(c *gin.Context, resolver GymRoleResolver) (int, bool) {
+	if v := c.Param("gymID"); v != "" {
+		gymID, err := strconv.Atoi(v)
+		return gymID, err == nil
+	}
+
+	for _, p := range c.Params {
+		id, err := strconv.Atoi(p.Value)
+		if err != nil {
+			return 0, false
+		}
+		gymID, err := resolver.ResolveGymID(c.Request.Context(), p.Key, id)
+		return gymID, err == nil
+	}
+
+	return 0, false
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
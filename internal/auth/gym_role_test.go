@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResolver struct {
+	slotGyms map[int]int
+	roles    map[[2]int]string
+}
+
+func (f fakeResolver) ResolveGymID(ctx context.Context, param string, id int) (int, error) {
+	if gymID, ok := f.slotGyms[id]; ok && param == "slotID" {
+		return gymID, nil
+	}
+	return 0, errors.New("not found")
+}
+
+func (f fakeResolver) GetGymRole(ctx context.Context, userID, gymID int) (string, error) {
+	if role, ok := f.roles[[2]int{userID, gymID}]; ok {
+		return role, nil
+	}
+	return "", errors.New("no role")
+}
+
+func TestRequireGymRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver := fakeResolver{
+		slotGyms: map[int]int{10: 1, 20: 2},
+		roles: map[[2]int]string{
+			{5, 1}: "manager",
+			{6, 1}: "front_desk",
+		},
+	}
+
+	tests := []struct {
+		name           string
+		role           string
+		userID         int
+		path           string
+		expectedStatus int
+	}{
+		{"Admin passes everywhere", "admin", 1, "/admin/gyms/2/slots", http.StatusOK},
+		{"Manager of the gym", "member", 5, "/admin/gyms/1/slots", http.StatusOK},
+		{"Manager of another gym", "member", 5, "/admin/gyms/2/slots", http.StatusForbidden},
+		{"Role not allowed", "member", 6, "/admin/gyms/1/slots", http.StatusForbidden},
+		{"Slot resolved to managed gym", "member", 5, "/admin/slots/10", http.StatusOK},
+		{"Slot of another gym", "member", 5, "/admin/slots/20", http.StatusForbidden},
+		{"Unknown slot", "member", 5, "/admin/slots/99", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("user_role", tt.role)
+			})
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/admin/gyms/:gymID/slots", RequireGymRole(resolver, "manager"), ok)
+			router.GET("/admin/slots/:slotID", RequireGymRole(resolver, "manager"), ok)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
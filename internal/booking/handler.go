@@ -1,14 +1,17 @@
 package booking
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"fitslot/internal/api"
 	"fitslot/internal/auth"
+	"fitslot/internal/gym"
 	"fitslot/internal/logger"
 	"fitslot/internal/metrics"
 	"fitslot/internal/subscription"
+	"fitslot/internal/wallet"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,17 +27,19 @@ func NewHandler(service Service) *Handler {
 }
 
 // @Summary      Book a time slot
-// @Description  Create a booking for the current user (paid with wallet or subscription)
+// @Description  Create a booking for the current user, paid with an active subscription or from the wallet in the gym's currency. Name another wallet_currency to pay from that wallet instead; the price is converted at the stored exchange rate.
 // @Tags         bookings
 // @Produce      json
 // @Security     BearerAuth
 // @Param        slotID path int true "Time slot ID"
+// @Param        wallet_currency query string false "Pay from the wallet in this currency" example(USD)
 // @Success      201 {object} BookSlotResponse
 // @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      402 {object} api.ErrorResponse
 // @Failure      404 {object} api.ErrorResponse
 // @Failure      409 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /slots/{slotID}/book [post]
 func (h *Handler) BookSlot(c *gin.Context) {
@@ -55,7 +60,7 @@ func (h *Handler) BookSlot(c *gin.Context) {
 	logger.Infof("User %d booking slot %d", userID, slotID)
 
 	ctx := c.Request.Context()
-	booking, paymentMethod, paymentDetails, err := h.service.BookSlot(ctx, userID, slotID)
+	booking, paymentMethod, paymentDetails, err := h.service.BookSlot(ctx, userID, slotID, c.Query("wallet_currency"))
 	if err != nil {
 		switch err.Error() {
 		case "time slot not found":
@@ -64,10 +69,18 @@ func (h *Handler) BookSlot(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Cannot book a slot in the past"})
 		case "time slot is full":
 			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "Time slot is full"})
+		case ErrRoomFull.Error():
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "The room is at full capacity for this time"})
+		case ErrSlotClosed.Error():
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "The gym is closed during this time slot"})
 		case "user already has a booking for this slot":
 			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "You already have a booking for this slot"})
 		case "insufficient wallet balance":
 			c.JSON(http.StatusPaymentRequired, api.ErrorResponse{Error: "insufficient wallet balance"})
+		case ErrCurrencyInvalid.Error():
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Unsupported wallet currency"})
+		case ErrNoExchangeRate.Error():
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: "Paying from this wallet currency is not available"})
 		default:
 			logger.Errorf("Failed to create booking for user %d, slot %d: %v", userID, slotID, err)
 			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create booking"})
@@ -82,11 +95,11 @@ func (h *Handler) BookSlot(c *gin.Context) {
 	resp.Booking = booking
 	resp.PaidWith = paymentMethod
 
-	if paymentMethod == "subscription" {
+	if paymentMethod == PaidWithSubscription {
 		if sub, ok := paymentDetails.(*subscription.Subscription); ok {
 			resp.Subscription = sub
 		}
-	} else if paymentMethod == "wallet" {
+	} else if paymentMethod == PaidWithWallet {
 		if details, ok := paymentDetails.(map[string]interface{}); ok {
 			switch v := details["amount_cents"].(type) {
 			case int64:
@@ -98,6 +111,12 @@ func (h *Handler) BookSlot(c *gin.Context) {
 				vv := int64(v)
 				resp.AmountCents = &vv
 			}
+			if currency, ok := details["currency"].(string); ok {
+				resp.Currency = currency
+			}
+			if txn, ok := details["transaction"].(*wallet.Transaction); ok {
+				resp.Transaction = txn
+			}
 		}
 	}
 
@@ -238,3 +257,141 @@ func (h *Handler) ListBookingsByGym(c *gin.Context) {
 
 	c.JSON(http.StatusOK, bookings)
 }
+
+// @Summary      Update a time slot (admin)
+// @Description  Change slot times and/or capacity. Members with bookings are emailed about time changes. Lowering capacity below the active bookings requires overflow_policy=cancel_latest, which cancels and refunds the most recent bookings.
+// @Tags         admin,gyms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slotID path int true "Time slot ID"
+// @Param        request body booking.UpdateSlotRequest true "Fields to change"
+// @Success      200 {object} booking.UpdateSlotResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/slots/{slotID} [patch]
+func (h *Handler) UpdateTimeSlot(c *gin.Context) {
+	slotIDStr := c.Param("slotID")
+	slotID, err := strconv.Atoi(slotIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid slot ID"})
+		return
+	}
+
+	var req UpdateSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.service.UpdateTimeSlot(ctx, slotID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTimeSlotNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Time slot not found"})
+		case errors.Is(err, gym.ErrTimeSlotInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInvalidOverflowPolicy):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "overflow_policy must be reject or cancel_latest"})
+		case errors.Is(err, ErrCapacityBelowBookings):
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "Capacity is below the number of active bookings"})
+		default:
+			logger.Errorf("Failed to update slot %d: %v", slotID, err)
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to update time slot"})
+		}
+		return
+	}
+
+	logger.Infof("Slot %d updated: cancelled=%d, notified=%d", slotID, resp.CancelledBookings, resp.NotifiedMembers)
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Delete a time slot (admin)
+// @Description  Remove a slot from the schedule. If it has active bookings the request is rejected unless cancel_bookings=true, in which case every booking is cancelled, refunded and the member is notified.
+// @Tags         admin,gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        slotID path int true "Time slot ID"
+// @Param        cancel_bookings query bool false "Cancel and refund active bookings"
+// @Success      200 {object} booking.DeleteSlotResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/slots/{slotID} [delete]
+func (h *Handler) DeleteTimeSlot(c *gin.Context) {
+	slotIDStr := c.Param("slotID")
+	slotID, err := strconv.Atoi(slotIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid slot ID"})
+		return
+	}
+
+	cancelBookings := c.Query("cancel_bookings") == "true"
+
+	ctx := c.Request.Context()
+	cancelled, err := h.service.DeleteTimeSlot(ctx, slotID, cancelBookings)
+	if err != nil {
+		switch err {
+		case ErrTimeSlotNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Time slot not found"})
+		case ErrSlotHasBookings:
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "Time slot has active bookings; pass cancel_bookings=true to cancel and refund them"})
+		default:
+			logger.Errorf("Failed to delete slot %d: %v", slotID, err)
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to delete time slot"})
+		}
+		return
+	}
+
+	logger.Infof("Slot %d deleted, %d bookings cancelled", slotID, cancelled)
+	c.JSON(http.StatusOK, DeleteSlotResponse{
+		Message:           "Time slot deleted",
+		CancelledBookings: cancelled,
+	})
+}
+
+// @Summary      Cancel slots inside a closure (admin)
+// @Description  Cancel every time slot that falls into a gym closure. Active bookings are cancelled, refunded and the members are notified. Each slot is cancelled with its bookings or not at all; slots that fail are listed in failed_slot_ids and are retried by calling again.
+// @Tags         admin,gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        closureID path int true "Closure ID"
+// @Success      200 {object} booking.CancelClosureSlotsResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/closures/{closureID}/cancel-slots [post]
+func (h *Handler) CancelClosureSlots(c *gin.Context) {
+	closureIDStr := c.Param("closureID")
+	closureID, err := strconv.Atoi(closureIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid closure ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.service.CancelClosureSlots(ctx, closureID)
+	if err != nil {
+		switch err {
+		case gym.ErrClosureNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Closure not found"})
+		default:
+			logger.Errorf("Failed to cancel slots for closure %d: %v", closureID, err)
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to cancel slots"})
+		}
+		return
+	}
+
+	logger.Infof("Closure %d: %d slots and %d bookings cancelled, %d slots failed", closureID, resp.CancelledSlots, resp.CancelledBookings, len(resp.FailedSlotIDs))
+	c.JSON(http.StatusOK, resp)
+}
@@ -3,34 +3,126 @@ package booking
 import (
 	"time"
 
+	"fitslot/internal/gym"
 	"fitslot/internal/subscription"
+	"fitslot/internal/wallet"
+)
+
+const (
+	PaidWithWallet       = "wallet"
+	PaidWithSubscription = "subscription"
 )
 
 type Booking struct {
-	ID         int       `db:"id" json:"id"`
-	UserID     int       `db:"user_id" json:"user_id"`
-	TimeSlotID int       `db:"time_slot_id" json:"time_slot_id"`
-	Status     string    `db:"status" json:"status"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	ID             int       `db:"id" json:"id"`
+	UserID         int       `db:"user_id" json:"user_id"`
+	TimeSlotID     int       `db:"time_slot_id" json:"time_slot_id"`
+	Status         string    `db:"status" json:"status"`
+	PaidWith       *string   `db:"paid_with" json:"paid_with,omitempty"`
+	AmountCents    int64     `db:"amount_cents" json:"amount_cents"`
+	Currency       string    `db:"currency" json:"currency" example:"KZT"`
+	SubscriptionID *int      `db:"subscription_id" json:"subscription_id,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// Payment records how a booking was paid so it can be refunded later.
+// AmountCents is what left the wallet, in its Currency, which differs from
+// the gym's currency when the member paid with conversion.
+type Payment struct {
+	Method         string
+	AmountCents    int64
+	Currency       string
+	SubscriptionID *int
 }
 
 type BookingWithDetails struct {
 	Booking
-	TimeSlotStart time.Time `db:"time_slot_start" json:"time_slot_start"`
-	TimeSlotEnd   time.Time `db:"time_slot_end" json:"time_slot_end"`
-	GymName       string    `db:"gym_name" json:"gym_name"`
-	GymLocation   string    `db:"gym_location" json:"gym_location"`
-	UserName      string    `db:"user_name" json:"user_name"`
-	UserEmail     string    `db:"user_email" json:"user_email"`
+	TimeSlotStart  time.Time `db:"time_slot_start" json:"time_slot_start"`
+	TimeSlotEnd    time.Time `db:"time_slot_end" json:"time_slot_end"`
+	GymID          int       `db:"gym_id" json:"gym_id"`
+	GymName        string    `db:"gym_name" json:"gym_name"`
+	GymLocation    string    `db:"gym_location" json:"gym_location"`
+	GymTimezone    string    `db:"gym_timezone" json:"gym_timezone" example:"Europe/Berlin"`
+	UserName       string    `db:"user_name" json:"user_name"`
+	UserEmail      string    `db:"user_email" json:"user_email"`
+	ClassTypeName  *string   `db:"class_type_name" json:"class_type_name,omitempty" example:"Yoga"`
+	InstructorName *string   `db:"instructor_name" json:"instructor_name,omitempty"`
+}
+
+// inGymZone returns the booking with its slot times in the gym's time zone.
+func (b BookingWithDetails) inGymZone() BookingWithDetails {
+	loc := gym.LoadZone(b.GymTimezone)
+	b.TimeSlotStart = b.TimeSlotStart.In(loc)
+	b.TimeSlotEnd = b.TimeSlotEnd.In(loc)
+	return b
+}
+
+// SlotType names what was booked for emails: the class type, or a plain
+// gym slot for open-gym time.
+func (b BookingWithDetails) SlotType() string {
+	if b.ClassTypeName != nil {
+		return *b.ClassTypeName
+	}
+	return "Gym Slot"
+}
+
+// Summary describes the booking in one line, e.g. "Yoga with Anna at
+// Downtown Gym (123 Main St)".
+func (b BookingWithDetails) Summary() string {
+	summary := b.SlotType()
+	if b.InstructorName != nil {
+		summary += " with " + *b.InstructorName
+	}
+	return summary + " at " + b.GymName + " (" + b.GymLocation + ")"
 }
 
+// BookSlotResponse carries, for wallet payments, what was taken from the
+// wallet and the wallet transaction, which records the conversion when the
+// member paid from a wallet in another currency than the gym's.
 type BookSlotResponse struct {
-	Booking      *Booking                    `json:"booking"`
-	PaidWith     string                      `json:"paid_with" example:"wallet"`
-	AmountCents  *int64                      `json:"amount_cents,omitempty" example:"1000"`
-	Subscription *subscription.Subscription  `json:"subscription,omitempty"`
+	Booking      *Booking                   `json:"booking"`
+	PaidWith     string                     `json:"paid_with" example:"wallet"`
+	AmountCents  *int64                     `json:"amount_cents,omitempty" example:"1000"`
+	Currency     string                     `json:"currency,omitempty" example:"KZT"`
+	Transaction  *wallet.Transaction        `json:"transaction,omitempty"`
+	Subscription *subscription.Subscription `json:"subscription,omitempty"`
 }
 
 type CancelBookingResponse struct {
 	Message string `json:"message" example:"Booking cancelled successfully"`
 }
+
+const (
+	// OverflowReject refuses a capacity change that would strand bookings.
+	OverflowReject = "reject"
+	// OverflowCancelLatest cancels and refunds the most recent bookings
+	// that no longer fit.
+	OverflowCancelLatest = "cancel_latest"
+)
+
+type UpdateSlotRequest struct {
+	StartTime      *string `json:"start_time,omitempty" example:"2024-01-20T10:00:00Z"`
+	EndTime        *string `json:"end_time,omitempty" example:"2024-01-20T11:00:00Z"`
+	Capacity       *int    `json:"capacity,omitempty" example:"15"`
+	OverflowPolicy string  `json:"overflow_policy,omitempty" example:"reject" enums:"reject,cancel_latest"`
+}
+
+type UpdateSlotResponse struct {
+	Slot              *gym.TimeSlot `json:"slot"`
+	CancelledBookings int           `json:"cancelled_bookings"`
+	NotifiedMembers   int           `json:"notified_members"`
+}
+
+type DeleteSlotResponse struct {
+	Message           string `json:"message" example:"Time slot deleted"`
+	CancelledBookings int    `json:"cancelled_bookings"`
+}
+
+type CancelClosureSlotsResponse struct {
+	ClosureID         int `json:"closure_id"`
+	CancelledSlots    int `json:"cancelled_slots"`
+	CancelledBookings int `json:"cancelled_bookings"`
+	// FailedSlotIDs are the slots left as they were, bookings included.
+	// Calling the endpoint again retries them.
+	FailedSlotIDs []int `json:"failed_slot_ids,omitempty"`
+}
@@ -3,6 +3,12 @@ package booking
 import (
 	"context"
 	"errors"
+	"time"
+
+	"fitslot/internal/gym"
+	"fitslot/internal/money"
+	"fitslot/internal/subscription"
+	"fitslot/internal/wallet"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -17,15 +23,60 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) CreateBooking(ctx context.Context, userID, timeSlotID int) (*Booking, error) {
+func (r *repository) CreateBooking(ctx context.Context, userID, timeSlotID int, payment Payment) (*Booking, error) {
+	return createBooking(ctx, r.db, userID, timeSlotID, payment)
+}
+
+// CreateBookingInRoom creates the booking unless the room is already full
+// at [startTime, endTime). The room row stays locked until the booking is
+// written, so bookings into the same room are made one at a time and two
+// of them cannot both take the last place.
+func (r *repository) CreateBookingInRoom(ctx context.Context, roomID int, startTime, endTime time.Time, userID, timeSlotID int, payment Payment) (*Booking, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var capacity int
+	err = tx.GetContext(ctx, &capacity, `SELECT capacity FROM rooms WHERE id = $1 FOR UPDATE`, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	occupied, err := countActiveBookingsInRoom(ctx, tx, roomID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if occupied >= capacity {
+		return nil, ErrRoomFull
+	}
+
+	booking, err := createBooking(ctx, tx, userID, timeSlotID, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+func createBooking(ctx context.Context, q sqlx.QueryerContext, userID, timeSlotID int, payment Payment) (*Booking, error) {
 	query := `
-		INSERT INTO bookings (user_id, time_slot_id, status)
-		VALUES ($1, $2, 'booked')
-		RETURNING id, user_id, time_slot_id, status, created_at
+		INSERT INTO bookings (user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id)
+		VALUES ($1, $2, 'booked', $3, $4, $5, $6)
+		RETURNING id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at
 	`
 
+	currency := payment.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
 	var booking Booking
-	err := r.db.GetContext(ctx, &booking, query, userID, timeSlotID)
+	err := sqlx.GetContext(ctx, q, &booking, query, userID, timeSlotID, payment.Method, payment.AmountCents, currency, payment.SubscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +86,7 @@ func (r *repository) CreateBooking(ctx context.Context, userID, timeSlotID int)
 
 func (r *repository) GetBookingByID(ctx context.Context, id int) (*Booking, error) {
 	query := `
-		SELECT id, user_id, time_slot_id, status, created_at
+		SELECT id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at
 		FROM bookings
 		WHERE id = $1
 	`
@@ -50,13 +101,17 @@ func (r *repository) GetBookingByID(ctx context.Context, id int) (*Booking, erro
 }
 
 func (r *repository) CancelBooking(ctx context.Context, id int) error {
+	return cancelBooking(ctx, r.db, id)
+}
+
+func cancelBooking(ctx context.Context, e sqlx.ExecerContext, id int) error {
 	query := `
 		UPDATE bookings
 		SET status = 'cancelled'
 		WHERE id = $1 AND status = 'booked'
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := e.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -89,6 +144,33 @@ func (r *repository) CountActiveBookingsForSlot(ctx context.Context, timeSlotID
 	return count, nil
 }
 
+// CountActiveBookingsInRoom counts active bookings across every live slot in
+// the room that overlaps [startTime, endTime). Summing all overlapping slots
+// is deliberately conservative: it never lets the room exceed its limit.
+func (r *repository) CountActiveBookingsInRoom(ctx context.Context, roomID int, startTime, endTime time.Time) (int, error) {
+	return countActiveBookingsInRoom(ctx, r.db, roomID, startTime, endTime)
+}
+
+func countActiveBookingsInRoom(ctx context.Context, q sqlx.QueryerContext, roomID int, startTime, endTime time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM bookings b
+		JOIN time_slots ts ON b.time_slot_id = ts.id
+		WHERE ts.room_id = $1
+		AND ts.cancelled_at IS NULL
+		AND ts.start_time < $3 AND ts.end_time > $2
+		AND b.status = 'booked'
+	`
+
+	var count int
+	err := sqlx.GetContext(ctx, q, &count, query, roomID, startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *repository) UserHasBookingForSlot(ctx context.Context, userID, timeSlotID int) (bool, error) {
 	query := `
 		SELECT EXISTS(
@@ -108,7 +190,7 @@ func (r *repository) UserHasBookingForSlot(ctx context.Context, userID, timeSlot
 
 func (r *repository) GetUserBookings(ctx context.Context, userID int) ([]Booking, error) {
 	query := `
-		SELECT id, user_id, time_slot_id, status, created_at
+		SELECT id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at
 		FROM bookings
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -123,24 +205,53 @@ func (r *repository) GetUserBookings(ctx context.Context, userID int) ([]Booking
 	return bookings, nil
 }
 
+// bookingDetailsSelect joins a booking with its slot, gym, member and, for
+// classes, the class type and instructor.
+const bookingDetailsSelect = `
+	SELECT
+		b.id,
+		b.user_id,
+		b.time_slot_id,
+		b.status,
+		b.paid_with,
+		b.amount_cents,
+		b.currency,
+		b.subscription_id,
+		b.created_at,
+		ts.start_time AS time_slot_start,
+		ts.end_time AS time_slot_end,
+		ts.gym_id,
+		g.name AS gym_name,
+		g.location AS gym_location,
+		g.timezone AS gym_timezone,
+		u.name AS user_name,
+		u.email AS user_email,
+		ct.name AS class_type_name,
+		i.name AS instructor_name
+	FROM bookings b
+	JOIN time_slots ts ON b.time_slot_id = ts.id
+	JOIN gyms g ON ts.gym_id = g.id
+	JOIN users u ON b.user_id = u.id
+	LEFT JOIN class_types ct ON ts.class_type_id = ct.id
+	LEFT JOIN instructors i ON ts.instructor_id = i.id
+`
+
+func (r *repository) GetBookingWithDetails(ctx context.Context, id int) (*BookingWithDetails, error) {
+	query := bookingDetailsSelect + `
+		WHERE b.id = $1
+	`
+
+	var booking BookingWithDetails
+	err := r.db.GetContext(ctx, &booking, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
 func (r *repository) GetBookingsByTimeSlot(ctx context.Context, timeSlotID int) ([]BookingWithDetails, error) {
-	query := `
-		SELECT 
-			b.id,
-			b.user_id,
-			b.time_slot_id,
-			b.status,
-			b.created_at,
-			ts.start_time AS time_slot_start,
-			ts.end_time AS time_slot_end,
-			g.name AS gym_name,
-			g.location AS gym_location,
-			u.name AS user_name,
-			u.email AS user_email
-		FROM bookings b
-		JOIN time_slots ts ON b.time_slot_id = ts.id
-		JOIN gyms g ON ts.gym_id = g.id
-		JOIN users u ON b.user_id = u.id
+	query := bookingDetailsSelect + `
 		WHERE b.time_slot_id = $1
 		ORDER BY b.created_at DESC
 	`
@@ -155,23 +266,7 @@ func (r *repository) GetBookingsByTimeSlot(ctx context.Context, timeSlotID int)
 }
 
 func (r *repository) GetBookingsByGym(ctx context.Context, gymID int) ([]BookingWithDetails, error) {
-	query := `
-		SELECT 
-			b.id,
-			b.user_id,
-			b.time_slot_id,
-			b.status,
-			b.created_at,
-			ts.start_time AS time_slot_start,
-			ts.end_time AS time_slot_end,
-			g.name AS gym_name,
-			g.location AS gym_location,
-			u.name AS user_name,
-			u.email AS user_email
-		FROM bookings b
-		JOIN time_slots ts ON b.time_slot_id = ts.id
-		JOIN gyms g ON ts.gym_id = g.id
-		JOIN users u ON b.user_id = u.id
+	query := bookingDetailsSelect + `
 		WHERE g.id = $1
 		ORDER BY ts.start_time DESC, b.created_at DESC
 	`
@@ -184,3 +279,72 @@ func (r *repository) GetBookingsByGym(ctx context.Context, gymID int) ([]Booking
 
 	return bookings, nil
 }
+
+// UpdateSlotCancellingBookings gives the slot new times and capacity and
+// cancels and refunds the bookings in cancel, all in one transaction: when
+// any of it fails, the slot and every booking stay as they were.
+func (r *repository) UpdateSlotCancellingBookings(ctx context.Context, slotID int, startTime, endTime time.Time, capacity int, cancel []BookingWithDetails) (*gym.TimeSlot, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	slot, err := gym.UpdateTimeSlotTx(ctx, tx, slotID, startTime, endTime, capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cancelAndRefund(ctx, tx, cancel); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+// CancelSlotWithBookings cancels the slot together with the bookings in
+// cancel, refunding them, in one transaction.
+func (r *repository) CancelSlotWithBookings(ctx context.Context, slotID int, cancel []BookingWithDetails) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := gym.CancelTimeSlotTx(ctx, tx, slotID); err != nil {
+		return err
+	}
+
+	if err := cancelAndRefund(ctx, tx, cancel); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// cancelAndRefund cancels the bookings on behalf of the gym and gives back
+// what was paid for them: the visit of a subscription, or the amount taken
+// from the wallet. A booking that is no longer active fails the whole
+// transaction, so nothing is refunded twice.
+func cancelAndRefund(ctx context.Context, tx *sqlx.Tx, bookings []BookingWithDetails) error {
+	for _, b := range bookings {
+		if err := cancelBooking(ctx, tx, b.ID); err != nil {
+			return err
+		}
+
+		var err error
+		switch {
+		case b.PaidWith != nil && *b.PaidWith == PaidWithSubscription && b.SubscriptionID != nil:
+			err = subscription.DecrementVisitsTx(ctx, tx, *b.SubscriptionID)
+		case b.AmountCents > 0:
+			err = wallet.RefundBookingTx(ctx, tx, b.ID, b.UserID, b.AmountCents, b.Currency, &b.GymID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
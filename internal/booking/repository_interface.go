@@ -1,14 +1,24 @@
 package booking
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"fitslot/internal/gym"
+)
 
 type Repository interface {
-	CreateBooking(ctx context.Context, userID, timeSlotID int) (*Booking, error)
+	CreateBooking(ctx context.Context, userID, timeSlotID int, payment Payment) (*Booking, error)
+	CreateBookingInRoom(ctx context.Context, roomID int, startTime, endTime time.Time, userID, timeSlotID int, payment Payment) (*Booking, error)
 	GetBookingByID(ctx context.Context, id int) (*Booking, error)
 	CancelBooking(ctx context.Context, id int) error
 	CountActiveBookingsForSlot(ctx context.Context, timeSlotID int) (int, error)
+	CountActiveBookingsInRoom(ctx context.Context, roomID int, startTime, endTime time.Time) (int, error)
 	UserHasBookingForSlot(ctx context.Context, userID, timeSlotID int) (bool, error)
 	GetUserBookings(ctx context.Context, userID int) ([]Booking, error)
+	GetBookingWithDetails(ctx context.Context, id int) (*BookingWithDetails, error)
 	GetBookingsByTimeSlot(ctx context.Context, timeSlotID int) ([]BookingWithDetails, error)
 	GetBookingsByGym(ctx context.Context, gymID int) ([]BookingWithDetails, error)
+	UpdateSlotCancellingBookings(ctx context.Context, slotID int, startTime, endTime time.Time, capacity int, cancel []BookingWithDetails) (*gym.TimeSlot, error)
+	CancelSlotWithBookings(ctx context.Context, slotID int, cancel []BookingWithDetails) error
 }
@@ -33,16 +33,17 @@ func TestCreateAndGetBooking(t *testing.T) {
 	now := time.Now()
 
 	// Expect INSERT ... RETURNING
-	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO bookings (user_id, time_slot_id, status) VALUES ($1, $2, 'booked') RETURNING id, user_id, time_slot_id, status, created_at")).
-		WithArgs(1, 2).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "created_at"}).AddRow(10, 1, 2, "booked", now))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO bookings (user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id) VALUES ($1, $2, 'booked', $3, $4, $5, $6) RETURNING id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at")).
+		WithArgs(1, 2, PaidWithWallet, int64(1000), "KZT", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "paid_with", "amount_cents", "currency", "subscription_id", "created_at"}).AddRow(10, 1, 2, "booked", PaidWithWallet, 1000, "KZT", nil, now))
 
-	b, err := repo.CreateBooking(ctx, 1, 2)
+	b, err := repo.CreateBooking(ctx, 1, 2, Payment{Method: PaidWithWallet, AmountCents: 1000})
 	require.NoError(t, err)
 	require.Equal(t, 10, b.ID)
+	require.Equal(t, int64(1000), b.AmountCents)
 
 	// Expect SELECT by id
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, time_slot_id, status, created_at FROM bookings WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at FROM bookings WHERE id = $1")).
 		WithArgs(10).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "created_at"}).AddRow(10, 1, 2, "booked", now))
 
@@ -51,6 +52,47 @@ func TestCreateAndGetBooking(t *testing.T) {
 	require.Equal(t, 10, got.ID)
 }
 
+func TestCreateBookingInRoom(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 12, 20, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	lockRoom := regexp.QuoteMeta("SELECT capacity FROM rooms WHERE id = $1 FOR UPDATE")
+	countRoom := regexp.QuoteMeta("SELECT COUNT(*) FROM bookings b JOIN time_slots ts ON b.time_slot_id = ts.id WHERE ts.room_id = $1")
+
+	t.Run("books while the room has a place", func(t *testing.T) {
+		repo, mock, close := setupMock(t)
+		defer close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockRoom).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(20))
+		mock.ExpectQuery(countRoom).WithArgs(4, start, end).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(19))
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO bookings")).
+			WithArgs(1, 2, PaidWithSubscription, int64(0), "KZT", 7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status"}).AddRow(10, 1, 2, "booked"))
+		mock.ExpectCommit()
+
+		subID := 7
+		b, err := repo.CreateBookingInRoom(ctx, 4, start, end, 1, 2, Payment{Method: PaidWithSubscription, SubscriptionID: &subID})
+		require.NoError(t, err)
+		require.Equal(t, 10, b.ID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("refuses a full room without booking", func(t *testing.T) {
+		repo, mock, close := setupMock(t)
+		defer close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockRoom).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(20))
+		mock.ExpectQuery(countRoom).WithArgs(4, start, end).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(20))
+		mock.ExpectRollback()
+
+		_, err := repo.CreateBookingInRoom(ctx, 4, start, end, 1, 2, Payment{Method: PaidWithWallet, AmountCents: 1000})
+		require.ErrorIs(t, err, ErrRoomFull)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestCancelBooking(t *testing.T) {
 	repo, mock, close := setupMock(t)
 	defer close()
@@ -75,6 +117,51 @@ func TestCancelBooking(t *testing.T) {
 	require.Equal(t, ErrBookingNotFoundOrAlreadyCancelled, err)
 }
 
+func TestCancelSlotWithBookings(t *testing.T) {
+	ctx := context.Background()
+	subPaid := PaidWithSubscription
+	subID := 7
+	bookings := []BookingWithDetails{
+		{Booking: Booking{ID: 20, UserID: 4, Status: "booked", PaidWith: &subPaid, SubscriptionID: &subID}},
+		{Booking: Booking{ID: 21, UserID: 5, Status: "booked", PaidWith: &subPaid, SubscriptionID: &subID}},
+	}
+	cancelSlot := regexp.QuoteMeta("UPDATE time_slots SET cancelled_at = NOW() WHERE id = $1 AND cancelled_at IS NULL")
+	cancelBooking := regexp.QuoteMeta("UPDATE bookings SET status = 'cancelled' WHERE id = $1 AND status = 'booked'")
+	returnVisit := regexp.QuoteMeta("UPDATE subscriptions SET visits_used = GREATEST(visits_used - 1, 0), updated_at = NOW() WHERE id = $1")
+
+	t.Run("commits the slot and every booking together", func(t *testing.T) {
+		repo, mock, close := setupMock(t)
+		defer close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(cancelSlot).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		for _, b := range bookings {
+			mock.ExpectExec(cancelBooking).WithArgs(b.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(returnVisit).WithArgs(subID).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.CancelSlotWithBookings(ctx, 1, bookings))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls everything back when a booking fails", func(t *testing.T) {
+		repo, mock, close := setupMock(t)
+		defer close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(cancelSlot).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(cancelBooking).WithArgs(20).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(returnVisit).WithArgs(subID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(cancelBooking).WithArgs(21).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.CancelSlotWithBookings(ctx, 1, bookings)
+		require.ErrorIs(t, err, ErrBookingNotFoundOrAlreadyCancelled)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestCountsAndExists(t *testing.T) {
 	repo, mock, close := setupMock(t)
 	defer close()
@@ -90,6 +177,17 @@ func TestCountsAndExists(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, cnt)
 
+	// CountActiveBookingsInRoom
+	start := time.Date(2024, 12, 20, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM bookings b JOIN time_slots ts ON b.time_slot_id = ts.id WHERE ts.room_id = $1 AND ts.cancelled_at IS NULL AND ts.start_time < $3 AND ts.end_time > $2 AND b.status = 'booked'")).
+		WithArgs(4, start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(18))
+
+	cnt, err = repo.CountActiveBookingsInRoom(ctx, 4, start, end)
+	require.NoError(t, err)
+	require.Equal(t, 18, cnt)
+
 	// UserHasBookingForSlot true
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS( SELECT 1 FROM bookings WHERE user_id = $1 AND time_slot_id = $2 AND status = 'booked' )")).
 		WithArgs(1, 3).
@@ -112,7 +210,7 @@ func TestGetUserBookingsAndByTimeSlot(t *testing.T) {
 		AddRow(1, 1, 10, "booked", now).
 		AddRow(2, 1, 11, "booked", now.Add(-time.Hour))
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, time_slot_id, status, created_at FROM bookings WHERE user_id = $1 ORDER BY created_at DESC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, time_slot_id, status, paid_with, amount_cents, currency, subscription_id, created_at FROM bookings WHERE user_id = $1 ORDER BY created_at DESC")).
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -121,10 +219,10 @@ func TestGetUserBookingsAndByTimeSlot(t *testing.T) {
 	require.Len(t, list, 2)
 
 	// GetBookingsByTimeSlot — return only booking columns (extra fields will be zero-valued)
-	rows2 := sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "created_at", "time_slot_start", "time_slot_end", "gym_name", "gym_location", "user_name", "user_email"}).
-		AddRow(1, 1, 10, "booked", now, now, now.Add(time.Hour), "Gym A", "Location A", "User", "user@example.com")
+	rows2 := sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "created_at", "time_slot_start", "time_slot_end", "gym_name", "gym_location", "gym_timezone", "user_name", "user_email"}).
+		AddRow(1, 1, 10, "booked", now, now, now.Add(time.Hour), "Gym A", "Location A", "UTC", "User", "user@example.com")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT b.id, b.user_id, b.time_slot_id, b.status, b.created_at, ts.start_time AS time_slot_start, ts.end_time AS time_slot_end, g.name AS gym_name, g.location AS gym_location, u.name AS user_name, u.email AS user_email FROM bookings b JOIN time_slots ts ON b.time_slot_id = ts.id JOIN gyms g ON ts.gym_id = g.id JOIN users u ON b.user_id = u.id WHERE b.time_slot_id = $1 ORDER BY b.created_at DESC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT b.id, b.user_id, b.time_slot_id, b.status, b.paid_with, b.amount_cents, b.currency, b.subscription_id, b.created_at, ts.start_time AS time_slot_start, ts.end_time AS time_slot_end, ts.gym_id, g.name AS gym_name, g.location AS gym_location, g.timezone AS gym_timezone, u.name AS user_name, u.email AS user_email, ct.name AS class_type_name, i.name AS instructor_name FROM bookings b JOIN time_slots ts ON b.time_slot_id = ts.id JOIN gyms g ON ts.gym_id = g.id JOIN users u ON b.user_id = u.id LEFT JOIN class_types ct ON ts.class_type_id = ct.id LEFT JOIN instructors i ON ts.instructor_id = i.id WHERE b.time_slot_id = $1 ORDER BY b.created_at DESC")).
 		WithArgs(10).
 		WillReturnRows(rows2)
 
@@ -133,4 +231,17 @@ func TestGetUserBookingsAndByTimeSlot(t *testing.T) {
 	require.Len(t, details, 1)
 	require.Equal(t, 1, details[0].ID)
 	require.Equal(t, 10, details[0].TimeSlotID)
+
+	// GetBookingWithDetails — class slots carry the class type and instructor
+	rows3 := sqlmock.NewRows([]string{"id", "user_id", "time_slot_id", "status", "created_at", "time_slot_start", "time_slot_end", "gym_name", "gym_location", "gym_timezone", "user_name", "user_email", "class_type_name", "instructor_name"}).
+		AddRow(1, 1, 10, "booked", now, now, now.Add(time.Hour), "Gym A", "Location A", "UTC", "User", "user@example.com", "Yoga", "Anna")
+
+	mock.ExpectQuery(`SELECT b\.id, .* LEFT JOIN instructors i ON ts\.instructor_id = i\.id WHERE b\.id = \$1`).
+		WithArgs(1).
+		WillReturnRows(rows3)
+
+	detail, err := repo.GetBookingWithDetails(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Yoga", detail.SlotType())
+	require.Equal(t, "Yoga with Anna at Gym A (Location A)", detail.Summary())
 }
@@ -3,26 +3,40 @@ package booking
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"fitslot/internal/email"
 	"fitslot/internal/gym"
+	"fitslot/internal/logger"
+	"fitslot/internal/money"
 	"fitslot/internal/subscription"
 	"fitslot/internal/user"
 	"fitslot/internal/wallet"
 )
 
 var (
-	ErrBookingNotFound   = errors.New("booking not found")
-	ErrInsufficientFunds = errors.New("insufficient wallet balance")
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrInsufficientFunds     = errors.New("insufficient wallet balance")
+	ErrTimeSlotNotFound      = errors.New("time slot not found")
+	ErrSlotHasBookings       = errors.New("time slot has active bookings")
+	ErrCapacityBelowBookings = errors.New("capacity is below the number of active bookings")
+	ErrInvalidOverflowPolicy = errors.New("invalid overflow policy")
+	ErrSlotClosed            = errors.New("gym is closed during this time slot")
+	ErrRoomFull              = errors.New("room is at full capacity")
+	ErrCurrencyInvalid       = errors.New("unsupported wallet currency")
+	ErrNoExchangeRate        = errors.New("no exchange rate for the wallet currency")
 )
 
 type Service interface {
-	BookSlot(ctx context.Context, userID, slotID int) (*Booking, string, interface{}, error)
+	BookSlot(ctx context.Context, userID, slotID int, walletCurrency string) (*Booking, string, interface{}, error)
 	CancelBooking(ctx context.Context, userID, bookingID int) error
 	GetUserBookings(ctx context.Context, userID int) ([]Booking, error)
 	GetBookingsByTimeSlot(ctx context.Context, slotID int) ([]BookingWithDetails, error)
 	GetBookingsByGym(ctx context.Context, gymID int) ([]BookingWithDetails, error)
+	UpdateTimeSlot(ctx context.Context, slotID int, req UpdateSlotRequest) (*UpdateSlotResponse, error)
+	DeleteTimeSlot(ctx context.Context, slotID int, cancelBookings bool) (int, error)
+	CancelClosureSlots(ctx context.Context, closureID int) (*CancelClosureSlotsResponse, error)
 }
 
 type service struct {
@@ -32,6 +46,7 @@ type service struct {
 	walletRepo       wallet.Repository
 	userRepo         user.Repository
 	emailService     *email.Service
+	availability     *gym.AvailabilityCache
 }
 
 func NewService(
@@ -41,6 +56,7 @@ func NewService(
 	walletRepo wallet.Repository,
 	userRepo user.Repository,
 	emailService *email.Service,
+	availability *gym.AvailabilityCache,
 ) Service {
 	return &service{
 		bookingRepo:      bookingRepo,
@@ -49,19 +65,40 @@ func NewService(
 		walletRepo:       walletRepo,
 		userRepo:         userRepo,
 		emailService:     emailService,
+		availability:     availability,
 	}
 }
 
-func (s *service) BookSlot(ctx context.Context, userID, slotID int) (*Booking, string, interface{}, error) {
+// BookSlot books the slot for the member, paid with an active subscription
+// or from the wallet. The wallet in the gym's currency pays unless the
+// member names another walletCurrency, in which case the price is converted
+// at the stored exchange rate.
+func (s *service) BookSlot(ctx context.Context, userID, slotID int, walletCurrency string) (*Booking, string, interface{}, error) {
+	if walletCurrency != "" {
+		c, err := money.Lookup(walletCurrency)
+		if err != nil {
+			return nil, "", nil, ErrCurrencyInvalid
+		}
+		walletCurrency = c.Code
+	}
+
 	slot, err := s.gymRepo.GetTimeSlotByID(ctx, slotID)
-	if err != nil {
-		return nil, "", nil, errors.New("time slot not found")
+	if err != nil || slot.CancelledAt != nil {
+		return nil, "", nil, ErrTimeSlotNotFound
 	}
 
 	if slot.StartTime.Before(time.Now()) {
 		return nil, "", nil, errors.New("cannot book a slot in the past")
 	}
 
+	closures, err := s.gymRepo.GetClosuresOverlapping(ctx, slot.GymID, slot.StartTime, slot.EndTime)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(closures) > 0 {
+		return nil, "", nil, ErrSlotClosed
+	}
+
 	bookedCount, err := s.bookingRepo.CountActiveBookingsForSlot(ctx, slotID)
 	if err != nil {
 		return nil, "", nil, err
@@ -71,6 +108,12 @@ func (s *service) BookSlot(ctx context.Context, userID, slotID int) (*Booking, s
 		return nil, "", nil, errors.New("time slot is full")
 	}
 
+	if slot.RoomID != nil {
+		if err := s.checkRoomCapacity(ctx, slot); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
 	hasBooking, err := s.bookingRepo.UserHasBookingForSlot(ctx, userID, slotID)
 	if err != nil {
 		return nil, "", nil, err
@@ -95,58 +138,138 @@ func (s *service) BookSlot(ctx context.Context, userID, slotID int) (*Booking, s
 		}
 	}
 
-	// Create booking
-	booking, err := s.bookingRepo.CreateBooking(ctx, userID, slotID)
-	if err != nil {
-		return nil, "", nil, err
-	}
-
 	// Handle payment
 	if useSubscription && activeSub != nil {
+		booking, err := s.createBooking(ctx, userID, slot, Payment{
+			Method:         PaidWithSubscription,
+			SubscriptionID: &activeSub.ID,
+		})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		s.availability.Invalidate(ctx, slot.GymID, slot.StartTime)
+
 		if err := s.subscriptionRepo.IncrementVisits(ctx, activeSub.ID); err != nil {
 			// Booking already created, return warning
-			return booking, "subscription", activeSub, err
+			return booking, PaidWithSubscription, activeSub, err
 		}
 
-		// Send confirmation email
-		user, _ := s.userRepo.FindByID(ctx, userID)
-		if user != nil {
-			s.emailService.SendBookingConfirmation(
-				ctx,
-				user.Email,
-				user.Name,
-				"Gym Slot",
-				slot.StartTime.Format("Jan 2, 2006 at 3:04 PM"),
-				slot.StartTime,
-			)
-		}
+		s.sendConfirmation(ctx, booking.ID)
 
-		return booking, "subscription", activeSub, nil
+		return booking, PaidWithSubscription, activeSub, nil
 	}
 
-	// Pay with wallet
-	const priceCents int64 = 1000
-	if err := s.walletRepo.AddTransaction(ctx, userID, -priceCents, "booking_payment"); err != nil {
-		if err.Error() == "insufficient balance" {
+	g, err := s.gymRepo.GetGymByID(ctx, slot.GymID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if walletCurrency == "" {
+		walletCurrency = g.Currency
+	}
+
+	// Pay with wallet before the booking exists so a failed charge never
+	// leaves an unpaid booking behind.
+	txn, err := s.walletRepo.Charge(ctx, wallet.Charge{
+		UserID:         userID,
+		Kind:           wallet.EntryBookingCharge,
+		GymID:          &slot.GymID,
+		PriceCents:     slot.PriceCents,
+		PriceCurrency:  g.Currency,
+		WalletCurrency: walletCurrency,
+		Description:    fmt.Sprintf("Class booking at %s, %s", g.Name, slot.StartTime.In(g.Zone()).Format("2 Jan 2006 15:04")),
+		Metadata: map[string]interface{}{
+			"slot_id":   slot.ID,
+			"gym_id":    slot.GymID,
+			"starts_at": slot.StartTime,
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, wallet.ErrInsufficientBalance):
 			return nil, "", nil, ErrInsufficientFunds
+		case errors.Is(err, wallet.ErrNoExchangeRate):
+			return nil, "", nil, ErrNoExchangeRate
 		}
 		return nil, "", nil, err
 	}
+	paidCents := -txn.AmountCents
+
+	booking, err := s.createBooking(ctx, userID, slot, Payment{
+		Method:      PaidWithWallet,
+		AmountCents: paidCents,
+		Currency:    walletCurrency,
+	})
+	if err != nil {
+		if _, refundErr := s.walletRepo.RefundCharge(ctx, txn.ID); refundErr != nil {
+			logger.Errorf("Failed to refund user %d after booking error: %v", userID, refundErr)
+		}
+		return nil, "", nil, err
+	}
+	s.availability.Invalidate(ctx, slot.GymID, slot.StartTime)
+
+	if err := s.walletRepo.LinkTransaction(ctx, txn.ID, wallet.TransactionLink{BookingID: &booking.ID}); err != nil {
+		logger.Errorf("Failed to link wallet transaction %d to booking %d: %v", txn.ID, booking.ID, err)
+	} else {
+		txn.BookingID = &booking.ID
+	}
+
+	s.sendConfirmation(ctx, booking.ID)
+
+	return booking, PaidWithWallet, map[string]interface{}{
+		"amount_cents": paidCents,
+		"currency":     walletCurrency,
+		"transaction":  txn,
+	}, nil
+}
+
+// createBooking writes the booking, checking again under the room's lock
+// that the slot's room has a place left.
+func (s *service) createBooking(ctx context.Context, userID int, slot *gym.TimeSlot, payment Payment) (*Booking, error) {
+	if slot.RoomID == nil {
+		return s.bookingRepo.CreateBooking(ctx, userID, slot.ID, payment)
+	}
+	return s.bookingRepo.CreateBookingInRoom(ctx, *slot.RoomID, slot.StartTime, slot.EndTime, userID, slot.ID, payment)
+}
+
+// checkRoomCapacity stops a booking when the slot's room is already full
+// across all slots held there at overlapping times. It runs before the
+// member is charged; createBooking repeats the count under the room's lock,
+// since the room can fill up in between.
+func (s *service) checkRoomCapacity(ctx context.Context, slot *gym.TimeSlot) error {
+	room, err := s.gymRepo.GetRoomByID(ctx, *slot.RoomID)
+	if err != nil {
+		return err
+	}
+
+	occupied, err := s.bookingRepo.CountActiveBookingsInRoom(ctx, room.ID, slot.StartTime, slot.EndTime)
+	if err != nil {
+		return err
+	}
 
-	// Send confirmation email
-	user, _ := s.userRepo.FindByID(ctx, userID)
-	if user != nil {
-		s.emailService.SendBookingConfirmation(
-			ctx,
-			user.Email,
-			user.Name,
-			"Gym Slot",
-			slot.StartTime.Format("Jan 2, 2006 at 3:04 PM"),
-			slot.StartTime,
-		)
+	if occupied >= room.Capacity {
+		return ErrRoomFull
 	}
 
-	return booking, "wallet", map[string]interface{}{"amount_cents": priceCents}, nil
+	return nil
+}
+
+// sendConfirmation emails the member what they booked, including the class
+// and instructor when the slot is a class.
+func (s *service) sendConfirmation(ctx context.Context, bookingID int) {
+	details, err := s.bookingRepo.GetBookingWithDetails(ctx, bookingID)
+	if err != nil {
+		logger.Errorf("Failed to load booking %d for confirmation email: %v", bookingID, err)
+		return
+	}
+
+	s.emailService.SendBookingConfirmation(
+		ctx,
+		details.UserEmail,
+		details.UserName,
+		details.SlotType(),
+		details.Summary(),
+		details.inGymZone().TimeSlotStart,
+	)
 }
 
 func (s *service) CancelBooking(ctx context.Context, userID, bookingID int) error {
@@ -167,6 +290,14 @@ func (s *service) CancelBooking(ctx context.Context, userID, bookingID int) erro
 		return err
 	}
 
+	// The booking does not know its gym, so the slot is only looked up
+	// when there is a cache to invalidate.
+	if s.availability != nil {
+		if slot, err := s.gymRepo.GetTimeSlotByID(ctx, booking.TimeSlotID); err == nil {
+			s.availability.Invalidate(ctx, slot.GymID, slot.StartTime)
+		}
+	}
+
 	return nil
 }
 
@@ -175,9 +306,221 @@ func (s *service) GetUserBookings(ctx context.Context, userID int) ([]Booking, e
 }
 
 func (s *service) GetBookingsByTimeSlot(ctx context.Context, slotID int) ([]BookingWithDetails, error) {
-	return s.bookingRepo.GetBookingsByTimeSlot(ctx, slotID)
+	bookings, err := s.bookingRepo.GetBookingsByTimeSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	return inGymZone(bookings), nil
 }
 
 func (s *service) GetBookingsByGym(ctx context.Context, gymID int) ([]BookingWithDetails, error) {
-	return s.bookingRepo.GetBookingsByGym(ctx, gymID)
+	bookings, err := s.bookingRepo.GetBookingsByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+	return inGymZone(bookings), nil
+}
+
+func inGymZone(bookings []BookingWithDetails) []BookingWithDetails {
+	for i := range bookings {
+		bookings[i] = bookings[i].inGymZone()
+	}
+	return bookings
+}
+
+// UpdateTimeSlot changes the times and/or capacity of a slot. Shrinking the
+// capacity below the number of active bookings is refused unless the
+// cancel_latest overflow policy is chosen, in which case the most recent
+// bookings are cancelled and refunded in the same transaction as the
+// change. Members keeping their booking are
+// emailed when the time changes. Times without an offset are read in the
+// gym's time zone. As when the slot is created, its capacity may not exceed
+// that of its room.
+func (s *service) UpdateTimeSlot(ctx context.Context, slotID int, req UpdateSlotRequest) (*UpdateSlotResponse, error) {
+	slot, err := s.gymRepo.GetTimeSlotByID(ctx, slotID)
+	if err != nil || slot.CancelledAt != nil {
+		return nil, ErrTimeSlotNotFound
+	}
+
+	policy := req.OverflowPolicy
+	if policy == "" {
+		policy = OverflowReject
+	}
+	if policy != OverflowReject && policy != OverflowCancelLatest {
+		return nil, ErrInvalidOverflowPolicy
+	}
+
+	g, err := s.gymRepo.GetGymByID(ctx, slot.GymID)
+	if err != nil {
+		return nil, err
+	}
+	loc := g.Zone()
+
+	startTime, endTime, capacity := slot.StartTime, slot.EndTime, slot.Capacity
+	if req.StartTime != nil {
+		if startTime, err = gym.ParseSlotTime(*req.StartTime, loc); err != nil {
+			return nil, fmt.Errorf("%w: start_time %v", gym.ErrTimeSlotInvalid, err)
+		}
+	}
+	if req.EndTime != nil {
+		if endTime, err = gym.ParseSlotTime(*req.EndTime, loc); err != nil {
+			return nil, fmt.Errorf("%w: end_time %v", gym.ErrTimeSlotInvalid, err)
+		}
+	}
+	if req.Capacity != nil {
+		capacity = *req.Capacity
+	}
+	if !endTime.After(startTime) || capacity <= 0 {
+		return nil, gym.ErrTimeSlotInvalid
+	}
+	if slot.RoomID != nil {
+		room, err := s.gymRepo.GetRoomByID(ctx, *slot.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if err := gym.CheckRoomCapacity(room, capacity); err != nil {
+			return nil, err
+		}
+	}
+
+	bookings, err := s.activeBookingsForSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+
+	overflow := len(bookings) - capacity
+	if overflow > 0 && policy == OverflowReject {
+		return nil, ErrCapacityBelowBookings
+	}
+
+	// Bookings are ordered newest first, so the overflow is at the front.
+	var cancel []BookingWithDetails
+	if overflow > 0 {
+		cancel, bookings = bookings[:overflow], bookings[overflow:]
+	}
+
+	updated, err := s.bookingRepo.UpdateSlotCancellingBookings(ctx, slotID, startTime, endTime, capacity, cancel)
+	if err != nil {
+		return nil, err
+	}
+	s.availability.Invalidate(ctx, updated.GymID, slot.StartTime, updated.StartTime)
+
+	localized := updated.In(loc)
+	resp := &UpdateSlotResponse{Slot: &localized, CancelledBookings: len(cancel)}
+	s.notifyCancelled(ctx, cancel, "The time slot capacity was reduced by the gym")
+
+	if !updated.StartTime.Equal(slot.StartTime) || !updated.EndTime.Equal(slot.EndTime) {
+		for _, b := range bookings {
+			s.emailService.SendSlotRescheduled(ctx, b.UserEmail, b.UserName, b.SlotType(), slot.StartTime.In(loc), localized.StartTime)
+			resp.NotifiedMembers++
+		}
+	}
+
+	return resp, nil
+}
+
+// DeleteTimeSlot removes a slot from the schedule. Active bookings must be
+// cancelled and refunded explicitly (cancelBookings) instead of silently
+// disappearing with the slot. The slot and its bookings are cancelled
+// together or not at all.
+func (s *service) DeleteTimeSlot(ctx context.Context, slotID int, cancelBookings bool) (int, error) {
+	slot, err := s.gymRepo.GetTimeSlotByID(ctx, slotID)
+	if err != nil || slot.CancelledAt != nil {
+		return 0, ErrTimeSlotNotFound
+	}
+
+	bookings, err := s.activeBookingsForSlot(ctx, slotID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(bookings) > 0 && !cancelBookings {
+		return 0, ErrSlotHasBookings
+	}
+
+	if err := s.bookingRepo.CancelSlotWithBookings(ctx, slotID, bookings); err != nil {
+		return 0, err
+	}
+	s.availability.Invalidate(ctx, slot.GymID, slot.StartTime)
+	s.notifyCancelled(ctx, bookings, "The time slot was removed by the gym")
+
+	return len(bookings), nil
+}
+
+// CancelClosureSlots cancels every slot that falls into a closure. Active
+// bookings are cancelled and refunded, and the members are told why. Each
+// slot is cancelled with its bookings in its own transaction; a slot that
+// fails is left as it was and reported in FailedSlotIDs, and calling again
+// retries only the slots that are still live.
+func (s *service) CancelClosureSlots(ctx context.Context, closureID int) (*CancelClosureSlotsResponse, error) {
+	closure, err := s.gymRepo.GetClosureByID(ctx, closureID)
+	if err != nil {
+		return nil, gym.ErrClosureNotFound
+	}
+
+	slots, err := s.gymRepo.GetTimeSlotsInRange(ctx, closure.GymID, closure.StartsAt, closure.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "The gym is closed"
+	if closure.Reason != "" {
+		reason += ": " + closure.Reason
+	}
+
+	resp := &CancelClosureSlotsResponse{ClosureID: closure.ID}
+	for _, slot := range slots {
+		cancelled, err := s.cancelSlot(ctx, slot, reason)
+		if err != nil {
+			logger.Errorf("Failed to cancel slot %d for closure %d: %v", slot.ID, closure.ID, err)
+			resp.FailedSlotIDs = append(resp.FailedSlotIDs, slot.ID)
+			continue
+		}
+		resp.CancelledSlots++
+		resp.CancelledBookings += cancelled
+	}
+
+	return resp, nil
+}
+
+// cancelSlot cancels the slot with its active bookings and tells their
+// members why. It returns the number of bookings cancelled.
+func (s *service) cancelSlot(ctx context.Context, slot gym.TimeSlot, reason string) (int, error) {
+	bookings, err := s.activeBookingsForSlot(ctx, slot.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.bookingRepo.CancelSlotWithBookings(ctx, slot.ID, bookings); err != nil {
+		return 0, err
+	}
+	s.availability.Invalidate(ctx, slot.GymID, slot.StartTime)
+	s.notifyCancelled(ctx, bookings, reason)
+
+	return len(bookings), nil
+}
+
+// activeBookingsForSlot returns the slot's booked (not cancelled) bookings,
+// newest first.
+func (s *service) activeBookingsForSlot(ctx context.Context, slotID int) ([]BookingWithDetails, error) {
+	all, err := s.bookingRepo.GetBookingsByTimeSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]BookingWithDetails, 0, len(all))
+	for _, b := range all {
+		if b.Status == "booked" {
+			active = append(active, b)
+		}
+	}
+
+	return active, nil
+}
+
+// notifyCancelled tells the members of bookings the gym cancelled why.
+func (s *service) notifyCancelled(ctx context.Context, bookings []BookingWithDetails, reason string) {
+	for _, b := range bookings {
+		s.emailService.SendCancellation(ctx, b.UserEmail, b.UserName, b.SlotType(), reason)
+	}
 }
@@ -3,6 +3,7 @@ package booking
 import (
 	"context"
 	"errors"
+	"math/big"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"fitslot/internal/user"
 	"fitslot/internal/wallet"
 
+	"github.com/go-redis/redismock/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,8 +25,16 @@ type MockSubscriptionRepo struct{ mock.Mock }
 type MockWalletRepo struct{ mock.Mock }
 type MockUserRepo struct{ mock.Mock }
 
-func (m *MockBookingRepo) CreateBooking(ctx context.Context, userID, timeSlotID int) (*Booking, error) {
-	args := m.Called(ctx, userID, timeSlotID)
+func (m *MockBookingRepo) CreateBooking(ctx context.Context, userID, timeSlotID int, payment Payment) (*Booking, error) {
+	args := m.Called(ctx, userID, timeSlotID, payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Booking), args.Error(1)
+}
+
+func (m *MockBookingRepo) CreateBookingInRoom(ctx context.Context, roomID int, startTime, endTime time.Time, userID, timeSlotID int, payment Payment) (*Booking, error) {
+	args := m.Called(ctx, roomID, startTime, endTime, userID, timeSlotID, payment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -61,6 +71,19 @@ func (m *MockBookingRepo) GetUserBookings(ctx context.Context, userID int) ([]Bo
 	return args.Get(0).([]Booking), args.Error(1)
 }
 
+func (m *MockBookingRepo) CountActiveBookingsInRoom(ctx context.Context, roomID int, startTime, endTime time.Time) (int, error) {
+	args := m.Called(ctx, roomID, startTime, endTime)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBookingRepo) GetBookingWithDetails(ctx context.Context, id int) (*BookingWithDetails, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*BookingWithDetails), args.Error(1)
+}
+
 func (m *MockBookingRepo) GetBookingsByTimeSlot(ctx context.Context, timeSlotID int) ([]BookingWithDetails, error) {
 	args := m.Called(ctx, timeSlotID)
 	if args.Get(0) == nil {
@@ -77,8 +100,21 @@ func (m *MockBookingRepo) GetBookingsByGym(ctx context.Context, gymID int) ([]Bo
 	return args.Get(0).([]BookingWithDetails), args.Error(1)
 }
 
-func (m *MockGymRepo) CreateGym(ctx context.Context, name, location string) (*gym.Gym, error) {
-	args := m.Called(ctx, name, location)
+func (m *MockBookingRepo) UpdateSlotCancellingBookings(ctx context.Context, slotID int, startTime, endTime time.Time, capacity int, cancel []BookingWithDetails) (*gym.TimeSlot, error) {
+	args := m.Called(ctx, slotID, startTime, endTime, capacity, cancel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.TimeSlot), args.Error(1)
+}
+
+func (m *MockBookingRepo) CancelSlotWithBookings(ctx context.Context, slotID int, cancel []BookingWithDetails) error {
+	args := m.Called(ctx, slotID, cancel)
+	return args.Error(0)
+}
+
+func (m *MockGymRepo) CreateGym(ctx context.Context, name, location, timezone, currency string) (*gym.Gym, error) {
+	args := m.Called(ctx, name, location, timezone, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -93,6 +129,14 @@ func (m *MockGymRepo) GetAllGyms(ctx context.Context) ([]gym.Gym, error) {
 	return args.Get(0).([]gym.Gym), args.Error(1)
 }
 
+func (m *MockGymRepo) ListGyms(ctx context.Context, query gym.GymQuery) ([]gym.Gym, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.Gym), args.Error(1)
+}
+
 func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -101,16 +145,24 @@ func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error)
 	return args.Get(0).(*gym.Gym), args.Error(1)
 }
 
-func (m *MockGymRepo) CreateTimeSlot(ctx context.Context, gymID int, startTime, endTime time.Time, capacity int) (*gym.TimeSlot, error) {
-	args := m.Called(ctx, gymID, startTime, endTime, capacity)
+func (m *MockGymRepo) CreateTimeSlot(ctx context.Context, gymID int, slot gym.NewTimeSlot) (*gym.TimeSlot, error) {
+	args := m.Called(ctx, gymID, slot)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*gym.TimeSlot), args.Error(1)
 }
 
-func (m *MockGymRepo) GetTimeSlotsByGym(ctx context.Context, gymID int, onlyFuture bool) ([]gym.TimeSlot, error) {
-	args := m.Called(ctx, gymID, onlyFuture)
+func (m *MockGymRepo) CreateTimeSlots(ctx context.Context, gymID int, slots []gym.NewTimeSlot) ([]gym.TimeSlot, error) {
+	args := m.Called(ctx, gymID, slots)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.TimeSlot), args.Error(1)
+}
+
+func (m *MockGymRepo) GetTimeSlotsByGym(ctx context.Context, gymID int, filter gym.SlotFilter) ([]gym.TimeSlot, error) {
+	args := m.Called(ctx, gymID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -125,14 +177,128 @@ func (m *MockGymRepo) GetTimeSlotByID(ctx context.Context, id int) (*gym.TimeSlo
 	return args.Get(0).(*gym.TimeSlot), args.Error(1)
 }
 
-func (m *MockGymRepo) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, onlyFuture bool) ([]gym.TimeSlotWithAvailability, error) {
-	args := m.Called(ctx, gymID, onlyFuture)
+func (m *MockGymRepo) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, filter gym.SlotFilter) ([]gym.TimeSlotWithAvailability, error) {
+	args := m.Called(ctx, gymID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]gym.TimeSlotWithAvailability), args.Error(1)
 }
 
+func (m *MockGymRepo) SearchTimeSlots(ctx context.Context, search gym.SlotSearch) ([]gym.SlotSearchResult, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.SlotSearchResult), args.Error(1)
+}
+
+func (m *MockGymRepo) UpdateTimeSlot(ctx context.Context, id int, startTime, endTime time.Time, capacity int) (*gym.TimeSlot, error) {
+	args := m.Called(ctx, id, startTime, endTime, capacity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.TimeSlot), args.Error(1)
+}
+
+func (m *MockGymRepo) CancelTimeSlot(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockGymRepo) GetTimeSlotsInRange(ctx context.Context, gymID int, startTime, endTime time.Time) ([]gym.TimeSlot, error) {
+	args := m.Called(ctx, gymID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.TimeSlot), args.Error(1)
+}
+
+func (m *MockGymRepo) GetOccupancySnapshot(ctx context.Context, gymID int, at time.Time) (*gym.OccupancySnapshot, error) {
+	args := m.Called(ctx, gymID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.OccupancySnapshot), args.Error(1)
+}
+
+func (m *MockGymRepo) GetHourlyBookings(ctx context.Context, gymID int, from, to time.Time, weekday time.Weekday, loc *time.Location) ([]gym.HourCount, error) {
+	args := m.Called(ctx, gymID, from, to, weekday, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.HourCount), args.Error(1)
+}
+
+func (m *MockGymRepo) CreateClosure(ctx context.Context, gymID int, startsAt, endsAt time.Time, reason string) (*gym.Closure, error) {
+	args := m.Called(ctx, gymID, startsAt, endsAt, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Closure), args.Error(1)
+}
+
+func (m *MockGymRepo) GetClosuresByGym(ctx context.Context, gymID int) ([]gym.Closure, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.Closure), args.Error(1)
+}
+
+func (m *MockGymRepo) GetClosureByID(ctx context.Context, id int) (*gym.Closure, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Closure), args.Error(1)
+}
+
+func (m *MockGymRepo) GetClosuresOverlapping(ctx context.Context, gymID int, startTime, endTime time.Time) ([]gym.Closure, error) {
+	args := m.Called(ctx, gymID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.Closure), args.Error(1)
+}
+
+func (m *MockGymRepo) DeleteClosure(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockGymRepo) CreateRoom(ctx context.Context, gymID int, name string, capacity int) (*gym.Room, error) {
+	args := m.Called(ctx, gymID, name, capacity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Room), args.Error(1)
+}
+
+func (m *MockGymRepo) GetRoomsByGym(ctx context.Context, gymID int) ([]gym.Room, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gym.Room), args.Error(1)
+}
+
+func (m *MockGymRepo) GetRoomByID(ctx context.Context, id int) (*gym.Room, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Room), args.Error(1)
+}
+
+func (m *MockGymRepo) ClassTypeExists(ctx context.Context, id int) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGymRepo) InstructorTeachesAt(ctx context.Context, instructorID, gymID int) (bool, error) {
+	args := m.Called(ctx, instructorID, gymID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockSubscriptionRepo) CreateSubscription(ctx context.Context, userID int, gymID *int, stype subscription.SubscriptionType, priceCents int64, visitsLimit *int) (*subscription.Subscription, error) {
 	args := m.Called(ctx, userID, gymID, stype, priceCents, visitsLimit)
 	if args.Get(0) == nil {
@@ -153,6 +319,10 @@ func (m *MockSubscriptionRepo) IncrementVisits(ctx context.Context, subID int) e
 	return m.Called(ctx, subID).Error(0)
 }
 
+func (m *MockSubscriptionRepo) DecrementVisits(ctx context.Context, subID int) error {
+	return m.Called(ctx, subID).Error(0)
+}
+
 func (m *MockSubscriptionRepo) ListActiveByUser(ctx context.Context, userID int) ([]*subscription.Subscription, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -161,30 +331,181 @@ func (m *MockSubscriptionRepo) ListActiveByUser(ctx context.Context, userID int)
 	return args.Get(0).([]*subscription.Subscription), args.Error(1)
 }
 
-func (m *MockWalletRepo) GetOrCreateWallet(ctx context.Context, userID int) (*wallet.Wallet, error) {
-	args := m.Called(ctx, userID)
+func (m *MockWalletRepo) GetOrCreateWallet(ctx context.Context, userID int, currency string) (*wallet.Wallet, error) {
+	args := m.Called(ctx, userID, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*wallet.Wallet), args.Error(1)
 }
 
-func (m *MockWalletRepo) AddTransaction(ctx context.Context, userID int, amountCents int64, txType string) error {
-	return m.Called(ctx, userID, amountCents, txType).Error(0)
+func (m *MockWalletRepo) AddTransaction(ctx context.Context, userID int, amountCents int64, currency string, kind wallet.EntryKind, gymID *int) error {
+	return m.Called(ctx, userID, amountCents, currency, kind, gymID).Error(0)
 }
 
 func (m *MockWalletRepo) TopUp(ctx context.Context, userID int, amountCents int64) error {
 	return m.Called(ctx, userID, amountCents).Error(0)
 }
 
-func (m *MockWalletRepo) GetTransactions(ctx context.Context, userID int, limit, offset int) ([]wallet.Transaction, error) {
-	args := m.Called(ctx, userID, limit, offset)
+func (m *MockWalletRepo) CreditTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletRepo) GetTransactions(ctx context.Context, userID int, filter wallet.TransactionFilter) ([]wallet.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) LinkTransaction(ctx context.Context, transactionID int, link wallet.TransactionLink) error {
+	args := m.Called(ctx, transactionID, link)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) GetStatement(ctx context.Context, userID int, currency string, from, until time.Time) (*wallet.Statement, error) {
+	args := m.Called(ctx, userID, currency, from, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Statement), args.Error(1)
+}
+
+func (m *MockWalletRepo) ReconcileWallets(ctx context.Context) (*wallet.Reconciliation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Reconciliation), args.Error(1)
+}
+
+func (m *MockWalletRepo) CorrectWallet(ctx context.Context, c wallet.WalletCorrection) (*wallet.Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) GetAccountBalances(ctx context.Context) ([]wallet.AccountBalance, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.AccountBalance), args.Error(1)
+}
+
+func (m *MockWalletRepo) CheckLedger(ctx context.Context) (*wallet.LedgerCheck, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.LedgerCheck), args.Error(1)
+}
+
+func (m *MockWalletRepo) Transfer(ctx context.Context, t wallet.Transfer) (*wallet.Transaction, error) {
+	args := m.Called(ctx, t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) Adjust(ctx context.Context, a wallet.Adjustment) (*wallet.Transaction, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundTransaction(ctx context.Context, refund wallet.TransactionRefund) (*wallet.Transaction, error) {
+	args := m.Called(ctx, refund)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundCharge(ctx context.Context, transactionID int) (*wallet.Transaction, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) GrantPromoCredit(ctx context.Context, g wallet.PromoGrant) (*wallet.Transaction, error) {
+	args := m.Called(ctx, g)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListCreditLots(ctx context.Context, userID int) ([]wallet.CreditLot, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.CreditLot), args.Error(1)
+}
+
+func (m *MockWalletRepo) ExpireCredits(ctx context.Context) ([]wallet.Transaction, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]wallet.Transaction), args.Error(1)
 }
 
+func (m *MockWalletRepo) ListWallets(ctx context.Context, userID int) ([]wallet.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) Charge(ctx context.Context, c wallet.Charge) (*wallet.Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListExchangeRates(ctx context.Context) ([]wallet.ExchangeRate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.ExchangeRate), args.Error(1)
+}
+
+func (m *MockWalletRepo) SetExchangeRate(ctx context.Context, base, quote, rate string, adminID int) (*wallet.ExchangeRate, error) {
+	args := m.Called(ctx, base, quote, rate, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.ExchangeRate), args.Error(1)
+}
+
+func (m *MockWalletRepo) ExchangeRate(ctx context.Context, from, to string) (*big.Rat, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Rat), args.Error(1)
+}
+
 func (m *MockUserRepo) Create(ctx context.Context, name, email, passwordHash, role string) (*user.User, error) {
 	args := m.Called(ctx, name, email, passwordHash, role)
 	if args.Get(0) == nil {
@@ -216,6 +537,7 @@ func (m *MockUserRepo) EmailExists(ctx context.Context, email string) (bool, err
 
 func TestService_BookSlot(t *testing.T) {
 	futureTime := time.Now().Add(24 * time.Hour)
+	gymID := 1
 	pastTime := time.Now().Add(-24 * time.Hour)
 
 	tests := []struct {
@@ -232,26 +554,38 @@ func TestService_BookSlot(t *testing.T) {
 			slotID: 1,
 			setupMocks: func(br *MockBookingRepo, gr *MockGymRepo, sr *MockSubscriptionRepo, wr *MockWalletRepo, ur *MockUserRepo) {
 				gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
-					ID:        1,
-					GymID:     1,
-					StartTime: futureTime,
-					EndTime:   futureTime.Add(time.Hour),
-					Capacity:  20,
+					ID:         1,
+					GymID:      1,
+					StartTime:  futureTime,
+					EndTime:    futureTime.Add(time.Hour),
+					Capacity:   20,
+					PriceCents: 1000,
 				}, nil)
+				gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
 				br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(5, nil)
 				br.On("UserHasBookingForSlot", mock.Anything, 1, 1).Return(false, nil)
 				sr.On("GetActiveForUserAndGym", mock.Anything, 1, 1).Return(nil, errors.New("no subscription"))
-				br.On("CreateBooking", mock.Anything, 1, 1).Return(&Booking{
+				g := &gym.Gym{ID: 1, Name: "Test Gym", Currency: "KZT"}
+				gr.On("GetGymByID", mock.Anything, 1).Return(g, nil)
+				wr.On("Charge", mock.Anything, wallet.Charge{
+					UserID: 1, Kind: wallet.EntryBookingCharge, GymID: &gymID,
+					PriceCents: 1000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+					Description: "Class booking at Test Gym, " + futureTime.In(g.Zone()).Format("2 Jan 2006 15:04"),
+					Metadata:    map[string]interface{}{"slot_id": 1, "gym_id": 1, "starts_at": futureTime},
+				}).Return(&wallet.Transaction{ID: 4, AmountCents: -1000, Currency: "KZT"}, nil)
+				br.On("CreateBooking", mock.Anything, 1, 1, Payment{Method: PaidWithWallet, AmountCents: 1000, Currency: "KZT"}).Return(&Booking{
 					ID:         1,
 					UserID:     1,
 					TimeSlotID: 1,
 					Status:     "booked",
 				}, nil)
-				wr.On("AddTransaction", mock.Anything, 1, int64(-1000), "booking_payment").Return(nil)
-				ur.On("FindByID", mock.Anything, 1).Return(&user.User{
-					ID:    1,
-					Email: "test@example.com",
-					Name:  "Test User",
+				bookingID := 1
+				wr.On("LinkTransaction", mock.Anything, 4, wallet.TransactionLink{BookingID: &bookingID}).Return(nil)
+				br.On("GetBookingWithDetails", mock.Anything, 1).Return(&BookingWithDetails{
+					Booking:   Booking{ID: 1, UserID: 1, TimeSlotID: 1, Status: "booked"},
+					GymName:   "Test Gym",
+					UserName:  "Test User",
+					UserEmail: "test@example.com",
 				}, nil)
 			},
 			expectError: false,
@@ -292,11 +626,81 @@ func TestService_BookSlot(t *testing.T) {
 					EndTime:   futureTime.Add(time.Hour),
 					Capacity:  20,
 				}, nil)
+				gr.On("GetClosuresOverlapping", mock.Anything, 0, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
 				br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(20, nil)
 			},
 			expectError: true,
 			errorMsg:    "time slot is full",
 		},
+		{
+			name:   "room full across overlapping slots",
+			userID: 1,
+			slotID: 1,
+			setupMocks: func(br *MockBookingRepo, gr *MockGymRepo, sr *MockSubscriptionRepo, wr *MockWalletRepo, ur *MockUserRepo) {
+				roomID := 3
+				gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
+					ID:        1,
+					GymID:     1,
+					StartTime: futureTime,
+					EndTime:   futureTime.Add(time.Hour),
+					Capacity:  20,
+					RoomID:    &roomID,
+				}, nil)
+				gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
+				br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(5, nil)
+				gr.On("GetRoomByID", mock.Anything, 3).Return(&gym.Room{ID: 3, GymID: 1, Capacity: 25}, nil)
+				br.On("CountActiveBookingsInRoom", mock.Anything, 3, futureTime, futureTime.Add(time.Hour)).Return(25, nil)
+			},
+			expectError: true,
+			errorMsg:    "room is at full capacity",
+		},
+		{
+			name:   "room filled up while paying refunds the charge",
+			userID: 1,
+			slotID: 1,
+			setupMocks: func(br *MockBookingRepo, gr *MockGymRepo, sr *MockSubscriptionRepo, wr *MockWalletRepo, ur *MockUserRepo) {
+				roomID := 3
+				gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
+					ID:         1,
+					GymID:      1,
+					StartTime:  futureTime,
+					EndTime:    futureTime.Add(time.Hour),
+					Capacity:   20,
+					PriceCents: 1000,
+					RoomID:     &roomID,
+				}, nil)
+				gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
+				br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(5, nil)
+				gr.On("GetRoomByID", mock.Anything, 3).Return(&gym.Room{ID: 3, GymID: 1, Capacity: 25}, nil)
+				br.On("CountActiveBookingsInRoom", mock.Anything, 3, futureTime, futureTime.Add(time.Hour)).Return(24, nil)
+				br.On("UserHasBookingForSlot", mock.Anything, 1, 1).Return(false, nil)
+				sr.On("GetActiveForUserAndGym", mock.Anything, 1, 1).Return(nil, errors.New("no subscription"))
+				gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1, Currency: "KZT"}, nil)
+				wr.On("Charge", mock.Anything, mock.Anything).Return(&wallet.Transaction{ID: 4, AmountCents: -1000, Currency: "KZT"}, nil)
+				br.On("CreateBookingInRoom", mock.Anything, 3, futureTime, futureTime.Add(time.Hour), 1, 1,
+					Payment{Method: PaidWithWallet, AmountCents: 1000, Currency: "KZT"}).Return(nil, ErrRoomFull)
+				wr.On("RefundCharge", mock.Anything, 4).Return(&wallet.Transaction{ID: 5, AmountCents: 1000, Currency: "KZT"}, nil).Once()
+			},
+			expectError: true,
+			errorMsg:    "room is at full capacity",
+		},
+		{
+			name:   "gym closed",
+			userID: 1,
+			slotID: 1,
+			setupMocks: func(br *MockBookingRepo, gr *MockGymRepo, sr *MockSubscriptionRepo, wr *MockWalletRepo, ur *MockUserRepo) {
+				gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
+					ID:        1,
+					GymID:     1,
+					StartTime: futureTime,
+					EndTime:   futureTime.Add(time.Hour),
+					Capacity:  20,
+				}, nil)
+				gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{{ID: 1, GymID: 1}}, nil)
+			},
+			expectError: true,
+			errorMsg:    "gym is closed",
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,9 +714,9 @@ func TestService_BookSlot(t *testing.T) {
 			tt.setupMocks(br, gr, sr, wr, ur)
 
 			emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
-			service := NewService(br, gr, sr, wr, ur, emailService)
+			service := NewService(br, gr, sr, wr, ur, emailService, nil)
 
-			booking, paymentMethod, _, err := service.BookSlot(context.Background(), tt.userID, tt.slotID)
+			booking, paymentMethod, _, err := service.BookSlot(context.Background(), tt.userID, tt.slotID, "")
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -329,6 +733,73 @@ func TestService_BookSlot(t *testing.T) {
 	}
 }
 
+func TestService_BookSlot_WalletCurrency(t *testing.T) {
+	futureTime := time.Now().Add(24 * time.Hour)
+	gymID := 1
+
+	t.Run("charges the named wallet at the converted amount", func(t *testing.T) {
+		br, gr, sr, wr := new(MockBookingRepo), new(MockGymRepo), new(MockSubscriptionRepo), new(MockWalletRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
+			ID: 1, GymID: 1, StartTime: futureTime, EndTime: futureTime.Add(time.Hour), Capacity: 20, PriceCents: 470000,
+		}, nil)
+		gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
+		br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(0, nil)
+		br.On("UserHasBookingForSlot", mock.Anything, 1, 1).Return(false, nil)
+		sr.On("GetActiveForUserAndGym", mock.Anything, 1, 1).Return(nil, errors.New("no subscription"))
+		g := &gym.Gym{ID: 1, Name: "Downtown", Currency: "KZT", Timezone: "Europe/Berlin"}
+		gr.On("GetGymByID", mock.Anything, 1).Return(g, nil)
+		wr.On("Charge", mock.Anything, mock.MatchedBy(func(c wallet.Charge) bool {
+			return c.UserID == 1 && c.Kind == wallet.EntryBookingCharge && *c.GymID == gymID &&
+				c.PriceCents == 470000 && c.PriceCurrency == "KZT" && c.WalletCurrency == "USD" &&
+				c.Description == "Class booking at Downtown, "+futureTime.In(g.Zone()).Format("2 Jan 2006 15:04") &&
+				c.Metadata["slot_id"] == 1
+		})).Return(&wallet.Transaction{ID: 7, AmountCents: -1000, Currency: "USD"}, nil)
+		br.On("CreateBooking", mock.Anything, 1, 1, Payment{Method: PaidWithWallet, AmountCents: 1000, Currency: "USD"}).
+			Return(&Booking{ID: 3, UserID: 1, TimeSlotID: 1, Status: "booked", Currency: "USD"}, nil)
+		bookingID := 3
+		wr.On("LinkTransaction", mock.Anything, 7, wallet.TransactionLink{BookingID: &bookingID}).Return(nil)
+		br.On("GetBookingWithDetails", mock.Anything, 3).Return(&BookingWithDetails{Booking: Booking{ID: 3}, UserEmail: "test@example.com"}, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, sr, wr, new(MockUserRepo), emailService, nil)
+
+		booking, method, details, err := service.BookSlot(context.Background(), 1, 1, "usd")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, booking.ID)
+		assert.Equal(t, PaidWithWallet, method)
+		assert.Equal(t, "USD", details.(map[string]interface{})["currency"])
+		assert.Equal(t, int64(1000), details.(map[string]interface{})["amount_cents"])
+		assert.Equal(t, &bookingID, details.(map[string]interface{})["transaction"].(*wallet.Transaction).BookingID)
+		wr.AssertExpectations(t)
+	})
+
+	t.Run("missing exchange rate", func(t *testing.T) {
+		br, gr, sr, wr := new(MockBookingRepo), new(MockGymRepo), new(MockSubscriptionRepo), new(MockWalletRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&gym.TimeSlot{
+			ID: 1, GymID: 1, StartTime: futureTime, EndTime: futureTime.Add(time.Hour), Capacity: 20, PriceCents: 1000,
+		}, nil)
+		gr.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]gym.Closure{}, nil)
+		br.On("CountActiveBookingsForSlot", mock.Anything, 1).Return(0, nil)
+		br.On("UserHasBookingForSlot", mock.Anything, 1, 1).Return(false, nil)
+		sr.On("GetActiveForUserAndGym", mock.Anything, 1, 1).Return(nil, errors.New("no subscription"))
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1, Currency: "KZT"}, nil)
+		wr.On("Charge", mock.Anything, mock.Anything).Return(nil, wallet.ErrNoExchangeRate)
+
+		service := NewService(br, gr, sr, wr, new(MockUserRepo), nil, nil)
+
+		_, _, _, err := service.BookSlot(context.Background(), 1, 1, "EUR")
+		assert.ErrorIs(t, err, ErrNoExchangeRate)
+		br.AssertNotCalled(t, "CreateBooking", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		service := NewService(new(MockBookingRepo), new(MockGymRepo), new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), nil, nil)
+
+		_, _, _, err := service.BookSlot(context.Background(), 1, 1, "XYZ")
+		assert.ErrorIs(t, err, ErrCurrencyInvalid)
+	})
+}
+
 func TestService_CancelBooking(t *testing.T) {
 	br := new(MockBookingRepo)
 	gr := new(MockGymRepo)
@@ -344,10 +815,281 @@ func TestService_CancelBooking(t *testing.T) {
 	br.On("CancelBooking", mock.Anything, 1).Return(nil)
 
 	emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
-	service := NewService(br, gr, sr, wr, ur, emailService)
+	service := NewService(br, gr, sr, wr, ur, emailService, nil)
 
 	err := service.CancelBooking(context.Background(), 1, 1)
 
 	assert.NoError(t, err)
 	br.AssertExpectations(t)
 }
+
+func TestService_CancelBooking_InvalidatesAvailability(t *testing.T) {
+	br, gr := new(MockBookingRepo), new(MockGymRepo)
+	start := time.Date(2099, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	br.On("GetBookingByID", mock.Anything, 1).Return(&Booking{ID: 1, UserID: 1, TimeSlotID: 7, Status: "booked"}, nil)
+	br.On("CancelBooking", mock.Anything, 1).Return(nil)
+	gr.On("GetTimeSlotByID", mock.Anything, 7).Return(&gym.TimeSlot{ID: 7, GymID: 3, StartTime: start}, nil)
+
+	rdb, rmock := redismock.NewClientMock()
+	rmock.Regexp().ExpectEvalSha(`.*`, []string{"fitslot:availability:3"}, "600", "2099-06-01").SetVal(int64(1))
+
+	emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+	service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService,
+		gym.NewAvailabilityCache(rdb, gym.DefaultAvailabilityTTL))
+
+	err := service.CancelBooking(context.Background(), 1, 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+	gr.AssertExpectations(t)
+}
+
+func TestService_UpdateTimeSlot(t *testing.T) {
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	end := start.Add(time.Hour)
+	walletPaid := PaidWithWallet
+
+	slot := &gym.TimeSlot{ID: 1, GymID: 1, StartTime: start, EndTime: end, Capacity: 3, PriceCents: 1000}
+	active := []BookingWithDetails{
+		{Booking: Booking{ID: 12, UserID: 3, Status: "booked", PaidWith: &walletPaid, AmountCents: 1000, Currency: "KZT"}, GymID: 1, UserEmail: "c@example.com", UserName: "C"},
+		{Booking: Booking{ID: 11, UserID: 2, Status: "booked", PaidWith: &walletPaid, AmountCents: 1000}, UserEmail: "b@example.com", UserName: "B"},
+		{Booking: Booking{ID: 10, UserID: 1, Status: "cancelled"}, UserEmail: "a@example.com", UserName: "A"},
+	}
+	newCapacity := 1
+
+	t.Run("rejects capacity below active bookings by default", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		_, err := service.UpdateTimeSlot(context.Background(), 1, UpdateSlotRequest{Capacity: &newCapacity})
+		assert.ErrorIs(t, err, ErrCapacityBelowBookings)
+		br.AssertNotCalled(t, "UpdateSlotCancellingBookings", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects capacity above the room's", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		roomID := 4
+		inRoom := *slot
+		inRoom.RoomID = &roomID
+		biggerCapacity := 30
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(&inRoom, nil)
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		gr.On("GetRoomByID", mock.Anything, 4).Return(&gym.Room{ID: 4, GymID: 1, Capacity: 25}, nil)
+
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), nil, nil)
+
+		_, err := service.UpdateTimeSlot(context.Background(), 1, UpdateSlotRequest{Capacity: &biggerCapacity})
+		assert.ErrorIs(t, err, gym.ErrTimeSlotInvalid)
+		assert.Contains(t, err.Error(), "room capacity of 25")
+		br.AssertNotCalled(t, "UpdateSlotCancellingBookings", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancel_latest refunds the newest booking and notifies on time change", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		newStart := start.Add(-30 * time.Minute)
+		newStartStr := newStart.Format(time.RFC3339)
+
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+		br.On("UpdateSlotCancellingBookings", mock.Anything, 1, newStart, end, 1, active[:1]).Return(&gym.TimeSlot{
+			ID: 1, GymID: 1, StartTime: newStart, EndTime: end, Capacity: 1,
+		}, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		resp, err := service.UpdateTimeSlot(context.Background(), 1, UpdateSlotRequest{
+			StartTime:      &newStartStr,
+			Capacity:       &newCapacity,
+			OverflowPolicy: OverflowCancelLatest,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.CancelledBookings)
+		assert.Equal(t, 1, resp.NotifiedMembers)
+		br.AssertExpectations(t)
+		gr.AssertExpectations(t)
+	})
+
+	t.Run("a failed refund leaves the slot unchanged", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+		br.On("UpdateSlotCancellingBookings", mock.Anything, 1, start, end, 1, active[:1]).Return(nil, errors.New("refund failed"))
+
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), nil, nil)
+
+		resp, err := service.UpdateTimeSlot(context.Background(), 1, UpdateSlotRequest{
+			Capacity:       &newCapacity,
+			OverflowPolicy: OverflowCancelLatest,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("reads local times in the gym's zone", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		berlin, _ := time.LoadLocation("Europe/Berlin")
+		winterSlot := &gym.TimeSlot{
+			ID: 2, GymID: 3, Capacity: 5,
+			StartTime: time.Date(2030, 3, 30, 8, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2030, 3, 30, 9, 0, 0, 0, time.UTC),
+		}
+		// 09:00 on the first day of summer time is 07:00 UTC.
+		wantStart := time.Date(2030, 3, 31, 7, 0, 0, 0, time.UTC)
+		wantEnd := wantStart.Add(time.Hour)
+		newStart, newEnd := "2030-03-31T09:00", "2030-03-31T10:00"
+
+		gr.On("GetTimeSlotByID", mock.Anything, 2).Return(winterSlot, nil)
+		gr.On("GetGymByID", mock.Anything, 3).Return(&gym.Gym{ID: 3, Timezone: "Europe/Berlin"}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 2).Return([]BookingWithDetails{}, nil)
+		br.On("UpdateSlotCancellingBookings", mock.Anything, 2,
+			mock.MatchedBy(wantStart.Equal), mock.MatchedBy(wantEnd.Equal), 5, []BookingWithDetails(nil),
+		).Return(&gym.TimeSlot{ID: 2, GymID: 3, StartTime: wantStart, EndTime: wantEnd, Capacity: 5}, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		resp, err := service.UpdateTimeSlot(context.Background(), 2, UpdateSlotRequest{StartTime: &newStart, EndTime: &newEnd})
+		assert.NoError(t, err)
+		assert.Equal(t, berlin.String(), resp.Slot.StartTime.Location().String())
+		assert.Equal(t, 9, resp.Slot.StartTime.Hour())
+		br.AssertExpectations(t)
+	})
+
+	t.Run("rejects a local time skipped by DST", func(t *testing.T) {
+		gr := new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		gr.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1, Timezone: "Europe/Berlin"}, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(new(MockBookingRepo), gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		gap := "2030-03-31T02:30"
+		_, err := service.UpdateTimeSlot(context.Background(), 1, UpdateSlotRequest{StartTime: &gap})
+		assert.ErrorIs(t, err, gym.ErrTimeSlotInvalid)
+	})
+}
+
+func TestService_DeleteTimeSlot(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour)
+	subPaid := PaidWithSubscription
+	subID := 7
+
+	slot := &gym.TimeSlot{ID: 1, GymID: 1, StartTime: start, EndTime: start.Add(time.Hour), Capacity: 10}
+	active := []BookingWithDetails{
+		{Booking: Booking{ID: 20, UserID: 4, Status: "booked", PaidWith: &subPaid, SubscriptionID: &subID}, UserEmail: "d@example.com", UserName: "D"},
+	}
+
+	t.Run("refuses to delete a slot with bookings", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		_, err := service.DeleteTimeSlot(context.Background(), 1, false)
+		assert.ErrorIs(t, err, ErrSlotHasBookings)
+		br.AssertNotCalled(t, "CancelSlotWithBookings", mock.Anything, 1, mock.Anything)
+	})
+
+	t.Run("cancels the slot together with its bookings", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+		br.On("CancelSlotWithBookings", mock.Anything, 1, active).Return(nil)
+
+		emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		cancelled, err := service.DeleteTimeSlot(context.Background(), 1, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cancelled)
+		br.AssertExpectations(t)
+	})
+
+	t.Run("reports no cancellations when the transaction fails", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetTimeSlotByID", mock.Anything, 1).Return(slot, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return(active, nil)
+		br.On("CancelSlotWithBookings", mock.Anything, 1, active).Return(errors.New("refund failed"))
+
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), nil, nil)
+
+		cancelled, err := service.DeleteTimeSlot(context.Background(), 1, true)
+		assert.Error(t, err)
+		assert.Equal(t, 0, cancelled)
+	})
+}
+
+func TestService_CancelClosureSlots(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).UTC()
+	closure := &gym.Closure{ID: 5, GymID: 1, StartsAt: start.Add(-time.Hour), EndsAt: start.Add(4 * time.Hour), Reason: "Maintenance"}
+	walletPaid := PaidWithWallet
+	slots := []gym.TimeSlot{
+		{ID: 1, GymID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+		{ID: 2, GymID: 1, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+	}
+	booked := BookingWithDetails{Booking: Booking{ID: 30, UserID: 9, Status: "booked", PaidWith: &walletPaid, AmountCents: 1500, Currency: "KZT"}, GymID: 1, UserEmail: "e@example.com", UserName: "E"}
+	emailService := email.New("from@test.com", "Test", "localhost", "1025", "", "", "localhost:6379")
+
+	t.Run("cancels each slot with its bookings", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetClosureByID", mock.Anything, 5).Return(closure, nil)
+		gr.On("GetTimeSlotsInRange", mock.Anything, 1, closure.StartsAt, closure.EndsAt).Return(slots, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return([]BookingWithDetails{
+			booked,
+			{Booking: Booking{ID: 31, UserID: 8, Status: "cancelled"}},
+		}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 2).Return([]BookingWithDetails{}, nil)
+		br.On("CancelSlotWithBookings", mock.Anything, 1, []BookingWithDetails{booked}).Return(nil)
+		br.On("CancelSlotWithBookings", mock.Anything, 2, []BookingWithDetails{}).Return(nil)
+
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		resp, err := service.CancelClosureSlots(context.Background(), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.CancelledSlots)
+		assert.Equal(t, 1, resp.CancelledBookings)
+		assert.Empty(t, resp.FailedSlotIDs)
+		br.AssertExpectations(t)
+		gr.AssertExpectations(t)
+	})
+
+	t.Run("reports a failed slot and carries on", func(t *testing.T) {
+		br, gr := new(MockBookingRepo), new(MockGymRepo)
+		gr.On("GetClosureByID", mock.Anything, 5).Return(closure, nil)
+		gr.On("GetTimeSlotsInRange", mock.Anything, 1, closure.StartsAt, closure.EndsAt).Return(slots, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 1).Return([]BookingWithDetails{booked}, nil)
+		br.On("GetBookingsByTimeSlot", mock.Anything, 2).Return([]BookingWithDetails{}, nil)
+		br.On("CancelSlotWithBookings", mock.Anything, 1, []BookingWithDetails{booked}).Return(errors.New("refund failed"))
+		br.On("CancelSlotWithBookings", mock.Anything, 2, []BookingWithDetails{}).Return(nil)
+
+		service := NewService(br, gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		resp, err := service.CancelClosureSlots(context.Background(), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.CancelledSlots)
+		assert.Equal(t, 0, resp.CancelledBookings)
+		assert.Equal(t, []int{1}, resp.FailedSlotIDs)
+		br.AssertExpectations(t)
+	})
+
+	t.Run("unknown closure", func(t *testing.T) {
+		gr := new(MockGymRepo)
+		gr.On("GetClosureByID", mock.Anything, 99).Return(nil, errors.New("not found"))
+
+		service := NewService(new(MockBookingRepo), gr, new(MockSubscriptionRepo), new(MockWalletRepo), new(MockUserRepo), emailService, nil)
+
+		_, err := service.CancelClosureSlots(context.Background(), 99)
+		assert.ErrorIs(t, err, gym.ErrClosureNotFound)
+	})
+}
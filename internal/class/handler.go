@@ -0,0 +1,212 @@
+package class
+
+import (
+	"net/http"
+	"strconv"
+
+	"fitslot/internal/api"
+	"fitslot/internal/gym"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// @Summary      Create a class type
+// @Description  Admin-only: add a kind of class (yoga, HIIT, boxing) that time slots can offer
+// @Tags         admin,classes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body class.CreateClassTypeRequest true "Class type payload"
+// @Success      201 {object} class.ClassType
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/class-types [post]
+func (h *Handler) CreateClassType(c *gin.Context) {
+	var req CreateClassTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	classType, err := h.service.CreateClassType(c.Request.Context(), req)
+	if err != nil {
+		switch err {
+		case ErrClassTypeInvalid:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid class type data"})
+		case ErrClassTypeExists:
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "Class type already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create class type"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, classType)
+}
+
+// @Summary      List class types
+// @Tags         classes
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} class.ClassType
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /class-types [get]
+func (h *Handler) ListClassTypes(c *gin.Context) {
+	classTypes, err := h.service.GetClassTypes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch class types"})
+		return
+	}
+
+	c.JSON(http.StatusOK, classTypes)
+}
+
+// @Summary      Create an instructor
+// @Description  Admin-only: add an instructor profile and the gyms they teach at
+// @Tags         admin,classes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body class.InstructorRequest true "Instructor payload"
+// @Success      201 {object} class.Instructor
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/instructors [post]
+func (h *Handler) CreateInstructor(c *gin.Context) {
+	var req InstructorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	instructor, err := h.service.CreateInstructor(c.Request.Context(), req)
+	if err != nil {
+		h.instructorError(c, err, "Failed to create instructor")
+		return
+	}
+
+	c.JSON(http.StatusCreated, instructor)
+}
+
+// @Summary      Update an instructor
+// @Description  Admin-only: replace an instructor's profile and the gyms they teach at
+// @Tags         admin,classes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        instructorID path int true "Instructor ID"
+// @Param        request body class.InstructorRequest true "Instructor payload"
+// @Success      200 {object} class.Instructor
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/instructors/{instructorID} [put]
+func (h *Handler) UpdateInstructor(c *gin.Context) {
+	instructorID, err := strconv.Atoi(c.Param("instructorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid instructor ID"})
+		return
+	}
+
+	var req InstructorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	instructor, err := h.service.UpdateInstructor(c.Request.Context(), instructorID, req)
+	if err != nil {
+		h.instructorError(c, err, "Failed to update instructor")
+		return
+	}
+
+	c.JSON(http.StatusOK, instructor)
+}
+
+func (h *Handler) instructorError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case ErrInstructorInvalid:
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid instructor data"})
+	case ErrInstructorNotFound:
+		c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Instructor not found"})
+	case gym.ErrGymNotFound:
+		c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: fallback})
+	}
+}
+
+// @Summary      List instructors
+// @Tags         classes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gym_id query int false "Only instructors teaching at this gym"
+// @Success      200 {array} class.Instructor
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /instructors [get]
+func (h *Handler) ListInstructors(c *gin.Context) {
+	var gymID *int
+	if v := c.Query("gym_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+			return
+		}
+		gymID = &id
+	}
+
+	instructors, err := h.service.GetInstructors(c.Request.Context(), gymID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch instructors"})
+		return
+	}
+
+	c.JSON(http.StatusOK, instructors)
+}
+
+// @Summary      Get an instructor
+// @Tags         classes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        instructorID path int true "Instructor ID"
+// @Success      200 {object} class.Instructor
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Router       /instructors/{instructorID} [get]
+func (h *Handler) GetInstructor(c *gin.Context) {
+	instructorID, err := strconv.Atoi(c.Param("instructorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid instructor ID"})
+		return
+	}
+
+	instructor, err := h.service.GetInstructor(c.Request.Context(), instructorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Instructor not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, instructor)
+}
@@ -0,0 +1,36 @@
+package class
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ClassType is a kind of session a time slot can offer, e.g. yoga or HIIT.
+type ClassType struct {
+	ID          int       `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name" example:"Yoga"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+type Instructor struct {
+	ID        int           `db:"id" json:"id"`
+	Name      string        `db:"name" json:"name"`
+	Bio       string        `db:"bio" json:"bio"`
+	GymIDs    pq.Int64Array `db:"gym_ids" json:"gym_ids" swaggertype:"array,integer"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+}
+
+type CreateClassTypeRequest struct {
+	Name        string `json:"name" binding:"required" example:"Yoga"`
+	Description string `json:"description"`
+}
+
+// InstructorRequest is used both to create an instructor and to replace an
+// existing instructor's profile and the gyms they teach at.
+type InstructorRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Bio    string `json:"bio"`
+	GymIDs []int  `json:"gym_ids"`
+}
@@ -0,0 +1,178 @@
+package class
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrInstructorNotFound = errors.New("instructor not found")
+
+// instructorSelect aggregates the gyms an instructor teaches at into gym_ids.
+const instructorSelect = `
+	SELECT i.id, i.name, i.bio, i.created_at,
+		COALESCE(ARRAY_AGG(ig.gym_id ORDER BY ig.gym_id) FILTER (WHERE ig.gym_id IS NOT NULL), '{}') AS gym_ids
+	FROM instructors i
+	LEFT JOIN instructor_gyms ig ON ig.instructor_id = i.id
+`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateClassType(ctx context.Context, name, description string) (*ClassType, error) {
+	query := `
+		INSERT INTO class_types (name, description)
+		VALUES ($1, $2)
+		RETURNING id, name, description, created_at
+	`
+
+	var classType ClassType
+	err := r.db.GetContext(ctx, &classType, query, name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	return &classType, nil
+}
+
+func (r *repository) GetAllClassTypes(ctx context.Context) ([]ClassType, error) {
+	query := `
+		SELECT id, name, description, created_at
+		FROM class_types
+		ORDER BY name ASC
+	`
+
+	var classTypes []ClassType
+	err := r.db.SelectContext(ctx, &classTypes, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return classTypes, nil
+}
+
+func (r *repository) ClassTypeNameExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM class_types WHERE LOWER(name) = LOWER($1))`
+	err := r.db.GetContext(ctx, &exists, query, name)
+	return exists, err
+}
+
+func (r *repository) CreateInstructor(ctx context.Context, name, bio string, gymIDs []int) (*Instructor, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.GetContext(ctx, &id, `INSERT INTO instructors (name, bio) VALUES ($1, $2) RETURNING id`, name, bio)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setInstructorGyms(ctx, tx, id, gymIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetInstructorByID(ctx, id)
+}
+
+func (r *repository) UpdateInstructor(ctx context.Context, id int, name, bio string, gymIDs []int) (*Instructor, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE instructors SET name = $2, bio = $3 WHERE id = $1`, id, name, bio)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, ErrInstructorNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM instructor_gyms WHERE instructor_id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	if err := setInstructorGyms(ctx, tx, id, gymIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetInstructorByID(ctx, id)
+}
+
+func setInstructorGyms(ctx context.Context, tx *sqlx.Tx, instructorID int, gymIDs []int) error {
+	for _, gymID := range gymIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO instructor_gyms (instructor_id, gym_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, instructorID, gymID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) GetInstructorByID(ctx context.Context, id int) (*Instructor, error) {
+	query := instructorSelect + `
+		WHERE i.id = $1
+		GROUP BY i.id
+	`
+
+	var instructor Instructor
+	err := r.db.GetContext(ctx, &instructor, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instructor, nil
+}
+
+// GetInstructors lists all instructors, or only those teaching at gymID.
+func (r *repository) GetInstructors(ctx context.Context, gymID *int) ([]Instructor, error) {
+	query := instructorSelect
+	args := []interface{}{}
+
+	if gymID != nil {
+		query += `
+		WHERE EXISTS (
+			SELECT 1 FROM instructor_gyms f
+			WHERE f.instructor_id = i.id AND f.gym_id = $1
+		)`
+		args = append(args, *gymID)
+	}
+
+	query += " GROUP BY i.id ORDER BY i.name ASC"
+
+	var instructors []Instructor
+	err := r.db.SelectContext(ctx, &instructors, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return instructors, nil
+}
@@ -0,0 +1,13 @@
+package class
+
+import "context"
+
+type Repository interface {
+	CreateClassType(ctx context.Context, name, description string) (*ClassType, error)
+	GetAllClassTypes(ctx context.Context) ([]ClassType, error)
+	ClassTypeNameExists(ctx context.Context, name string) (bool, error)
+	CreateInstructor(ctx context.Context, name, bio string, gymIDs []int) (*Instructor, error)
+	UpdateInstructor(ctx context.Context, id int, name, bio string, gymIDs []int) (*Instructor, error)
+	GetInstructorByID(ctx context.Context, id int) (*Instructor, error)
+	GetInstructors(ctx context.Context, gymID *int) ([]Instructor, error)
+}
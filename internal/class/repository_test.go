@@ -0,0 +1,96 @@
+package class
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func setupClassMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(sqlxDB)
+
+	closer := func() { sqlxDB.Close() }
+	return repo, mock, closer
+}
+
+func TestCreateClassType(t *testing.T) {
+	repo, mock, close := setupClassMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO class_types.*`).
+		WithArgs("Yoga", "Slow flow").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
+			AddRow(1, "Yoga", "Slow flow", time.Now()))
+
+	classType, err := repo.CreateClassType(context.Background(), "Yoga", "Slow flow")
+	require.NoError(t, err)
+	require.Equal(t, 1, classType.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateInstructor(t *testing.T) {
+	repo, mock, close := setupClassMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO instructors.*`).
+		WithArgs("Anna", "Certified yoga teacher").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectExec(`INSERT INTO instructor_gyms.*`).
+		WithArgs(5, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO instructor_gyms.*`).
+		WithArgs(5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT i\.id, .* FROM instructors i LEFT JOIN instructor_gyms ig .* WHERE i\.id = \$1 GROUP BY i\.id`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "bio", "created_at", "gym_ids"}).
+			AddRow(5, "Anna", "Certified yoga teacher", time.Now(), "{1,2}"))
+
+	instructor, err := repo.CreateInstructor(context.Background(), "Anna", "Certified yoga teacher", []int{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 5, instructor.ID)
+	require.Equal(t, pq.Int64Array{1, 2}, instructor.GymIDs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInstructor_NotFound(t *testing.T) {
+	repo, mock, close := setupClassMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE instructors SET name = \$2, bio = \$3 WHERE id = \$1`).
+		WithArgs(9, "Anna", "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateInstructor(context.Background(), 9, "Anna", "", nil)
+	require.ErrorIs(t, err, ErrInstructorNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInstructors_ByGym(t *testing.T) {
+	repo, mock, close := setupClassMock(t)
+	defer close()
+
+	gymID := 2
+	mock.ExpectQuery(`FROM instructors i .* WHERE EXISTS \( SELECT 1 FROM instructor_gyms f WHERE f\.instructor_id = i\.id AND f\.gym_id = \$1 \) GROUP BY i\.id ORDER BY i\.name ASC`).
+		WithArgs(gymID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "bio", "created_at", "gym_ids"}).
+			AddRow(5, "Anna", "", time.Now(), "{1,2}"))
+
+	instructors, err := repo.GetInstructors(context.Background(), &gymID)
+	require.NoError(t, err)
+	require.Len(t, instructors, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+package class
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"fitslot/internal/gym"
+)
+
+var (
+	ErrClassTypeExists   = errors.New("class type already exists")
+	ErrClassTypeInvalid  = errors.New("invalid class type")
+	ErrInstructorInvalid = errors.New("invalid instructor")
+)
+
+type Service interface {
+	CreateClassType(ctx context.Context, req CreateClassTypeRequest) (*ClassType, error)
+	GetClassTypes(ctx context.Context) ([]ClassType, error)
+	CreateInstructor(ctx context.Context, req InstructorRequest) (*Instructor, error)
+	UpdateInstructor(ctx context.Context, id int, req InstructorRequest) (*Instructor, error)
+	GetInstructor(ctx context.Context, id int) (*Instructor, error)
+	GetInstructors(ctx context.Context, gymID *int) ([]Instructor, error)
+}
+
+type service struct {
+	repo    Repository
+	gymRepo gym.Repository
+}
+
+func NewService(repo Repository, gymRepo gym.Repository) Service {
+	return &service{
+		repo:    repo,
+		gymRepo: gymRepo,
+	}
+}
+
+func (s *service) CreateClassType(ctx context.Context, req CreateClassTypeRequest) (*ClassType, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrClassTypeInvalid
+	}
+
+	exists, err := s.repo.ClassTypeNameExists(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrClassTypeExists
+	}
+
+	return s.repo.CreateClassType(ctx, name, strings.TrimSpace(req.Description))
+}
+
+func (s *service) GetClassTypes(ctx context.Context) ([]ClassType, error) {
+	return s.repo.GetAllClassTypes(ctx)
+}
+
+func (s *service) CreateInstructor(ctx context.Context, req InstructorRequest) (*Instructor, error) {
+	if err := s.validateInstructor(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateInstructor(ctx, strings.TrimSpace(req.Name), req.Bio, req.GymIDs)
+}
+
+func (s *service) UpdateInstructor(ctx context.Context, id int, req InstructorRequest) (*Instructor, error) {
+	if err := s.validateInstructor(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateInstructor(ctx, id, strings.TrimSpace(req.Name), req.Bio, req.GymIDs)
+}
+
+func (s *service) validateInstructor(ctx context.Context, req InstructorRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return ErrInstructorInvalid
+	}
+
+	for _, gymID := range req.GymIDs {
+		if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+			return gym.ErrGymNotFound
+		}
+	}
+
+	return nil
+}
+
+func (s *service) GetInstructor(ctx context.Context, id int) (*Instructor, error) {
+	instructor, err := s.repo.GetInstructorByID(ctx, id)
+	if err != nil {
+		return nil, ErrInstructorNotFound
+	}
+	return instructor, nil
+}
+
+func (s *service) GetInstructors(ctx context.Context, gymID *int) ([]Instructor, error) {
+	return s.repo.GetInstructors(ctx, gymID)
+}
@@ -0,0 +1,137 @@
+package class
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fitslot/internal/gym"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateClassType(ctx context.Context, name, description string) (*ClassType, error) {
+	args := m.Called(ctx, name, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ClassType), args.Error(1)
+}
+
+func (m *MockRepository) GetAllClassTypes(ctx context.Context) ([]ClassType, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ClassType), args.Error(1)
+}
+
+func (m *MockRepository) ClassTypeNameExists(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateInstructor(ctx context.Context, name, bio string, gymIDs []int) (*Instructor, error) {
+	args := m.Called(ctx, name, bio, gymIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Instructor), args.Error(1)
+}
+
+func (m *MockRepository) UpdateInstructor(ctx context.Context, id int, name, bio string, gymIDs []int) (*Instructor, error) {
+	args := m.Called(ctx, id, name, bio, gymIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Instructor), args.Error(1)
+}
+
+func (m *MockRepository) GetInstructorByID(ctx context.Context, id int) (*Instructor, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Instructor), args.Error(1)
+}
+
+func (m *MockRepository) GetInstructors(ctx context.Context, gymID *int) ([]Instructor, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Instructor), args.Error(1)
+}
+
+// MockGymRepo only stubs the gym lookups the class service relies on;
+// calling anything else panics on the nil embedded interface.
+type MockGymRepo struct {
+	mock.Mock
+	gym.Repository
+}
+
+func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Gym), args.Error(1)
+}
+
+func TestService_CreateClassType(t *testing.T) {
+	t.Run("creates a new class type", func(t *testing.T) {
+		repo := new(MockRepository)
+		repo.On("ClassTypeNameExists", mock.Anything, "Boxing").Return(false, nil)
+		repo.On("CreateClassType", mock.Anything, "Boxing", "").Return(&ClassType{ID: 1, Name: "Boxing"}, nil)
+
+		service := NewService(repo, new(MockGymRepo))
+		classType, err := service.CreateClassType(context.Background(), CreateClassTypeRequest{Name: " Boxing "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Boxing", classType.Name)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a duplicate name", func(t *testing.T) {
+		repo := new(MockRepository)
+		repo.On("ClassTypeNameExists", mock.Anything, "yoga").Return(true, nil)
+
+		service := NewService(repo, new(MockGymRepo))
+		_, err := service.CreateClassType(context.Background(), CreateClassTypeRequest{Name: "yoga"})
+
+		assert.ErrorIs(t, err, ErrClassTypeExists)
+		repo.AssertNotCalled(t, "CreateClassType", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_CreateInstructor(t *testing.T) {
+	t.Run("validates every gym", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		gymRepo.On("GetGymByID", mock.Anything, 99).Return(nil, errors.New("not found"))
+
+		service := NewService(repo, gymRepo)
+		_, err := service.CreateInstructor(context.Background(), InstructorRequest{Name: "Anna", GymIDs: []int{1, 99}})
+
+		assert.ErrorIs(t, err, gym.ErrGymNotFound)
+		repo.AssertNotCalled(t, "CreateInstructor", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("creates the instructor", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("CreateInstructor", mock.Anything, "Anna", "Yoga teacher", []int{1}).Return(&Instructor{ID: 5, Name: "Anna"}, nil)
+
+		service := NewService(repo, gymRepo)
+		instructor, err := service.CreateInstructor(context.Background(), InstructorRequest{Name: "Anna", Bio: "Yoga teacher", GymIDs: []int{1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, instructor.ID)
+		repo.AssertExpectations(t)
+	})
+}
@@ -26,8 +26,25 @@ func Connect(databaseURL string) (*sqlx.DB, error) {
 	return db, nil
 }
 
-func RunMigrations(db *sqlx.DB, migrationsPath string) error {
-	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+// RunMigrations brings the schema up to date. legacyTimeZone is the zone
+// the server wrote times in before they were stored as TIMESTAMPTZ;
+// migration 013 reads the old values in it.
+func RunMigrations(db *sqlx.DB, migrationsPath, legacyTimeZone string) error {
+	ctx := context.Background()
+
+	// The setting lives in the session, so the migrations must run on the
+	// connection it is set on.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT set_config('fitslot.legacy_timezone', $1, false)`, legacyTimeZone); err != nil {
+		return fmt.Errorf("failed to set the legacy time zone: %w", err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
 	if err != nil {
 		return fmt.Errorf("failed to create postgres driver: %w", err)
 	}
@@ -12,6 +12,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// whenLayout renders slot times in emails. Callers pass times already in the
+// gym's zone, so the abbreviation tells members which clock is meant.
+const whenLayout = "Jan 2, 2006 at 3:04 PM MST"
+
 type EmailJob struct {
 	To      string    `json:"to"`
 	Name    string    `json:"name"`
@@ -168,7 +172,7 @@ Time: %s
 
 See you at the gym!
 
-- FitSlot Team`, name, bookingType, details, when.Format("Jan 2, 2006 at 3:04 PM"))
+- FitSlot Team`, name, bookingType, details, when.Format(whenLayout))
 
 	return s.Send(ctx, email, name, subject, body)
 }
@@ -185,7 +189,7 @@ Time: %s
 
 See you soon!
 
-- FitSlot Team`, name, bookingType, details, when.Format("Jan 2, 2006 at 3:04 PM"))
+- FitSlot Team`, name, bookingType, details, when.Format(whenLayout))
 
 	return s.Send(ctx, email, name, subject, body)
 }
@@ -204,3 +208,56 @@ Details: %s
 
 	return s.Send(ctx, email, name, subject, body)
 }
+
+func (s *Service) SendSlotRescheduled(ctx context.Context, email, name, bookingType string, oldWhen, newWhen time.Time) error {
+	subject := "Booking Rescheduled - " + bookingType
+	body := fmt.Sprintf(`Hi %s,
+
+The time of your booking has changed:
+
+Type: %s
+Was: %s
+Now: %s
+
+If the new time does not work for you, you can cancel the booking in the app.
+
+- FitSlot Team`, name, bookingType, oldWhen.Format(whenLayout), newWhen.Format(whenLayout))
+
+	return s.Send(ctx, email, name, subject, body)
+}
+
+func (s *Service) SendAutoTopUp(ctx context.Context, email, name, amount string) error {
+	subject := "Wallet Topped Up - " + amount
+	body := fmt.Sprintf(`Hi %s,
+
+Your wallet balance was running low, so we topped it up automatically:
+
+Amount: %s
+
+You can change or turn off auto top-up in the app.
+
+- FitSlot Team`, name, amount)
+
+	return s.Send(ctx, email, name, subject, body)
+}
+
+func (s *Service) SendAutoTopUpDeclined(ctx context.Context, email, name, amount, reason string, disabled bool) error {
+	subject := "Auto Top-Up Declined"
+	next := "We will try again the next time your balance runs low."
+	if disabled {
+		subject = "Auto Top-Up Turned Off"
+		next = "After several declined payments we have turned auto top-up off. Please check your payment method and turn it back on in the app."
+	}
+	body := fmt.Sprintf(`Hi %s,
+
+We could not top up your wallet automatically:
+
+Amount: %s
+Reason: %s
+
+%s
+
+- FitSlot Team`, name, amount, reason, next)
+
+	return s.Send(ctx, email, name, subject, body)
+}
@@ -88,6 +88,46 @@ func TestSendCancellation(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSendSlotRescheduled(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	ctx := context.Background()
+
+	mock.Regexp().ExpectLPush("emails", `.*`).SetVal(1)
+
+	svc := newTestService(db)
+
+	oldWhen := time.Now().Add(24 * time.Hour)
+	err := svc.SendSlotRescheduled(ctx, "user@example.com", "User", "Gym Slot", oldWhen, oldWhen.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendAutoTopUp(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	ctx := context.Background()
+
+	mock.Regexp().ExpectLPush("emails", `.*`).SetVal(1)
+
+	svc := newTestService(db)
+
+	err := svc.SendAutoTopUp(ctx, "user@example.com", "User", "10000.00 KZT")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendAutoTopUpDeclined(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	ctx := context.Background()
+
+	mock.Regexp().ExpectLPush("emails", `.*`).SetVal(1)
+
+	svc := newTestService(db)
+
+	err := svc.SendAutoTopUpDeclined(ctx, "user@example.com", "User", "10000.00 KZT", "card_declined", true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestQueueLength(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	ctx := context.Background()
@@ -0,0 +1,335 @@
+package gym
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"fitslot/internal/logger"
+	"fitslot/internal/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultAvailabilityTTL bounds how long a gym's cached availability lives
+// even when no invalidation reaches it.
+const DefaultAvailabilityTTL = 10 * time.Minute
+
+// availabilityRetryAfter is how long the cache stays out of the way after
+// Redis failed, so requests do not each wait for a timeout.
+const availabilityRetryAfter = 30 * time.Second
+
+const (
+	availabilityKeyPrefix = "fitslot:availability:"
+	availabilityDayLayout = "2006-01-02"
+
+	// Bookkeeping fields next to the day fields of a gym's hash.
+	loadedField  = "_loaded"
+	versionField = "_version"
+)
+
+// storeAvailability writes day fields unless an invalidation happened since
+// the hash was read (the version changed). A full load replaces the hash,
+// dropping days that no longer have slots, and restarts its TTL.
+//
+// KEYS[1] gym hash; ARGV[1] version read; ARGV[2] TTL in seconds; ARGV[3]
+// "1" for a full load; then day/JSON pairs.
+var storeAvailability = redis.NewScript(`
+local version = redis.call('HGET', KEYS[1], '_version') or '0'
+if version ~= ARGV[1] then
+	return 0
+end
+if ARGV[3] == '1' then
+	redis.call('DEL', KEYS[1])
+	redis.call('HSET', KEYS[1], '_version', version, '_loaded', '1')
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+for i = 4, #ARGV, 2 do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+return 1
+`)
+
+// readAvailability returns the version and loaded flag of a gym's hash,
+// then the name and value of each day field from the first day through the
+// last one (open-ended when empty). Days outside that range are not read.
+//
+// KEYS[1] gym hash; ARGV[1] first day; ARGV[2] last day or "".
+var readAvailability = redis.NewScript(`
+local days = {}
+for _, field in ipairs(redis.call('HKEYS', KEYS[1])) do
+	if string.sub(field, 1, 1) ~= '_' and field >= ARGV[1] and (ARGV[2] == '' or field <= ARGV[2]) then
+		table.insert(days, field)
+	end
+end
+local result = redis.call('HMGET', KEYS[1], '_version', '_loaded')
+if #days > 0 then
+	local values = redis.call('HMGET', KEYS[1], unpack(days))
+	for i, day in ipairs(days) do
+		table.insert(result, day)
+		table.insert(result, values[i])
+	end
+end
+return result
+`)
+
+// invalidateAvailability marks days for reload, or the whole gym when no day
+// is given, and bumps the version so that a reader holding older data does
+// not write it back. The hash is kept rather than deleted for the same
+// reason: the version must survive.
+//
+// KEYS[1] gym hash; ARGV[1] TTL in seconds; then the days to reload.
+var invalidateAvailability = redis.NewScript(`
+redis.call('HINCRBY', KEYS[1], '_version', 1)
+if #ARGV == 1 then
+	redis.call('HDEL', KEYS[1], '_loaded')
+end
+for i = 2, #ARGV do
+	redis.call('HSET', KEYS[1], ARGV[i], '')
+end
+if redis.call('TTL', KEYS[1]) < 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// AvailabilityCache keeps each gym's upcoming slot availability in Redis as
+// one hash with a field per UTC day holding that day's slots, starting with
+// today. Bookings and slot changes invalidate single days, so only those are
+// read again from the database, and a listing reads only the days it covers.
+//
+// Redis is optional: when it fails the cache reports errors, callers fall
+// back to the database and the cache is skipped for a short while.
+// Invalidations that could not be written are replayed for the whole gym
+// once Redis answers again. A nil *AvailabilityCache is valid and caches
+// nothing.
+type AvailabilityCache struct {
+	redis *redis.Client
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+	pending   map[int]struct{}
+}
+
+func NewAvailabilityCache(rdb *redis.Client, ttl time.Duration) *AvailabilityCache {
+	if ttl <= 0 {
+		ttl = DefaultAvailabilityTTL
+	}
+	return &AvailabilityCache{
+		redis:   rdb,
+		ttl:     ttl,
+		pending: make(map[int]struct{}),
+	}
+}
+
+// cachedAvailability is what a gym's hash held when it was read.
+type cachedAvailability struct {
+	version string
+	loaded  bool
+	days    map[string][]TimeSlotWithAvailability
+	stale   []string
+}
+
+// slots returns the cached slots of all days in start time order.
+func (a *cachedAvailability) slots() []TimeSlotWithAvailability {
+	days := make([]string, 0, len(a.days))
+	for day := range a.days {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	result := []TimeSlotWithAvailability{}
+	for _, day := range days {
+		result = append(result, a.days[day]...)
+	}
+	return result
+}
+
+func availabilityKey(gymID int) string {
+	return fmt.Sprintf("%s%d", availabilityKeyPrefix, gymID)
+}
+
+// availabilityDay names the cache field of the UTC day t falls on.
+func availabilityDay(t time.Time) string {
+	return t.UTC().Format(availabilityDayLayout)
+}
+
+// availabilityStart is the start of the first cached day, the UTC day of now.
+func availabilityStart(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Invalidate marks the days of the given times for reload in the gym's
+// cached availability, or the whole gym when no time is given.
+func (c *AvailabilityCache) Invalidate(ctx context.Context, gymID int, times ...time.Time) {
+	if c == nil {
+		return
+	}
+
+	if !c.available(ctx) {
+		c.deferInvalidation(gymID)
+		return
+	}
+
+	if err := c.invalidate(ctx, gymID, times...); err != nil {
+		c.fail(err)
+		c.deferInvalidation(gymID)
+		metrics.RecordAvailabilityCacheInvalidation("error")
+		return
+	}
+	metrics.RecordAvailabilityCacheInvalidation("ok")
+}
+
+func (c *AvailabilityCache) invalidate(ctx context.Context, gymID int, times ...time.Time) error {
+	args := []interface{}{c.ttlSeconds()}
+	seen := make(map[string]bool, len(times))
+	for _, t := range times {
+		day := availabilityDay(t)
+		if !seen[day] {
+			seen[day] = true
+			args = append(args, day)
+		}
+	}
+
+	return invalidateAvailability.Run(ctx, c.redis, []string{availabilityKey(gymID)}, args...).Err()
+}
+
+// read returns what the cache holds for the gym's days from firstDay
+// through lastDay, or all later days when lastDay is empty. Days marked for
+// reload are listed in stale instead of days.
+func (c *AvailabilityCache) read(ctx context.Context, gymID int, firstDay, lastDay string) (*cachedAvailability, error) {
+	if !c.available(ctx) {
+		return nil, fmt.Errorf("availability cache unavailable until %s", c.retryAt().Format(time.RFC3339))
+	}
+
+	values, err := readAvailability.Run(ctx, c.redis, []string{availabilityKey(gymID)}, firstDay, lastDay).Slice()
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	if len(values) < 2 {
+		err := fmt.Errorf("availability cache returned %d values", len(values))
+		c.fail(err)
+		return nil, err
+	}
+
+	cached := &cachedAvailability{
+		version: "0",
+		days:    make(map[string][]TimeSlotWithAvailability),
+	}
+	if version, ok := values[0].(string); ok {
+		cached.version = version
+	}
+	cached.loaded = values[1] != nil
+
+	for i := 2; i+1 < len(values); i += 2 {
+		day, _ := values[i].(string)
+		value, _ := values[i+1].(string)
+		if value == "" {
+			cached.stale = append(cached.stale, day)
+			continue
+		}
+		var slots []TimeSlotWithAvailability
+		if err := json.Unmarshal([]byte(value), &slots); err != nil {
+			cached.stale = append(cached.stale, day)
+			continue
+		}
+		cached.days[day] = slots
+	}
+	sort.Strings(cached.stale)
+
+	return cached, nil
+}
+
+// write stores freshly loaded days. It is a no-op when the gym was
+// invalidated after version was read.
+func (c *AvailabilityCache) write(ctx context.Context, gymID int, version string, days map[string][]TimeSlotWithAvailability, full bool) error {
+	fullArg := "0"
+	if full {
+		fullArg = "1"
+	}
+	args := []interface{}{version, c.ttlSeconds(), fullArg}
+
+	names := make([]string, 0, len(days))
+	for day := range days {
+		names = append(names, day)
+	}
+	sort.Strings(names)
+
+	for _, day := range names {
+		slots := days[day]
+		if slots == nil {
+			slots = []TimeSlotWithAvailability{}
+		}
+		data, err := json.Marshal(slots)
+		if err != nil {
+			return err
+		}
+		args = append(args, day, string(data))
+	}
+
+	if err := storeAvailability.Run(ctx, c.redis, []string{availabilityKey(gymID)}, args...).Err(); err != nil {
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+func (c *AvailabilityCache) ttlSeconds() string {
+	return fmt.Sprintf("%d", int(c.ttl.Seconds()))
+}
+
+// available reports whether Redis should be tried. Once it is back,
+// invalidations missed while it was down are replayed first.
+func (c *AvailabilityCache) available(ctx context.Context) bool {
+	c.mu.Lock()
+	if time.Now().Before(c.downUntil) {
+		c.mu.Unlock()
+		return false
+	}
+	pending := c.pending
+	c.pending = make(map[int]struct{})
+	c.mu.Unlock()
+
+	for gymID := range pending {
+		if err := c.invalidate(ctx, gymID); err != nil {
+			c.fail(err)
+			c.mu.Lock()
+			for id := range pending {
+				c.pending[id] = struct{}{}
+			}
+			c.mu.Unlock()
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *AvailabilityCache) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.downUntil) {
+		return
+	}
+	c.downUntil = time.Now().Add(availabilityRetryAfter)
+	logger.Errorf("Availability cache disabled for %s: %v", availabilityRetryAfter, err)
+}
+
+func (c *AvailabilityCache) retryAt() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.downUntil
+}
+
+func (c *AvailabilityCache) deferInvalidation(gymID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[gymID] = struct{}{}
+}
@@ -0,0 +1,255 @@
+package gym
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"fitslot/internal/metrics"
+)
+
+// cachedRepository serves GetTimeSlotsWithAvailability from an
+// AvailabilityCache and invalidates it on every slot and closure change
+// made through the repository. Bookings change outside of it, so the
+// booking service invalidates the cache itself.
+type cachedRepository struct {
+	Repository
+	cache *AvailabilityCache
+}
+
+// NewCachedRepository wraps repo with the availability cache. A nil cache
+// returns repo unchanged.
+func NewCachedRepository(repo Repository, cache *AvailabilityCache) Repository {
+	if cache == nil {
+		return repo
+	}
+	return &cachedRepository{Repository: repo, cache: cache}
+}
+
+// GetTimeSlotsWithAvailability answers listings from the cache, reading only
+// the days the listing covers and applying the time range, availability,
+// sort and page of the filter in memory. Listings filtered by class type or
+// instructor, and listings reaching back before today, go to the database.
+func (r *cachedRepository) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlotWithAvailability, error) {
+	if filter.ClassType != "" || filter.InstructorID != nil {
+		return r.Repository.GetTimeSlotsWithAvailability(ctx, gymID, filter)
+	}
+
+	now := time.Now()
+	start := availabilityStart(now)
+	from := filter.From
+	if filter.OnlyFuture && from.Before(now) {
+		from = now
+	}
+	if from.Before(start) {
+		return r.Repository.GetTimeSlotsWithAvailability(ctx, gymID, filter)
+	}
+
+	var lastDay string
+	if !filter.To.IsZero() {
+		lastDay = availabilityDay(filter.To.Add(-time.Nanosecond))
+	}
+
+	cached, err := r.cache.read(ctx, gymID, availabilityDay(from), lastDay)
+	if err != nil {
+		metrics.RecordAvailabilityCache("error")
+		return r.Repository.GetTimeSlotsWithAvailability(ctx, gymID, filter)
+	}
+
+	if cached.loaded && len(cached.stale) == 0 {
+		metrics.RecordAvailabilityCache("hit")
+		return applySlotFilter(cached.slots(), filter)
+	}
+
+	metrics.RecordAvailabilityCache("miss")
+	fresh, err := r.loadDays(ctx, gymID, cached, start)
+	if err != nil {
+		return nil, err
+	}
+	for day, slots := range fresh {
+		cached.days[day] = slots
+	}
+
+	if err := r.cache.write(ctx, gymID, cached.version, fresh, !cached.loaded); err != nil {
+		metrics.RecordAvailabilityCache("error")
+	}
+
+	return applySlotFilter(cached.slots(), filter)
+}
+
+// loadDays reads what the cache is missing from the database: every day
+// from start on when the gym was never loaded, otherwise only the stale
+// days. Stale days without slots are returned empty so they stop being
+// reloaded.
+func (r *cachedRepository) loadDays(ctx context.Context, gymID int, cached *cachedAvailability, start time.Time) (map[string][]TimeSlotWithAvailability, error) {
+	fresh := make(map[string][]TimeSlotWithAvailability)
+
+	if !cached.loaded {
+		slots, err := r.Repository.GetTimeSlotsWithAvailability(ctx, gymID, SlotFilter{From: start})
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			day := availabilityDay(slot.StartTime)
+			fresh[day] = append(fresh[day], slot)
+		}
+		cached.days = make(map[string][]TimeSlotWithAvailability)
+	} else {
+		for _, day := range cached.stale {
+			from, err := time.Parse(availabilityDayLayout, day)
+			if err != nil {
+				continue
+			}
+			slots, err := r.Repository.GetTimeSlotsWithAvailability(ctx, gymID, SlotFilter{From: from, To: from.AddDate(0, 0, 1)})
+			if err != nil {
+				return nil, err
+			}
+			fresh[day] = slots
+		}
+	}
+
+	for _, day := range cached.stale {
+		if _, ok := fresh[day]; !ok {
+			fresh[day] = []TimeSlotWithAvailability{}
+		}
+	}
+
+	return fresh, nil
+}
+
+// applySlotFilter does in memory what the repository query does for a
+// filter without class type or instructor.
+func applySlotFilter(slots []TimeSlotWithAvailability, filter SlotFilter) ([]TimeSlotWithAvailability, error) {
+	var after int64
+	if filter.After != nil {
+		var err error
+		if after, err = cursorSortValue(filter.Sort.Field, filter.After.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	result := make([]TimeSlotWithAvailability, 0, len(slots))
+	for _, slot := range slots {
+		switch {
+		case filter.OnlyFuture && !slot.StartTime.After(now):
+		case !filter.From.IsZero() && slot.StartTime.Before(filter.From):
+		case !filter.To.IsZero() && !slot.StartTime.Before(filter.To):
+		case filter.AvailableOnly && slot.IsFull:
+		default:
+			result = append(result, slot)
+		}
+	}
+
+	less := func(a, b TimeSlotWithAvailability) bool {
+		va, vb := slotSortValue(a, filter.Sort.Field), slotSortValue(b, filter.Sort.Field)
+		if va != vb {
+			return va < vb
+		}
+		return a.ID < b.ID
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if filter.Sort.Desc {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+
+	if filter.After != nil {
+		start := sort.Search(len(result), func(i int) bool {
+			value := slotSortValue(result[i], filter.Sort.Field)
+			if filter.Sort.Desc {
+				return value < after || (value == after && result[i].ID < filter.After.ID)
+			}
+			return value > after || (value == after && result[i].ID > filter.After.ID)
+		})
+		result = result[start:]
+	}
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+
+	return result, nil
+}
+
+func (r *cachedRepository) CreateTimeSlot(ctx context.Context, gymID int, slot NewTimeSlot) (*TimeSlot, error) {
+	created, err := r.Repository.CreateTimeSlot(ctx, gymID, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Invalidate(ctx, gymID, created.StartTime)
+	return created, nil
+}
+
+func (r *cachedRepository) CreateTimeSlots(ctx context.Context, gymID int, slots []NewTimeSlot) ([]TimeSlot, error) {
+	created, err := r.Repository.CreateTimeSlots(ctx, gymID, slots)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, len(created))
+	for _, slot := range created {
+		times = append(times, slot.StartTime)
+	}
+	if len(times) > 0 {
+		r.cache.Invalidate(ctx, gymID, times...)
+	}
+	return created, nil
+}
+
+func (r *cachedRepository) UpdateTimeSlot(ctx context.Context, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+	previous, err := r.Repository.GetTimeSlotByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := r.Repository.UpdateTimeSlot(ctx, id, startTime, endTime, capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Invalidate(ctx, updated.GymID, previous.StartTime, updated.StartTime)
+	return updated, nil
+}
+
+func (r *cachedRepository) CancelTimeSlot(ctx context.Context, id int) error {
+	slot, err := r.Repository.GetTimeSlotByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Repository.CancelTimeSlot(ctx, id); err != nil {
+		return err
+	}
+
+	r.cache.Invalidate(ctx, slot.GymID, slot.StartTime)
+	return nil
+}
+
+// Closures hide every slot they overlap, possibly over many days, so they
+// invalidate the whole gym.
+func (r *cachedRepository) CreateClosure(ctx context.Context, gymID int, startsAt, endsAt time.Time, reason string) (*Closure, error) {
+	closure, err := r.Repository.CreateClosure(ctx, gymID, startsAt, endsAt, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Invalidate(ctx, gymID)
+	return closure, nil
+}
+
+func (r *cachedRepository) DeleteClosure(ctx context.Context, id int) error {
+	closure, err := r.Repository.GetClosureByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Repository.DeleteClosure(ctx, id); err != nil {
+		return err
+	}
+
+	r.cache.Invalidate(ctx, closure.GymID)
+	return nil
+}
@@ -0,0 +1,260 @@
+package gym
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func availabilityJSON(t *testing.T, slots ...TimeSlotWithAvailability) string {
+	t.Helper()
+	if slots == nil {
+		slots = []TimeSlotWithAvailability{}
+	}
+	data, err := json.Marshal(slots)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestCachedRepository_GetTimeSlotsWithAvailability(t *testing.T) {
+	ctx := context.Background()
+	past := TimeSlotWithAvailability{
+		TimeSlot:    TimeSlot{ID: 1, GymID: 1, StartTime: time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC), Capacity: 10},
+		BookedCount: 10,
+		IsFull:      true,
+	}
+	upcoming := TimeSlotWithAvailability{
+		TimeSlot:    TimeSlot{ID: 2, GymID: 1, StartTime: time.Date(2099, 6, 1, 9, 0, 0, 0, time.UTC), Capacity: 10},
+		BookedCount: 3,
+		Available:   7,
+	}
+
+	t.Run("miss loads the upcoming days and stores them per day", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		now := time.Now()
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:1"}, availabilityDay(now), "").
+			SetVal([]interface{}{nil, nil})
+		repo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, SlotFilter{From: availabilityStart(now)}).
+			Return([]TimeSlotWithAvailability{upcoming}, nil)
+		rmock.ExpectEvalSha(storeAvailability.Hash(), []string{"fitslot:availability:1"},
+			"0", "60", "1",
+			"2099-06-01", availabilityJSON(t, upcoming),
+		).SetVal(int64(1))
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{OnlyFuture: true})
+		assert.NoError(t, err)
+		assert.Len(t, slots, 1)
+		assert.Equal(t, 2, slots[0].ID)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("hit does not touch the database", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:1"}, availabilityDay(time.Now()), "").
+			SetVal([]interface{}{"3", "1", "2099-06-01", availabilityJSON(t, upcoming)})
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{OnlyFuture: true})
+		assert.NoError(t, err)
+		assert.Len(t, slots, 1)
+		assert.Equal(t, 2, slots[0].ID)
+		assert.Equal(t, 7, slots[0].Available)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+		repo.AssertNotCalled(t, "GetTimeSlotsWithAvailability", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("hit reads the days of the range and applies the filter in memory", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		slot := func(id int, hour int, price int64, available int) TimeSlotWithAvailability {
+			return TimeSlotWithAvailability{
+				TimeSlot:  TimeSlot{ID: id, GymID: 1, StartTime: time.Date(2099, 6, 1, hour, 0, 0, 0, time.UTC), PriceCents: price},
+				Available: available,
+				IsFull:    available == 0,
+			}
+		}
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:1"}, "2099-06-01", "2099-06-01").
+			SetVal([]interface{}{nil, "1", "2099-06-01", availabilityJSON(t,
+				slot(3, 8, 1500, 2), slot(4, 9, 2000, 0), slot(5, 10, 1500, 1), slot(6, 11, 1000, 4), slot(7, 20, 3000, 5))})
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{
+			From:          time.Date(2099, 6, 1, 8, 0, 0, 0, time.UTC),
+			To:            time.Date(2099, 6, 1, 20, 0, 0, 0, time.UTC),
+			AvailableOnly: true,
+			Sort:          SortOrder{Field: SlotSortPrice, Desc: true},
+			Limit:         2,
+			After:         &Cursor{Sort: "-price", Key: "1500", ID: 5},
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, slots, 2) {
+			assert.Equal(t, 3, slots[0].ID)
+			assert.Equal(t, 6, slots[1].ID)
+		}
+		repo.AssertNotCalled(t, "GetTimeSlotsWithAvailability", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale day is reloaded alone", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		rebooked := upcoming
+		rebooked.BookedCount, rebooked.Available = 4, 6
+
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:1"}, availabilityDay(time.Now()), "").
+			SetVal([]interface{}{"4", "1", "2099-06-01", ""})
+		day := time.Date(2099, 6, 1, 0, 0, 0, 0, time.UTC)
+		repo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, SlotFilter{From: day, To: day.AddDate(0, 0, 1)}).
+			Return([]TimeSlotWithAvailability{rebooked}, nil)
+		rmock.ExpectEvalSha(storeAvailability.Hash(), []string{"fitslot:availability:1"},
+			"4", "60", "0",
+			"2099-06-01", availabilityJSON(t, rebooked),
+		).SetVal(int64(1))
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{OnlyFuture: true})
+		assert.NoError(t, err)
+		assert.Len(t, slots, 1)
+		assert.Equal(t, 6, slots[0].Available)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("filtered listings bypass the cache", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		filter := SlotFilter{ClassType: "yoga"}
+		repo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, filter).Return([]TimeSlotWithAvailability{upcoming}, nil)
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, filter)
+		assert.NoError(t, err)
+		assert.Len(t, slots, 1)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("listings reaching into the past bypass the cache", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		repo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, SlotFilter{}).
+			Return([]TimeSlotWithAvailability{past, upcoming}, nil)
+
+		slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, slots, 2)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("falls back to the database while redis is down", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		filter := SlotFilter{OnlyFuture: true}
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:1"}, availabilityDay(time.Now()), "").
+			SetErr(errors.New("connection refused"))
+		repo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, filter).Return([]TimeSlotWithAvailability{upcoming}, nil)
+
+		for i := 0; i < 2; i++ {
+			slots, err := cached.GetTimeSlotsWithAvailability(ctx, 1, filter)
+			assert.NoError(t, err)
+			assert.Len(t, slots, 1)
+		}
+
+		// The second call skipped redis altogether.
+		assert.NoError(t, rmock.ExpectationsWereMet())
+		repo.AssertNumberOfCalls(t, "GetTimeSlotsWithAvailability", 2)
+	})
+}
+
+func TestCachedRepository_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2099, 6, 1, 23, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	t.Run("new slot marks its UTC day for reload", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		repo.On("CreateTimeSlot", mock.Anything, 1, mock.Anything).Return(&TimeSlot{ID: 5, GymID: 1, StartTime: start}, nil)
+		rmock.ExpectEvalSha(invalidateAvailability.Hash(), []string{"fitslot:availability:1"}, "60", "2099-06-01").SetVal(int64(1))
+
+		_, err := cached.CreateTimeSlot(ctx, 1, NewTimeSlot{StartTime: start})
+		assert.NoError(t, err)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("moving a slot marks both days", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		moved := start.AddDate(0, 0, 2)
+		repo.On("GetTimeSlotByID", mock.Anything, 5).Return(&TimeSlot{ID: 5, GymID: 1, StartTime: start}, nil)
+		repo.On("UpdateTimeSlot", mock.Anything, 5, moved, moved.Add(time.Hour), 8).Return(&TimeSlot{ID: 5, GymID: 1, StartTime: moved}, nil)
+		rmock.ExpectEvalSha(invalidateAvailability.Hash(), []string{"fitslot:availability:1"}, "60", "2099-06-01", "2099-06-03").SetVal(int64(1))
+
+		_, err := cached.UpdateTimeSlot(ctx, 5, moved, moved.Add(time.Hour), 8)
+		assert.NoError(t, err)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("closures invalidate the whole gym", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		cached := NewCachedRepository(repo, NewAvailabilityCache(rdb, time.Minute))
+
+		repo.On("GetClosureByID", mock.Anything, 9).Return(&Closure{ID: 9, GymID: 1}, nil)
+		repo.On("DeleteClosure", mock.Anything, 9).Return(nil)
+		rmock.ExpectEvalSha(invalidateAvailability.Hash(), []string{"fitslot:availability:1"}, "60").SetVal(int64(1))
+
+		assert.NoError(t, cached.DeleteClosure(ctx, 9))
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("failed invalidation is replayed for the whole gym", func(t *testing.T) {
+		rdb, rmock := redismock.NewClientMock()
+		cache := NewAvailabilityCache(rdb, time.Minute)
+
+		rmock.ExpectEvalSha(invalidateAvailability.Hash(), []string{"fitslot:availability:1"}, "60", "2099-06-01").
+			SetErr(errors.New("connection refused"))
+		cache.Invalidate(ctx, 1, start)
+
+		// Pretend the retry period is over.
+		cache.mu.Lock()
+		cache.downUntil = time.Time{}
+		cache.mu.Unlock()
+
+		rmock.ExpectEvalSha(invalidateAvailability.Hash(), []string{"fitslot:availability:1"}, "60").SetVal(int64(1))
+		rmock.ExpectEvalSha(readAvailability.Hash(), []string{"fitslot:availability:2"}, "2099-06-01", "").
+			SetVal([]interface{}{"2", "1"})
+
+		_, err := cache.read(ctx, 2, "2099-06-01", "")
+		assert.NoError(t, err)
+		assert.NoError(t, rmock.ExpectationsWereMet())
+	})
+
+	t.Run("nil cache is a no-op", func(t *testing.T) {
+		var cache *AvailabilityCache
+		cache.Invalidate(ctx, 1, start)
+
+		repo := new(MockRepository)
+		assert.Same(t, Repository(repo), NewCachedRepository(repo, nil))
+	})
+}
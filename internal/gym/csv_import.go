@@ -0,0 +1,169 @@
+package gym
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxImportRows caps the size of a single CSV import.
+const MaxImportRows = 1000
+
+var requiredImportColumns = []string{"start_time", "end_time", "capacity"}
+
+// ImportTimeSlots validates every CSV row with the same rules as
+// CreateTimeSlot, including gym closures. In dry-run mode nothing is
+// written; otherwise the slots are created in one transaction, and only if
+// every row is valid.
+func (s *service) ImportTimeSlots(ctx context.Context, gymID int, csvData io.Reader, dryRun bool) (*ImportResult, error) {
+	g, err := s.repo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, ErrGymNotFound
+	}
+	loc := g.Zone()
+
+	requests, err := parseImportCSV(csvData)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.repo.GetClosuresByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:    dryRun,
+		TotalRows: len(requests),
+		Rows:      make([]ImportRowResult, 0, len(requests)),
+	}
+	slots := make([]NewTimeSlot, 0, len(requests))
+
+	for i, row := range requests {
+		// Row 1 is the header.
+		rowResult := ImportRowResult{Row: i + 2}
+
+		if row.err != nil {
+			rowResult.Error = row.err.Error()
+		} else if slot, err := validateTimeSlot(row.req, loc); err != nil {
+			rowResult.Error = strings.TrimPrefix(err.Error(), ErrTimeSlotInvalid.Error()+": ")
+		} else if err := checkClosures(slot, closures); err != nil {
+			rowResult.Error = err.Error()
+		} else {
+			startTime, endTime := slot.StartTime.In(loc), slot.EndTime.In(loc)
+			rowResult.Valid = true
+			rowResult.StartTime = &startTime
+			rowResult.EndTime = &endTime
+			slots = append(slots, slot)
+		}
+
+		if rowResult.Valid {
+			result.ValidRows++
+		} else {
+			result.InvalidRows++
+		}
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if result.InvalidRows > 0 {
+		return result, ErrImportHasErrors
+	}
+
+	created, err := s.repo.CreateTimeSlots(ctx, gymID, slots)
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(created)
+
+	return result, nil
+}
+
+type importRow struct {
+	req CreateTimeSlotRequest
+	err error
+}
+
+// parseImportCSV reads a header row followed by one slot per line. Columns
+// are matched by header name; price_cents and tags (separated by ';') are
+// optional.
+func parseImportCSV(data io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(data)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrImportInvalid)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrImportInvalid, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImportInvalid, err)
+		}
+
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrImportInvalid, MaxImportRows)
+		}
+
+		var row importRow
+		row.req.StartTime = field(record, "start_time")
+		row.req.EndTime = field(record, "end_time")
+
+		if capacity, err := strconv.Atoi(field(record, "capacity")); err != nil {
+			row.err = errors.New("capacity must be a number")
+		} else {
+			row.req.Capacity = capacity
+		}
+
+		if price := field(record, "price_cents"); price != "" && row.err == nil {
+			if priceCents, err := strconv.ParseInt(price, 10, 64); err != nil {
+				row.err = errors.New("price_cents must be a number")
+			} else {
+				row.req.PriceCents = &priceCents
+			}
+		}
+
+		if tags := field(record, "tags"); tags != "" {
+			for _, tag := range strings.Split(tags, ";") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					row.req.Tags = append(row.req.Tags, tag)
+				}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
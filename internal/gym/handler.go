@@ -1,9 +1,13 @@
 package gym
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"fitslot/internal/api"
 
@@ -43,6 +47,14 @@ func (h *Handler) CreateGym(c *gin.Context) {
 	ctx := c.Request.Context()
 	gym, err := h.service.CreateGym(ctx, req)
 	if err != nil {
+		if errors.Is(err, ErrTimezoneInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid timezone"})
+			return
+		}
+		if errors.Is(err, ErrCurrencyInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Unsupported currency"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create gym"})
 		return
 	}
@@ -51,18 +63,47 @@ func (h *Handler) CreateGym(c *gin.Context) {
 }
 
 // @Summary      List gyms
+// @Description  Cursor-paginated. from/to (RFC3339, or a local time or date read in each gym's time zone) and available_only restrict the list to gyms with a matching slot; to is exclusive.
 // @Tags         gyms,admin
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200 {array} gym.Gym
+// @Param        from query string false "Only gyms with a slot starting at or after this time"
+// @Param        to query string false "Only gyms with a slot starting before this time"
+// @Param        available_only query bool false "Only gyms with an upcoming slot that has free places"
+// @Param        sort query string false "created_at, name or rating; prefix with - for descending" default(-created_at)
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Success      200 {object} api.Page[gym.Gym]
+// @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /gyms [get]
 // @Router       /admin/gyms [get]
 func (h *Handler) ListGyms(c *gin.Context) {
+	req := ListGymsRequest{
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+
+	var err error
+	if req.AvailableOnly, err = queryBool(c, "available_only"); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.Limit, err = queryLimit(c); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	ctx := c.Request.Context()
-	gyms, err := h.service.GetAllGyms(ctx)
+	gyms, err := h.service.ListGyms(ctx, req)
 	if err != nil {
+		if errors.Is(err, ErrListingInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch gyms"})
 		return
 	}
@@ -70,6 +111,32 @@ func (h *Handler) ListGyms(c *gin.Context) {
 	c.JSON(http.StatusOK, gyms)
 }
 
+// queryBool reads an optional boolean query parameter.
+func queryBool(c *gin.Context, name string) (bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be true or false", ErrListingInvalid, name)
+	}
+	return b, nil
+}
+
+// queryLimit reads the optional page size; the service applies defaults.
+func queryLimit(c *gin.Context) (int, error) {
+	v := c.Query("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("%w: limit must be a positive number", ErrListingInvalid)
+	}
+	return limit, nil
+}
+
 // @Summary      Create a time slot
 // @Description  Admin-only: create a time slot for a gym
 // @Tags         admin,gyms
@@ -83,6 +150,7 @@ func (h *Handler) ListGyms(c *gin.Context) {
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      403 {object} api.ErrorResponse
 // @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /admin/gyms/{gymID}/slots [post]
 func (h *Handler) CreateTimeSlot(c *gin.Context) {
@@ -102,11 +170,13 @@ func (h *Handler) CreateTimeSlot(c *gin.Context) {
 	ctx := c.Request.Context()
 	slot, err := h.service.CreateTimeSlot(ctx, gymID, req)
 	if err != nil {
-		switch err {
-		case ErrGymNotFound:
+		switch {
+		case errors.Is(err, ErrGymNotFound):
 			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
-		case ErrTimeSlotInvalid:
-			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid time slot data"})
+		case errors.Is(err, ErrTimeSlotInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrGymClosed):
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create time slot"})
 		}
@@ -116,12 +186,96 @@ func (h *Handler) CreateTimeSlot(c *gin.Context) {
 	c.JSON(http.StatusCreated, slot)
 }
 
+// maxImportBytes limits the size of an uploaded CSV file.
+const maxImportBytes = 1 << 20
+
+// @Summary      Import time slots from CSV
+// @Description  Admin-only: bulk-create time slots from a CSV file with columns start_time, end_time, capacity and optional price_cents and tags (separated by ';').
+// @Description  With dry_run=true every row is validated and reported but nothing is created. Otherwise the import is all-or-nothing.
+// @Tags         admin,gyms
+// @Accept       multipart/form-data
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        file formData file false "CSV file (or send the CSV as the request body)"
+// @Param        dry_run query bool false "Validate only"
+// @Success      200 {object} gym.ImportResult
+// @Success      201 {object} gym.ImportResult
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      422 {object} gym.ImportResult
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/slots/import [post]
+func (h *Handler) ImportTimeSlots(c *gin.Context) {
+	gymIDStr := c.Param("gymID")
+	gymID, err := strconv.Atoi(gymIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var data io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "CSV file is required"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Failed to read CSV file"})
+			return
+		}
+		defer file.Close()
+		data = file
+	}
+
+	ctx := c.Request.Context()
+	result, err := h.service.ImportTimeSlots(ctx, gymID, data, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case errors.Is(err, ErrImportInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrImportHasErrors):
+			c.JSON(http.StatusUnprocessableEntity, result)
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to import time slots"})
+		}
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
 // @Summary      List time slots for a gym
+// @Description  Cursor-paginated. Members only see upcoming slots. from/to take RFC3339, or a local time or date read in the gym's time zone; to is exclusive.
 // @Tags         gyms,admin
 // @Produce      json
 // @Security     BearerAuth
 // @Param        gymID path int true "Gym ID"
-// @Success      200 {array} gym.TimeSlotWithAvailability
+// @Param        class_type query string false "Class type ID or name"
+// @Param        instructor_id query int false "Instructor ID"
+// @Param        from query string false "Earliest start time"
+// @Param        to query string false "Start time upper bound (exclusive)"
+// @Param        available_only query bool false "Only slots with free places"
+// @Param        sort query string false "start_time, price or available; prefix with - for descending" default(start_time)
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Success      200 {object} api.Page[gym.TimeSlotWithAvailability]
 // @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      404 {object} api.ErrorResponse
@@ -129,6 +283,170 @@ func (h *Handler) CreateTimeSlot(c *gin.Context) {
 // @Router       /gyms/{gymID}/slots [get]
 // @Router       /admin/gyms/{gymID}/slots [get]
 func (h *Handler) ListTimeSlots(c *gin.Context) {
+	if slots, ok := h.listTimeSlots(c); ok {
+		c.JSON(http.StatusOK, slots)
+	}
+}
+
+// listTimeSlots reads the slot listing of the request. On failure it writes
+// the error response and returns false.
+func (h *Handler) listTimeSlots(c *gin.Context) (*api.Page[TimeSlotWithAvailability], bool) {
+	gymIDStr := c.Param("gymID")
+	gymID, err := strconv.Atoi(gymIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return nil, false
+	}
+
+	req := ListTimeSlotsRequest{
+		OnlyFuture: !strings.Contains(c.Request.URL.Path, "/admin/"),
+		ClassType:  c.Query("class_type"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		Sort:       c.Query("sort"),
+		Cursor:     c.Query("cursor"),
+	}
+	if v := c.Query("instructor_id"); v != "" {
+		instructorID, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid instructor ID"})
+			return nil, false
+		}
+		req.InstructorID = &instructorID
+	}
+	if req.AvailableOnly, err = queryBool(c, "available_only"); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if req.Limit, err = queryLimit(c); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	slots, err := h.service.GetTimeSlots(ctx, gymID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case errors.Is(err, ErrListingInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch time slots"})
+		}
+		return nil, false
+	}
+
+	return slots, true
+}
+
+// @Summary      Search slots across gyms
+// @Description  Upcoming slots from every gym matching the filters, ordered by start time. from/to bound the slot start time. By default only slots with at least one free seat are returned.
+// @Description  Paginated with a cursor: pass next_cursor back as cursor with the same filters.
+// @Tags         gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from query string false "Earliest start time (RFC3339)"
+// @Param        to query string false "Latest start time, exclusive (RFC3339)"
+// @Param        gym_ids query string false "Comma-separated gym IDs"
+// @Param        class_type query string false "Class type ID or name"
+// @Param        min_available query int false "Minimum free seats" default(1)
+// @Param        currency query string false "Only gyms pricing slots in this currency; required with max_price_cents"
+// @Param        max_price_cents query int false "Maximum price in cents of currency"
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Success      200 {object} api.Page[gym.SlotSearchResult]
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /slots/search [get]
+func (h *Handler) SearchSlots(c *gin.Context) {
+	search, err := parseSlotSearch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	slots, err := h.service.SearchTimeSlots(c.Request.Context(), search)
+	if err != nil {
+		if errors.Is(err, ErrSearchInvalid) || errors.Is(err, ErrListingInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to search time slots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+func parseSlotSearch(c *gin.Context) (SlotSearch, error) {
+	search := SlotSearch{
+		ClassType:    c.Query("class_type"),
+		Currency:     c.Query("currency"),
+		MinAvailable: 1,
+	}
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if search.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return search, fmt.Errorf("%w: from must be RFC3339", ErrSearchInvalid)
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if search.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return search, fmt.Errorf("%w: to must be RFC3339", ErrSearchInvalid)
+		}
+	}
+
+	if v := c.Query("gym_ids"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			gymID, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return search, fmt.Errorf("%w: gym_ids must be a comma-separated list of IDs", ErrSearchInvalid)
+			}
+			search.GymIDs = append(search.GymIDs, gymID)
+		}
+	}
+
+	if v := c.Query("min_available"); v != "" {
+		if search.MinAvailable, err = strconv.Atoi(v); err != nil {
+			return search, fmt.Errorf("%w: min_available must be a number", ErrSearchInvalid)
+		}
+	}
+	if v := c.Query("max_price_cents"); v != "" {
+		maxPrice, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return search, fmt.Errorf("%w: max_price_cents must be a number", ErrSearchInvalid)
+		}
+		search.MaxPriceCents = &maxPrice
+	}
+
+	if search.Limit, err = queryLimit(c); err != nil {
+		return search, err
+	}
+	search.Cursor = c.Query("cursor")
+
+	return search, nil
+}
+
+// @Summary      Add a gym closure
+// @Description  Admin-only: close the gym for a whole day (date) or a partial day (starts_at/ends_at).
+// @Description  Slots inside the closure are hidden from listings and returned as affected_slots; cancel them with POST /admin/closures/{closureID}/cancel-slots.
+// @Tags         admin,gyms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body gym.CreateClosureRequest true "Closure payload"
+// @Success      201 {object} gym.CreateClosureResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/closures [post]
+func (h *Handler) CreateClosure(c *gin.Context) {
 	gymIDStr := c.Param("gymID")
 	gymID, err := strconv.Atoi(gymIDStr)
 	if err != nil {
@@ -136,18 +454,226 @@ func (h *Handler) ListTimeSlots(c *gin.Context) {
 		return
 	}
 
+	var req CreateClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.service.CreateClosure(ctx, gymID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case errors.Is(err, ErrClosureInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create closure"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// @Summary      List gym closures
+// @Tags         gyms,admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {array} gym.Closure
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID}/closures [get]
+// @Router       /admin/gyms/{gymID}/closures [get]
+func (h *Handler) ListClosures(c *gin.Context) {
+	gymIDStr := c.Param("gymID")
+	gymID, err := strconv.Atoi(gymIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	closures, err := h.service.GetClosures(ctx, gymID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch closures"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, closures)
+}
+
+// @Summary      Delete a gym closure
+// @Description  Admin-only: reopen the gym. Slots cancelled because of the closure stay cancelled.
+// @Tags         admin,gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        closureID path int true "Closure ID"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/closures/{closureID} [delete]
+func (h *Handler) DeleteClosure(c *gin.Context) {
+	closureID, err := strconv.Atoi(c.Param("closureID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid closure ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.DeleteClosure(ctx, closureID); err != nil {
+		switch {
+		case errors.Is(err, ErrClosureNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Closure not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to delete closure"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "Closure deleted"})
+}
+
+// @Summary      Add a room
+// @Description  Admin-only: add a room or zone (weights floor, studio, pool) with its own maximum occupancy
+// @Tags         admin,gyms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body gym.CreateRoomRequest true "Room payload"
+// @Success      201 {object} gym.Room
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/rooms [post]
+func (h *Handler) CreateRoom(c *gin.Context) {
+	gymIDStr := c.Param("gymID")
+	gymID, err := strconv.Atoi(gymIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	var req CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	ctx := c.Request.Context()
-	onlyFuture := !strings.Contains(c.Request.URL.Path, "/admin/")
-	slots, err := h.service.GetTimeSlots(ctx, gymID, onlyFuture)
+	room, err := h.service.CreateRoom(ctx, gymID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case errors.Is(err, ErrRoomInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid room data"})
+		case errors.Is(err, ErrRoomExists):
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "A room with this name already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create room"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
+}
+
+// @Summary      List rooms of a gym
+// @Tags         gyms,admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {array} gym.Room
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID}/rooms [get]
+// @Router       /admin/gyms/{gymID}/rooms [get]
+func (h *Handler) ListRooms(c *gin.Context) {
+	gymIDStr := c.Param("gymID")
+	gymID, err := strconv.Atoi(gymIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rooms, err := h.service.GetRooms(ctx, gymID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch rooms"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+// @Summary      Get live gym occupancy
+// @Description  Current headcount, based on the bookings of the slots running right now, plus the typical busyness per hour of a weekday averaged over past weeks.
+// @Tags         gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        weeks query int false "Weeks of history to average" default(4)
+// @Param        weekday query int false "Weekday for the typical busyness, 0 = Sunday (default: today)"
+// @Success      200 {object} gym.Occupancy
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID}/occupancy [get]
+func (h *Handler) GetOccupancy(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(DefaultOccupancyWeeks)))
+
+	var weekday *time.Weekday
+	if v := c.Query("weekday"); v != "" {
+		day, err := strconv.Atoi(v)
+		if err != nil || day < 0 || day > 6 {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "weekday must be between 0 (Sunday) and 6 (Saturday)"})
+			return
+		}
+		wd := time.Weekday(day)
+		weekday = &wd
+	}
+
+	occupancy, err := h.service.GetOccupancy(c.Request.Context(), gymID, weeks, weekday)
 	if err != nil {
 		switch err {
 		case ErrGymNotFound:
 			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
 		default:
-			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch time slots"})
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch occupancy"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, slots)
+	c.JSON(http.StatusOK, occupancy)
 }
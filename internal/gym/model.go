@@ -1,39 +1,291 @@
 package gym
 
-import "time"
+import (
+	"time"
 
+	"github.com/lib/pq"
+)
+
+// Gym carries its average rating and review count in listings; both only
+// account for reviews that have not been hidden by a moderator.
+//
+// Timezone is the IANA zone the gym operates in. Slot times are rendered in
+// it, and wall-clock times without an offset are read in it. Slot prices are
+// in the gym's Currency.
 type Gym struct {
-	ID        int       `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Location  string    `db:"location" json:"location"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID            int       `db:"id" json:"id"`
+	Name          string    `db:"name" json:"name"`
+	Location      string    `db:"location" json:"location"`
+	Timezone      string    `db:"timezone" json:"timezone" example:"Europe/Berlin"`
+	Currency      string    `db:"currency" json:"currency" example:"KZT"`
+	AverageRating float64   `db:"average_rating" json:"average_rating" example:"4.5"`
+	ReviewCount   int       `db:"review_count" json:"review_count" example:"12"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
 }
 
 type TimeSlot struct {
-	ID        int       `db:"id" json:"id"`
-	GymID     int       `db:"gym_id" json:"gym_id"`
-	StartTime time.Time `db:"start_time" json:"start_time"`
-	EndTime   time.Time `db:"end_time" json:"end_time"`
-	Capacity  int       `db:"capacity" json:"capacity"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID           int            `db:"id" json:"id"`
+	GymID        int            `db:"gym_id" json:"gym_id"`
+	StartTime    time.Time      `db:"start_time" json:"start_time"`
+	EndTime      time.Time      `db:"end_time" json:"end_time"`
+	Capacity     int            `db:"capacity" json:"capacity"`
+	PriceCents   int64          `db:"price_cents" json:"price_cents"`
+	Tags         pq.StringArray `db:"tags" json:"tags" swaggertype:"array,string"`
+	ClassTypeID  *int           `db:"class_type_id" json:"class_type_id,omitempty"`
+	InstructorID *int           `db:"instructor_id" json:"instructor_id,omitempty"`
+	RoomID       *int           `db:"room_id" json:"room_id,omitempty"`
+	TemplateID   *int           `db:"template_id" json:"template_id,omitempty"`
+	CancelledAt  *time.Time     `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
 }
 
 type TimeSlotWithAvailability struct {
 	TimeSlot
-	BookedCount int  `json:"booked_count"`
-	Available   int  `json:"available"`
-	IsFull      bool `json:"is_full"`
+	BookedCount int  `db:"booked_count" json:"booked_count"`
+	Available   int  `db:"available" json:"available"`
+	IsFull      bool `db:"is_full" json:"is_full"`
+}
+
+// PublicTimeSlot is what the public catalogue shows of a slot: its
+// schedule and free places, without booking counts or internal references.
+type PublicTimeSlot struct {
+	ID           int            `json:"id"`
+	GymID        int            `json:"gym_id"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	Capacity     int            `json:"capacity"`
+	PriceCents   int64          `json:"price_cents"`
+	Tags         pq.StringArray `json:"tags" swaggertype:"array,string"`
+	ClassTypeID  *int           `json:"class_type_id,omitempty"`
+	InstructorID *int           `json:"instructor_id,omitempty"`
+	RoomID       *int           `json:"room_id,omitempty"`
+	Available    int            `json:"available"`
+	IsFull       bool           `json:"is_full"`
+}
+
+func (s TimeSlotWithAvailability) Public() PublicTimeSlot {
+	return PublicTimeSlot{
+		ID:           s.ID,
+		GymID:        s.GymID,
+		StartTime:    s.StartTime,
+		EndTime:      s.EndTime,
+		Capacity:     s.Capacity,
+		PriceCents:   s.PriceCents,
+		Tags:         s.Tags,
+		ClassTypeID:  s.ClassTypeID,
+		InstructorID: s.InstructorID,
+		RoomID:       s.RoomID,
+		Available:    s.Available,
+		IsFull:       s.IsFull,
+	}
 }
 
 type CreateGymRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Location string `json:"location" binding:"required"`
+	Timezone string `json:"timezone,omitempty" example:"Europe/Berlin"`
+	Currency string `json:"currency,omitempty" example:"EUR"`
 }
 
 type CreateTimeSlotRequest struct {
-	StartTime string `json:"start_time" binding:"required"`
-	EndTime   string `json:"end_time" binding:"required"`
-	Capacity  int    `json:"capacity" binding:"required,min=1"`
+	StartTime    string   `json:"start_time" binding:"required"`
+	EndTime      string   `json:"end_time" binding:"required"`
+	Capacity     int      `json:"capacity" binding:"required,min=1"`
+	PriceCents   *int64   `json:"price_cents,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	ClassTypeID  *int     `json:"class_type_id,omitempty"`
+	InstructorID *int     `json:"instructor_id,omitempty"`
+	RoomID       *int     `json:"room_id,omitempty"`
+}
+
+// NewTimeSlot is a validated slot ready to be written by the repository.
+type NewTimeSlot struct {
+	StartTime    time.Time
+	EndTime      time.Time
+	Capacity     int
+	PriceCents   int64
+	Tags         []string
+	ClassTypeID  *int
+	InstructorID *int
+	RoomID       *int
+}
+
+// SlotFilter narrows down, sorts and pages a gym's slot listing. ClassType
+// matches either a class type ID or its name. From and To bound the slot
+// start time to [From, To); a zero value leaves that side open.
+// AvailableOnly drops full slots. The zero Sort orders by start time, and a
+// zero Limit returns every match.
+type SlotFilter struct {
+	OnlyFuture    bool
+	ClassType     string
+	InstructorID  *int
+	From          time.Time
+	To            time.Time
+	AvailableOnly bool
+	Sort          SortOrder
+	Limit         int
+	After         *Cursor
+}
+
+// ListTimeSlotsRequest holds the query parameters of a gym's slot listing.
+// From and To accept RFC3339, a local time or a date, read in the gym's time
+// zone; To is exclusive.
+type ListTimeSlotsRequest struct {
+	OnlyFuture    bool
+	ClassType     string
+	InstructorID  *int
+	From          string
+	To            string
+	AvailableOnly bool
+	Sort          string
+	Limit         int
+	Cursor        string
+}
+
+// GymQuery filters, sorts and pages the gym listing. When From, To or
+// AvailableOnly is set only gyms with a live slot starting in [From, To)
+// are listed; with AvailableOnly that slot must be upcoming and have free
+// places. Local bounds are read in each gym's own time zone.
+type GymQuery struct {
+	From          TimeBound
+	To            TimeBound
+	AvailableOnly bool
+	Sort          SortOrder
+	Limit         int
+	After         *Cursor
+}
+
+// ListGymsRequest holds the query parameters of the gym listing.
+type ListGymsRequest struct {
+	From          string
+	To            string
+	AvailableOnly bool
+	Sort          string
+	Limit         int
+	Cursor        string
+}
+
+// SlotSearch filters the cross-gym slot search. From and To bound the slot
+// start time; a zero value leaves that side open. Only upcoming, bookable
+// slots are ever returned, ordered by start time. Currency keeps gyms that
+// price their slots in it; MaxPriceCents is in that currency, so it needs
+// one. Cursor is the next_cursor of the previous page; the service decodes
+// it into After.
+type SlotSearch struct {
+	From          time.Time
+	To            time.Time
+	GymIDs        []int
+	ClassType     string
+	MinAvailable  int
+	Currency      string
+	MaxPriceCents *int64
+	Limit         int
+	Cursor        string
+	After         *Cursor
+}
+
+type SlotSearchResult struct {
+	TimeSlotWithAvailability
+	GymName     string `db:"gym_name" json:"gym_name" example:"Downtown Fitness"`
+	GymLocation string `db:"gym_location" json:"gym_location" example:"123 Main St"`
+	GymTimezone string `db:"gym_timezone" json:"gym_timezone" example:"Europe/Berlin"`
+	// Currency is the gym's currency, which PriceCents is in.
+	Currency string `db:"gym_currency" json:"currency" example:"EUR"`
+}
+
+// Occupancy is how crowded a gym is right now, alongside how busy it
+// typically is at each hour of the same weekday. Source tells where the
+// current headcount comes from: "bookings" counts members booked into the
+// slots running right now.
+type Occupancy struct {
+	GymID     int              `json:"gym_id"`
+	AsOf      time.Time        `json:"as_of"`
+	Source    string           `json:"source" example:"bookings"`
+	Headcount int              `json:"headcount" example:"18"`
+	Capacity  int              `json:"capacity" example:"40"`
+	Percent   int              `json:"percent" example:"45"`
+	Weekday   string           `json:"weekday" example:"Monday"`
+	Weeks     int              `json:"weeks" example:"4"`
+	Typical   []HourlyBusyness `json:"typical"`
+}
+
+// HourlyBusyness is the average headcount during one hour of the day.
+// Busyness scales it from 0 to 100 relative to the busiest hour.
+type HourlyBusyness struct {
+	Hour             int     `json:"hour" example:"18"`
+	AverageHeadcount float64 `json:"average_headcount" example:"12.5"`
+	Busyness         int     `json:"busyness" example:"80"`
 }
 
+// OccupancySnapshot sums the bookings and capacity of the slots running at
+// a given moment.
+type OccupancySnapshot struct {
+	Headcount int `db:"headcount"`
+	Capacity  int `db:"capacity"`
+}
 
+// HourCount is the number of booked members present during an hour of the
+// day, summed over a period.
+type HourCount struct {
+	Hour     int `db:"hour"`
+	Bookings int `db:"bookings"`
+}
+
+type ImportRowResult struct {
+	Row       int        `json:"row" example:"2"`
+	Valid     bool       `json:"valid"`
+	Error     string     `json:"error,omitempty" example:"end_time must be after start_time"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun      bool              `json:"dry_run"`
+	TotalRows   int               `json:"total_rows"`
+	ValidRows   int               `json:"valid_rows"`
+	InvalidRows int               `json:"invalid_rows"`
+	Imported    int               `json:"imported"`
+	Rows        []ImportRowResult `json:"rows"`
+}
+
+// Room is a physical area of a gym (weights floor, studio, pool) with its
+// own maximum occupancy, shared by every slot held there at the same time.
+type Room struct {
+	ID        int       `db:"id" json:"id"`
+	GymID     int       `db:"gym_id" json:"gym_id"`
+	Name      string    `db:"name" json:"name" example:"Studio"`
+	Capacity  int       `db:"capacity" json:"capacity" example:"25"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type CreateRoomRequest struct {
+	Name     string `json:"name" binding:"required" example:"Studio"`
+	Capacity int    `json:"capacity" binding:"required,min=1" example:"25"`
+}
+
+// Closure is a period during which the gym is closed: a public holiday,
+// a maintenance day or just a few hours.
+type Closure struct {
+	ID        int       `db:"id" json:"id"`
+	GymID     int       `db:"gym_id" json:"gym_id"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt    time.Time `db:"ends_at" json:"ends_at"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateClosureRequest takes either a whole day (date) or an explicit
+// starts_at/ends_at range for partial-day closures.
+type CreateClosureRequest struct {
+	Date     string `json:"date,omitempty" example:"2024-12-25"`
+	StartsAt string `json:"starts_at,omitempty" example:"2024-12-24T14:00:00Z"`
+	EndsAt   string `json:"ends_at,omitempty" example:"2024-12-24T18:00:00Z"`
+	Reason   string `json:"reason" example:"Christmas"`
+}
+
+// CreateClosureResponse lists the existing slots that fall into the new
+// closure. They stay in place until an admin cancels them.
+type CreateClosureResponse struct {
+	Closure       Closure    `json:"closure"`
+	AffectedSlots []TimeSlot `json:"affected_slots"`
+}
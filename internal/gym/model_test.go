@@ -2,6 +2,7 @@ package gym
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -59,3 +60,18 @@ func TestCreateTimeSlotRequest_Validation(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Capacity")
 	assert.Contains(t, w.Body.String(), "required")
 }
+
+func TestTimeSlotWithAvailability_Public(t *testing.T) {
+	templateID := 3
+	slot := TimeSlotWithAvailability{
+		TimeSlot:    TimeSlot{ID: 1, GymID: 2, Capacity: 10, PriceCents: 1500, TemplateID: &templateID},
+		BookedCount: 4,
+		Available:   6,
+	}
+
+	data, err := json.Marshal(slot.Public())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"available":6`)
+	assert.NotContains(t, string(data), "booked_count")
+	assert.NotContains(t, string(data), "template_id")
+}
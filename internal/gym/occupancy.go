@@ -0,0 +1,99 @@
+package gym
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Number of past weeks the typical busyness is averaged over.
+const (
+	DefaultOccupancyWeeks = 4
+	MaxOccupancyWeeks     = 26
+)
+
+// OccupancySourceBookings marks a headcount derived from the bookings of
+// the slots currently running.
+const OccupancySourceBookings = "bookings"
+
+// GetOccupancy reports the gym's current headcount and its typical busyness
+// per hour on weekday (today when nil), averaged over the last weeks full
+// weeks. Days and hours follow the gym's local time.
+func (s *service) GetOccupancy(ctx context.Context, gymID int, weeks int, weekday *time.Weekday) (*Occupancy, error) {
+	g, err := s.repo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, ErrGymNotFound
+	}
+	loc := g.Zone()
+
+	if weeks <= 0 {
+		weeks = DefaultOccupancyWeeks
+	}
+	if weeks > MaxOccupancyWeeks {
+		weeks = MaxOccupancyWeeks
+	}
+
+	now := time.Now().In(loc)
+	day := now.Weekday()
+	if weekday != nil {
+		day = *weekday
+	}
+
+	snapshot, err := s.repo.GetOccupancySnapshot(ctx, gymID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only whole past days count, so today's partial data doesn't drag
+	// the averages down. AddDate keeps local midnight across DST changes.
+	year, month, date := now.Date()
+	to := time.Date(year, month, date, 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -7*weeks)
+	counts, err := s.repo.GetHourlyBookings(ctx, gymID, from, to, day, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Occupancy{
+		GymID:     gymID,
+		AsOf:      now,
+		Source:    OccupancySourceBookings,
+		Headcount: snapshot.Headcount,
+		Capacity:  snapshot.Capacity,
+		Percent:   percent(snapshot.Headcount, snapshot.Capacity),
+		Weekday:   day.String(),
+		Weeks:     weeks,
+		Typical:   typicalBusyness(counts, weeks),
+	}, nil
+}
+
+// typicalBusyness turns booking counts summed over weeks into one entry per
+// hour of the day.
+func typicalBusyness(counts []HourCount, weeks int) []HourlyBusyness {
+	byHour := make([]int, 24)
+	peak := 0
+	for _, c := range counts {
+		if c.Hour < 0 || c.Hour > 23 {
+			continue
+		}
+		byHour[c.Hour] = c.Bookings
+		peak = max(peak, c.Bookings)
+	}
+
+	typical := make([]HourlyBusyness, 24)
+	for hour, bookings := range byHour {
+		typical[hour] = HourlyBusyness{
+			Hour:             hour,
+			AverageHeadcount: math.Round(float64(bookings)/float64(weeks)*10) / 10,
+			Busyness:         percent(bookings, peak),
+		}
+	}
+	return typical
+}
+
+func percent(part, total int) int {
+	if total <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(part) * 100 / float64(total)))
+}
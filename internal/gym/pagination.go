@@ -0,0 +1,260 @@
+package gym
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitslot/internal/api"
+)
+
+// Page sizes for the gym and slot listings.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrListingInvalid = errors.New("invalid listing parameters")
+
+// Sort fields of the gym listing.
+const (
+	GymSortCreatedAt = "created_at"
+	GymSortName      = "name"
+	GymSortRating    = "rating"
+)
+
+// Sort fields of the slot listing.
+const (
+	SlotSortStartTime = "start_time"
+	SlotSortPrice     = "price"
+	SlotSortAvailable = "available"
+)
+
+// sortColumn is the listing column a sort field orders by and the SQL type
+// its cursor key is cast to.
+type sortColumn struct {
+	column string
+	cast   string
+}
+
+var gymSortColumns = map[string]sortColumn{
+	GymSortCreatedAt: {column: "created_at", cast: "timestamptz"},
+	GymSortName:      {column: "name", cast: "text"},
+	GymSortRating:    {column: "average_rating", cast: "float8"},
+}
+
+var slotSortColumns = map[string]sortColumn{
+	SlotSortStartTime: {column: "start_time", cast: "timestamptz"},
+	SlotSortPrice:     {column: "price_cents", cast: "bigint"},
+	SlotSortAvailable: {column: "available", cast: "integer"},
+}
+
+// SortOrder is a listing's sort field and direction, written "price" for
+// ascending and "-price" for descending. Ties are broken by ID in the same
+// direction so that every item has a unique position.
+type SortOrder struct {
+	Field string
+	Desc  bool
+}
+
+func (o SortOrder) String() string {
+	if o.Desc {
+		return "-" + o.Field
+	}
+	return o.Field
+}
+
+func parseSortOrder(value string, columns map[string]sortColumn, def SortOrder) (SortOrder, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	order := SortOrder{Field: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+	if _, ok := columns[order.Field]; !ok {
+		fields := make([]string, 0, len(columns))
+		for field := range columns {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		return order, fmt.Errorf("%w: sort must be one of %s, optionally prefixed with -", ErrListingInvalid, strings.Join(fields, ", "))
+	}
+	return order, nil
+}
+
+// Cursor marks the last item of a page by its sort key and ID. Listings are
+// paginated by key rather than by offset, so items added or removed on
+// earlier pages do not shift the following ones.
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int    `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseCursor decodes a cursor and checks that it was issued for the same
+// sort order and carries a key of the right type.
+func parseCursor(value string, order SortOrder) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("%w: cursor is malformed", ErrListingInvalid)
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, invalid
+	}
+
+	if cursor.Sort != order.String() {
+		return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrListingInvalid, cursor.Sort)
+	}
+
+	switch order.Field {
+	case GymSortCreatedAt, SlotSortStartTime:
+		_, err = time.Parse(time.RFC3339Nano, cursor.Key)
+	case GymSortRating:
+		_, err = strconv.ParseFloat(cursor.Key, 64)
+	case SlotSortPrice, SlotSortAvailable:
+		_, err = strconv.ParseInt(cursor.Key, 10, 64)
+	}
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &cursor, nil
+}
+
+// pageLimit applies the default and maximum page size.
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+// keyset returns the cursor condition (if any) and ORDER BY of a listing
+// query over the subquery alias, appending the cursor's arguments.
+func keyset(alias string, order SortOrder, columns map[string]sortColumn, after *Cursor, args []interface{}) (string, []interface{}) {
+	col := columns[order.Field]
+	dir, cmp := "ASC", ">"
+	if order.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	var clause string
+	if after != nil {
+		args = append(args, after.Key, after.ID)
+		clause = fmt.Sprintf(" WHERE (%s.%s, %s.id) %s ($%d::%s, $%d)",
+			alias, col.column, alias, cmp, len(args)-1, col.cast, len(args))
+	}
+	clause += fmt.Sprintf(" ORDER BY %s.%s %s, %s.id %s", alias, col.column, dir, alias, dir)
+
+	return clause, args
+}
+
+// newPage trims the limit+1 items a repository returned to one page and
+// points the cursor at its last item.
+func newPage[T any](items []T, limit int, cursor func(T) Cursor) *api.Page[T] {
+	page := &api.Page[T]{Items: items, Limit: limit}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.HasMore = true
+		page.NextCursor = cursor(page.Items[limit-1]).Encode()
+	}
+
+	return page
+}
+
+func gymCursor(order SortOrder) func(Gym) Cursor {
+	return func(g Gym) Cursor {
+		var key string
+		switch order.Field {
+		case GymSortName:
+			key = g.Name
+		case GymSortRating:
+			key = strconv.FormatFloat(g.AverageRating, 'g', -1, 64)
+		default:
+			key = g.CreatedAt.UTC().Format(time.RFC3339Nano)
+		}
+		return Cursor{Sort: order.String(), Key: key, ID: g.ID}
+	}
+}
+
+func slotCursor(order SortOrder) func(TimeSlotWithAvailability) Cursor {
+	return func(s TimeSlotWithAvailability) Cursor {
+		key := s.StartTime.UTC().Format(time.RFC3339Nano)
+		if order.Field != SlotSortStartTime && order.Field != "" {
+			key = strconv.FormatInt(slotSortValue(s, order.Field), 10)
+		}
+		return Cursor{Sort: order.String(), Key: key, ID: s.ID}
+	}
+}
+
+// slotSortValue is the value a slot is ordered by, as a number.
+func slotSortValue(s TimeSlotWithAvailability, field string) int64 {
+	switch field {
+	case SlotSortPrice:
+		return s.PriceCents
+	case SlotSortAvailable:
+		return int64(s.Available)
+	default:
+		return s.StartTime.UnixNano()
+	}
+}
+
+// cursorSortValue is the counterpart of slotSortValue for a cursor key.
+func cursorSortValue(field, key string) (int64, error) {
+	if field == SlotSortStartTime || field == "" {
+		t, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return 0, err
+		}
+		return t.UnixNano(), nil
+	}
+	return strconv.ParseInt(key, 10, 64)
+}
+
+// parseRange reads the from/to parameters of a listing.
+func parseRange(from, to string) (TimeBound, TimeBound, error) {
+	var start, end TimeBound
+	var err error
+
+	if from != "" {
+		if start, err = ParseTimeBound(from); err != nil {
+			return start, end, fmt.Errorf("%w: from %v", ErrListingInvalid, err)
+		}
+	}
+	if to != "" {
+		if end, err = ParseTimeBound(to); err != nil {
+			return start, end, fmt.Errorf("%w: to %v", ErrListingInvalid, err)
+		}
+	}
+
+	// Bounds of different kinds depend on each gym's zone and are compared
+	// per gym by the query instead.
+	if !start.IsZero() && !end.IsZero() && start.Local == end.Local && !end.Time.After(start.Time) {
+		return start, end, fmt.Errorf("%w: to must be after from", ErrListingInvalid)
+	}
+
+	return start, end, nil
+}
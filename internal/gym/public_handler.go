@@ -0,0 +1,70 @@
+package gym
+
+import (
+	"net/http"
+
+	"fitslot/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List gyms (public)
+// @Description  Same as GET /gyms, without authentication. Responses carry an ETag and may be cached.
+// @Tags         public
+// @Produce      json
+// @Param        from query string false "Only gyms with a slot starting at or after this time"
+// @Param        to query string false "Only gyms with a slot starting before this time"
+// @Param        available_only query bool false "Only gyms with an upcoming slot that has free places"
+// @Param        sort query string false "created_at, name or rating; prefix with - for descending" default(-created_at)
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Success      200 {object} api.Page[gym.Gym]
+// @Success      304 "Not modified"
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      429 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /public/gyms [get]
+func (h *Handler) ListPublicGyms(c *gin.Context) {
+	h.ListGyms(c)
+}
+
+// @Summary      List upcoming time slots of a gym (public)
+// @Description  Upcoming slots with their free places, without booking details. Takes the same filters as GET /gyms/{gymID}/slots. Responses carry an ETag and may be cached.
+// @Tags         public
+// @Produce      json
+// @Param        gymID path int true "Gym ID"
+// @Param        class_type query string false "Class type ID or name"
+// @Param        instructor_id query int false "Instructor ID"
+// @Param        from query string false "Earliest start time"
+// @Param        to query string false "Start time upper bound (exclusive)"
+// @Param        available_only query bool false "Only slots with free places"
+// @Param        sort query string false "start_time, price or available; prefix with - for descending" default(start_time)
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Success      200 {object} api.Page[gym.PublicTimeSlot]
+// @Success      304 "Not modified"
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      429 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /public/gyms/{gymID}/slots [get]
+func (h *Handler) ListPublicTimeSlots(c *gin.Context) {
+	slots, ok := h.listTimeSlots(c)
+	if !ok {
+		return
+	}
+
+	page := api.Page[PublicTimeSlot]{
+		Items:      make([]PublicTimeSlot, 0, len(slots.Items)),
+		Limit:      slots.Limit,
+		HasMore:    slots.HasMore,
+		NextCursor: slots.NextCursor,
+	}
+	for _, slot := range slots.Items {
+		page.Items = append(page.Items, slot.Public())
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -2,11 +2,42 @@ package gym
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// timeSlotColumns is the column list scanned into TimeSlot.
+const timeSlotColumns = "id, gym_id, start_time, end_time, capacity, price_cents, tags, class_type_id, instructor_id, room_id, template_id, cancelled_at, created_at"
+
+// gymSelect reads gyms along with the rating summary of their visible
+// reviews.
+const gymSelect = `
+	SELECT g.id, g.name, g.location, g.timezone, g.currency, g.created_at,
+		COALESCE(r.average_rating, 0) AS average_rating,
+		COALESCE(r.review_count, 0) AS review_count
+	FROM gyms g
+	LEFT JOIN (
+		SELECT gym_id, ROUND(AVG(rating), 2)::float8 AS average_rating, COUNT(*) AS review_count
+		FROM gym_reviews
+		WHERE hidden_at IS NULL
+		GROUP BY gym_id
+	) r ON r.gym_id = g.id
+`
+
+// slotAvailabilityColumns selects a slot (ts) along with its booked count
+// from a lateral subquery (bc).
+const slotAvailabilityColumns = `ts.id, ts.gym_id, ts.start_time, ts.end_time, ts.capacity, ts.price_cents, ts.tags,
+	ts.class_type_id, ts.instructor_id, ts.room_id, ts.template_id, ts.cancelled_at, ts.created_at,
+	bc.booked AS booked_count,
+	ts.capacity - bc.booked AS available,
+	ts.capacity - bc.booked <= 0 AS is_full`
+
+const closureColumns = "id, gym_id, starts_at, ends_at, reason, created_at"
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -15,15 +46,15 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) CreateGym(ctx context.Context, name, location string) (*Gym, error) {
+func (r *repository) CreateGym(ctx context.Context, name, location, timezone, currency string) (*Gym, error) {
 	query := `
-		INSERT INTO gyms (name, location)
-		VALUES ($1, $2)
-		RETURNING id, name, location, created_at
+		INSERT INTO gyms (name, location, timezone, currency)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, location, timezone, currency, created_at
 	`
 
 	var gym Gym
-	err := r.db.GetContext(ctx, &gym, query, name, location)
+	err := r.db.GetContext(ctx, &gym, query, name, location, timezone, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -32,10 +63,8 @@ func (r *repository) CreateGym(ctx context.Context, name, location string) (*Gym
 }
 
 func (r *repository) GetAllGyms(ctx context.Context) ([]Gym, error) {
-	query := `
-		SELECT id, name, location, created_at
-		FROM gyms
-		ORDER BY created_at DESC
+	query := gymSelect + `
+		ORDER BY g.created_at DESC
 	`
 
 	var gyms []Gym
@@ -47,11 +76,83 @@ func (r *repository) GetAllGyms(ctx context.Context) ([]Gym, error) {
 	return gyms, nil
 }
 
+// ListGyms returns one page of gyms. The slot filters of the query become
+// an EXISTS condition; local bounds are converted with each gym's zone.
+func (r *repository) ListGyms(ctx context.Context, q GymQuery) ([]Gym, error) {
+	query := "SELECT * FROM (" + gymSelect
+	var args []interface{}
+
+	if !q.From.IsZero() || !q.To.IsZero() || q.AvailableOnly {
+		query += `
+			WHERE EXISTS (
+				SELECT 1 FROM time_slots ts
+				WHERE ts.gym_id = g.id AND ts.cancelled_at IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM gym_closures c
+					WHERE c.gym_id = ts.gym_id
+					AND c.starts_at < ts.end_time AND c.ends_at > ts.start_time
+				)
+		`
+
+		if !q.From.IsZero() {
+			args = append(args, boundArg(q.From))
+			query += " AND ts.start_time >= " + boundSQL(q.From, len(args))
+		}
+
+		if !q.To.IsZero() {
+			args = append(args, boundArg(q.To))
+			query += " AND ts.start_time < " + boundSQL(q.To, len(args))
+		}
+
+		if q.AvailableOnly {
+			query += `
+				AND ts.start_time > NOW()
+				AND (
+					SELECT COUNT(*) FROM bookings b
+					WHERE b.time_slot_id = ts.id AND b.status = 'booked'
+				) < ts.capacity
+			`
+		}
+
+		query += ")"
+	}
+
+	var clause string
+	clause, args = keyset("gs", q.Sort, gymSortColumns, q.After, args)
+	query += ") gs" + clause
+
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	var gyms []Gym
+	if err := r.db.SelectContext(ctx, &gyms, query, args...); err != nil {
+		return nil, err
+	}
+
+	return gyms, nil
+}
+
+// boundArg and boundSQL pass a range bound to a gym query: an instant as is,
+// a wall-clock time as a plain timestamp placed in the gym's zone.
+func boundArg(b TimeBound) interface{} {
+	if b.Local {
+		return b.Time.Format("2006-01-02 15:04:05.999999")
+	}
+	return b.Time
+}
+
+func boundSQL(b TimeBound, n int) string {
+	if b.Local {
+		return fmt.Sprintf("($%d::timestamp AT TIME ZONE g.timezone)", n)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
 func (r *repository) GetGymByID(ctx context.Context, id int) (*Gym, error) {
-	query := `
-		SELECT id, name, location, created_at
-		FROM gyms
-		WHERE id = $1
+	query := gymSelect + `
+		WHERE g.id = $1
 	`
 
 	var gym Gym
@@ -63,34 +164,158 @@ func (r *repository) GetGymByID(ctx context.Context, id int) (*Gym, error) {
 	return &gym, nil
 }
 
-func (r *repository) CreateTimeSlot(ctx context.Context, gymID int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+const insertTimeSlotQuery = `
+	INSERT INTO time_slots (gym_id, start_time, end_time, capacity, price_cents, tags, class_type_id, instructor_id, room_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING ` + timeSlotColumns
+
+func (r *repository) CreateTimeSlot(ctx context.Context, gymID int, slot NewTimeSlot) (*TimeSlot, error) {
+	var created TimeSlot
+	err := r.db.GetContext(ctx, &created, insertTimeSlotQuery,
+		gymID, slot.StartTime, slot.EndTime, slot.Capacity, slot.PriceCents, tagsArray(slot.Tags),
+		slot.ClassTypeID, slot.InstructorID, slot.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// CreateTimeSlots inserts all slots in a single transaction: either every
+// slot is created or none is.
+func (r *repository) CreateTimeSlots(ctx context.Context, gymID int, slots []NewTimeSlot) ([]TimeSlot, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created := make([]TimeSlot, 0, len(slots))
+	for _, slot := range slots {
+		var ts TimeSlot
+		err := tx.GetContext(ctx, &ts, insertTimeSlotQuery,
+			gymID, slot.StartTime, slot.EndTime, slot.Capacity, slot.PriceCents, tagsArray(slot.Tags),
+			slot.ClassTypeID, slot.InstructorID, slot.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, ts)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *repository) CreateRoom(ctx context.Context, gymID int, name string, capacity int) (*Room, error) {
 	query := `
-		INSERT INTO time_slots (gym_id, start_time, end_time, capacity)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, gym_id, start_time, end_time, capacity, created_at
+		INSERT INTO rooms (gym_id, name, capacity)
+		VALUES ($1, $2, $3)
+		RETURNING id, gym_id, name, capacity, created_at
 	`
 
-	var slot TimeSlot
-	err := r.db.GetContext(ctx, &slot, query, gymID, startTime, endTime, capacity)
+	var room Room
+	err := r.db.GetContext(ctx, &room, query, gymID, name, capacity)
 	if err != nil {
 		return nil, err
 	}
 
-	return &slot, nil
+	return &room, nil
 }
 
-func (r *repository) GetTimeSlotsByGym(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlot, error) {
+func (r *repository) GetRoomsByGym(ctx context.Context, gymID int) ([]Room, error) {
 	query := `
-		SELECT id, gym_id, start_time, end_time, capacity, created_at
-		FROM time_slots
+		SELECT id, gym_id, name, capacity, created_at
+		FROM rooms
 		WHERE gym_id = $1
+		ORDER BY name ASC
+	`
+
+	var rooms []Room
+	err := r.db.SelectContext(ctx, &rooms, query, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (r *repository) GetRoomByID(ctx context.Context, id int) (*Room, error) {
+	query := `
+		SELECT id, gym_id, name, capacity, created_at
+		FROM rooms
+		WHERE id = $1
+	`
+
+	var room Room
+	err := r.db.GetContext(ctx, &room, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &room, nil
+}
+
+func (r *repository) ClassTypeExists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM class_types WHERE id = $1)`, id)
+	return exists, err
+}
+
+func (r *repository) InstructorTeachesAt(ctx context.Context, instructorID, gymID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM instructor_gyms WHERE instructor_id = $1 AND gym_id = $2)`
+	err := r.db.GetContext(ctx, &exists, query, instructorID, gymID)
+	return exists, err
+}
+
+// tagsArray never returns nil so the NOT NULL tags column gets an empty array.
+func tagsArray(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(tags)
+}
+
+func (r *repository) GetTimeSlotsByGym(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlot, error) {
+	query := `
+		SELECT ` + timeSlotColumns + `
+		FROM time_slots
+		WHERE gym_id = $1 AND cancelled_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM gym_closures c
+			WHERE c.gym_id = time_slots.gym_id
+			AND c.starts_at < time_slots.end_time AND c.ends_at > time_slots.start_time
+		)
 	`
 	args := []interface{}{gymID}
 
-	if onlyFuture {
+	if filter.OnlyFuture {
 		query += " AND start_time > NOW()"
 	}
 
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND start_time >= $%d", len(args))
+	}
+
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND start_time < $%d", len(args))
+	}
+
+	if filter.ClassType != "" {
+		args = append(args, filter.ClassType)
+		query += fmt.Sprintf(" AND class_type_id IN (SELECT id FROM class_types WHERE id::text = $%d OR LOWER(name) = LOWER($%d))", len(args), len(args))
+	}
+
+	if filter.InstructorID != nil {
+		args = append(args, *filter.InstructorID)
+		query += fmt.Sprintf(" AND instructor_id = $%d", len(args))
+	}
+
 	query += " ORDER BY start_time ASC"
 
 	var slots []TimeSlot
@@ -104,7 +329,7 @@ func (r *repository) GetTimeSlotsByGym(ctx context.Context, gymID int, onlyFutur
 
 func (r *repository) GetTimeSlotByID(ctx context.Context, id int) (*TimeSlot, error) {
 	query := `
-		SELECT id, gym_id, start_time, end_time, capacity, created_at
+		SELECT ` + timeSlotColumns + `
 		FROM time_slots
 		WHERE id = $1
 	`
@@ -118,38 +343,368 @@ func (r *repository) GetTimeSlotByID(ctx context.Context, id int) (*TimeSlot, er
 	return &slot, nil
 }
 
-func (r *repository) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlotWithAvailability, error) {
+// GetTimeSlotsWithAvailability lists the gym's live slots with their
+// booked counts in one query. Filters, sort order, cursor and limit are all
+// applied by the database.
+func (r *repository) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlotWithAvailability, error) {
+	query := `
+		SELECT * FROM (
+			SELECT ` + slotAvailabilityColumns + `
+			FROM time_slots ts
+			CROSS JOIN LATERAL (
+				SELECT COUNT(*) AS booked
+				FROM bookings b
+				WHERE b.time_slot_id = ts.id AND b.status = 'booked'
+			) bc
+			WHERE ts.gym_id = $1 AND ts.cancelled_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM gym_closures c
+				WHERE c.gym_id = ts.gym_id
+				AND c.starts_at < ts.end_time AND c.ends_at > ts.start_time
+			)
+	`
+	args := []interface{}{gymID}
+
+	if filter.OnlyFuture {
+		query += " AND ts.start_time > NOW()"
+	}
+
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND ts.start_time >= $%d", len(args))
+	}
+
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND ts.start_time < $%d", len(args))
+	}
+
+	if filter.ClassType != "" {
+		args = append(args, filter.ClassType)
+		query += fmt.Sprintf(" AND ts.class_type_id IN (SELECT id FROM class_types WHERE id::text = $%d OR LOWER(name) = LOWER($%d))", len(args), len(args))
+	}
+
+	if filter.InstructorID != nil {
+		args = append(args, *filter.InstructorID)
+		query += fmt.Sprintf(" AND ts.instructor_id = $%d", len(args))
+	}
+
+	if filter.AvailableOnly {
+		query += " AND bc.booked < ts.capacity"
+	}
+
+	order := filter.Sort
+	if order.Field == "" {
+		order.Field = SlotSortStartTime
+	}
+	var clause string
+	clause, args = keyset("s", order, slotSortColumns, filter.After, args)
+	query += ") s" + clause
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	var slots []TimeSlotWithAvailability
+	if err := r.db.SelectContext(ctx, &slots, query, args...); err != nil {
+		return nil, err
+	}
+
+	return slots, nil
+}
+
+// SearchTimeSlots looks for bookable slots across all gyms in one query,
+// ordered by start time and resumed after the search's cursor. Booked counts
+// come from a lateral subquery so only the matching slots are counted.
+func (r *repository) SearchTimeSlots(ctx context.Context, search SlotSearch) ([]SlotSearchResult, error) {
+	query := `
+		SELECT * FROM (
+			SELECT ` + slotAvailabilityColumns + `,
+				g.name AS gym_name,
+				g.location AS gym_location,
+				g.timezone AS gym_timezone,
+				g.currency AS gym_currency
+			FROM time_slots ts
+			JOIN gyms g ON g.id = ts.gym_id
+			CROSS JOIN LATERAL (
+				SELECT COUNT(*) AS booked
+				FROM bookings b
+				WHERE b.time_slot_id = ts.id AND b.status = 'booked'
+			) bc
+			WHERE ts.cancelled_at IS NULL AND ts.start_time > NOW()
+			AND NOT EXISTS (
+				SELECT 1 FROM gym_closures c
+				WHERE c.gym_id = ts.gym_id
+				AND c.starts_at < ts.end_time AND c.ends_at > ts.start_time
+			)
+	`
+	var args []interface{}
 
-	slots, err := r.GetTimeSlotsByGym(ctx, gymID, onlyFuture)
+	if !search.From.IsZero() {
+		args = append(args, search.From)
+		query += fmt.Sprintf(" AND ts.start_time >= $%d", len(args))
+	}
+
+	if !search.To.IsZero() {
+		args = append(args, search.To)
+		query += fmt.Sprintf(" AND ts.start_time < $%d", len(args))
+	}
+
+	if len(search.GymIDs) > 0 {
+		args = append(args, pq.Array(search.GymIDs))
+		query += fmt.Sprintf(" AND ts.gym_id = ANY($%d)", len(args))
+	}
+
+	if search.ClassType != "" {
+		args = append(args, search.ClassType)
+		query += fmt.Sprintf(" AND ts.class_type_id IN (SELECT id FROM class_types WHERE id::text = $%d OR LOWER(name) = LOWER($%d))", len(args), len(args))
+	}
+
+	if search.MinAvailable > 0 {
+		args = append(args, search.MinAvailable)
+		query += fmt.Sprintf(" AND ts.capacity - bc.booked >= $%d", len(args))
+	}
+
+	if search.Currency != "" {
+		args = append(args, search.Currency)
+		query += fmt.Sprintf(" AND g.currency = $%d", len(args))
+	}
+
+	if search.MaxPriceCents != nil {
+		args = append(args, *search.MaxPriceCents)
+		query += fmt.Sprintf(" AND ts.price_cents <= $%d", len(args))
+	}
+
+	var clause string
+	clause, args = keyset("s", SortOrder{Field: SlotSortStartTime}, slotSortColumns, search.After, args)
+	query += ") s" + clause
+
+	if search.Limit > 0 {
+		args = append(args, search.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	var results []SlotSearchResult
+	if err := r.db.SelectContext(ctx, &results, query, args...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r *repository) UpdateTimeSlot(ctx context.Context, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+	return updateTimeSlot(ctx, r.db, id, startTime, endTime, capacity)
+}
+
+// UpdateTimeSlotTx is UpdateTimeSlot within the caller's transaction.
+func UpdateTimeSlotTx(ctx context.Context, tx *sqlx.Tx, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+	return updateTimeSlot(ctx, tx, id, startTime, endTime, capacity)
+}
+
+func updateTimeSlot(ctx context.Context, q sqlx.QueryerContext, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+	query := `
+		UPDATE time_slots
+		SET start_time = $2, end_time = $3, capacity = $4
+		WHERE id = $1 AND cancelled_at IS NULL
+		RETURNING ` + timeSlotColumns
+
+	var slot TimeSlot
+	err := sqlx.GetContext(ctx, q, &slot, query, id, startTime, endTime, capacity)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]TimeSlotWithAvailability, 0, len(slots))
-	for _, slot := range slots {
-		var bookedCount int
-		countQuery := `
-			SELECT COUNT(*)
-			FROM bookings
-			WHERE time_slot_id = $1 AND status = 'booked'
-		`
-		err := r.db.GetContext(ctx, &bookedCount, countQuery, slot.ID)
-		if err != nil {
-			return nil, err
-		}
+	return &slot, nil
+}
+
+// CancelTimeSlot soft-deletes a slot. The row is kept so that bookings
+// history stays intact and the schedule generator does not recreate it.
+func (r *repository) CancelTimeSlot(ctx context.Context, id int) error {
+	return cancelTimeSlot(ctx, r.db, id)
+}
+
+// CancelTimeSlotTx is CancelTimeSlot within the caller's transaction.
+func CancelTimeSlotTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	return cancelTimeSlot(ctx, tx, id)
+}
+
+func cancelTimeSlot(ctx context.Context, e sqlx.ExecerContext, id int) error {
+	result, err := e.ExecContext(ctx, `
+		UPDATE time_slots
+		SET cancelled_at = NOW()
+		WHERE id = $1 AND cancelled_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *repository) CreateClosure(ctx context.Context, gymID int, startsAt, endsAt time.Time, reason string) (*Closure, error) {
+	query := `
+		INSERT INTO gym_closures (gym_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + closureColumns
+
+	var closure Closure
+	err := r.db.GetContext(ctx, &closure, query, gymID, startsAt, endsAt, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &closure, nil
+}
+
+func (r *repository) GetClosuresByGym(ctx context.Context, gymID int) ([]Closure, error) {
+	query := `
+		SELECT ` + closureColumns + `
+		FROM gym_closures
+		WHERE gym_id = $1
+		ORDER BY starts_at ASC
+	`
+
+	var closures []Closure
+	err := r.db.SelectContext(ctx, &closures, query, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return closures, nil
+}
+
+func (r *repository) GetClosureByID(ctx context.Context, id int) (*Closure, error) {
+	query := `
+		SELECT ` + closureColumns + `
+		FROM gym_closures
+		WHERE id = $1
+	`
+
+	var closure Closure
+	err := r.db.GetContext(ctx, &closure, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &closure, nil
+}
+
+// GetClosuresOverlapping returns the gym's closures that intersect the
+// half-open range [startTime, endTime).
+func (r *repository) GetClosuresOverlapping(ctx context.Context, gymID int, startTime, endTime time.Time) ([]Closure, error) {
+	query := `
+		SELECT ` + closureColumns + `
+		FROM gym_closures
+		WHERE gym_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at ASC
+	`
+
+	var closures []Closure
+	err := r.db.SelectContext(ctx, &closures, query, gymID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return closures, nil
+}
+
+func (r *repository) DeleteClosure(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM gym_closures WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
 
-		available := slot.Capacity - bookedCount
-		isFull := available <= 0
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetTimeSlotsInRange returns the gym's active slots that intersect
+// [startTime, endTime), regardless of closures.
+func (r *repository) GetTimeSlotsInRange(ctx context.Context, gymID int, startTime, endTime time.Time) ([]TimeSlot, error) {
+	query := `
+		SELECT ` + timeSlotColumns + `
+		FROM time_slots
+		WHERE gym_id = $1 AND cancelled_at IS NULL
+		AND start_time < $3 AND end_time > $2
+		ORDER BY start_time ASC
+	`
+
+	var slots []TimeSlot
+	err := r.db.SelectContext(ctx, &slots, query, gymID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
 
-		result = append(result, TimeSlotWithAvailability{
-			TimeSlot:    slot,
-			BookedCount: bookedCount,
-			Available:   available,
-			IsFull:      isFull,
-		})
+	return slots, nil
+}
+
+func (r *repository) GetOccupancySnapshot(ctx context.Context, gymID int, at time.Time) (*OccupancySnapshot, error) {
+	query := `
+		SELECT COALESCE(SUM(bc.booked), 0) AS headcount, COALESCE(SUM(ts.capacity), 0) AS capacity
+		FROM time_slots ts
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS booked
+			FROM bookings b
+			WHERE b.time_slot_id = ts.id AND b.status = 'booked'
+		) bc
+		WHERE ts.gym_id = $1 AND ts.cancelled_at IS NULL
+		AND ts.start_time <= $2 AND ts.end_time > $2
+	`
+
+	var snapshot OccupancySnapshot
+	err := r.db.GetContext(ctx, &snapshot, query, gymID, at)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &snapshot, nil
 }
 
+// GetHourlyBookings counts, for each hour of the day, the booked members
+// present during that hour on the given weekday between from and to. Hours
+// and weekdays are those of the wall clock in loc. A booking counts towards
+// every hour its slot overlaps.
+func (r *repository) GetHourlyBookings(ctx context.Context, gymID int, from, to time.Time, weekday time.Weekday, loc *time.Location) ([]HourCount, error) {
+	query := `
+		SELECT EXTRACT(HOUR FROM h.hour)::int AS hour, COUNT(*) AS bookings
+		FROM time_slots ts
+		JOIN bookings b ON b.time_slot_id = ts.id AND b.status = 'booked'
+		CROSS JOIN LATERAL generate_series(
+			date_trunc('hour', ts.start_time AT TIME ZONE $5),
+			(ts.end_time AT TIME ZONE $5) - interval '1 microsecond',
+			interval '1 hour'
+		) AS h(hour)
+		WHERE ts.gym_id = $1 AND ts.cancelled_at IS NULL
+		AND ts.start_time < $3 AND ts.end_time > $2
+		AND h.hour >= ($2 AT TIME ZONE $5) AND h.hour < ($3 AT TIME ZONE $5)
+		AND EXTRACT(DOW FROM h.hour) = $4
+		GROUP BY 1
+		ORDER BY 1
+	`
 
+	var counts []HourCount
+	err := r.db.SelectContext(ctx, &counts, query, gymID, from, to, int(weekday), loc.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
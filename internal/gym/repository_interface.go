@@ -6,11 +6,29 @@ import (
 )
 
 type Repository interface {
-	CreateGym(ctx context.Context, name, location string) (*Gym, error)
+	CreateGym(ctx context.Context, name, location, timezone, currency string) (*Gym, error)
 	GetAllGyms(ctx context.Context) ([]Gym, error)
+	ListGyms(ctx context.Context, query GymQuery) ([]Gym, error)
 	GetGymByID(ctx context.Context, id int) (*Gym, error)
-	CreateTimeSlot(ctx context.Context, gymID int, startTime, endTime time.Time, capacity int) (*TimeSlot, error)
-	GetTimeSlotsByGym(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlot, error)
+	CreateTimeSlot(ctx context.Context, gymID int, slot NewTimeSlot) (*TimeSlot, error)
+	CreateTimeSlots(ctx context.Context, gymID int, slots []NewTimeSlot) ([]TimeSlot, error)
+	GetTimeSlotsByGym(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlot, error)
 	GetTimeSlotByID(ctx context.Context, id int) (*TimeSlot, error)
-	GetTimeSlotsWithAvailability(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlotWithAvailability, error)
+	GetTimeSlotsWithAvailability(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlotWithAvailability, error)
+	SearchTimeSlots(ctx context.Context, search SlotSearch) ([]SlotSearchResult, error)
+	UpdateTimeSlot(ctx context.Context, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error)
+	CancelTimeSlot(ctx context.Context, id int) error
+	GetTimeSlotsInRange(ctx context.Context, gymID int, startTime, endTime time.Time) ([]TimeSlot, error)
+	GetOccupancySnapshot(ctx context.Context, gymID int, at time.Time) (*OccupancySnapshot, error)
+	GetHourlyBookings(ctx context.Context, gymID int, from, to time.Time, weekday time.Weekday, loc *time.Location) ([]HourCount, error)
+	CreateClosure(ctx context.Context, gymID int, startsAt, endsAt time.Time, reason string) (*Closure, error)
+	GetClosuresByGym(ctx context.Context, gymID int) ([]Closure, error)
+	GetClosureByID(ctx context.Context, id int) (*Closure, error)
+	GetClosuresOverlapping(ctx context.Context, gymID int, startTime, endTime time.Time) ([]Closure, error)
+	DeleteClosure(ctx context.Context, id int) error
+	CreateRoom(ctx context.Context, gymID int, name string, capacity int) (*Room, error)
+	GetRoomsByGym(ctx context.Context, gymID int) ([]Room, error)
+	GetRoomByID(ctx context.Context, id int) (*Room, error)
+	ClassTypeExists(ctx context.Context, id int) (bool, error)
+	InstructorTeachesAt(ctx context.Context, instructorID, gymID int) (bool, error)
 }
@@ -2,11 +2,14 @@ package gym
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,15 +24,16 @@ func TestCreateGym(t *testing.T) {
 	ctx := context.Background()
 
 	mock.ExpectQuery(`INSERT INTO gyms.*`).
-		WithArgs("Gym A", "City X").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "created_at"}).
-			AddRow(1, "Gym A", "City X", time.Now()))
+		WithArgs("Gym A", "City X", "Europe/Berlin", "EUR").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "timezone", "currency", "created_at"}).
+			AddRow(1, "Gym A", "City X", "Europe/Berlin", "EUR", time.Now()))
 
-	gym, err := repo.CreateGym(ctx, "Gym A", "City X")
+	gym, err := repo.CreateGym(ctx, "Gym A", "City X", "Europe/Berlin", "EUR")
 	assert.NoError(t, err)
 	assert.Equal(t, 1, gym.ID)
 	assert.Equal(t, "Gym A", gym.Name)
 	assert.Equal(t, "City X", gym.Location)
+	assert.Equal(t, "EUR", gym.Currency)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -43,17 +47,60 @@ func TestGetAllGyms(t *testing.T) {
 
 	ctx := context.Background()
 
-	mock.ExpectQuery(`SELECT id, name, location, created_at FROM gyms.*`).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "created_at"}).
-			AddRow(1, "Gym A", "City X", time.Now()).
-			AddRow(2, "Gym B", "City Y", time.Now()))
+	mock.ExpectQuery(`SELECT g.id, g.name, g.location, g.timezone, g.currency, g.created_at, .* FROM gyms g LEFT JOIN \(.*FROM gym_reviews WHERE hidden_at IS NULL GROUP BY gym_id \) r.*`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "created_at", "average_rating", "review_count"}).
+			AddRow(1, "Gym A", "City X", time.Now(), 4.5, 2).
+			AddRow(2, "Gym B", "City Y", time.Now(), 0, 0))
 
 	gyms, err := repo.GetAllGyms(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, gyms, 2)
+	assert.Equal(t, 4.5, gyms[0].AverageRating)
+	assert.Equal(t, 2, gyms[0].ReviewCount)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestListGyms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	t.Run("without a range lists every gym", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM \( SELECT g.id, .* GROUP BY gym_id \) r ON r.gym_id = g.id\s*\) gs ORDER BY gs.name ASC, gs.id ASC LIMIT \$1$`).
+			WithArgs(21).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Gym A").AddRow(2, "Gym B"))
+
+		gyms, err := repo.ListGyms(context.Background(), GymQuery{Sort: SortOrder{Field: GymSortName}, Limit: 21})
+		assert.NoError(t, err)
+		assert.Len(t, gyms, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("local bounds are read in each gym's zone", func(t *testing.T) {
+		from := TimeBound{Time: time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC), Local: true}
+		to := TimeBound{Time: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)}
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		mock.ExpectQuery(`WHERE EXISTS \( SELECT 1 FROM time_slots ts WHERE ts.gym_id = g.id .* AND ts.start_time >= \(\$1::timestamp AT TIME ZONE g.timezone\) AND ts.start_time < \$2 AND ts.start_time > NOW\(\) .*\) < ts.capacity\s*\)\s*\) gs WHERE \(gs.created_at, gs.id\) < \(\$3::timestamptz, \$4\) ORDER BY gs.created_at DESC, gs.id DESC LIMIT \$5$`).
+			WithArgs("2024-06-01 18:00:00", to.Time, created.Format(time.RFC3339Nano), 7, 21).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "Gym A"))
+
+		gyms, err := repo.ListGyms(context.Background(), GymQuery{
+			From:          from,
+			To:            to,
+			AvailableOnly: true,
+			Sort:          SortOrder{Field: GymSortCreatedAt, Desc: true},
+			Limit:         21,
+			After:         &Cursor{Sort: "-created_at", Key: created.Format(time.RFC3339Nano), ID: 7},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, gyms, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestGetGymByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -64,7 +111,7 @@ func TestGetGymByID(t *testing.T) {
 
 	ctx := context.Background()
 
-	mock.ExpectQuery(`SELECT id, name, location, created_at FROM gyms WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT g.id, g.name, g.location, g.timezone, g.currency, g.created_at, .* FROM gyms g LEFT JOIN .* WHERE g.id = \$1`).
 		WithArgs(1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "created_at"}).
 			AddRow(1, "Gym A", "City X", time.Now()))
@@ -88,17 +135,75 @@ func TestCreateTimeSlot(t *testing.T) {
 	end := start.Add(time.Hour)
 
 	mock.ExpectQuery(`INSERT INTO time_slots.*`).
-		WithArgs(1, start, end, 10).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at"}).
-			AddRow(1, 1, start, end, 10, time.Now()))
+		WithArgs(1, start, end, 10, int64(1500), pq.StringArray{"yoga"}, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "price_cents", "tags", "created_at"}).
+			AddRow(1, 1, start, end, 10, 1500, "{yoga}", time.Now()))
 
-	slot, err := repo.CreateTimeSlot(ctx, 1, start, end, 10)
+	slot, err := repo.CreateTimeSlot(ctx, 1, NewTimeSlot{
+		StartTime:  start,
+		EndTime:    end,
+		Capacity:   10,
+		PriceCents: 1500,
+		Tags:       []string{"yoga"},
+	})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, slot.ID)
 	assert.Equal(t, 10, slot.Capacity)
+	assert.Equal(t, int64(1500), slot.PriceCents)
+	assert.Equal(t, pq.StringArray{"yoga"}, slot.Tags)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateTimeSlots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	ctx := context.Background()
+	start := time.Now()
+	end := start.Add(time.Hour)
+	slots := []NewTimeSlot{
+		{StartTime: start, EndTime: end, Capacity: 10, PriceCents: 1000},
+		{StartTime: end, EndTime: end.Add(time.Hour), Capacity: 10, PriceCents: 1000},
+	}
+
+	t.Run("commits all slots", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO time_slots.*`).
+			WithArgs(1, start, end, 10, int64(1000), pq.StringArray{}, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at"}).
+				AddRow(1, 1, start, end, 10, time.Now()))
+		mock.ExpectQuery(`INSERT INTO time_slots.*`).
+			WithArgs(1, end, end.Add(time.Hour), 10, int64(1000), pq.StringArray{}, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at"}).
+				AddRow(2, 1, end, end.Add(time.Hour), 10, time.Now()))
+		mock.ExpectCommit()
+
+		created, err := repo.CreateTimeSlots(ctx, 1, slots)
+		assert.NoError(t, err)
+		assert.Len(t, created, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO time_slots.*`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at"}).
+				AddRow(1, 1, start, end, 10, time.Now()))
+		mock.ExpectQuery(`INSERT INTO time_slots.*`).
+			WillReturnError(errors.New("insert failed"))
+		mock.ExpectRollback()
+
+		created, err := repo.CreateTimeSlots(ctx, 1, slots)
+		assert.Error(t, err)
+		assert.Nil(t, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestGetTimeSlotsWithAvailability(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -110,21 +215,181 @@ func TestGetTimeSlotsWithAvailability(t *testing.T) {
 	start := time.Now().Add(time.Hour)
 	end := start.Add(time.Hour)
 
-	// Сначала мок для GetTimeSlotsByGym
-	mock.ExpectQuery(`SELECT id, gym_id, start_time, end_time, capacity, created_at FROM time_slots.*`).
+	mock.ExpectQuery(`SELECT \* FROM \( SELECT ts.id, .* bc.booked AS booked_count, .* FROM time_slots ts CROSS JOIN LATERAL \(.*\) bc WHERE ts.gym_id = \$1 .* AND ts.start_time > NOW\(\)\s*\) s ORDER BY s.start_time ASC, s.id ASC$`).
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at"}).
-			AddRow(1, 1, start, end, 10, time.Now()))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "end_time", "capacity", "created_at", "booked_count", "available", "is_full"}).
+			AddRow(1, 1, start, end, 10, time.Now(), 3, 7, false))
 
-	// Мок для подсчёта бронирований
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bookings.*`).
-		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-
-	slots, err := repo.GetTimeSlotsWithAvailability(ctx, 1, true)
+	slots, err := repo.GetTimeSlotsWithAvailability(ctx, 1, SlotFilter{OnlyFuture: true})
 	assert.NoError(t, err)
 	assert.Len(t, slots, 1)
 	assert.Equal(t, 7, slots[0].Available)
 	assert.Equal(t, false, slots[0].IsFull)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetTimeSlotsWithAvailability_Page(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`AND ts.start_time >= \$2 AND bc.booked < ts.capacity\s*\) s WHERE \(s.price_cents, s.id\) < \(\$3::bigint, \$4\) ORDER BY s.price_cents DESC, s.id DESC LIMIT \$5$`).
+		WithArgs(1, from, "1500", 8, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "price_cents", "available"}).AddRow(3, 1, 1500, 2))
+
+	slots, err := repo.GetTimeSlotsWithAvailability(context.Background(), 1, SlotFilter{
+		From:          from,
+		AvailableOnly: true,
+		Sort:          SortOrder{Field: SlotSortPrice, Desc: true},
+		Limit:         11,
+		After:         &Cursor{Sort: "-price", Key: "1500", ID: 8},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTimeSlotsByGym_Filters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	instructorID := 4
+	mock.ExpectQuery(`FROM time_slots .* AND class_type_id IN \(SELECT id FROM class_types WHERE id::text = \$2 OR LOWER\(name\) = LOWER\(\$2\)\) AND instructor_id = \$3 ORDER BY start_time ASC`).
+		WithArgs(1, "yoga", instructorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "class_type_id", "instructor_id"}).
+			AddRow(1, 1, 2, instructorID))
+
+	slots, err := repo.GetTimeSlotsByGym(context.Background(), 1, SlotFilter{ClassType: "yoga", InstructorID: &instructorID})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.Equal(t, instructorID, *slots[0].InstructorID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTimeSlotsByGym_Range(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	mock.ExpectQuery(`FROM time_slots .* AND start_time >= \$2 AND start_time < \$3 ORDER BY start_time ASC`).
+		WithArgs(1, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id"}).AddRow(1, 1))
+
+	slots, err := repo.GetTimeSlotsByGym(context.Background(), 1, SlotFilter{From: from, To: to})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchTimeSlots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	from := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+	maxPrice := int64(1500)
+	search := SlotSearch{
+		From:          from,
+		To:            to,
+		GymIDs:        []int{1, 2},
+		MinAvailable:  1,
+		Currency:      "EUR",
+		MaxPriceCents: &maxPrice,
+		Limit:         21,
+	}
+
+	after := &Cursor{Sort: SlotSortStartTime, Key: "2024-06-01T18:30:00Z", ID: 5}
+	search.After = after
+
+	filters := `AND ts.start_time >= \$1 AND ts.start_time < \$2 AND ts.gym_id = ANY\(\$3\) AND ts.capacity - bc.booked >= \$4 AND g.currency = \$5 AND ts.price_cents <= \$6`
+	mock.ExpectQuery(`SELECT \* FROM \( SELECT ts.id, .* AS gym_currency FROM time_slots ts JOIN gyms g .*CROSS JOIN LATERAL .*`+filters+
+		`\s*\) s WHERE \(s.start_time, s.id\) > \(\$7::timestamptz, \$8\) ORDER BY s.start_time ASC, s.id ASC LIMIT \$9`).
+		WithArgs(from, to, sqlmock.AnyArg(), 1, "EUR", maxPrice, after.Key, after.ID, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "start_time", "capacity", "booked_count", "available", "is_full", "gym_name", "gym_location", "gym_currency"}).
+			AddRow(7, 2, from, 10, 4, 6, false, "Downtown", "Main St", "EUR"))
+
+	slots, err := repo.SearchTimeSlots(context.Background(), search)
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.Equal(t, 6, slots[0].Available)
+	assert.Equal(t, "Downtown", slots[0].GymName)
+	assert.Equal(t, "EUR", slots[0].Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHourlyBookings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	to := time.Date(2024, 6, 2, 22, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -28)
+	mock.ExpectQuery(`SELECT EXTRACT\(HOUR FROM h.hour\)::int AS hour, COUNT\(\*\) AS bookings .* generate_series\(.*\) AS h\(hour\) WHERE ts.gym_id = \$1 .* AND EXTRACT\(DOW FROM h.hour\) = \$4 GROUP BY 1`).
+		WithArgs(1, from, to, 1, "Europe/Berlin").
+		WillReturnRows(sqlmock.NewRows([]string{"hour", "bookings"}).AddRow(7, 12).AddRow(18, 30))
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	counts, err := repo.GetHourlyBookings(context.Background(), 1, from, to, time.Monday, berlin)
+	assert.NoError(t, err)
+	assert.Equal(t, []HourCount{{Hour: 7, Bookings: 12}, {Hour: 18, Bookings: 30}}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClosuresOverlapping(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	ctx := context.Background()
+	start := time.Date(2024, 12, 25, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	mock.ExpectQuery(`SELECT id, gym_id, starts_at, ends_at, reason, created_at FROM gym_closures WHERE gym_id = \$1 AND starts_at < \$3 AND ends_at > \$2`).
+		WithArgs(1, start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "starts_at", "ends_at", "reason", "created_at"}).
+			AddRow(1, 1, start.Add(-10*time.Hour), start.Add(14*time.Hour), "Christmas", time.Now()))
+
+	closures, err := repo.GetClosuresOverlapping(ctx, 1, start, end)
+	assert.NoError(t, err)
+	assert.Len(t, closures, 1)
+	assert.Equal(t, "Christmas", closures[0].Reason)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteClosure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(dbx)
+
+	ctx := context.Background()
+
+	mock.ExpectExec(`DELETE FROM gym_closures WHERE id = \$1`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteClosure(ctx, 42)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,21 +2,52 @@ package gym
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
+
+	"fitslot/internal/api"
+	"fitslot/internal/money"
 )
 
+// DefaultSlotPriceCents is charged from the wallet when a slot is created
+// without an explicit price.
+const DefaultSlotPriceCents int64 = 1000
+
+// DefaultTimezone is used for gyms created without a time zone.
+const DefaultTimezone = "UTC"
+
 var (
 	ErrGymNotFound     = errors.New("gym not found")
 	ErrTimeSlotInvalid = errors.New("invalid time slot")
+	ErrImportInvalid   = errors.New("invalid import file")
+	ErrImportHasErrors = errors.New("import contains invalid rows")
+	ErrGymClosed       = errors.New("gym is closed")
+	ErrClosureInvalid  = errors.New("invalid closure")
+	ErrClosureNotFound = errors.New("closure not found")
+	ErrRoomInvalid     = errors.New("invalid room")
+	ErrRoomExists      = errors.New("room already exists")
+	ErrSearchInvalid   = errors.New("invalid search")
+	ErrCurrencyInvalid = errors.New("unsupported currency")
 )
 
 type Service interface {
 	CreateGym(ctx context.Context, req CreateGymRequest) (*Gym, error)
-	GetAllGyms(ctx context.Context) ([]Gym, error)
+	ListGyms(ctx context.Context, req ListGymsRequest) (*api.Page[Gym], error)
 	GetGymByID(ctx context.Context, id int) (*Gym, error)
 	CreateTimeSlot(ctx context.Context, gymID int, req CreateTimeSlotRequest) (*TimeSlot, error)
-	GetTimeSlots(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlotWithAvailability, error)
+	GetTimeSlots(ctx context.Context, gymID int, req ListTimeSlotsRequest) (*api.Page[TimeSlotWithAvailability], error)
+	SearchTimeSlots(ctx context.Context, search SlotSearch) (*api.Page[SlotSearchResult], error)
+	ImportTimeSlots(ctx context.Context, gymID int, csvData io.Reader, dryRun bool) (*ImportResult, error)
+	CreateClosure(ctx context.Context, gymID int, req CreateClosureRequest) (*CreateClosureResponse, error)
+	GetClosures(ctx context.Context, gymID int) ([]Closure, error)
+	DeleteClosure(ctx context.Context, id int) error
+	CreateRoom(ctx context.Context, gymID int, req CreateRoomRequest) (*Room, error)
+	GetRooms(ctx context.Context, gymID int) ([]Room, error)
+	GetOccupancy(ctx context.Context, gymID int, weeks int, weekday *time.Weekday) (*Occupancy, error)
 }
 
 type service struct {
@@ -30,11 +61,52 @@ func NewService(repo Repository) Service {
 }
 
 func (s *service) CreateGym(ctx context.Context, req CreateGymRequest) (*Gym, error) {
-	return s.repo.CreateGym(ctx, req.Name, req.Location)
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	if err := ValidateTimezone(timezone); err != nil {
+		return nil, err
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, ErrCurrencyInvalid
+	}
+
+	return s.repo.CreateGym(ctx, req.Name, req.Location, timezone, currency)
 }
 
-func (s *service) GetAllGyms(ctx context.Context) ([]Gym, error) {
-	return s.repo.GetAllGyms(ctx)
+// ListGyms returns one page of gyms, newest first unless sorted otherwise.
+func (s *service) ListGyms(ctx context.Context, req ListGymsRequest) (*api.Page[Gym], error) {
+	from, to, err := parseRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	sort, err := parseSortOrder(req.Sort, gymSortColumns, SortOrder{Field: GymSortCreatedAt, Desc: true})
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := parseCursor(req.Cursor, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageLimit(req.Limit)
+	gyms, err := s.repo.ListGyms(ctx, GymQuery{
+		From:          from,
+		To:            to,
+		AvailableOnly: req.AvailableOnly,
+		Sort:          sort,
+		Limit:         limit + 1,
+		After:         after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(gyms, limit, gymCursor(sort)), nil
 }
 
 func (s *service) GetGymByID(ctx context.Context, id int) (*Gym, error) {
@@ -46,38 +118,363 @@ func (s *service) GetGymByID(ctx context.Context, id int) (*Gym, error) {
 }
 
 func (s *service) CreateTimeSlot(ctx context.Context, gymID int, req CreateTimeSlotRequest) (*TimeSlot, error) {
-	_, err := s.repo.GetGymByID(ctx, gymID)
+	g, err := s.repo.GetGymByID(ctx, gymID)
 	if err != nil {
 		return nil, ErrGymNotFound
 	}
 
-	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	slot, err := validateTimeSlot(req, g.Zone())
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.repo.GetClosuresOverlapping(ctx, gymID, slot.StartTime, slot.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClosures(slot, closures); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkClassReferences(ctx, gymID, slot); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRoom(ctx, gymID, slot); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateTimeSlot(ctx, gymID, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := created.In(g.Zone())
+	return &localized, nil
+}
+
+// checkClassReferences makes sure the slot's class type exists and that its
+// instructor teaches at the gym.
+func (s *service) checkClassReferences(ctx context.Context, gymID int, slot NewTimeSlot) error {
+	if slot.ClassTypeID != nil {
+		exists, err := s.repo.ClassTypeExists(ctx, *slot.ClassTypeID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: class type does not exist", ErrTimeSlotInvalid)
+		}
+	}
+
+	if slot.InstructorID != nil {
+		teaches, err := s.repo.InstructorTeachesAt(ctx, *slot.InstructorID, gymID)
+		if err != nil {
+			return err
+		}
+		if !teaches {
+			return fmt.Errorf("%w: instructor does not teach at this gym", ErrTimeSlotInvalid)
+		}
+	}
+
+	return nil
+}
+
+// checkClosures rejects a slot that overlaps any of the given closures.
+func checkClosures(slot NewTimeSlot, closures []Closure) error {
+	for _, c := range closures {
+		if c.StartsAt.Before(slot.EndTime) && c.EndsAt.After(slot.StartTime) {
+			if c.Reason == "" {
+				return ErrGymClosed
+			}
+			return fmt.Errorf("%w: %s", ErrGymClosed, c.Reason)
+		}
+	}
+	return nil
+}
+
+// validateTimeSlot applies the rules every new slot must satisfy, whether it
+// comes from the API or from a CSV import. Times without an offset are read
+// in loc, the gym's time zone. Returned errors wrap ErrTimeSlotInvalid with
+// a human-readable reason.
+func validateTimeSlot(req CreateTimeSlotRequest, loc *time.Location) (NewTimeSlot, error) {
+	startTime, err := ParseSlotTime(req.StartTime, loc)
 	if err != nil {
-		return nil, ErrTimeSlotInvalid
+		return NewTimeSlot{}, fmt.Errorf("%w: start_time %v", ErrTimeSlotInvalid, err)
 	}
 
-	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	endTime, err := ParseSlotTime(req.EndTime, loc)
 	if err != nil {
-		return nil, ErrTimeSlotInvalid
+		return NewTimeSlot{}, fmt.Errorf("%w: end_time %v", ErrTimeSlotInvalid, err)
 	}
 
-	if endTime.Before(startTime) || endTime.Equal(startTime) {
-		return nil, ErrTimeSlotInvalid
+	if !endTime.After(startTime) {
+		return NewTimeSlot{}, fmt.Errorf("%w: end_time must be after start_time", ErrTimeSlotInvalid)
 	}
 
 	if req.Capacity <= 0 {
-		return nil, ErrTimeSlotInvalid
+		return NewTimeSlot{}, fmt.Errorf("%w: capacity must be positive", ErrTimeSlotInvalid)
+	}
+
+	priceCents := DefaultSlotPriceCents
+	if req.PriceCents != nil {
+		if *req.PriceCents < 0 {
+			return NewTimeSlot{}, fmt.Errorf("%w: price_cents must not be negative", ErrTimeSlotInvalid)
+		}
+		priceCents = *req.PriceCents
+	}
+
+	return NewTimeSlot{
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Capacity:     req.Capacity,
+		PriceCents:   priceCents,
+		Tags:         req.Tags,
+		ClassTypeID:  req.ClassTypeID,
+		InstructorID: req.InstructorID,
+		RoomID:       req.RoomID,
+	}, nil
+}
+
+// checkRoom makes sure the slot's room belongs to the gym and that the slot
+// alone can't exceed the room's occupancy.
+func (s *service) checkRoom(ctx context.Context, gymID int, slot NewTimeSlot) error {
+	if slot.RoomID == nil {
+		return nil
+	}
+
+	room, err := s.repo.GetRoomByID(ctx, *slot.RoomID)
+	if err != nil || room.GymID != gymID {
+		return fmt.Errorf("%w: room does not belong to this gym", ErrTimeSlotInvalid)
+	}
+
+	return CheckRoomCapacity(room, slot.Capacity)
+}
+
+// CheckRoomCapacity refuses a slot capacity larger than the room holds.
+func CheckRoomCapacity(room *Room, capacity int) error {
+	if capacity > room.Capacity {
+		return fmt.Errorf("%w: capacity exceeds the room capacity of %d", ErrTimeSlotInvalid, room.Capacity)
 	}
 
-	return s.repo.CreateTimeSlot(ctx, gymID, startTime, endTime, req.Capacity)
+	return nil
 }
 
-func (s *service) GetTimeSlots(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlotWithAvailability, error) {
-	// Validate gym exists
-	_, err := s.repo.GetGymByID(ctx, gymID)
+// GetTimeSlots returns one page of the gym's slots, by start time unless
+// sorted otherwise. Range bounds without an offset are read in the gym's
+// time zone.
+func (s *service) GetTimeSlots(ctx context.Context, gymID int, req ListTimeSlotsRequest) (*api.Page[TimeSlotWithAvailability], error) {
+	g, err := s.repo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, ErrGymNotFound
+	}
+	loc := g.Zone()
+
+	from, to, err := parseRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := SlotFilter{
+		OnlyFuture:    req.OnlyFuture,
+		ClassType:     req.ClassType,
+		InstructorID:  req.InstructorID,
+		From:          from.In(loc),
+		To:            to.In(loc),
+		AvailableOnly: req.AvailableOnly,
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrListingInvalid)
+	}
+
+	if filter.Sort, err = parseSortOrder(req.Sort, slotSortColumns, SortOrder{Field: SlotSortStartTime}); err != nil {
+		return nil, err
+	}
+	if filter.After, err = parseCursor(req.Cursor, filter.Sort); err != nil {
+		return nil, err
+	}
+
+	limit := pageLimit(req.Limit)
+	filter.Limit = limit + 1
+
+	slots, err := s.repo.GetTimeSlotsWithAvailability(ctx, gymID, filter)
 	if err != nil {
+		return nil, err
+	}
+
+	for i := range slots {
+		slots[i].TimeSlot = slots[i].TimeSlot.In(loc)
+	}
+	return newPage(slots, limit, slotCursor(filter.Sort)), nil
+}
+
+// SearchTimeSlots pages through the matching slots by start time, like the
+// per-gym slot listing.
+func (s *service) SearchTimeSlots(ctx context.Context, search SlotSearch) (*api.Page[SlotSearchResult], error) {
+	if !search.From.IsZero() && !search.To.IsZero() && !search.To.After(search.From) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrSearchInvalid)
+	}
+	if search.MinAvailable < 0 {
+		return nil, fmt.Errorf("%w: min_available must not be negative", ErrSearchInvalid)
+	}
+	if search.MaxPriceCents != nil && *search.MaxPriceCents < 0 {
+		return nil, fmt.Errorf("%w: max_price_cents must not be negative", ErrSearchInvalid)
+	}
+	// Slots are priced in their gym's currency, so a price limit only means
+	// something together with the currency it is in.
+	if search.Currency != "" {
+		currency, err := money.Normalize(search.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unsupported currency %q", ErrSearchInvalid, search.Currency)
+		}
+		search.Currency = currency
+	} else if search.MaxPriceCents != nil {
+		return nil, fmt.Errorf("%w: max_price_cents needs a currency", ErrSearchInvalid)
+	}
+
+	order := SortOrder{Field: SlotSortStartTime}
+	var err error
+	if search.After, err = parseCursor(search.Cursor, order); err != nil {
+		return nil, err
+	}
+
+	limit := pageLimit(search.Limit)
+	search.Limit = limit + 1
+
+	slots, err := s.repo.SearchTimeSlots(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range slots {
+		slots[i].TimeSlot = slots[i].TimeSlot.In(LoadZone(slots[i].GymTimezone))
+	}
+	slotKey := slotCursor(order)
+	return newPage(slots, limit, func(r SlotSearchResult) Cursor {
+		return slotKey(r.TimeSlotWithAvailability)
+	}), nil
+}
+
+// CreateClosure records a closure and reports the slots that fall into it.
+// Those slots are hidden from listings right away but are only cancelled
+// (and their bookings refunded) when an admin asks for it.
+func (s *service) CreateClosure(ctx context.Context, gymID int, req CreateClosureRequest) (*CreateClosureResponse, error) {
+	g, err := s.repo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, ErrGymNotFound
+	}
+	loc := g.Zone()
+
+	startsAt, endsAt, err := closureRange(req, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	closure, err := s.repo.CreateClosure(ctx, gymID, startsAt, endsAt, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.GetTimeSlotsInRange(ctx, gymID, startsAt, endsAt)
+	if err != nil {
+		return nil, err
+	}
+	if affected == nil {
+		affected = []TimeSlot{}
+	}
+	for i := range affected {
+		affected[i] = affected[i].In(loc)
+	}
+
+	return &CreateClosureResponse{
+		Closure:       closure.In(loc),
+		AffectedSlots: affected,
+	}, nil
+}
+
+// closureRange turns a request into a [start, end) range: a whole local day
+// in loc for date, or the explicit starts_at/ends_at for partial-day
+// closures.
+func closureRange(req CreateClosureRequest, loc *time.Location) (time.Time, time.Time, error) {
+	if req.Date != "" {
+		if req.StartsAt != "" || req.EndsAt != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: use either date or starts_at/ends_at", ErrClosureInvalid)
+		}
+		day, err := time.ParseInLocation(time.DateOnly, req.Date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrClosureInvalid)
+		}
+		return day, day.AddDate(0, 0, 1), nil
+	}
+
+	startsAt, err := ParseSlotTime(req.StartsAt, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: starts_at %v", ErrClosureInvalid, err)
+	}
+
+	endsAt, err := ParseSlotTime(req.EndsAt, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: ends_at %v", ErrClosureInvalid, err)
+	}
+
+	if !endsAt.After(startsAt) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: ends_at must be after starts_at", ErrClosureInvalid)
+	}
+
+	return startsAt, endsAt, nil
+}
+
+func (s *service) GetClosures(ctx context.Context, gymID int) ([]Closure, error) {
+	g, err := s.repo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, ErrGymNotFound
+	}
+
+	closures, err := s.repo.GetClosuresByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range closures {
+		closures[i] = closures[i].In(g.Zone())
+	}
+	return closures, nil
+}
+
+func (s *service) DeleteClosure(ctx context.Context, id int) error {
+	err := s.repo.DeleteClosure(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrClosureNotFound
+	}
+	return err
+}
+
+func (s *service) CreateRoom(ctx context.Context, gymID int, req CreateRoomRequest) (*Room, error) {
+	if _, err := s.repo.GetGymByID(ctx, gymID); err != nil {
+		return nil, ErrGymNotFound
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || req.Capacity <= 0 {
+		return nil, ErrRoomInvalid
+	}
+
+	rooms, err := s.repo.GetRoomsByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range rooms {
+		if strings.EqualFold(room.Name, name) {
+			return nil, ErrRoomExists
+		}
+	}
+
+	return s.repo.CreateRoom(ctx, gymID, name, req.Capacity)
+}
+
+func (s *service) GetRooms(ctx context.Context, gymID int) ([]Room, error) {
+	if _, err := s.repo.GetGymByID(ctx, gymID); err != nil {
 		return nil, ErrGymNotFound
 	}
 
-	return s.repo.GetTimeSlotsWithAvailability(ctx, gymID, onlyFuture)
+	return s.repo.GetRoomsByGym(ctx, gymID)
 }
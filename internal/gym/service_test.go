@@ -3,6 +3,7 @@ package gym
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,8 +16,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateGym(ctx context.Context, name, location string) (*Gym, error) {
-	args := m.Called(ctx, name, location)
+func (m *MockRepository) CreateGym(ctx context.Context, name, location, timezone, currency string) (*Gym, error) {
+	args := m.Called(ctx, name, location, timezone, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -31,6 +32,14 @@ func (m *MockRepository) GetAllGyms(ctx context.Context) ([]Gym, error) {
 	return args.Get(0).([]Gym), args.Error(1)
 }
 
+func (m *MockRepository) ListGyms(ctx context.Context, query GymQuery) ([]Gym, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Gym), args.Error(1)
+}
+
 func (m *MockRepository) GetGymByID(ctx context.Context, id int) (*Gym, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -39,16 +48,24 @@ func (m *MockRepository) GetGymByID(ctx context.Context, id int) (*Gym, error) {
 	return args.Get(0).(*Gym), args.Error(1)
 }
 
-func (m *MockRepository) CreateTimeSlot(ctx context.Context, gymID int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
-	args := m.Called(ctx, gymID, startTime, endTime, capacity)
+func (m *MockRepository) CreateTimeSlot(ctx context.Context, gymID int, slot NewTimeSlot) (*TimeSlot, error) {
+	args := m.Called(ctx, gymID, slot)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*TimeSlot), args.Error(1)
 }
 
-func (m *MockRepository) GetTimeSlotsByGym(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlot, error) {
-	args := m.Called(ctx, gymID, onlyFuture)
+func (m *MockRepository) CreateTimeSlots(ctx context.Context, gymID int, slots []NewTimeSlot) ([]TimeSlot, error) {
+	args := m.Called(ctx, gymID, slots)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]TimeSlot), args.Error(1)
+}
+
+func (m *MockRepository) GetTimeSlotsByGym(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlot, error) {
+	args := m.Called(ctx, gymID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -63,14 +80,128 @@ func (m *MockRepository) GetTimeSlotByID(ctx context.Context, id int) (*TimeSlot
 	return args.Get(0).(*TimeSlot), args.Error(1)
 }
 
-func (m *MockRepository) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, onlyFuture bool) ([]TimeSlotWithAvailability, error) {
-	args := m.Called(ctx, gymID, onlyFuture)
+func (m *MockRepository) GetTimeSlotsWithAvailability(ctx context.Context, gymID int, filter SlotFilter) ([]TimeSlotWithAvailability, error) {
+	args := m.Called(ctx, gymID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]TimeSlotWithAvailability), args.Error(1)
 }
 
+func (m *MockRepository) SearchTimeSlots(ctx context.Context, search SlotSearch) ([]SlotSearchResult, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]SlotSearchResult), args.Error(1)
+}
+
+func (m *MockRepository) UpdateTimeSlot(ctx context.Context, id int, startTime, endTime time.Time, capacity int) (*TimeSlot, error) {
+	args := m.Called(ctx, id, startTime, endTime, capacity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TimeSlot), args.Error(1)
+}
+
+func (m *MockRepository) CancelTimeSlot(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) GetTimeSlotsInRange(ctx context.Context, gymID int, startTime, endTime time.Time) ([]TimeSlot, error) {
+	args := m.Called(ctx, gymID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]TimeSlot), args.Error(1)
+}
+
+func (m *MockRepository) GetOccupancySnapshot(ctx context.Context, gymID int, at time.Time) (*OccupancySnapshot, error) {
+	args := m.Called(ctx, gymID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OccupancySnapshot), args.Error(1)
+}
+
+func (m *MockRepository) GetHourlyBookings(ctx context.Context, gymID int, from, to time.Time, weekday time.Weekday, loc *time.Location) ([]HourCount, error) {
+	args := m.Called(ctx, gymID, from, to, weekday, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]HourCount), args.Error(1)
+}
+
+func (m *MockRepository) CreateClosure(ctx context.Context, gymID int, startsAt, endsAt time.Time, reason string) (*Closure, error) {
+	args := m.Called(ctx, gymID, startsAt, endsAt, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Closure), args.Error(1)
+}
+
+func (m *MockRepository) GetClosuresByGym(ctx context.Context, gymID int) ([]Closure, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Closure), args.Error(1)
+}
+
+func (m *MockRepository) GetClosureByID(ctx context.Context, id int) (*Closure, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Closure), args.Error(1)
+}
+
+func (m *MockRepository) GetClosuresOverlapping(ctx context.Context, gymID int, startTime, endTime time.Time) ([]Closure, error) {
+	args := m.Called(ctx, gymID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Closure), args.Error(1)
+}
+
+func (m *MockRepository) DeleteClosure(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) CreateRoom(ctx context.Context, gymID int, name string, capacity int) (*Room, error) {
+	args := m.Called(ctx, gymID, name, capacity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Room), args.Error(1)
+}
+
+func (m *MockRepository) GetRoomsByGym(ctx context.Context, gymID int) ([]Room, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Room), args.Error(1)
+}
+
+func (m *MockRepository) GetRoomByID(ctx context.Context, id int) (*Room, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Room), args.Error(1)
+}
+
+func (m *MockRepository) ClassTypeExists(ctx context.Context, id int) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) InstructorTeachesAt(ctx context.Context, instructorID, gymID int) (bool, error) {
+	args := m.Called(ctx, instructorID, gymID)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestService_CreateGym(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
@@ -80,7 +211,7 @@ func TestService_CreateGym(t *testing.T) {
 		Location: "Test Location",
 	}
 
-	mockRepo.On("CreateGym", mock.Anything, "Test Gym", "Test Location").Return(&Gym{
+	mockRepo.On("CreateGym", mock.Anything, "Test Gym", "Test Location", DefaultTimezone, "KZT").Return(&Gym{
 		ID:       1,
 		Name:     "Test Gym",
 		Location: "Test Location",
@@ -94,6 +225,45 @@ func TestService_CreateGym(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_CreateGym_InvalidTimezone(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	_, err := service.CreateGym(context.Background(), CreateGymRequest{
+		Name:     "Test Gym",
+		Location: "Test Location",
+		Timezone: "Europe/Atlantis",
+	})
+
+	assert.ErrorIs(t, err, ErrTimezoneInvalid)
+	mockRepo.AssertNotCalled(t, "CreateGym", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_CreateGym_Currency(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("CreateGym", mock.Anything, "Berlin Gym", "Berlin", "Europe/Berlin", "EUR").
+		Return(&Gym{ID: 2, Currency: "EUR"}, nil)
+
+	gym, err := service.CreateGym(context.Background(), CreateGymRequest{
+		Name:     "Berlin Gym",
+		Location: "Berlin",
+		Timezone: "Europe/Berlin",
+		Currency: "eur",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", gym.Currency)
+
+	_, err = service.CreateGym(context.Background(), CreateGymRequest{
+		Name:     "Berlin Gym",
+		Location: "Berlin",
+		Currency: "DEM",
+	})
+	assert.ErrorIs(t, err, ErrCurrencyInvalid)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_CreateTimeSlot(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -114,7 +284,13 @@ func TestService_CreateTimeSlot(t *testing.T) {
 				m.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
 				start, _ := time.Parse(time.RFC3339, "2024-12-20T10:00:00Z")
 				end, _ := time.Parse(time.RFC3339, "2024-12-20T11:00:00Z")
-				m.On("CreateTimeSlot", mock.Anything, 1, start, end, 20).Return(&TimeSlot{
+				m.On("GetClosuresOverlapping", mock.Anything, 1, start, end).Return([]Closure{}, nil)
+				m.On("CreateTimeSlot", mock.Anything, 1, NewTimeSlot{
+					StartTime:  start,
+					EndTime:    end,
+					Capacity:   20,
+					PriceCents: DefaultSlotPriceCents,
+				}).Return(&TimeSlot{
 					ID:        1,
 					GymID:     1,
 					StartTime: start,
@@ -124,6 +300,76 @@ func TestService_CreateTimeSlot(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:  "instructor does not teach at the gym",
+			gymID: 1,
+			req: CreateTimeSlotRequest{
+				StartTime:    "2024-12-20T10:00:00Z",
+				EndTime:      "2024-12-20T11:00:00Z",
+				Capacity:     20,
+				ClassTypeID:  intPtr(2),
+				InstructorID: intPtr(4),
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+				m.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]Closure{}, nil)
+				m.On("ClassTypeExists", mock.Anything, 2).Return(true, nil)
+				m.On("InstructorTeachesAt", mock.Anything, 4, 1).Return(false, nil)
+			},
+			expectError: true,
+		},
+		{
+			name:  "capacity exceeds the room",
+			gymID: 1,
+			req: CreateTimeSlotRequest{
+				StartTime: "2024-12-20T10:00:00Z",
+				EndTime:   "2024-12-20T11:00:00Z",
+				Capacity:  30,
+				RoomID:    intPtr(3),
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+				m.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]Closure{}, nil)
+				m.On("GetRoomByID", mock.Anything, 3).Return(&Room{ID: 3, GymID: 1, Capacity: 25}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name:  "room of another gym",
+			gymID: 1,
+			req: CreateTimeSlotRequest{
+				StartTime: "2024-12-20T10:00:00Z",
+				EndTime:   "2024-12-20T11:00:00Z",
+				Capacity:  10,
+				RoomID:    intPtr(7),
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+				m.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]Closure{}, nil)
+				m.On("GetRoomByID", mock.Anything, 7).Return(&Room{ID: 7, GymID: 2, Capacity: 25}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name:  "gym closed",
+			gymID: 1,
+			req: CreateTimeSlotRequest{
+				StartTime: "2024-12-25T10:00:00Z",
+				EndTime:   "2024-12-25T11:00:00Z",
+				Capacity:  20,
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+				m.On("GetClosuresOverlapping", mock.Anything, 1, mock.Anything, mock.Anything).Return([]Closure{{
+					ID:       1,
+					GymID:    1,
+					StartsAt: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+					EndsAt:   time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
+					Reason:   "Christmas",
+				}}, nil)
+			},
+			expectError: true,
+		},
 		{
 			name:  "gym not found",
 			gymID: 999,
@@ -191,7 +437,11 @@ func TestService_GetTimeSlots(t *testing.T) {
 	service := NewService(mockRepo)
 
 	mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
-	mockRepo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, true).Return([]TimeSlotWithAvailability{
+	mockRepo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, SlotFilter{
+		OnlyFuture: true,
+		Sort:       SortOrder{Field: SlotSortStartTime},
+		Limit:      DefaultPageLimit + 1,
+	}).Return([]TimeSlotWithAvailability{
 		{
 			TimeSlot: TimeSlot{
 				ID:        1,
@@ -206,11 +456,390 @@ func TestService_GetTimeSlots(t *testing.T) {
 		},
 	}, nil)
 
-	slots, err := service.GetTimeSlots(context.Background(), 1, true)
+	page, err := service.GetTimeSlots(context.Background(), 1, ListTimeSlotsRequest{OnlyFuture: true})
 
 	assert.NoError(t, err)
-	assert.Len(t, slots, 1)
+	assert.Len(t, page.Items, 1)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_GetTimeSlots_Paginated(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	start := time.Date(2099, 6, 1, 7, 0, 0, 0, time.UTC)
+	slot := func(id int, price int64) TimeSlotWithAvailability {
+		return TimeSlotWithAvailability{TimeSlot: TimeSlot{ID: id, GymID: 1, StartTime: start, PriceCents: price}}
+	}
+
+	t.Run("local range is read in the gym's zone and the extra item becomes the cursor", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo)
+
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1, Timezone: "Europe/Berlin"}, nil)
+		mockRepo.On("GetTimeSlotsWithAvailability", mock.Anything, 1, SlotFilter{
+			From:          time.Date(2099, 6, 1, 0, 0, 0, 0, berlin),
+			To:            time.Date(2099, 6, 2, 0, 0, 0, 0, berlin),
+			AvailableOnly: true,
+			Sort:          SortOrder{Field: SlotSortPrice, Desc: true},
+			Limit:         3,
+		}).Return([]TimeSlotWithAvailability{slot(3, 2000), slot(1, 1500), slot(2, 1500)}, nil)
+
+		page, err := service.GetTimeSlots(context.Background(), 1, ListTimeSlotsRequest{
+			From:          "2099-06-01",
+			To:            "2099-06-02",
+			AvailableOnly: true,
+			Sort:          "-price",
+			Limit:         2,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, "Europe/Berlin", page.Items[0].StartTime.Location().String())
+
+		cursor, err := parseCursor(page.NextCursor, SortOrder{Field: SlotSortPrice, Desc: true})
+		assert.NoError(t, err)
+		assert.Equal(t, &Cursor{Sort: "-price", Key: "1500", ID: 1}, cursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+
+		for _, req := range []ListTimeSlotsRequest{
+			{From: "tomorrow"},
+			{From: "2099-06-02", To: "2099-06-01"},
+			{Sort: "capacity"},
+			{Cursor: "not-a-cursor"},
+			{Sort: "price", Cursor: Cursor{Sort: "start_time", Key: start.Format(time.RFC3339Nano), ID: 1}.Encode()},
+		} {
+			_, err := service.GetTimeSlots(context.Background(), 1, req)
+			assert.ErrorIs(t, err, ErrListingInvalid)
+		}
+		mockRepo.AssertNotCalled(t, "GetTimeSlotsWithAvailability", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_ListGyms(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	after := &Cursor{Sort: "-created_at", Key: created.Format(time.RFC3339Nano), ID: 7}
+
+	mockRepo.On("ListGyms", mock.Anything, GymQuery{
+		From:  TimeBound{Time: time.Date(2099, 6, 1, 18, 0, 0, 0, time.UTC), Local: true},
+		Sort:  SortOrder{Field: GymSortCreatedAt, Desc: true},
+		Limit: 2,
+		After: after,
+	}).Return([]Gym{{ID: 6, CreatedAt: created}, {ID: 5, CreatedAt: created}}, nil)
+
+	page, err := service.ListGyms(context.Background(), ListGymsRequest{
+		From:   "2099-06-01T18:00",
+		Limit:  1,
+		Cursor: after.Encode(),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 6, page.Items[0].ID)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, Cursor{Sort: "-created_at", Key: after.Key, ID: 6}.Encode(), page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ImportTimeSlots(t *testing.T) {
+	const validCSV = `start_time,end_time,capacity,price_cents,tags
+2024-12-20T10:00:00Z,2024-12-20T11:00:00Z,20,1500,yoga;morning
+2024-12-20T12:00:00Z,2024-12-20T13:00:00Z,15,,
+`
+	const invalidCSV = `start_time,end_time,capacity
+2024-12-20T10:00:00Z,2024-12-20T11:00:00Z,20
+2024-12-20T12:00:00Z,2024-12-20T11:00:00Z,15
+2024-12-20T14:00:00Z,2024-12-20T15:00:00Z,many
+`
+
+	t.Run("dry run reports every row without writing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetClosuresByGym", mock.Anything, 1).Return([]Closure{}, nil)
+
+		service := NewService(mockRepo)
+		result, err := service.ImportTimeSlots(context.Background(), 1, strings.NewReader(invalidCSV), true)
+
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 3, result.TotalRows)
+		assert.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, 2, result.InvalidRows)
+		assert.Equal(t, 3, result.Rows[1].Row)
+		assert.Equal(t, "end_time must be after start_time", result.Rows[1].Error)
+		assert.Equal(t, "capacity must be a number", result.Rows[2].Error)
+		mockRepo.AssertNotCalled(t, "CreateTimeSlots", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("commit with invalid rows imports nothing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetClosuresByGym", mock.Anything, 1).Return([]Closure{}, nil)
+
+		service := NewService(mockRepo)
+		result, err := service.ImportTimeSlots(context.Background(), 1, strings.NewReader(invalidCSV), false)
+
+		assert.ErrorIs(t, err, ErrImportHasErrors)
+		assert.Equal(t, 0, result.Imported)
+		mockRepo.AssertNotCalled(t, "CreateTimeSlots", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("commit creates all slots", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetClosuresByGym", mock.Anything, 1).Return([]Closure{}, nil)
+		mockRepo.On("CreateTimeSlots", mock.Anything, 1, mock.MatchedBy(func(slots []NewTimeSlot) bool {
+			return len(slots) == 2 &&
+				slots[0].PriceCents == 1500 &&
+				len(slots[0].Tags) == 2 &&
+				slots[1].PriceCents == DefaultSlotPriceCents
+		})).Return([]TimeSlot{{ID: 1}, {ID: 2}}, nil)
+
+		service := NewService(mockRepo)
+		result, err := service.ImportTimeSlots(context.Background(), 1, strings.NewReader(validCSV), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Imported)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rows inside a closure are invalid", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetClosuresByGym", mock.Anything, 1).Return([]Closure{{
+			StartsAt: time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC),
+			EndsAt:   time.Date(2024, 12, 20, 18, 0, 0, 0, time.UTC),
+			Reason:   "Maintenance",
+		}}, nil)
+
+		service := NewService(mockRepo)
+		result, err := service.ImportTimeSlots(context.Background(), 1, strings.NewReader(validCSV), true)
 
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, "gym is closed: Maintenance", result.Rows[1].Error)
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetClosuresByGym", mock.Anything, 1).Return([]Closure{}, nil)
+
+		service := NewService(mockRepo)
+		_, err := service.ImportTimeSlots(context.Background(), 1, strings.NewReader("start_time,end_time\n"), true)
+
+		assert.ErrorIs(t, err, ErrImportInvalid)
+	})
+}
+
+func TestService_CreateClosure(t *testing.T) {
+	t.Run("whole day closure reports affected slots", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		day := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+		next := day.AddDate(0, 0, 1)
+
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("CreateClosure", mock.Anything, 1, day, next, "Christmas").Return(&Closure{
+			ID: 3, GymID: 1, StartsAt: day, EndsAt: next, Reason: "Christmas",
+		}, nil)
+		mockRepo.On("GetTimeSlotsInRange", mock.Anything, 1, day, next).Return([]TimeSlot{{ID: 10}, {ID: 11}}, nil)
+
+		service := NewService(mockRepo)
+		resp, err := service.CreateClosure(context.Background(), 1, CreateClosureRequest{Date: "2024-12-25", Reason: "Christmas"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, resp.Closure.ID)
+		assert.Len(t, resp.AffectedSlots, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an empty range", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+
+		service := NewService(mockRepo)
+		_, err := service.CreateClosure(context.Background(), 1, CreateClosureRequest{
+			StartsAt: "2024-12-24T18:00:00Z",
+			EndsAt:   "2024-12-24T14:00:00Z",
+		})
+
+		assert.ErrorIs(t, err, ErrClosureInvalid)
+		mockRepo.AssertNotCalled(t, "CreateClosure", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects date combined with a range", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+
+		service := NewService(mockRepo)
+		_, err := service.CreateClosure(context.Background(), 1, CreateClosureRequest{
+			Date:     "2024-12-25",
+			StartsAt: "2024-12-24T14:00:00Z",
+		})
+
+		assert.ErrorIs(t, err, ErrClosureInvalid)
+	})
+}
+
+func TestService_CreateRoom(t *testing.T) {
+	t.Run("creates a room", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetRoomsByGym", mock.Anything, 1).Return([]Room{{ID: 1, Name: "Pool"}}, nil)
+		mockRepo.On("CreateRoom", mock.Anything, 1, "Studio", 25).Return(&Room{ID: 2, GymID: 1, Name: "Studio", Capacity: 25}, nil)
+
+		service := NewService(mockRepo)
+		room, err := service.CreateRoom(context.Background(), 1, CreateRoomRequest{Name: "Studio", Capacity: 25})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, room.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a duplicate name", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+		mockRepo.On("GetRoomsByGym", mock.Anything, 1).Return([]Room{{ID: 1, Name: "Studio"}}, nil)
+
+		service := NewService(mockRepo)
+		_, err := service.CreateRoom(context.Background(), 1, CreateRoomRequest{Name: "studio", Capacity: 25})
+
+		assert.ErrorIs(t, err, ErrRoomExists)
+		mockRepo.AssertNotCalled(t, "CreateRoom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_SearchTimeSlots(t *testing.T) {
+	t.Run("applies the default page size", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("SearchTimeSlots", mock.Anything, SlotSearch{MinAvailable: 1, Limit: DefaultPageLimit + 1}).
+			Return(nil, nil)
+
+		service := NewService(mockRepo)
+		page, err := service.SearchTimeSlots(context.Background(), SlotSearch{MinAvailable: 1})
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Equal(t, DefaultPageLimit, page.Limit)
+		assert.False(t, page.HasMore)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("pages by start time", func(t *testing.T) {
+		start := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+		slots := []SlotSearchResult{
+			{TimeSlotWithAvailability: TimeSlotWithAvailability{TimeSlot: TimeSlot{ID: 7, StartTime: start}}, GymName: "Downtown"},
+			{TimeSlotWithAvailability: TimeSlotWithAvailability{TimeSlot: TimeSlot{ID: 9, StartTime: start.Add(time.Hour)}}, GymName: "Uptown"},
+		}
+		mockRepo := new(MockRepository)
+		mockRepo.On("SearchTimeSlots", mock.Anything, SlotSearch{Limit: 2}).Return(slots, nil)
+
+		service := NewService(mockRepo)
+		page, err := service.SearchTimeSlots(context.Background(), SlotSearch{Limit: 1})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.True(t, page.HasMore)
+
+		after, err := parseCursor(page.NextCursor, SortOrder{Field: SlotSortStartTime})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, after.ID)
+
+		mockRepo.On("SearchTimeSlots", mock.Anything, SlotSearch{Limit: 2, Cursor: page.NextCursor, After: after}).
+			Return(slots[1:], nil)
+		page, err = service.SearchTimeSlots(context.Background(), SlotSearch{Limit: 1, Cursor: page.NextCursor})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Uptown", page.Items[0].GymName)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("rejects a cursor of another listing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		cursor := Cursor{Sort: "-price", Key: "1500", ID: 7}.Encode()
+
+		service := NewService(mockRepo)
+		_, err := service.SearchTimeSlots(context.Background(), SlotSearch{Cursor: cursor})
+
+		assert.ErrorIs(t, err, ErrListingInvalid)
+		mockRepo.AssertNotCalled(t, "SearchTimeSlots", mock.Anything, mock.Anything)
+	})
+
+	t.Run("limits the price in the given currency", func(t *testing.T) {
+		maxPrice := int64(1500)
+		mockRepo := new(MockRepository)
+		mockRepo.On("SearchTimeSlots", mock.Anything, SlotSearch{Currency: "EUR", MaxPriceCents: &maxPrice, Limit: DefaultPageLimit + 1}).
+			Return(nil, nil)
+
+		service := NewService(mockRepo)
+		_, err := service.SearchTimeSlots(context.Background(), SlotSearch{Currency: "eur", MaxPriceCents: &maxPrice})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a price limit without a currency", func(t *testing.T) {
+		maxPrice := int64(1500)
+		mockRepo := new(MockRepository)
+
+		service := NewService(mockRepo)
+		_, err := service.SearchTimeSlots(context.Background(), SlotSearch{MaxPriceCents: &maxPrice})
+
+		assert.ErrorIs(t, err, ErrSearchInvalid)
+		mockRepo.AssertNotCalled(t, "SearchTimeSlots", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an empty time range", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		at := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+
+		service := NewService(mockRepo)
+		_, err := service.SearchTimeSlots(context.Background(), SlotSearch{From: at, To: at})
+
+		assert.ErrorIs(t, err, ErrSearchInvalid)
+		mockRepo.AssertNotCalled(t, "SearchTimeSlots", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_GetOccupancy(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetGymByID", mock.Anything, 1).Return(&Gym{ID: 1}, nil)
+	mockRepo.On("GetOccupancySnapshot", mock.Anything, 1, mock.Anything).Return(&OccupancySnapshot{Headcount: 18, Capacity: 40}, nil)
+	mockRepo.On("GetHourlyBookings", mock.Anything, 1, mock.Anything, mock.Anything, time.Monday, time.UTC).Return([]HourCount{
+		{Hour: 7, Bookings: 20},
+		{Hour: 18, Bookings: 40},
+	}, nil)
+
+	service := NewService(mockRepo)
+	monday := time.Monday
+	occupancy, err := service.GetOccupancy(context.Background(), 1, 0, &monday)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 45, occupancy.Percent)
+	assert.Equal(t, "Monday", occupancy.Weekday)
+	assert.Equal(t, DefaultOccupancyWeeks, occupancy.Weeks)
+	assert.Len(t, occupancy.Typical, 24)
+	assert.Equal(t, HourlyBusyness{Hour: 7, AverageHeadcount: 5, Busyness: 50}, occupancy.Typical[7])
+	assert.Equal(t, HourlyBusyness{Hour: 18, AverageHeadcount: 10, Busyness: 100}, occupancy.Typical[18])
+	assert.Equal(t, 0, occupancy.Typical[3].Busyness)
+
+	from := mockRepo.Calls[2].Arguments.Get(2).(time.Time)
+	to := mockRepo.Calls[2].Arguments.Get(3).(time.Time)
+	assert.Equal(t, 7*DefaultOccupancyWeeks*24*time.Hour, to.Sub(from))
+}
+
+func intPtr(v int) *int {
+	return &v
+}
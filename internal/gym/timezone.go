@@ -0,0 +1,168 @@
+package gym
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrTimezoneInvalid = errors.New("invalid timezone")
+
+// localLayouts are the accepted forms of a wall-clock time without an
+// offset, interpreted in the gym's time zone.
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// boundLayouts are localLayouts plus a bare date, for range bounds.
+var boundLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+var zones sync.Map
+
+// LoadZone returns the named IANA time zone, or UTC when the name is empty
+// or unknown. Zones are cached after the first lookup.
+func LoadZone(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	zones.Store(name, loc)
+	return loc
+}
+
+// ValidateTimezone checks that name is a known IANA time zone such as
+// "Europe/Berlin".
+func ValidateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return ErrTimezoneInvalid
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrTimezoneInvalid
+	}
+	return nil
+}
+
+// Zone returns the gym's time zone.
+func (g Gym) Zone() *time.Location {
+	return LoadZone(g.Timezone)
+}
+
+// In returns the slot with its start and end expressed in loc.
+func (ts TimeSlot) In(loc *time.Location) TimeSlot {
+	ts.StartTime = ts.StartTime.In(loc)
+	ts.EndTime = ts.EndTime.In(loc)
+	return ts
+}
+
+// In returns the closure with its range expressed in loc.
+func (c Closure) In(loc *time.Location) Closure {
+	c.StartsAt = c.StartsAt.In(loc)
+	c.EndsAt = c.EndsAt.In(loc)
+	return c
+}
+
+// ParseSlotTime reads a time given either in RFC3339, with an explicit
+// offset, or as a wall-clock time such as "2024-03-31T09:00" in the gym's
+// time zone. See LocalTime for how DST changes are handled.
+func ParseSlotTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range localLayouts {
+		wall, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		t, ok := LocalTime(wall, loc)
+		if !ok {
+			return time.Time{}, fmt.Errorf("%s does not exist in %s because of a DST change", value, loc)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, errors.New("must be RFC3339 or a local time like 2006-01-02T15:04")
+}
+
+// LocalTime returns the instant at which the clock in loc shows the date and
+// time of wall (whose own location is ignored). ok is false when that wall
+// time is skipped by a DST change. When it occurs twice, as clocks go back,
+// the earlier instant is returned.
+//
+// Unlike time.Date, which silently normalizes such times, this never
+// returns an instant whose local wall clock differs from the one asked for.
+func LocalTime(wall time.Time, loc *time.Location) (time.Time, bool) {
+	naive := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+
+	// A day either side of a wall time always covers the offsets in
+	// effect around it, including both sides of a DST change.
+	var result time.Time
+	found := false
+	for _, probe := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		_, offset := naive.Add(probe).In(loc).Zone()
+		candidate := naive.Add(-time.Duration(offset) * time.Second)
+		if !sameWallClock(candidate.In(loc), naive) {
+			continue
+		}
+		if !found || candidate.Before(result) {
+			result, found = candidate, true
+		}
+	}
+
+	if !found {
+		return time.Time{}, false
+	}
+	return result.In(loc), true
+}
+
+func sameWallClock(t, naive time.Time) bool {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := naive.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2 &&
+		t.Hour() == naive.Hour() && t.Minute() == naive.Minute() &&
+		t.Second() == naive.Second() && t.Nanosecond() == naive.Nanosecond()
+}
+
+// TimeBound is one end of a listing's date range. A bound given with an
+// offset is an instant; one without is a wall-clock time, or the start of a
+// date, read in each gym's own time zone.
+type TimeBound struct {
+	Time  time.Time
+	Local bool
+}
+
+// ParseTimeBound reads an RFC3339 time, a local time such as
+// "2024-06-01T18:00" or a date such as "2024-06-01".
+func ParseTimeBound(value string) (TimeBound, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return TimeBound{Time: t}, nil
+	}
+
+	for _, layout := range boundLayouts {
+		if wall, err := time.Parse(layout, value); err == nil {
+			return TimeBound{Time: wall, Local: true}, nil
+		}
+	}
+
+	return TimeBound{}, errors.New("must be RFC3339, a local time like 2006-01-02T15:04 or a date")
+}
+
+func (b TimeBound) IsZero() bool {
+	return b.Time.IsZero()
+}
+
+// In returns the instant of the bound for a gym in loc. Local times skipped
+// by a DST change move forward, which is harmless for a range bound.
+func (b TimeBound) In(loc *time.Location) time.Time {
+	if b.IsZero() || !b.Local {
+		return b.Time
+	}
+	w := b.Time
+	return time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), loc)
+}
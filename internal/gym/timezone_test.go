@@ -0,0 +1,67 @@
+package gym
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSlotTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"RFC3339 keeps its offset", "2024-06-01T18:00:00Z", "2024-06-01T18:00:00Z", false},
+		{"local time in summer", "2024-06-01T18:00", "2024-06-01T16:00:00Z", false},
+		{"local time in winter", "2024-01-15T18:00:00", "2024-01-15T17:00:00Z", false},
+		{"just before clocks go forward", "2024-03-31T01:59", "2024-03-31T00:59:00Z", false},
+		{"skipped by clocks going forward", "2024-03-31T02:30", "", true},
+		{"just after clocks go forward", "2024-03-31T03:00", "2024-03-31T01:00:00Z", false},
+		{"repeated as clocks go back picks the first", "2024-10-27T02:30", "2024-10-27T00:30:00Z", false},
+		{"after clocks go back", "2024-10-27T03:00", "2024-10-27T02:00:00Z", false},
+		{"garbage", "tomorrow evening", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSlotTime(tt.value, berlin)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.UTC().Format(time.RFC3339))
+		})
+	}
+}
+
+func TestLocalTime_KeepsWallClockAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 09:00 every day around the March 10, 2024 change stays 09:00 local
+	// while the UTC instant moves by an hour.
+	before, ok := LocalTime(time.Date(2024, 3, 9, 9, 0, 0, 0, time.UTC), newYork)
+	require.True(t, ok)
+	after, ok := LocalTime(time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC), newYork)
+	require.True(t, ok)
+
+	assert.Equal(t, 9, before.Hour())
+	assert.Equal(t, 9, after.Hour())
+	assert.Equal(t, 23*time.Hour, after.Sub(before))
+}
+
+func TestValidateTimezone(t *testing.T) {
+	assert.NoError(t, ValidateTimezone("Europe/Berlin"))
+	assert.NoError(t, ValidateTimezone("UTC"))
+	assert.ErrorIs(t, ValidateTimezone("Mars/Olympus_Mons"), ErrTimezoneInvalid)
+	assert.ErrorIs(t, ValidateTimezone(""), ErrTimezoneInvalid)
+	assert.Equal(t, time.UTC, LoadZone("Mars/Olympus_Mons"))
+}
@@ -0,0 +1,29 @@
+// Package job runs background work on an interval.
+package job
+
+import (
+	"context"
+	"time"
+
+	"fitslot/internal/logger"
+)
+
+// Run calls run right away and then every interval until ctx is done. name
+// describes the work in the log when it starts and stops.
+func Run(ctx context.Context, name string, interval time.Duration, run func(context.Context)) {
+	logger.Info(name + " started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(name + " stopped")
+			return
+		case <-ticker.C:
+			run(ctx)
+		}
+	}
+}
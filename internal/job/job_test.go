@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, "Test job", time.Millisecond, func(context.Context) {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after the context was cancelled")
+	}
+	assert.GreaterOrEqual(t, runs, 3)
+}
@@ -1,111 +1,209 @@
-package metrics
-
-import (
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
-)
-
-var (
-	HTTPRequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "fitslot_http_requests_total",
-			Help: "Total number of HTTP requests",
-		},
-		[]string{"method", "path", "status"},
-	)
-
-	HTTPRequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "fitslot_http_request_duration_seconds",
-			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path"},
-	)
-
-	BookingsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "fitslot_bookings_total",
-			Help: "Total number of bookings",
-		},
-		[]string{"status", "payment_method"},
-	)
-
-	BookingCancellationsTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "fitslot_booking_cancellations_total",
-			Help: "Total number of booking cancellations",
-		},
-	)
-
-	EmailsSentTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "fitslot_emails_sent_total",
-			Help: "Total number of emails sent",
-		},
-		[]string{"type", "status"},
-	)
-
-	EmailQueueLength = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "fitslot_email_queue_length",
-			Help: "Current length of email queue",
-		},
-	)
-
-	WalletTopUpsTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "fitslot_wallet_topups_total",
-			Help: "Total number of wallet top-ups",
-		},
-	)
-
-	WalletBalance = promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "fitslot_wallet_balance_cents",
-			Help: "Current wallet balance in cents",
-		},
-		[]string{"user_id"},
-	)
-
-	SubscriptionsCreatedTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "fitslot_subscriptions_created_total",
-			Help: "Total number of subscriptions created",
-		},
-		[]string{"type"},
-	)
-
-	ActiveSubscriptions = promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "fitslot_active_subscriptions",
-			Help: "Number of active subscriptions",
-		},
-		[]string{"type"},
-	)
-)
-
-func RecordHTTPRequest(method, path, status string, duration float64) {
-	HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
-	HTTPRequestDuration.WithLabelValues(method, path).Observe(duration)
-}
-
-func RecordBooking(status, paymentMethod string) {
-	BookingsTotal.WithLabelValues(status, paymentMethod).Inc()
-}
-
-func RecordBookingCancellation() {
-	BookingCancellationsTotal.Inc()
-}
-
-func RecordEmail(emailType, status string) {
-	EmailsSentTotal.WithLabelValues(emailType, status).Inc()
-}
-
-func RecordWalletTopUp() {
-	WalletTopUpsTotal.Inc()
-}
-
-func RecordSubscription(subType string) {
-	SubscriptionsCreatedTotal.WithLabelValues(subType).Inc()
-}
\ No newline at end of file
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_http_requests_total",
+			Help: "Total number of HTTP requests",
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	HTTPRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "fitslot_http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "path"},
+	)
+
+	BookingsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_bookings_total",
+			Help: "Total number of bookings",
+		},
+		[]string{"status", "payment_method"},
+	)
+
+	BookingCancellationsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fitslot_booking_cancellations_total",
+			Help: "Total number of booking cancellations",
+		},
+	)
+
+	EmailsSentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_emails_sent_total",
+			Help: "Total number of emails sent",
+		},
+		[]string{"type", "status"},
+	)
+
+	EmailQueueLength = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fitslot_email_queue_length",
+			Help: "Current length of email queue",
+		},
+	)
+
+	WalletTopUpsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fitslot_wallet_topups_total",
+			Help: "Total number of wallet top-ups",
+		},
+	)
+
+	WalletAutoTopUpsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_wallet_auto_topups_total",
+			Help: "Settled auto top-ups by result (succeeded, declined), and auto top-ups turned off after repeated declines (disabled)",
+		},
+		[]string{"result"},
+	)
+
+	WalletPromoCreditCentsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_wallet_promo_credit_cents_total",
+			Help: "Promotional credit in cents granted to members or expired unspent, by event (granted, expired) and currency",
+		},
+		[]string{"event", "currency"},
+	)
+
+	WalletTransfersTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_wallet_transfers_total",
+			Help: "Wallet-to-wallet transfers by status (completed, rejected)",
+		},
+		[]string{"status"},
+	)
+
+	WalletBalance = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "fitslot_wallet_balance_cents",
+			Help: "Current wallet balance in cents",
+		},
+		[]string{"user_id"},
+	)
+
+	SubscriptionsCreatedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_subscriptions_created_total",
+			Help: "Total number of subscriptions created",
+		},
+		[]string{"type"},
+	)
+
+	ActiveSubscriptions = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "fitslot_active_subscriptions",
+			Help: "Number of active subscriptions",
+		},
+		[]string{"type"},
+	)
+
+	AvailabilityCacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_availability_cache_requests_total",
+			Help: "Slot availability lookups by cache result (hit, miss, error)",
+		},
+		[]string{"result"},
+	)
+
+	AvailabilityCacheInvalidationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fitslot_availability_cache_invalidations_total",
+			Help: "Slot availability cache invalidations by status",
+		},
+		[]string{"status"},
+	)
+
+	LedgerBalanced = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fitslot_ledger_balanced",
+			Help: "1 when the last ledger check found no imbalance, 0 otherwise",
+		},
+	)
+
+	WalletReconciliationDiscrepancies = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "fitslot_wallet_reconciliation_discrepancies",
+			Help: "Wallets found by the last reconciliation whose balance differs from their transactions (balance) or whose balance_after chain is broken (chain)",
+		},
+		[]string{"kind"},
+	)
+
+	WalletReconciliationLastRun = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fitslot_wallet_reconciliation_last_run_timestamp_seconds",
+			Help: "Unix time of the last completed wallet reconciliation",
+		},
+	)
+)
+
+func RecordHTTPRequest(method, path, status string, duration float64) {
+	HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
+	HTTPRequestDuration.WithLabelValues(method, path).Observe(duration)
+}
+
+func RecordBooking(status, paymentMethod string) {
+	BookingsTotal.WithLabelValues(status, paymentMethod).Inc()
+}
+
+func RecordBookingCancellation() {
+	BookingCancellationsTotal.Inc()
+}
+
+func RecordEmail(emailType, status string) {
+	EmailsSentTotal.WithLabelValues(emailType, status).Inc()
+}
+
+func RecordWalletTopUp() {
+	WalletTopUpsTotal.Inc()
+}
+
+func RecordWalletAutoTopUp(result string) {
+	WalletAutoTopUpsTotal.WithLabelValues(result).Inc()
+}
+
+func RecordWalletPromoCredit(event, currency string, cents int64) {
+	WalletPromoCreditCentsTotal.WithLabelValues(event, currency).Add(float64(cents))
+}
+
+func RecordWalletTransfer(status string) {
+	WalletTransfersTotal.WithLabelValues(status).Inc()
+}
+
+func RecordSubscription(subType string) {
+	SubscriptionsCreatedTotal.WithLabelValues(subType).Inc()
+}
+
+func RecordAvailabilityCache(result string) {
+	AvailabilityCacheRequestsTotal.WithLabelValues(result).Inc()
+}
+
+func RecordAvailabilityCacheInvalidation(status string) {
+	AvailabilityCacheInvalidationsTotal.WithLabelValues(status).Inc()
+}
+
+func RecordWalletReconciliation(balanceMismatches, brokenChains int, at time.Time) {
+	WalletReconciliationDiscrepancies.WithLabelValues("balance").Set(float64(balanceMismatches))
+	WalletReconciliationDiscrepancies.WithLabelValues("chain").Set(float64(brokenChains))
+	WalletReconciliationLastRun.Set(float64(at.Unix()))
+}
+
+func RecordLedgerCheck(ok bool) {
+	if ok {
+		LedgerBalanced.Set(1)
+	} else {
+		LedgerBalanced.Set(0)
+	}
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -137,6 +138,28 @@ func TestRecordWalletTopUp(t *testing.T) {
 	assert.Equal(t, float64(3), count)
 }
 
+func TestRecordWalletAutoTopUp(t *testing.T) {
+	WalletAutoTopUpsTotal.Reset()
+
+	RecordWalletAutoTopUp("succeeded")
+	RecordWalletAutoTopUp("declined")
+	RecordWalletAutoTopUp("declined")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(WalletAutoTopUpsTotal.WithLabelValues("succeeded")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(WalletAutoTopUpsTotal.WithLabelValues("declined")))
+}
+
+func TestRecordWalletPromoCredit(t *testing.T) {
+	WalletPromoCreditCentsTotal.Reset()
+
+	RecordWalletPromoCredit("granted", "KZT", 200000)
+	RecordWalletPromoCredit("expired", "KZT", 50000)
+	RecordWalletPromoCredit("expired", "KZT", 25000)
+
+	assert.Equal(t, float64(200000), testutil.ToFloat64(WalletPromoCreditCentsTotal.WithLabelValues("granted", "KZT")))
+	assert.Equal(t, float64(75000), testutil.ToFloat64(WalletPromoCreditCentsTotal.WithLabelValues("expired", "KZT")))
+}
+
 func TestRecordSubscription(t *testing.T) {
 	SubscriptionsCreatedTotal.Reset()
 
@@ -241,4 +264,46 @@ func TestMetricsIntegration(t *testing.T) {
 	assert.Equal(t, float64(1), bookingCount)
 	assert.Equal(t, float64(1), emailCount)
 	assert.Equal(t, float64(1), subCount)
-}
\ No newline at end of file
+}
+func TestRecordAvailabilityCache(t *testing.T) {
+	AvailabilityCacheRequestsTotal.Reset()
+	AvailabilityCacheInvalidationsTotal.Reset()
+
+	RecordAvailabilityCache("hit")
+	RecordAvailabilityCache("hit")
+	RecordAvailabilityCache("miss")
+	RecordAvailabilityCacheInvalidation("ok")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(AvailabilityCacheRequestsTotal.WithLabelValues("hit")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(AvailabilityCacheRequestsTotal.WithLabelValues("miss")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(AvailabilityCacheInvalidationsTotal.WithLabelValues("ok")))
+}
+
+func TestRecordLedgerCheck(t *testing.T) {
+	RecordLedgerCheck(false)
+	assert.Equal(t, float64(0), testutil.ToFloat64(LedgerBalanced))
+
+	RecordLedgerCheck(true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(LedgerBalanced))
+}
+
+func TestRecordWalletTransfer(t *testing.T) {
+	WalletTransfersTotal.Reset()
+
+	RecordWalletTransfer("completed")
+	RecordWalletTransfer("rejected")
+	RecordWalletTransfer("completed")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(WalletTransfersTotal.WithLabelValues("completed")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(WalletTransfersTotal.WithLabelValues("rejected")))
+}
+
+func TestRecordWalletReconciliation(t *testing.T) {
+	at := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	RecordWalletReconciliation(2, 1, at)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(WalletReconciliationDiscrepancies.WithLabelValues("balance")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(WalletReconciliationDiscrepancies.WithLabelValues("chain")))
+	assert.Equal(t, float64(at.Unix()), testutil.ToFloat64(WalletReconciliationLastRun))
+}
@@ -0,0 +1,129 @@
+// Package money knows the currencies prices and wallets can be held in and
+// converts amounts between them.
+//
+// Amounts are integers in the currency's minor unit: tiyn for KZT, cents for
+// USD, whole yen for JPY and fils (thousandths) for KWD.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrRateInvalid     = errors.New("invalid exchange rate")
+)
+
+// DefaultCurrency is what wallets and prices are held in unless a gym or
+// request says otherwise.
+const DefaultCurrency = "KZT"
+
+// MaxRateDecimals is the most decimal places an exchange rate is stored with.
+const MaxRateDecimals = 10
+
+// Currency is an ISO 4217 currency and the number of decimal places of its
+// minor unit.
+type Currency struct {
+	Code       string `json:"code" example:"KZT"`
+	MinorUnits int    `json:"minor_units" example:"2"`
+}
+
+var currencies = map[string]Currency{
+	"KZT": {"KZT", 2},
+	"USD": {"USD", 2},
+	"EUR": {"EUR", 2},
+	"GBP": {"GBP", 2},
+	"RUB": {"RUB", 2},
+	"UZS": {"UZS", 2},
+	"KGS": {"KGS", 2},
+	"JPY": {"JPY", 0},
+	"KRW": {"KRW", 0},
+	"KWD": {"KWD", 3},
+	"BHD": {"BHD", 3},
+}
+
+// Lookup returns the currency with the code, which is case-insensitive.
+func Lookup(code string) (Currency, error) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, ErrUnknownCurrency
+	}
+	return c, nil
+}
+
+// Normalize returns the canonical code of a supported currency, or
+// DefaultCurrency when code is empty.
+func Normalize(code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return DefaultCurrency, nil
+	}
+	c, err := Lookup(code)
+	if err != nil {
+		return "", err
+	}
+	return c.Code, nil
+}
+
+// ParseRate reads an exchange rate written as a positive decimal such as
+// "0.0021" or "470.15".
+func ParseRate(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "eE/+-") {
+		return nil, ErrRateInvalid
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > MaxRateDecimals {
+		return nil, ErrRateInvalid
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrRateInvalid
+	}
+	return rate, nil
+}
+
+// Convert converts amount from one currency to another at rate, the price of
+// one unit of from in units of to. The result is rounded to the minor unit
+// of to, halves away from zero.
+func Convert(amount int64, from, to Currency, rate *big.Rat) int64 {
+	v := new(big.Rat).SetInt64(amount)
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(to.MinorUnits), pow10(from.MinorUnits)))
+	return roundHalfAway(v)
+}
+
+// Format writes amount as a plain decimal in the currency's major unit, such
+// as "-10.50" for -1050 KZT or "1868" for 1868 JPY.
+func Format(amount int64, c Currency) string {
+	sign := ""
+	abs := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		abs = uint64(-amount)
+	}
+	if c.MinorUnits == 0 {
+		return sign + fmt.Sprint(abs)
+	}
+	div := pow10(c.MinorUnits).Uint64()
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/div, c.MinorUnits, abs%div)
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func roundHalfAway(v *big.Rat) int64 {
+	num := new(big.Int).Abs(v.Num())
+	den := v.Denom()
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Lsh(r, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
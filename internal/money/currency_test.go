@@ -0,0 +1,86 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		from   string
+		to     string
+		rate   string
+		want   int64
+	}{
+		{"same minor units", 1000, "USD", "KZT", "470.15", 470150},
+		{"two to two rounds half up", 1, "KZT", "USD", "0.005", 0},
+		{"two to two rounds half away", 100, "KZT", "USD", "0.005", 1},
+		{"two to two rounds down below half", 149, "KZT", "USD", "0.0021", 0},
+		{"two to zero rounds to whole yen", 1234, "USD", "JPY", "151.37", 1868},
+		{"two to zero half goes up", 50, "USD", "JPY", "1", 1},
+		{"two to zero below half goes down", 49, "USD", "JPY", "1", 0},
+		{"zero to two", 1868, "JPY", "USD", "0.0066", 1233},
+		{"two to three keeps fils", 1000, "USD", "KWD", "0.3071", 3071},
+		{"two to three rounds half away", 1, "EUR", "KWD", "0.3335", 3},
+		{"three to two rounds half away", 5, "KWD", "USD", "1", 1},
+		{"three to two below half", 4, "KWD", "USD", "1", 0},
+		{"three to zero", 1500, "BHD", "JPY", "401.2", 602},
+		{"zero to three", 1000, "KRW", "KWD", "0.000222", 222},
+		{"negative amounts round away from zero", -50, "USD", "JPY", "1", -1},
+		{"negative amounts below half", -49, "USD", "JPY", "1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := Lookup(tt.from)
+			require.NoError(t, err)
+			to, err := Lookup(tt.to)
+			require.NoError(t, err)
+			rate, err := ParseRate(tt.rate)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, Convert(tt.amount, from, to, rate))
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"470.15", "0.0021", "1", " 151.37 ", "0.0000000001"} {
+		_, err := ParseRate(s)
+		assert.NoError(t, err, s)
+	}
+	for _, s := range []string{"", "0", "-1", "+2", "1e3", "1/3", "abc", "0.00000000001"} {
+		_, err := ParseRate(s)
+		assert.ErrorIs(t, err, ErrRateInvalid, s)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	code, err := Normalize("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCurrency, code)
+
+	code, err = Normalize(" usd ")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", code)
+
+	_, err = Normalize("XYZ")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestFormat(t *testing.T) {
+	kzt, _ := Lookup("KZT")
+	jpy, _ := Lookup("JPY")
+	kwd, _ := Lookup("KWD")
+
+	assert.Equal(t, "10.50", Format(1050, kzt))
+	assert.Equal(t, "-0.05", Format(-5, kzt))
+	assert.Equal(t, "0.00", Format(0, kzt))
+	assert.Equal(t, "1868", Format(1868, jpy))
+	assert.Equal(t, "-1868", Format(-1868, jpy))
+	assert.Equal(t, "3.071", Format(3071, kwd))
+}
@@ -0,0 +1,221 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"fitslot/internal/logger"
+	"fitslot/internal/metrics"
+	"fitslot/internal/money"
+	"fitslot/internal/wallet"
+)
+
+// SavePaymentMethod saves a payment method with the provider for the
+// member's auto top-ups.
+func (s *service) SavePaymentMethod(ctx context.Context, userID int, req SavePaymentMethodRequest) (*PaymentMethod, error) {
+	if s.provider == nil {
+		return nil, ErrProviderUnavailable
+	}
+	saved, err := s.provider.SavePaymentMethod(ctx, req.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreatePaymentMethod(ctx, PaymentMethod{
+		UserID:           userID,
+		Provider:         s.provider.Name(),
+		ProviderMethodID: saved.ID,
+		Label:            saved.Label,
+	})
+}
+
+func (s *service) ListPaymentMethods(ctx context.Context, userID int) ([]PaymentMethod, error) {
+	return s.repo.ListPaymentMethods(ctx, userID)
+}
+
+func (s *service) DeletePaymentMethod(ctx context.Context, userID, id int) error {
+	return s.repo.DeletePaymentMethod(ctx, userID, id)
+}
+
+// SetAutoTopUp turns on auto top-up of one of the member's wallets from one
+// of their saved payment methods.
+func (s *service) SetAutoTopUp(ctx context.Context, userID int, req AutoTopUpRequest) (*AutoTopUp, error) {
+	if s.provider == nil {
+		return nil, ErrProviderUnavailable
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrAutoTopUpInvalid, req.Currency)
+	}
+	switch {
+	case req.ThresholdCents < 0:
+		return nil, fmt.Errorf("%w: threshold_cents must not be negative", ErrAutoTopUpInvalid)
+	case req.AmountCents <= 0:
+		return nil, fmt.Errorf("%w: amount_cents must be positive", ErrAutoTopUpInvalid)
+	case req.MonthlyCapCents < req.AmountCents:
+		return nil, fmt.Errorf("%w: monthly_cap_cents must be at least amount_cents", ErrAutoTopUpInvalid)
+	}
+
+	m, err := s.repo.GetPaymentMethod(ctx, req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if m.UserID != userID {
+		return nil, ErrPaymentMethodNotFound
+	}
+	if m.Provider != s.provider.Name() {
+		return nil, ErrProviderUnavailable
+	}
+
+	a, err := s.repo.SaveAutoTopUp(ctx, AutoTopUp{
+		UserID:          userID,
+		Currency:        currency,
+		ThresholdCents:  req.ThresholdCents,
+		AmountCents:     req.AmountCents,
+		MonthlyCapCents: req.MonthlyCapCents,
+		PaymentMethodID: m.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("User %d turned on auto top-up of their %s wallet: %d below %d, at most %d a month",
+		userID, currency, a.AmountCents, a.ThresholdCents, a.MonthlyCapCents)
+	return a, nil
+}
+
+func (s *service) ListAutoTopUps(ctx context.Context, userID int) ([]AutoTopUp, error) {
+	return s.repo.ListAutoTopUps(ctx, userID)
+}
+
+func (s *service) DeleteAutoTopUp(ctx context.Context, userID int, currency string) error {
+	currency, err := money.Normalize(currency)
+	if err != nil {
+		return ErrAutoTopUpNotFound
+	}
+	return s.repo.DeleteAutoTopUp(ctx, userID, currency)
+}
+
+// CollectAutoTopUp charges a pending auto top-up payment, started by a
+// wallet charge, to the member's saved payment method.
+func (s *service) CollectAutoTopUp(ctx context.Context, paymentID int) (*Payment, error) {
+	p, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.AutoTopUp || p.PaymentMethodID == nil {
+		return nil, ErrPaymentNotFound
+	}
+	if p.Status != StatusPending {
+		return p, nil
+	}
+	// The method was saved with a provider that is no longer configured. This
+	// is not the member's decline, so it does not count towards turning auto
+	// top-up off.
+	if s.provider == nil || p.Provider != s.provider.Name() {
+		if _, err := s.repo.MarkFailed(ctx, p.ID, ErrProviderUnavailable.Error()); err != nil {
+			return nil, err
+		}
+		return nil, ErrProviderUnavailable
+	}
+
+	m, err := s.repo.GetPaymentMethod(ctx, *p.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.charge(ctx, p, m.ProviderMethodID)
+}
+
+// autoTopUpSettled counts a settled auto top-up payment and tells the member
+// about it.
+func (s *service) autoTopUpSettled(ctx context.Context, p *Payment, succeeded bool, reason string) {
+	outcome, err := s.repo.RecordAutoTopUpResult(ctx, p.ID, succeeded, reason)
+	if err != nil {
+		logger.Errorf("Failed to record the result of auto top-up payment %d: %v", p.ID, err)
+		return
+	}
+
+	amount := fmt.Sprintf("%d %s", p.AmountCents, p.Currency)
+	if c, err := money.Lookup(p.Currency); err == nil {
+		amount = money.Format(p.AmountCents, c) + " " + c.Code
+	}
+
+	if succeeded {
+		metrics.RecordWalletAutoTopUp("succeeded")
+	} else {
+		metrics.RecordWalletAutoTopUp("declined")
+	}
+	if outcome == nil {
+		return
+	}
+
+	if succeeded {
+		err = s.mailer.SendAutoTopUp(ctx, outcome.Email, outcome.Name, amount)
+	} else {
+		if !outcome.Enabled {
+			metrics.RecordWalletAutoTopUp("disabled")
+			logger.Infof("Auto top-up of user %d's %s wallet turned off after %d declines", outcome.UserID, outcome.Currency, outcome.ConsecutiveFailures)
+		}
+		err = s.mailer.SendAutoTopUpDeclined(ctx, outcome.Email, outcome.Name, amount, reason, !outcome.Enabled)
+	}
+	if err != nil {
+		logger.Errorf("Failed to email user %d about auto top-up payment %d: %v", outcome.UserID, p.ID, err)
+	}
+}
+
+// autoTopUpWallet collects the auto top-ups that wallet charges start.
+type autoTopUpWallet struct {
+	wallet.Repository
+	service Service
+}
+
+// WithAutoTopUp returns wallets with charges that collect the auto top-ups
+// they start from the member's saved payment method. A charge the balance
+// does not cover is retried once its top-up has succeeded.
+func WithAutoTopUp(wallets wallet.Repository, service Service) wallet.Repository {
+	return &autoTopUpWallet{Repository: wallets, service: service}
+}
+
+func (w *autoTopUpWallet) Charge(ctx context.Context, c wallet.Charge) (*wallet.Transaction, error) {
+	txn, err := w.Repository.Charge(ctx, c)
+
+	var required *wallet.AutoTopUpRequired
+	if errors.As(err, &required) {
+		if !w.collect(ctx, required.PaymentID) {
+			return nil, err
+		}
+		txn, err = w.Repository.Charge(ctx, c)
+		if errors.As(err, &required) {
+			// Spent by another charge in the meantime.
+			w.collect(ctx, required.PaymentID)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if txn.AutoTopUpPaymentID == nil {
+			txn.AutoTopUpPaymentID = &required.PaymentID
+			return txn, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if txn.AutoTopUpPaymentID != nil {
+		w.collect(ctx, *txn.AutoTopUpPaymentID)
+	}
+	return txn, nil
+}
+
+// collect charges an auto top-up and reports whether it succeeded.
+func (w *autoTopUpWallet) collect(ctx context.Context, paymentID int) bool {
+	p, err := w.service.CollectAutoTopUp(ctx, paymentID)
+	if err != nil {
+		logger.Errorf("Failed to collect auto top-up payment %d: %v", paymentID, err)
+		return false
+	}
+	return p.Status == StatusSucceeded
+}
@@ -0,0 +1,215 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"fitslot/internal/wallet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SetAutoTopUp(t *testing.T) {
+	ctx := context.Background()
+	method := &PaymentMethod{ID: 4, UserID: 1, Provider: FakeProviderName}
+
+	tests := []struct {
+		name        string
+		req         AutoTopUpRequest
+		setupMocks  func(*MockRepository)
+		expectedErr error
+	}{
+		{
+			name: "Saved for the wallet's currency",
+			req:  AutoTopUpRequest{Currency: "usd", ThresholdCents: 1000, AmountCents: 5000, MonthlyCapCents: 20000, PaymentMethodID: 4},
+			setupMocks: func(r *MockRepository) {
+				r.On("GetPaymentMethod", ctx, 4).Return(method, nil)
+				r.On("SaveAutoTopUp", ctx, AutoTopUp{
+					UserID: 1, Currency: "USD", ThresholdCents: 1000, AmountCents: 5000, MonthlyCapCents: 20000, PaymentMethodID: 4,
+				}).Return(&AutoTopUp{UserID: 1, Currency: "USD", Enabled: true}, nil)
+			},
+		},
+		{
+			name:        "Cap below the amount",
+			req:         AutoTopUpRequest{AmountCents: 5000, MonthlyCapCents: 4000, PaymentMethodID: 4},
+			setupMocks:  func(r *MockRepository) {},
+			expectedErr: ErrAutoTopUpInvalid,
+		},
+		{
+			name:        "Negative threshold",
+			req:         AutoTopUpRequest{ThresholdCents: -1, AmountCents: 5000, MonthlyCapCents: 5000, PaymentMethodID: 4},
+			setupMocks:  func(r *MockRepository) {},
+			expectedErr: ErrAutoTopUpInvalid,
+		},
+		{
+			name:        "Unknown currency",
+			req:         AutoTopUpRequest{Currency: "XYZ", AmountCents: 5000, MonthlyCapCents: 5000, PaymentMethodID: 4},
+			setupMocks:  func(r *MockRepository) {},
+			expectedErr: ErrAutoTopUpInvalid,
+		},
+		{
+			name: "Another member's payment method",
+			req:  AutoTopUpRequest{AmountCents: 5000, MonthlyCapCents: 5000, PaymentMethodID: 5},
+			setupMocks: func(r *MockRepository) {
+				r.On("GetPaymentMethod", ctx, 5).Return(&PaymentMethod{ID: 5, UserID: 2}, nil)
+			},
+			expectedErr: ErrPaymentMethodNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			tt.setupMocks(repo)
+
+			svc := NewService(repo, new(MockWalletRepo), NewFakeProvider("secret"), new(MockMailer))
+			a, err := svc.SetAutoTopUp(ctx, 1, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, a.Enabled)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// autoTopUpMocks prepares pending auto top-up payment 7 of 10,000.00 KZT
+// from a payment method saved as the fake method.
+func autoTopUpMocks(t *testing.T, fakeMethod string) (*MockRepository, *MockWalletRepo, *MockMailer, Service) {
+	ctx := context.Background()
+	provider := NewFakeProvider("secret")
+	saved, err := provider.SavePaymentMethod(ctx, fakeMethod)
+	require.NoError(t, err)
+
+	methodID := 4
+	repo, wallets, mailer := new(MockRepository), new(MockWalletRepo), new(MockMailer)
+	repo.On("GetByID", ctx, 7).Return(&Payment{
+		ID: 7, UserID: 1, AmountCents: 1000000, Currency: "KZT", Status: StatusPending,
+		Provider: FakeProviderName, PaymentMethodID: &methodID, AutoTopUp: true,
+	}, nil).Once()
+	repo.On("GetPaymentMethod", ctx, 4).Return(&PaymentMethod{ID: 4, UserID: 1, ProviderMethodID: saved.ID}, nil)
+	repo.On("SetProviderPaymentID", ctx, 7, mock.AnythingOfType("string")).Return(nil)
+
+	return repo, wallets, mailer, NewService(repo, wallets, provider, mailer)
+}
+
+func TestService_CollectAutoTopUp_Succeeded(t *testing.T) {
+	ctx := context.Background()
+	repo, wallets, mailer, svc := autoTopUpMocks(t, FakeMethodSuccess)
+
+	wallets.On("CreditTopUp", ctx, 7).Return(true, nil)
+	repo.On("RecordAutoTopUpResult", ctx, 7, true, "").
+		Return(&AutoTopUpOutcome{AutoTopUp: AutoTopUp{UserID: 1, Enabled: true}, Email: "anna@example.com", Name: "Anna"}, nil)
+	mailer.On("SendAutoTopUp", ctx, "anna@example.com", "Anna", "10000.00 KZT").Return(nil)
+	repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusSucceeded, AutoTopUp: true}, nil).Once()
+
+	p, err := svc.CollectAutoTopUp(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, p.Status)
+	repo.AssertExpectations(t)
+	wallets.AssertExpectations(t)
+	mailer.AssertExpectations(t)
+}
+
+func TestService_CollectAutoTopUp_DeclinedTurnsOff(t *testing.T) {
+	ctx := context.Background()
+	repo, wallets, mailer, svc := autoTopUpMocks(t, FakeMethodFailure)
+
+	reason := "3 auto top-ups in a row were declined, the last with: card_declined"
+	repo.On("MarkFailed", ctx, 7, "card_declined").Return(true, nil)
+	repo.On("RecordAutoTopUpResult", ctx, 7, false, "card_declined").Return(&AutoTopUpOutcome{
+		AutoTopUp: AutoTopUp{UserID: 1, Currency: "KZT", ConsecutiveFailures: 3, DisabledReason: &reason},
+		Email:     "anna@example.com",
+		Name:      "Anna",
+	}, nil)
+	mailer.On("SendAutoTopUpDeclined", ctx, "anna@example.com", "Anna", "10000.00 KZT", "card_declined", true).Return(nil)
+	repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusFailed, AutoTopUp: true}, nil).Once()
+
+	p, err := svc.CollectAutoTopUp(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, p.Status)
+	repo.AssertExpectations(t)
+	wallets.AssertNotCalled(t, "CreditTopUp", mock.Anything, mock.Anything)
+	mailer.AssertExpectations(t)
+}
+
+func TestService_CollectAutoTopUp_NoProvider(t *testing.T) {
+	ctx := context.Background()
+	methodID := 4
+	repo, mailer := new(MockRepository), new(MockMailer)
+	repo.On("GetByID", ctx, 7).Return(&Payment{
+		ID: 7, UserID: 1, AmountCents: 1000000, Currency: "KZT", Status: StatusPending,
+		Provider: FakeProviderName, PaymentMethodID: &methodID, AutoTopUp: true,
+	}, nil)
+	repo.On("MarkFailed", ctx, 7, ErrProviderUnavailable.Error()).Return(true, nil)
+
+	svc := NewService(repo, new(MockWalletRepo), nil, mailer)
+	_, err := svc.CollectAutoTopUp(ctx, 7)
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "RecordAutoTopUpResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mailer.AssertExpectations(t)
+}
+
+func TestService_CollectAutoTopUp_NotAutoTopUp(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusPending}, nil)
+
+	svc := NewService(repo, new(MockWalletRepo), NewFakeProvider("secret"), new(MockMailer))
+	_, err := svc.CollectAutoTopUp(ctx, 7)
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestWithAutoTopUp_Charge(t *testing.T) {
+	ctx := context.Background()
+	charge := wallet.Charge{UserID: 1, Kind: wallet.EntryBookingCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT"}
+
+	t.Run("retries once the top-up covers the charge", func(t *testing.T) {
+		repo, wallets, mailer, svc := autoTopUpMocks(t, FakeMethodSuccess)
+		wallets.On("CreditTopUp", ctx, 7).Return(true, nil)
+		repo.On("RecordAutoTopUpResult", ctx, 7, true, "").Return(nil, nil)
+		repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusSucceeded, AutoTopUp: true}, nil).Once()
+		wallets.On("Charge", ctx, charge).Return(nil, &wallet.AutoTopUpRequired{PaymentID: 7}).Once()
+		wallets.On("Charge", ctx, charge).Return(&wallet.Transaction{ID: 30, AmountCents: -150000}, nil).Once()
+
+		txn, err := WithAutoTopUp(wallets, svc).Charge(ctx, charge)
+		require.NoError(t, err)
+		assert.Equal(t, 30, txn.ID)
+		assert.Equal(t, 7, *txn.AutoTopUpPaymentID)
+		wallets.AssertExpectations(t)
+		mailer.AssertNotCalled(t, "SendAutoTopUp", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("declined top-up leaves the charge unpaid", func(t *testing.T) {
+		repo, wallets, mailer, svc := autoTopUpMocks(t, FakeMethodFailure)
+		repo.On("MarkFailed", ctx, 7, "card_declined").Return(true, nil)
+		repo.On("RecordAutoTopUpResult", ctx, 7, false, "card_declined").Return(nil, nil)
+		repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusFailed, AutoTopUp: true}, nil).Once()
+		wallets.On("Charge", ctx, charge).Return(nil, &wallet.AutoTopUpRequired{PaymentID: 7}).Once()
+
+		_, err := WithAutoTopUp(wallets, svc).Charge(ctx, charge)
+		assert.ErrorIs(t, err, wallet.ErrInsufficientBalance)
+		wallets.AssertNumberOfCalls(t, "Charge", 1)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("collects a top-up started by a covered charge", func(t *testing.T) {
+		repo, wallets, _, svc := autoTopUpMocks(t, FakeMethodSuccess)
+		wallets.On("CreditTopUp", ctx, 7).Return(true, nil)
+		repo.On("RecordAutoTopUpResult", ctx, 7, true, "").Return(nil, nil)
+		repo.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusSucceeded, AutoTopUp: true}, nil).Once()
+		paymentID := 7
+		wallets.On("Charge", ctx, charge).Return(&wallet.Transaction{ID: 31, AutoTopUpPaymentID: &paymentID}, nil).Once()
+
+		txn, err := WithAutoTopUp(wallets, svc).Charge(ctx, charge)
+		require.NoError(t, err)
+		assert.Equal(t, 31, txn.ID)
+		wallets.AssertExpectations(t)
+	})
+}
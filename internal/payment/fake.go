@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FakeProviderName    = "fake"
+	FakeSignatureHeader = "X-Fake-Signature"
+)
+
+// Payment methods of the fake provider and the outcome each simulates.
+const (
+	FakeMethodSuccess = "fake_success"
+	FakeMethodFailure = "fake_failure"
+	FakeMethodPending = "fake_pending"
+)
+
+// FakeProvider simulates a payment provider without any network access, for
+// development and tests. The payment method decides the outcome of a
+// capture: fake_success succeeds, fake_failure is declined and fake_pending
+// stays pending until a webhook, signed with the provider's secret, settles
+// it. Saved payment methods behave like the method they were saved from.
+// Intents and saved methods are kept in memory only, and no money moves, so
+// the fake provider is never used in production.
+type FakeProvider struct {
+	secret string
+	now    func() time.Time
+
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+	methods map[string]string
+}
+
+type fakeIntent struct {
+	Intent
+	amountCents int64
+	method      string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:  webhookSecret,
+		now:     time.Now,
+		intents: make(map[string]*fakeIntent),
+		methods: make(map[string]string),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (f *FakeProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("fake provider: amount must be positive")
+	}
+
+	method := req.PaymentMethod
+	if method == "" {
+		method = FakeMethodSuccess
+	}
+	if strings.HasPrefix(method, fakeSavedMethodPrefix) {
+		f.mu.Lock()
+		saved, ok := f.methods[method]
+		f.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown saved method %q", ErrPaymentMethodInvalid, method)
+		}
+		method = saved
+	}
+	if err := checkFakeMethod(method); err != nil {
+		return nil, err
+	}
+
+	id, err := fakeID("fake_")
+	if err != nil {
+		return nil, err
+	}
+
+	intent := &fakeIntent{
+		Intent:      Intent{ID: id, Status: StatusPending},
+		amountCents: req.AmountCents,
+		method:      method,
+	}
+
+	f.mu.Lock()
+	f.intents[intent.ID] = intent
+	f.mu.Unlock()
+
+	result := intent.Intent
+	return &result, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return nil, fmt.Errorf("fake provider: unknown intent %q", intentID)
+	}
+
+	if intent.Status == StatusPending {
+		switch intent.method {
+		case FakeMethodSuccess:
+			intent.Status = StatusSucceeded
+		case FakeMethodFailure:
+			intent.Status = StatusFailed
+			intent.FailureReason = "card_declined"
+		}
+	}
+
+	result := intent.Intent
+	return &result, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, intentID string, amountCents int64) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return nil, fmt.Errorf("fake provider: unknown intent %q", intentID)
+	}
+	if intent.Status != StatusSucceeded {
+		return nil, fmt.Errorf("fake provider: intent %q is %s, not succeeded", intentID, intent.Status)
+	}
+	if amountCents <= 0 || amountCents > intent.amountCents {
+		return nil, fmt.Errorf("fake provider: refund must be between 1 and %d", intent.amountCents)
+	}
+
+	intent.Status = StatusRefunded
+	result := intent.Intent
+	return &result, nil
+}
+
+const fakeSavedMethodPrefix = "fake_pm_"
+
+// SavePaymentMethod saves one of the fake payment methods under an ID of
+// the form fake_pm_<hex>.
+func (f *FakeProvider) SavePaymentMethod(ctx context.Context, method string) (*SavedMethod, error) {
+	if err := checkFakeMethod(method); err != nil {
+		return nil, err
+	}
+
+	id, err := fakeID(fakeSavedMethodPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.methods[id] = method
+	f.mu.Unlock()
+
+	return &SavedMethod{ID: id, Label: "Fake card (" + method + ")"}, nil
+}
+
+func checkFakeMethod(method string) error {
+	switch method {
+	case FakeMethodSuccess, FakeMethodFailure, FakeMethodPending:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrPaymentMethodInvalid, method)
+}
+
+func fakeID(prefix string) (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(id), nil
+}
+
+// VerifyWebhook expects the event as JSON, signed with SignWebhook in the
+// X-Fake-Signature header.
+func (f *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	if err := verifySignature(f.secret, payload, header.Get(FakeSignatureHeader), f.now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if event.IntentID == "" {
+		return nil, fmt.Errorf("%w: intent_id is required", ErrInvalidEvent)
+	}
+	switch event.Status {
+	case StatusPending, StatusSucceeded, StatusFailed, StatusRefunded:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidEvent, event.Status)
+	}
+
+	return &event, nil
+}
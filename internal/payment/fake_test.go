@@ -0,0 +1,132 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeProvider_Capture(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		method   string
+		expected Status
+		reason   string
+	}{
+		{method: "", expected: StatusSucceeded},
+		{method: FakeMethodSuccess, expected: StatusSucceeded},
+		{method: FakeMethodFailure, expected: StatusFailed, reason: "card_declined"},
+		{method: FakeMethodPending, expected: StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			f := NewFakeProvider("secret")
+
+			intent, err := f.CreateIntent(ctx, IntentRequest{AmountCents: 1000, Currency: "KZT", PaymentMethod: tt.method})
+			require.NoError(t, err)
+			assert.Equal(t, StatusPending, intent.Status)
+
+			captured, err := f.Capture(ctx, intent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, intent.ID, captured.ID)
+			assert.Equal(t, tt.expected, captured.Status)
+			assert.Equal(t, tt.reason, captured.FailureReason)
+		})
+	}
+}
+
+func TestFakeProvider_InvalidMethod(t *testing.T) {
+	f := NewFakeProvider("secret")
+
+	_, err := f.CreateIntent(context.Background(), IntentRequest{AmountCents: 1000, PaymentMethod: "visa"})
+	assert.ErrorIs(t, err, ErrPaymentMethodInvalid)
+}
+
+func TestFakeProvider_SavedMethod(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeProvider("secret")
+
+	saved, err := f.SavePaymentMethod(ctx, FakeMethodFailure)
+	require.NoError(t, err)
+	assert.Contains(t, saved.ID, "fake_pm_")
+	assert.Equal(t, "Fake card (fake_failure)", saved.Label)
+
+	intent, err := f.CreateIntent(ctx, IntentRequest{AmountCents: 1000, Currency: "KZT", PaymentMethod: saved.ID})
+	require.NoError(t, err)
+	captured, err := f.Capture(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, captured.Status)
+
+	_, err = f.CreateIntent(ctx, IntentRequest{AmountCents: 1000, PaymentMethod: "fake_pm_unknown"})
+	assert.ErrorIs(t, err, ErrPaymentMethodInvalid)
+
+	_, err = f.SavePaymentMethod(ctx, "visa")
+	assert.ErrorIs(t, err, ErrPaymentMethodInvalid)
+}
+
+func TestFakeProvider_Refund(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeProvider("secret")
+
+	intent, err := f.CreateIntent(ctx, IntentRequest{AmountCents: 1000})
+	require.NoError(t, err)
+
+	_, err = f.Refund(ctx, intent.ID, 1000)
+	assert.Error(t, err, "a pending intent cannot be refunded")
+
+	_, err = f.Capture(ctx, intent.ID)
+	require.NoError(t, err)
+
+	_, err = f.Refund(ctx, intent.ID, 1500)
+	assert.Error(t, err)
+
+	refunded, err := f.Refund(ctx, intent.ID, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+}
+
+func TestFakeProvider_VerifyWebhook(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"id":"evt_1","intent_id":"fake_abc","status":"succeeded"}`)
+
+	f := NewFakeProvider("secret")
+	f.now = func() time.Time { return now }
+
+	header := func(signature string) http.Header {
+		h := http.Header{}
+		h.Set(FakeSignatureHeader, signature)
+		return h
+	}
+
+	event, err := f.VerifyWebhook(payload, header(SignWebhook("secret", payload, now.Add(-time.Minute))))
+	require.NoError(t, err)
+	assert.Equal(t, "fake_abc", event.IntentID)
+	assert.Equal(t, StatusSucceeded, event.Status)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		expected  error
+	}{
+		{"missing signature", payload, "", ErrInvalidSignature},
+		{"wrong secret", payload, SignWebhook("other", payload, now), ErrInvalidSignature},
+		{"tampered payload", []byte(`{"id":"evt_1","intent_id":"fake_xyz","status":"succeeded"}`), SignWebhook("secret", payload, now), ErrInvalidSignature},
+		{"replayed", payload, SignWebhook("secret", payload, now.Add(-time.Hour)), ErrInvalidSignature},
+		{"unknown status", []byte(`{"intent_id":"fake_abc","status":"done"}`), SignWebhook("secret", []byte(`{"intent_id":"fake_abc","status":"done"}`), now), ErrInvalidEvent},
+		{"not json", []byte(`status=succeeded`), SignWebhook("secret", []byte(`status=succeeded`), now), ErrInvalidEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.VerifyWebhook(tt.payload, header(tt.signature))
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
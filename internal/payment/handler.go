@@ -0,0 +1,330 @@
+package payment
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"fitslot/internal/api"
+	"fitslot/internal/auth"
+	"fitslot/internal/logger"
+	"fitslot/internal/wallet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBytes bounds the body of a webhook request.
+const maxWebhookBytes = 64 << 10
+
+type Handler struct {
+	service Service
+	wallets wallet.Repository
+}
+
+func NewHandler(service Service, wallets wallet.Repository) *Handler {
+	return &Handler{
+		service: service,
+		wallets: wallets,
+	}
+}
+
+// @Summary      Top up wallet
+// @Description  Charges the amount through the payment provider. The wallet in the payment's currency (KZT by default) is credited once the payment succeeds: right away (200), or later through the provider's webhook when the payment is still pending (202). Answers 503 when no payment provider is configured.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body payment.TopUpRequest true "Top up payload"
+// @Success      200 {object} payment.TopUpResponse
+// @Success      202 {object} payment.TopUpResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      402 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Failure      503 {object} api.ErrorResponse
+// @Router       /wallet/topup [post]
+func (h *Handler) TopUp(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	var req TopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "amount_cents must be positive"})
+		return
+	}
+
+	p, err := h.service.TopUp(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAmountInvalid), errors.Is(err, ErrCurrencyInvalid), errors.Is(err, ErrPaymentMethodInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrProviderUnavailable):
+			c.JSON(http.StatusServiceUnavailable, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to top up wallet"})
+		}
+		return
+	}
+
+	switch p.Status {
+	case StatusSucceeded:
+		w, err := h.wallets.GetOrCreateWallet(c.Request.Context(), userID, p.Currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load wallet after top up"})
+			return
+		}
+		c.JSON(http.StatusOK, TopUpResponse{Message: "wallet recharged", Payment: *p, Wallet: w})
+	case StatusPending:
+		c.JSON(http.StatusAccepted, TopUpResponse{Message: "payment pending", Payment: *p})
+	default:
+		reason := "payment failed"
+		if p.FailureReason != nil {
+			reason += ": " + *p.FailureReason
+		}
+		c.JSON(http.StatusPaymentRequired, api.ErrorResponse{Error: reason})
+	}
+}
+
+// @Summary      Payment provider webhook
+// @Description  Receives payment status changes from the provider. The request must be signed with the provider's webhook secret; for the fake provider, the X-Fake-Signature header is "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">".
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Param        provider path string true "Provider name" example(fake)
+// @Param        event body payment.Event true "Event"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /payments/webhook/{provider} [post]
+func (h *Handler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "failed to read request body"})
+		return
+	}
+
+	err = h.service.HandleWebhook(c.Request.Context(), c.Param("provider"), payload, c.Request.Header)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Unknown payment provider"})
+		case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrInvalidEvent):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Payment not found"})
+		default:
+			logger.Errorf("Failed to handle %s webhook: %v", c.Param("provider"), err)
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to handle webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "ok"})
+}
+
+// @Summary      Save a payment method
+// @Description  Saves a payment method with the payment provider so that auto top-ups can be charged to it. The fake provider takes fake_success, fake_failure and fake_pending.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body payment.SavePaymentMethodRequest true "Payment method"
+// @Success      201 {object} payment.PaymentMethod
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Failure      503 {object} api.ErrorResponse
+// @Router       /wallet/payment-methods [post]
+func (h *Handler) SavePaymentMethod(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	var req SavePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	m, err := h.service.SavePaymentMethod(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentMethodInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrProviderUnavailable):
+			c.JSON(http.StatusServiceUnavailable, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to save payment method"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, m)
+}
+
+// @Summary      List my payment methods
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} payment.PaymentMethod
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/payment-methods [get]
+func (h *Handler) ListPaymentMethods(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	methods, err := h.service.ListPaymentMethods(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load payment methods"})
+		return
+	}
+
+	c.JSON(http.StatusOK, methods)
+}
+
+// @Summary      Delete a payment method
+// @Description  Deletes the saved payment method and the auto top-ups that use it.
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Param        methodID path int true "Payment method ID"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/payment-methods/{methodID} [delete]
+func (h *Handler) DeletePaymentMethod(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("methodID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "invalid payment method ID"})
+		return
+	}
+
+	if err := h.service.DeletePaymentMethod(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, ErrPaymentMethodNotFound) {
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Payment method not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to delete payment method"})
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "payment method deleted"})
+}
+
+// @Summary      Turn on auto top-up
+// @Description  Tops up the wallet in the currency (KZT by default) by amount_cents from a saved payment method whenever a booking or subscription charge would leave its balance below threshold_cents, at most monthly_cap_cents per calendar month (UTC). A charge the balance does not cover waits for the top-up. Replaces the wallet's previous auto top-up and turns it back on if declines had turned it off; it is turned off after 3 declines in a row.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body payment.AutoTopUpRequest true "Auto top-up"
+// @Success      200 {object} payment.AutoTopUp
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Failure      503 {object} api.ErrorResponse
+// @Router       /wallet/auto-topup [put]
+func (h *Handler) SetAutoTopUp(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	var req AutoTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	a, err := h.service.SetAutoTopUp(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAutoTopUpInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrPaymentMethodNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Payment method not found"})
+		case errors.Is(err, ErrProviderUnavailable):
+			c.JSON(http.StatusServiceUnavailable, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to save auto top-up"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, a)
+}
+
+// @Summary      List my auto top-ups
+// @Description  Auto top-ups of the member's wallets, one per currency, with the declines in a row and, when declines turned it off, why.
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} payment.AutoTopUp
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/auto-topup [get]
+func (h *Handler) ListAutoTopUps(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	topUps, err := h.service.ListAutoTopUps(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load auto top-ups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, topUps)
+}
+
+// @Summary      Turn off auto top-up
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Param        currency path string true "Currency code" example(KZT)
+// @Success      200 {object} api.MessageResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/auto-topup/{currency} [delete]
+func (h *Handler) DeleteAutoTopUp(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	if err := h.service.DeleteAutoTopUp(c.Request.Context(), userID, c.Param("currency")); err != nil {
+		if errors.Is(err, ErrAutoTopUpNotFound) {
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Auto top-up not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to turn off auto top-up"})
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "auto top-up turned off"})
+}
@@ -0,0 +1,111 @@
+package payment
+
+import (
+	"time"
+
+	"fitslot/internal/wallet"
+)
+
+// Status is where a payment stands. A payment starts pending and is settled
+// by the provider as succeeded or failed; a succeeded payment can later be
+// refunded.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRefunded  Status = "refunded"
+)
+
+// Payment is money collected through a payment provider. Payments without a
+// booking or subscription are wallet top-ups.
+type Payment struct {
+	ID                int       `db:"id" json:"id"`
+	UserID            int       `db:"user_id" json:"user_id"`
+	BookingID         *int      `db:"booking_id" json:"booking_id,omitempty"`
+	SubscriptionID    *int      `db:"subscription_id" json:"subscription_id,omitempty"`
+	AmountCents       int64     `db:"amount_cents" json:"amount_cents"`
+	Currency          string    `db:"currency" json:"currency" example:"KZT"`
+	Status            Status    `db:"status" json:"status" example:"succeeded"`
+	Provider          string    `db:"provider" json:"provider" example:"fake"`
+	ProviderPaymentID *string   `db:"provider_payment_id" json:"provider_payment_id,omitempty"`
+	FailureReason     *string   `db:"failure_reason" json:"failure_reason,omitempty"`
+	PaymentMethodID   *int      `db:"payment_method_id" json:"payment_method_id,omitempty"`
+	AutoTopUp         bool      `db:"auto_topup" json:"auto_topup"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// TopUpRequest tops up the wallet in the currency, KZT when empty.
+type TopUpRequest struct {
+	AmountCents int64  `json:"amount_cents" binding:"required" example:"10000"`
+	Currency    string `json:"currency" example:"KZT"`
+	// PaymentMethod is passed on to the provider. The fake provider takes
+	// fake_success (the default), fake_failure and fake_pending.
+	PaymentMethod string `json:"payment_method" example:"fake_success"`
+}
+
+// TopUpResponse carries the wallet once the payment has succeeded.
+type TopUpResponse struct {
+	Message string         `json:"message" example:"wallet recharged"`
+	Payment Payment        `json:"payment"`
+	Wallet  *wallet.Wallet `json:"wallet,omitempty"`
+}
+
+// PaymentMethod is a card the member saved with the provider, so that it can
+// be charged without them, for auto top-ups.
+type PaymentMethod struct {
+	ID               int       `db:"id" json:"id"`
+	UserID           int       `db:"user_id" json:"user_id"`
+	Provider         string    `db:"provider" json:"provider" example:"fake"`
+	ProviderMethodID string    `db:"provider_method_id" json:"-"`
+	Label            string    `db:"label" json:"label" example:"Fake card (fake_success)"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+// SavePaymentMethodRequest saves a payment method with the provider. The
+// fake provider takes fake_success, fake_failure and fake_pending.
+type SavePaymentMethodRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required" example:"fake_success"`
+}
+
+// MaxAutoTopUpFailures is how many auto top-ups in a row may be declined
+// before auto top-up is turned off.
+const MaxAutoTopUpFailures = 3
+
+// AutoTopUp tops up the member's wallet in Currency by AmountCents from a
+// saved payment method when a charge would leave the balance below
+// ThresholdCents, up to MonthlyCapCents per calendar month (UTC).
+type AutoTopUp struct {
+	UserID              int       `db:"user_id" json:"user_id"`
+	Currency            string    `db:"currency" json:"currency" example:"KZT"`
+	ThresholdCents      int64     `db:"threshold_cents" json:"threshold_cents" example:"200000"`
+	AmountCents         int64     `db:"amount_cents" json:"amount_cents" example:"1000000"`
+	MonthlyCapCents     int64     `db:"monthly_cap_cents" json:"monthly_cap_cents" example:"3000000"`
+	PaymentMethodID     int       `db:"payment_method_id" json:"payment_method_id"`
+	Enabled             bool      `db:"enabled" json:"enabled"`
+	ConsecutiveFailures int       `db:"consecutive_failures" json:"consecutive_failures"`
+	DisabledReason      *string   `db:"disabled_reason" json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// AutoTopUpRequest turns on auto top-up of the wallet in the currency (KZT
+// when empty), replacing its previous settings. A threshold of zero tops up
+// only when a charge would otherwise be declined.
+type AutoTopUpRequest struct {
+	Currency        string `json:"currency" example:"KZT"`
+	ThresholdCents  int64  `json:"threshold_cents" example:"200000"`
+	AmountCents     int64  `json:"amount_cents" binding:"required" example:"1000000"`
+	MonthlyCapCents int64  `json:"monthly_cap_cents" binding:"required" example:"3000000"`
+	PaymentMethodID int    `json:"payment_method_id" binding:"required" example:"1"`
+}
+
+// AutoTopUpOutcome is the member's auto top-up after one of its payments
+// settled, with whom to notify.
+type AutoTopUpOutcome struct {
+	AutoTopUp
+	Email string `db:"email"`
+	Name  string `db:"name"`
+}
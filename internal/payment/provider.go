@@ -0,0 +1,126 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how old a webhook signature may be. Older requests
+// are rejected so that a captured request cannot be replayed later.
+const SignatureTolerance = 5 * time.Minute
+
+var (
+	ErrPaymentMethodInvalid = errors.New("unsupported payment method")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrInvalidEvent         = errors.New("invalid webhook event")
+)
+
+// Provider is an external payment provider. Amounts are in the smallest
+// unit of the currency.
+type Provider interface {
+	// Name identifies the provider in payments and in its webhook URL.
+	Name() string
+	// CreateIntent registers a payment with the provider before any money
+	// moves.
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture collects the money of an intent. The outcome may still be
+	// pending, in which case a webhook reports it later.
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	Refund(ctx context.Context, intentID string, amountCents int64) (*Intent, error)
+	// SavePaymentMethod stores a payment method with the provider so that
+	// later intents can be charged to it without the member.
+	SavePaymentMethod(ctx context.Context, method string) (*SavedMethod, error)
+	// VerifyWebhook checks the signature of a webhook request and decodes
+	// the event it carries.
+	VerifyWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+type IntentRequest struct {
+	AmountCents int64
+	Currency    string
+	// PaymentMethod is a method the member enters now or the ID of a
+	// saved one.
+	PaymentMethod string
+	// Reference is our ID of the payment, stored with the intent.
+	Reference string
+}
+
+// Intent is a payment as the provider sees it.
+type Intent struct {
+	ID            string
+	Status        Status
+	FailureReason string
+}
+
+// SavedMethod is a payment method stored with the provider.
+type SavedMethod struct {
+	ID string
+	// Label describes the method to the member, e.g. "Visa •••• 4242".
+	Label string
+}
+
+// Event is a webhook notification about a change of an intent's status.
+type Event struct {
+	ID            string `json:"id"`
+	IntentID      string `json:"intent_id"`
+	Status        Status `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// SignWebhook returns the signature of a webhook payload sent at the given
+// time, in the form "t=<unix time>,v1=<hex HMAC-SHA256>". The MAC covers the
+// timestamp and the payload, so neither can be changed without the secret.
+func SignWebhook(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(webhookMAC(secret, timestamp, payload))
+}
+
+// verifySignature checks a signature made by SignWebhook.
+func verifySignature(secret string, payload []byte, signature string, now time.Time) error {
+	var timestamp string
+	var macs [][]byte
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if mac, err := hex.DecodeString(value); err == nil {
+				macs = append(macs, mac)
+			}
+		}
+	}
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(macs) == 0 {
+		return fmt.Errorf("%w: malformed signature header", ErrInvalidSignature)
+	}
+	age := now.Sub(time.Unix(sent, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+
+	expected := webhookMAC(secret, timestamp, payload)
+	for _, mac := range macs {
+		if hmac.Equal(mac, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func webhookMAC(secret, timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,214 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const paymentColumns = "id, user_id, booking_id, subscription_id, amount_cents, currency, status, provider, provider_payment_id, failure_reason, payment_method_id, auto_topup, created_at, updated_at"
+
+const paymentMethodColumns = "id, user_id, provider, provider_method_id, label, created_at"
+
+const autoTopUpColumns = "user_id, currency, threshold_cents, amount_cents, monthly_cap_cents, payment_method_id, enabled, consecutive_failures, disabled_reason, created_at, updated_at"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, p Payment) (*Payment, error) {
+	query := `
+		INSERT INTO payments (user_id, booking_id, subscription_id, amount_cents, currency, status, provider)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + paymentColumns
+
+	var created Payment
+	err := r.db.GetContext(ctx, &created, query,
+		p.UserID, p.BookingID, p.SubscriptionID, p.AmountCents, p.Currency, p.Status, p.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int) (*Payment, error) {
+	var p Payment
+	err := r.db.GetContext(ctx, &p, `SELECT `+paymentColumns+` FROM payments WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*Payment, error) {
+	var p Payment
+	err := r.db.GetContext(ctx, &p,
+		`SELECT `+paymentColumns+` FROM payments WHERE provider = $1 AND provider_payment_id = $2`,
+		provider, providerPaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) SetProviderPaymentID(ctx context.Context, id int, providerPaymentID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE payments SET provider_payment_id = $1, updated_at = NOW() WHERE id = $2`,
+		providerPaymentID, id)
+	return err
+}
+
+// MarkFailed settles a pending payment as failed. It returns false when the
+// payment was no longer pending.
+func (r *repository) MarkFailed(ctx context.Context, id int, reason string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE payments
+		 SET status = 'failed', failure_reason = $1, updated_at = NOW()
+		 WHERE id = $2 AND status = 'pending'`,
+		reason, id)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) CreatePaymentMethod(ctx context.Context, m PaymentMethod) (*PaymentMethod, error) {
+	var created PaymentMethod
+	err := r.db.GetContext(ctx, &created,
+		`INSERT INTO payment_methods (user_id, provider, provider_method_id, label)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+paymentMethodColumns,
+		m.UserID, m.Provider, m.ProviderMethodID, m.Label)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r *repository) GetPaymentMethod(ctx context.Context, id int) (*PaymentMethod, error) {
+	var m PaymentMethod
+	err := r.db.GetContext(ctx, &m, `SELECT `+paymentMethodColumns+` FROM payment_methods WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) ListPaymentMethods(ctx context.Context, userID int) ([]PaymentMethod, error) {
+	methods := []PaymentMethod{}
+	err := r.db.SelectContext(ctx, &methods,
+		`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE user_id = $1 ORDER BY id`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// DeletePaymentMethod removes one of the member's payment methods, and with
+// it the auto top-ups that used it.
+func (r *repository) DeletePaymentMethod(ctx context.Context, userID, id int) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM payment_methods WHERE id = $1 AND user_id = $2`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrPaymentMethodNotFound
+	}
+	return err
+}
+
+// SaveAutoTopUp creates or replaces the auto top-up of the member's wallet
+// in a.Currency, turned on and with no declines counted.
+func (r *repository) SaveAutoTopUp(ctx context.Context, a AutoTopUp) (*AutoTopUp, error) {
+	var saved AutoTopUp
+	err := r.db.GetContext(ctx, &saved,
+		`INSERT INTO auto_topups (user_id, currency, threshold_cents, amount_cents, monthly_cap_cents, payment_method_id)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, currency) DO UPDATE
+		 SET threshold_cents = EXCLUDED.threshold_cents,
+			amount_cents = EXCLUDED.amount_cents,
+			monthly_cap_cents = EXCLUDED.monthly_cap_cents,
+			payment_method_id = EXCLUDED.payment_method_id,
+			enabled = TRUE,
+			consecutive_failures = 0,
+			disabled_reason = NULL,
+			updated_at = NOW()
+		 RETURNING `+autoTopUpColumns,
+		a.UserID, a.Currency, a.ThresholdCents, a.AmountCents, a.MonthlyCapCents, a.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (r *repository) ListAutoTopUps(ctx context.Context, userID int) ([]AutoTopUp, error) {
+	topUps := []AutoTopUp{}
+	err := r.db.SelectContext(ctx, &topUps,
+		`SELECT `+autoTopUpColumns+` FROM auto_topups WHERE user_id = $1 ORDER BY currency`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	return topUps, nil
+}
+
+func (r *repository) DeleteAutoTopUp(ctx context.Context, userID int, currency string) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM auto_topups WHERE user_id = $1 AND currency = $2`,
+		userID, currency)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrAutoTopUpNotFound
+	}
+	return err
+}
+
+// RecordAutoTopUpResult counts a settled auto top-up payment against the
+// auto top-up that made it: a success clears the count of declines, and the
+// MaxAutoTopUpFailures-th decline in a row turns auto top-up off. It returns
+// nil when the auto top-up has since been removed.
+func (r *repository) RecordAutoTopUpResult(ctx context.Context, paymentID int, succeeded bool, reason string) (*AutoTopUpOutcome, error) {
+	var outcome AutoTopUpOutcome
+	err := r.db.GetContext(ctx, &outcome,
+		`UPDATE auto_topups a
+		 SET consecutive_failures = CASE WHEN $2 THEN 0 ELSE a.consecutive_failures + 1 END,
+			enabled = a.enabled AND ($2 OR a.consecutive_failures + 1 < $3),
+			disabled_reason = CASE WHEN $2 OR a.consecutive_failures + 1 < $3 THEN a.disabled_reason ELSE $4 END,
+			updated_at = NOW()
+		 FROM payments p, users u
+		 WHERE p.id = $1 AND p.auto_topup
+			AND a.user_id = p.user_id AND a.currency = p.currency
+			AND u.id = p.user_id
+		 RETURNING a.user_id, a.currency, a.threshold_cents, a.amount_cents, a.monthly_cap_cents, a.payment_method_id,
+			a.enabled, a.consecutive_failures, a.disabled_reason, a.created_at, a.updated_at, u.email, u.name`,
+		paymentID, succeeded, MaxAutoTopUpFailures,
+		fmt.Sprintf("%d auto top-ups in a row were declined, the last with: %s", MaxAutoTopUpFailures, reason))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &outcome, nil
+}
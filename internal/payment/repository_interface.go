@@ -0,0 +1,21 @@
+package payment
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, p Payment) (*Payment, error)
+	GetByID(ctx context.Context, id int) (*Payment, error)
+	GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*Payment, error)
+	SetProviderPaymentID(ctx context.Context, id int, providerPaymentID string) error
+	MarkFailed(ctx context.Context, id int, reason string) (bool, error)
+
+	CreatePaymentMethod(ctx context.Context, m PaymentMethod) (*PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, id int) (*PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, userID int) ([]PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, userID, id int) error
+
+	SaveAutoTopUp(ctx context.Context, a AutoTopUp) (*AutoTopUp, error)
+	ListAutoTopUps(ctx context.Context, userID int) ([]AutoTopUp, error)
+	DeleteAutoTopUp(ctx context.Context, userID int, currency string) error
+	RecordAutoTopUpResult(ctx context.Context, paymentID int, succeeded bool, reason string) (*AutoTopUpOutcome, error)
+}
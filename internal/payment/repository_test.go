@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return NewRepository(sqlxDB), mock, func() { sqlxDB.Close() }
+}
+
+func paymentRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "user_id", "booking_id", "subscription_id", "amount_cents", "currency",
+		"status", "provider", "provider_payment_id", "failure_reason", "created_at", "updated_at"})
+}
+
+func TestCreate(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO payments \(user_id, booking_id, subscription_id, amount_cents, currency, status, provider\)`).
+		WithArgs(3, nil, nil, 5000, "KZT", StatusPending, FakeProviderName).
+		WillReturnRows(paymentRows().AddRow(1, 3, nil, nil, 5000, "KZT", "pending", "fake", nil, nil, time.Now(), time.Now()))
+
+	p, err := repo.Create(context.Background(), Payment{
+		UserID:      3,
+		AmountCents: 5000,
+		Currency:    "KZT",
+		Status:      StatusPending,
+		Provider:    FakeProviderName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.ID)
+	assert.Equal(t, StatusPending, p.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByProviderPaymentID(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM payments WHERE provider = $1 AND provider_payment_id = $2")).
+		WithArgs("fake", "fake_abc").
+		WillReturnRows(paymentRows().AddRow(1, 3, nil, nil, 5000, "KZT", "pending", "fake", "fake_abc", nil, time.Now(), time.Now()))
+
+	p, err := repo.GetByProviderPaymentID(context.Background(), "fake", "fake_abc")
+	require.NoError(t, err)
+	assert.Equal(t, "fake_abc", *p.ProviderPaymentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkFailed(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	query := `UPDATE payments\s+SET status = 'failed', failure_reason = \$1, updated_at = NOW\(\)\s+WHERE id = \$2 AND status = 'pending'`
+	mock.ExpectExec(query).WithArgs("card_declined", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("card_declined", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	failed, err := repo.MarkFailed(context.Background(), 1, "card_declined")
+	require.NoError(t, err)
+	assert.True(t, failed)
+
+	failed, err = repo.MarkFailed(context.Background(), 1, "card_declined")
+	require.NoError(t, err)
+	assert.False(t, failed, "a settled payment stays as it is")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPaymentMethod_NotFound(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM payment_methods WHERE id = $1")).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetPaymentMethod(context.Background(), 9)
+	assert.ErrorIs(t, err, ErrPaymentMethodNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeletePaymentMethod(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	query := regexp.QuoteMeta("DELETE FROM payment_methods WHERE id = $1 AND user_id = $2")
+	mock.ExpectExec(query).WithArgs(4, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(4, 8).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeletePaymentMethod(context.Background(), 3, 4))
+	assert.ErrorIs(t, repo.DeletePaymentMethod(context.Background(), 8, 4), ErrPaymentMethodNotFound, "another member's method")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveAutoTopUp(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO auto_topups .* ON CONFLICT \(user_id, currency\) DO UPDATE.*enabled = TRUE,\s+consecutive_failures = 0`).
+		WithArgs(3, "KZT", 200000, 1000000, 3000000, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "threshold_cents", "amount_cents", "monthly_cap_cents", "payment_method_id", "enabled", "consecutive_failures"}).
+			AddRow(3, "KZT", 200000, 1000000, 3000000, 4, true, 0))
+
+	a, err := repo.SaveAutoTopUp(context.Background(), AutoTopUp{
+		UserID: 3, Currency: "KZT", ThresholdCents: 200000, AmountCents: 1000000, MonthlyCapCents: 3000000, PaymentMethodID: 4,
+	})
+	require.NoError(t, err)
+	assert.True(t, a.Enabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAutoTopUpResult(t *testing.T) {
+	repo, mock, close := setupMock(t)
+	defer close()
+
+	mock.ExpectQuery(`UPDATE auto_topups a\s+SET consecutive_failures = .*FROM payments p, users u\s+WHERE p.id = \$1 AND p.auto_topup`).
+		WithArgs(7, false, MaxAutoTopUpFailures, "3 auto top-ups in a row were declined, the last with: card_declined").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "enabled", "consecutive_failures", "email", "name"}).
+			AddRow(3, "KZT", false, 3, "anna@example.com", "Anna"))
+	mock.ExpectQuery(`UPDATE auto_topups a`).
+		WithArgs(8, true, MaxAutoTopUpFailures, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	outcome, err := repo.RecordAutoTopUpResult(context.Background(), 7, false, "card_declined")
+	require.NoError(t, err)
+	assert.False(t, outcome.Enabled)
+	assert.Equal(t, "anna@example.com", outcome.Email)
+
+	outcome, err = repo.RecordAutoTopUpResult(context.Background(), 8, true, "")
+	require.NoError(t, err)
+	assert.Nil(t, outcome, "the auto top-up was removed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
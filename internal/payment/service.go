@@ -0,0 +1,190 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fitslot/internal/logger"
+	"fitslot/internal/metrics"
+	"fitslot/internal/money"
+	"fitslot/internal/wallet"
+)
+
+var (
+	ErrAmountInvalid   = errors.New("amount must be positive")
+	ErrCurrencyInvalid = errors.New("unsupported currency")
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrProviderUnavailable means no payment provider is configured, so no
+	// money can be collected.
+	ErrProviderUnavailable = errors.New("payments are unavailable")
+
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrAutoTopUpInvalid      = errors.New("invalid auto top-up")
+	ErrAutoTopUpNotFound     = errors.New("auto top-up not found")
+)
+
+type Service interface {
+	TopUp(ctx context.Context, userID int, req TopUpRequest) (*Payment, error)
+	HandleWebhook(ctx context.Context, provider string, payload []byte, header http.Header) error
+
+	SavePaymentMethod(ctx context.Context, userID int, req SavePaymentMethodRequest) (*PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, userID int) ([]PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, userID, id int) error
+
+	SetAutoTopUp(ctx context.Context, userID int, req AutoTopUpRequest) (*AutoTopUp, error)
+	ListAutoTopUps(ctx context.Context, userID int) ([]AutoTopUp, error)
+	DeleteAutoTopUp(ctx context.Context, userID int, currency string) error
+	CollectAutoTopUp(ctx context.Context, paymentID int) (*Payment, error)
+}
+
+// Mailer emails members about their auto top-ups.
+type Mailer interface {
+	SendAutoTopUp(ctx context.Context, email, name, amount string) error
+	SendAutoTopUpDeclined(ctx context.Context, email, name, amount, reason string, disabled bool) error
+}
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Repository
+	provider Provider
+	mailer   Mailer
+}
+
+// NewService returns the payment service. provider is nil when no payment
+// provider is configured; payments are then refused.
+func NewService(repo Repository, wallets wallet.Repository, provider Provider, mailer Mailer) Service {
+	return &service{
+		repo:     repo,
+		wallets:  wallets,
+		provider: provider,
+		mailer:   mailer,
+	}
+}
+
+// TopUp charges the member through the provider and credits the wallet in
+// the payment's currency if the payment succeeds right away. A pending
+// payment is credited when the provider's webhook reports it as succeeded.
+func (s *service) TopUp(ctx context.Context, userID int, req TopUpRequest) (*Payment, error) {
+	if s.provider == nil {
+		return nil, ErrProviderUnavailable
+	}
+	if req.AmountCents <= 0 {
+		return nil, ErrAmountInvalid
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, ErrCurrencyInvalid
+	}
+
+	p, err := s.repo.Create(ctx, Payment{
+		UserID:      userID,
+		AmountCents: req.AmountCents,
+		Currency:    currency,
+		Status:      StatusPending,
+		Provider:    s.provider.Name(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.charge(ctx, p, req.PaymentMethod)
+}
+
+// charge collects a pending payment through the provider from the payment
+// method and settles it.
+func (s *service) charge(ctx context.Context, p *Payment, method string) (*Payment, error) {
+	intent, err := s.provider.CreateIntent(ctx, IntentRequest{
+		AmountCents:   p.AmountCents,
+		Currency:      p.Currency,
+		PaymentMethod: method,
+		Reference:     strconv.Itoa(p.ID),
+	})
+	if err != nil {
+		// No money has moved yet.
+		if settleErr := s.settle(ctx, p, StatusFailed, err.Error()); settleErr != nil {
+			logger.Errorf("Failed to mark payment %d as failed: %v", p.ID, settleErr)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.SetProviderPaymentID(ctx, p.ID, intent.ID); err != nil {
+		return nil, err
+	}
+
+	// A capture that errors may still have gone through at the provider, so
+	// the payment stays pending and is settled by the webhook.
+	intent, err = s.provider.Capture(ctx, intent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.settle(ctx, p, intent.Status, intent.FailureReason); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, p.ID)
+}
+
+// HandleWebhook applies a status change reported by the provider. Reports
+// may arrive more than once and in any order; a payment is settled once.
+func (s *service) HandleWebhook(ctx context.Context, provider string, payload []byte, header http.Header) error {
+	if s.provider == nil || provider != s.provider.Name() {
+		return ErrUnknownProvider
+	}
+
+	event, err := s.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	// The webhook can race the top-up request that stores the intent ID.
+	// Reporting the payment as missing makes the provider retry.
+	p, err := s.repo.GetByProviderPaymentID(ctx, provider, event.IntentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+
+	return s.settle(ctx, p, event.Status, event.FailureReason)
+}
+
+// settle records the outcome of a pending payment, crediting the wallet when
+// it succeeded. A refund of a succeeded top-up takes the money back out.
+func (s *service) settle(ctx context.Context, p *Payment, status Status, reason string) error {
+	switch status {
+	case StatusSucceeded:
+		credited, err := s.wallets.CreditTopUp(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		if credited {
+			metrics.RecordWalletTopUp()
+			if p.AutoTopUp {
+				s.autoTopUpSettled(ctx, p, true, "")
+			}
+		}
+	case StatusFailed:
+		failed, err := s.repo.MarkFailed(ctx, p.ID, reason)
+		if err != nil {
+			return err
+		}
+		if failed && p.AutoTopUp {
+			s.autoTopUpSettled(ctx, p, false, reason)
+		}
+	case StatusRefunded:
+		refunded, err := s.wallets.RefundTopUp(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		if refunded {
+			logger.Infof("Payment %d was refunded by %s", p.ID, p.Provider)
+		}
+	}
+	return nil
+}
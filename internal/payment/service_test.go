@@ -0,0 +1,507 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"fitslot/internal/wallet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct{ mock.Mock }
+
+func (m *MockRepository) Create(ctx context.Context, p Payment) (*Payment, error) {
+	args := m.Called(ctx, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payment), args.Error(1)
+}
+
+func (m *MockRepository) GetByID(ctx context.Context, id int) (*Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payment), args.Error(1)
+}
+
+func (m *MockRepository) GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*Payment, error) {
+	args := m.Called(ctx, provider, providerPaymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payment), args.Error(1)
+}
+
+func (m *MockRepository) SetProviderPaymentID(ctx context.Context, id int, providerPaymentID string) error {
+	args := m.Called(ctx, id, providerPaymentID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkFailed(ctx context.Context, id int, reason string) (bool, error) {
+	args := m.Called(ctx, id, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreatePaymentMethod(ctx context.Context, pm PaymentMethod) (*PaymentMethod, error) {
+	args := m.Called(ctx, pm)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PaymentMethod), args.Error(1)
+}
+
+func (m *MockRepository) GetPaymentMethod(ctx context.Context, id int) (*PaymentMethod, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PaymentMethod), args.Error(1)
+}
+
+func (m *MockRepository) ListPaymentMethods(ctx context.Context, userID int) ([]PaymentMethod, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]PaymentMethod), args.Error(1)
+}
+
+func (m *MockRepository) DeletePaymentMethod(ctx context.Context, userID, id int) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SaveAutoTopUp(ctx context.Context, a AutoTopUp) (*AutoTopUp, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AutoTopUp), args.Error(1)
+}
+
+func (m *MockRepository) ListAutoTopUps(ctx context.Context, userID int) ([]AutoTopUp, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]AutoTopUp), args.Error(1)
+}
+
+func (m *MockRepository) DeleteAutoTopUp(ctx context.Context, userID int, currency string) error {
+	args := m.Called(ctx, userID, currency)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RecordAutoTopUpResult(ctx context.Context, paymentID int, succeeded bool, reason string) (*AutoTopUpOutcome, error) {
+	args := m.Called(ctx, paymentID, succeeded, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AutoTopUpOutcome), args.Error(1)
+}
+
+type MockMailer struct{ mock.Mock }
+
+func (m *MockMailer) SendAutoTopUp(ctx context.Context, email, name, amount string) error {
+	args := m.Called(ctx, email, name, amount)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendAutoTopUpDeclined(ctx context.Context, email, name, amount, reason string, disabled bool) error {
+	args := m.Called(ctx, email, name, amount, reason, disabled)
+	return args.Error(0)
+}
+
+type MockWalletRepo struct{ mock.Mock }
+
+func (m *MockWalletRepo) GetOrCreateWallet(ctx context.Context, userID int, currency string) (*wallet.Wallet, error) {
+	args := m.Called(ctx, userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) AddTransaction(ctx context.Context, userID int, amountCents int64, currency string, kind wallet.EntryKind, gymID *int) error {
+	args := m.Called(ctx, userID, amountCents, currency, kind, gymID)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) TopUp(ctx context.Context, userID int, amountCents int64) error {
+	args := m.Called(ctx, userID, amountCents)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) CreditTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletRepo) GetTransactions(ctx context.Context, userID int, filter wallet.TransactionFilter) ([]wallet.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).([]wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) LinkTransaction(ctx context.Context, transactionID int, link wallet.TransactionLink) error {
+	args := m.Called(ctx, transactionID, link)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) GetStatement(ctx context.Context, userID int, currency string, from, until time.Time) (*wallet.Statement, error) {
+	args := m.Called(ctx, userID, currency, from, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Statement), args.Error(1)
+}
+
+func (m *MockWalletRepo) ReconcileWallets(ctx context.Context) (*wallet.Reconciliation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Reconciliation), args.Error(1)
+}
+
+func (m *MockWalletRepo) CorrectWallet(ctx context.Context, c wallet.WalletCorrection) (*wallet.Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) GetAccountBalances(ctx context.Context) ([]wallet.AccountBalance, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]wallet.AccountBalance), args.Error(1)
+}
+
+func (m *MockWalletRepo) CheckLedger(ctx context.Context) (*wallet.LedgerCheck, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.LedgerCheck), args.Error(1)
+}
+
+func (m *MockWalletRepo) Transfer(ctx context.Context, t wallet.Transfer) (*wallet.Transaction, error) {
+	args := m.Called(ctx, t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) Adjust(ctx context.Context, a wallet.Adjustment) (*wallet.Transaction, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundTransaction(ctx context.Context, refund wallet.TransactionRefund) (*wallet.Transaction, error) {
+	args := m.Called(ctx, refund)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) RefundCharge(ctx context.Context, transactionID int) (*wallet.Transaction, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) GrantPromoCredit(ctx context.Context, g wallet.PromoGrant) (*wallet.Transaction, error) {
+	args := m.Called(ctx, g)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListCreditLots(ctx context.Context, userID int) ([]wallet.CreditLot, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.CreditLot), args.Error(1)
+}
+
+func (m *MockWalletRepo) ExpireCredits(ctx context.Context) ([]wallet.Transaction, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListWallets(ctx context.Context, userID int) ([]wallet.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) Charge(ctx context.Context, c wallet.Charge) (*wallet.Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListExchangeRates(ctx context.Context) ([]wallet.ExchangeRate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wallet.ExchangeRate), args.Error(1)
+}
+
+func (m *MockWalletRepo) SetExchangeRate(ctx context.Context, base, quote, rate string, adminID int) (*wallet.ExchangeRate, error) {
+	args := m.Called(ctx, base, quote, rate, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.ExchangeRate), args.Error(1)
+}
+
+func (m *MockWalletRepo) ExchangeRate(ctx context.Context, from, to string) (*big.Rat, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Rat), args.Error(1)
+}
+
+func TestService_TopUp(t *testing.T) {
+	ctx := context.Background()
+	pending := &Payment{ID: 7, UserID: 1, AmountCents: 5000, Currency: "KZT", Status: StatusPending, Provider: FakeProviderName}
+
+	tests := []struct {
+		name        string
+		req         TopUpRequest
+		setupMocks  func(*MockRepository, *MockWalletRepo)
+		expected    Status
+		expectedErr error
+	}{
+		{
+			name: "Succeeded payment credits the wallet",
+			req:  TopUpRequest{AmountCents: 5000},
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				w.On("CreditTopUp", ctx, 7).Return(true, nil)
+				r.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusSucceeded}, nil)
+			},
+			expected: StatusSucceeded,
+		},
+		{
+			name: "Declined payment is marked failed",
+			req:  TopUpRequest{AmountCents: 5000, PaymentMethod: FakeMethodFailure},
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("MarkFailed", ctx, 7, "card_declined").Return(true, nil)
+				r.On("GetByID", ctx, 7).Return(&Payment{ID: 7, Status: StatusFailed}, nil)
+			},
+			expected: StatusFailed,
+		},
+		{
+			name: "Pending payment waits for the webhook",
+			req:  TopUpRequest{AmountCents: 5000, PaymentMethod: FakeMethodPending},
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByID", ctx, 7).Return(pending, nil)
+			},
+			expected: StatusPending,
+		},
+		{
+			name: "Unsupported payment method",
+			req:  TopUpRequest{AmountCents: 5000, PaymentMethod: "visa"},
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("MarkFailed", ctx, 7, mock.AnythingOfType("string")).Return(true, nil)
+			},
+			expectedErr: ErrPaymentMethodInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			wallets := new(MockWalletRepo)
+
+			repo.On("Create", ctx, Payment{UserID: 1, AmountCents: 5000, Currency: "KZT", Status: StatusPending, Provider: FakeProviderName}).
+				Return(pending, nil)
+			if tt.expectedErr == nil {
+				repo.On("SetProviderPaymentID", ctx, 7, mock.AnythingOfType("string")).Return(nil)
+			}
+			tt.setupMocks(repo, wallets)
+
+			svc := NewService(repo, wallets, NewFakeProvider("secret"), new(MockMailer))
+			p, err := svc.TopUp(ctx, 1, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, p.Status)
+			}
+
+			repo.AssertExpectations(t)
+			wallets.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_TopUp_InvalidAmount(t *testing.T) {
+	svc := NewService(new(MockRepository), new(MockWalletRepo), NewFakeProvider("secret"), new(MockMailer))
+
+	_, err := svc.TopUp(context.Background(), 1, TopUpRequest{AmountCents: -100})
+	assert.ErrorIs(t, err, ErrAmountInvalid)
+}
+
+func TestService_TopUp_InvalidCurrency(t *testing.T) {
+	svc := NewService(new(MockRepository), new(MockWalletRepo), NewFakeProvider("secret"), new(MockMailer))
+
+	_, err := svc.TopUp(context.Background(), 1, TopUpRequest{AmountCents: 100, Currency: "XYZ"})
+	assert.ErrorIs(t, err, ErrCurrencyInvalid)
+}
+
+func TestService_NoProvider(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	svc := NewService(repo, new(MockWalletRepo), nil, new(MockMailer))
+
+	_, err := svc.TopUp(ctx, 1, TopUpRequest{AmountCents: 5000})
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+
+	_, err = svc.SavePaymentMethod(ctx, 1, SavePaymentMethodRequest{PaymentMethod: FakeMethodSuccess})
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+
+	_, err = svc.SetAutoTopUp(ctx, 1, AutoTopUpRequest{AmountCents: 5000, MonthlyCapCents: 5000, PaymentMethodID: 4})
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+
+	payload := []byte(`{"id":"evt_1","intent_id":"fake_abc","status":"succeeded"}`)
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, SignWebhook("secret", payload, time.Now()))
+	err = svc.HandleWebhook(ctx, FakeProviderName, payload, header)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+
+	repo.AssertExpectations(t)
+}
+
+func TestService_HandleWebhook(t *testing.T) {
+	ctx := context.Background()
+	payment := &Payment{ID: 7, Status: StatusPending, Provider: FakeProviderName}
+
+	signed := func(payload string) http.Header {
+		h := http.Header{}
+		h.Set(FakeSignatureHeader, SignWebhook("secret", []byte(payload), time.Now()))
+		return h
+	}
+
+	tests := []struct {
+		name        string
+		provider    string
+		payload     string
+		header      http.Header
+		setupMocks  func(*MockRepository, *MockWalletRepo)
+		expectedErr error
+	}{
+		{
+			name:     "Succeeded",
+			provider: FakeProviderName,
+			payload:  `{"id":"evt_1","intent_id":"fake_abc","status":"succeeded"}`,
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByProviderPaymentID", ctx, FakeProviderName, "fake_abc").Return(payment, nil)
+				w.On("CreditTopUp", ctx, 7).Return(true, nil)
+			},
+		},
+		{
+			name:     "Repeated success is not credited again",
+			provider: FakeProviderName,
+			payload:  `{"id":"evt_1","intent_id":"fake_abc","status":"succeeded"}`,
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByProviderPaymentID", ctx, FakeProviderName, "fake_abc").Return(&Payment{ID: 7, Status: StatusSucceeded}, nil)
+				w.On("CreditTopUp", ctx, 7).Return(false, nil)
+			},
+		},
+		{
+			name:     "Failed",
+			provider: FakeProviderName,
+			payload:  `{"id":"evt_2","intent_id":"fake_abc","status":"failed","failure_reason":"insufficient_funds"}`,
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByProviderPaymentID", ctx, FakeProviderName, "fake_abc").Return(payment, nil)
+				r.On("MarkFailed", ctx, 7, "insufficient_funds").Return(true, nil)
+			},
+		},
+		{
+			name:     "Refunded",
+			provider: FakeProviderName,
+			payload:  `{"id":"evt_4","intent_id":"fake_abc","status":"refunded"}`,
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByProviderPaymentID", ctx, FakeProviderName, "fake_abc").Return(&Payment{ID: 7, Status: StatusSucceeded}, nil)
+				w.On("RefundTopUp", ctx, 7).Return(true, nil)
+			},
+		},
+		{
+			name:     "Unknown payment",
+			provider: FakeProviderName,
+			payload:  `{"id":"evt_3","intent_id":"fake_xyz","status":"succeeded"}`,
+			setupMocks: func(r *MockRepository, w *MockWalletRepo) {
+				r.On("GetByProviderPaymentID", ctx, FakeProviderName, "fake_xyz").Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrPaymentNotFound,
+		},
+		{
+			name:        "Bad signature",
+			provider:    FakeProviderName,
+			payload:     `{"id":"evt_1","intent_id":"fake_abc","status":"succeeded"}`,
+			header:      http.Header{FakeSignatureHeader: []string{"t=1,v1=00"}},
+			setupMocks:  func(r *MockRepository, w *MockWalletRepo) {},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "Unknown provider",
+			provider:    "stripe",
+			payload:     `{}`,
+			setupMocks:  func(r *MockRepository, w *MockWalletRepo) {},
+			expectedErr: ErrUnknownProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			wallets := new(MockWalletRepo)
+			tt.setupMocks(repo, wallets)
+
+			header := tt.header
+			if header == nil {
+				header = signed(tt.payload)
+			}
+
+			svc := NewService(repo, wallets, NewFakeProvider("secret"), new(MockMailer))
+			err := svc.HandleWebhook(ctx, tt.provider, []byte(tt.payload), header)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			repo.AssertExpectations(t)
+			wallets.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package pdf writes simple text documents as PDF without any external
+// service or dependency: A4 pages with text in the standard Helvetica and
+// Courier fonts, and horizontal rules.
+//
+// The standard fonts only cover WinAnsi (Latin-1) characters. Cyrillic and
+// Kazakh letters are transliterated to Latin; anything else is printed as "?".
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+// Font is one of the standard fonts every PDF reader provides.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	Courier
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// CourierWidth is the width of a Courier glyph as a fraction of the font
+// size; it lets callers right-align monospaced columns.
+const CourierWidth = 0.6
+
+// Document is a PDF under construction. Coordinates are in points from the
+// bottom-left corner of the page.
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+// New returns a document with no pages.
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page; following drawing goes onto it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+}
+
+// PageCount returns the number of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, y, escape(encode(s)))
+}
+
+// Line draws a thin line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, cw.n)
+		fmt.Fprintf(cw, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects: 1 catalog, 2 page tree, the fonts, then a page and its
+	// content stream for every page.
+	firstPage := 3 + len(fontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	var fonts strings.Builder
+	for i := range fontNames {
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, 3+i)
+	}
+
+	io.WriteString(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, fonts.String(), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, bw.Flush()
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// translit spells Russian and Kazakh letters in Latin.
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u",
+	'һ': "h", 'і': "i",
+}
+
+// encode converts s to WinAnsi bytes.
+func encode(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			b = append(b, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		case r == '–' || r == '—':
+			b = append(b, '-')
+		case r == '‘' || r == '’':
+			b = append(b, '\'')
+		case r == '“' || r == '”':
+			b = append(b, '"')
+		default:
+			lower := unicode.ToLower(r)
+			t, ok := translit[lower]
+			if !ok {
+				b = append(b, '?')
+				continue
+			}
+			if lower != r && t != "" {
+				t = strings.ToUpper(t[:1]) + t[1:]
+			}
+			b = append(b, t...)
+		}
+	}
+	return b
+}
+
+// escape quotes the characters that are special in a PDF string literal.
+func escape(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == '(' || c == ')' || c == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return out
+}
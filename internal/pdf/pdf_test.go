@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	d := New()
+	d.AddPage()
+	d.Text(50, 800, HelveticaBold, 16, "Wallet statement")
+	d.Line(50, 790, 545, 790)
+	d.AddPage()
+	d.Text(50, 800, Courier, 9, "(1) \\ done")
+
+	var buf bytes.Buffer
+	n, err := d.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.Bytes()
+	assert.Equal(t, int64(len(out)), n)
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/BaseFont /Helvetica-Bold")
+	assert.Contains(t, string(out), "(Wallet statement) Tj")
+	assert.Contains(t, string(out), `(\(1\) \\ done) Tj`)
+
+	// Every xref entry points at the start of its object.
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, start)
+	xref, _ := strconv.Atoi(string(start[1]))
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n0 10\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.Len(t, entries, 9)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(out[off:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, "Fitnes-klub Almaty", string(encode("Фитнес-клуб Almaty")))
+	assert.Equal(t, "Qazaq Zhattygu", string(encode("Қазақ Жаттығу")))
+	assert.Equal(t, "Cafe\xe9 - ok", string(encode("Cafeé – ok")))
+	assert.Equal(t, "a b ?", string(encode("a\tb 😀")))
+}
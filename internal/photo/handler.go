@@ -0,0 +1,219 @@
+package photo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fitslot/internal/api"
+	"fitslot/internal/gym"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadBytes leaves room for the multipart framing around a photo.
+const maxUploadBytes = MaxPhotoBytes + 64<<10
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// @Summary      Get a gym profile
+// @Description  The gym with its rating and photos in display order.
+// @Tags         gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {object} photo.GymProfile
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID} [get]
+func (h *Handler) GetGymProfile(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	profile, err := h.service.GetGymProfile(c.Request.Context(), gymID)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch gym"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary      Get a gym profile (public)
+// @Description  Same as GET /gyms/{gymID}, without authentication. Responses carry an ETag and may be cached.
+// @Tags         public
+// @Produce      json
+// @Param        gymID path int true "Gym ID"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Success      200 {object} photo.GymProfile
+// @Success      304 "Not modified"
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      429 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /public/gyms/{gymID} [get]
+func (h *Handler) GetPublicGymProfile(c *gin.Context) {
+	h.GetGymProfile(c)
+}
+
+// @Summary      Upload a gym photo
+// @Description  JPEG or PNG, up to 5 MB and 8000x8000 pixels. The type is detected from the file content. A thumbnail is generated and the photo is added after the gym's existing photos.
+// @Tags         admin,gyms
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        photo formData file true "Photo"
+// @Success      201 {object} photo.Photo
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      413 {object} api.ErrorResponse
+// @Failure      415 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/photos [post]
+func (h *Handler) UploadPhoto(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, api.ErrorResponse{Error: "Photo must be at most 5 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Photo file is required"})
+		return
+	}
+	if fileHeader.Size > MaxPhotoBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, api.ErrorResponse{Error: "Photo must be at most 5 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Failed to read photo"})
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.service.UploadPhoto(c.Request.Context(), gymID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, gym.ErrGymNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case errors.Is(err, ErrPhotoTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, api.ErrorResponse{Error: "Photo must be at most 5 MB"})
+		case errors.Is(err, ErrUnsupportedType):
+			c.JSON(http.StatusUnsupportedMediaType, api.ErrorResponse{Error: "Photo must be a JPEG or PNG image"})
+		case errors.Is(err, ErrPhotoInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to upload photo"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// @Summary      Reorder gym photos
+// @Description  photo_ids must list every photo of the gym exactly once, in the new display order.
+// @Tags         admin,gyms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body photo.ReorderPhotosRequest true "New order"
+// @Success      200 {array} photo.Photo
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/photos/order [put]
+func (h *Handler) ReorderPhotos(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	var req ReorderPhotosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	photos, err := h.service.ReorderPhotos(c.Request.Context(), gymID, req.PhotoIDs)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case ErrPhotoOrderInvalid:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "photo_ids must list every photo of the gym once"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to reorder photos"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, photos)
+}
+
+// @Summary      Delete a gym photo
+// @Tags         admin,gyms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        photoID path int true "Photo ID"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/photos/{photoID} [delete]
+func (h *Handler) DeletePhoto(c *gin.Context) {
+	photoID, err := strconv.Atoi(c.Param("photoID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid photo ID"})
+		return
+	}
+
+	if err := h.service.DeletePhoto(c.Request.Context(), photoID); err != nil {
+		switch err {
+		case ErrPhotoNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Photo not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to delete photo"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "Photo deleted"})
+}
@@ -0,0 +1,43 @@
+package photo
+
+import (
+	"time"
+
+	"fitslot/internal/gym"
+)
+
+// Photo is an image of a gym. Files live in storage; URL and ThumbnailURL
+// are filled in from the storage keys when the photo is returned.
+type Photo struct {
+	ID           int       `db:"id" json:"id"`
+	GymID        int       `db:"gym_id" json:"gym_id"`
+	StorageKey   string    `db:"storage_key" json:"-"`
+	ThumbnailKey string    `db:"thumbnail_key" json:"-"`
+	ContentType  string    `db:"content_type" json:"content_type" example:"image/jpeg"`
+	Width        int       `db:"width" json:"width" example:"1920"`
+	Height       int       `db:"height" json:"height" example:"1080"`
+	Position     int       `db:"position" json:"position" example:"0"`
+	URL          string    `db:"-" json:"url" example:"/media/gyms/1/3f2a9c.jpg"`
+	ThumbnailURL string    `db:"-" json:"thumbnail_url" example:"/media/gyms/1/3f2a9c_thumb.jpg"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// NewPhoto describes stored files ready to be recorded by the repository.
+type NewPhoto struct {
+	StorageKey   string
+	ThumbnailKey string
+	ContentType  string
+	Width        int
+	Height       int
+}
+
+// GymProfile is a gym with its photos in display order.
+type GymProfile struct {
+	gym.Gym
+	Photos []Photo `json:"photos"`
+}
+
+// ReorderPhotosRequest lists every photo of the gym in the new display order.
+type ReorderPhotosRequest struct {
+	PhotoIDs []int `json:"photo_ids" binding:"required" example:"3,1,2"`
+}
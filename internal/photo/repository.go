@@ -0,0 +1,101 @@
+package photo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const photoColumns = "id, gym_id, storage_key, thumbnail_key, content_type, width, height, position, created_at"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreatePhoto appends the photo after the gym's existing ones.
+func (r *repository) CreatePhoto(ctx context.Context, gymID int, photo NewPhoto) (*Photo, error) {
+	query := `
+		INSERT INTO gym_photos (gym_id, storage_key, thumbnail_key, content_type, width, height, position)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			(SELECT COALESCE(MAX(position) + 1, 0) FROM gym_photos WHERE gym_id = $1))
+		RETURNING ` + photoColumns
+
+	var created Photo
+	err := r.db.GetContext(ctx, &created, query,
+		gymID, photo.StorageKey, photo.ThumbnailKey, photo.ContentType, photo.Width, photo.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *repository) GetPhotosByGym(ctx context.Context, gymID int) ([]Photo, error) {
+	query := `
+		SELECT ` + photoColumns + `
+		FROM gym_photos
+		WHERE gym_id = $1
+		ORDER BY position ASC, id ASC
+	`
+
+	var photos []Photo
+	err := r.db.SelectContext(ctx, &photos, query, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+func (r *repository) GetPhotoByID(ctx context.Context, id int) (*Photo, error) {
+	query := `
+		SELECT ` + photoColumns + `
+		FROM gym_photos
+		WHERE id = $1
+	`
+
+	var photo Photo
+	err := r.db.GetContext(ctx, &photo, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+func (r *repository) DeletePhoto(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM gym_photos WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReorderPhotos sets each photo's position to its index in photoIDs.
+func (r *repository) ReorderPhotos(ctx context.Context, gymID int, photoIDs []int) error {
+	query := `
+		UPDATE gym_photos p
+		SET position = o.ord - 1
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, ord)
+		WHERE p.id = o.id AND p.gym_id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, gymID, pq.Array(photoIDs))
+	return err
+}
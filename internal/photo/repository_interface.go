@@ -0,0 +1,11 @@
+package photo
+
+import "context"
+
+type Repository interface {
+	CreatePhoto(ctx context.Context, gymID int, photo NewPhoto) (*Photo, error)
+	GetPhotosByGym(ctx context.Context, gymID int) ([]Photo, error)
+	GetPhotoByID(ctx context.Context, id int) (*Photo, error)
+	DeletePhoto(ctx context.Context, id int) error
+	ReorderPhotos(ctx context.Context, gymID int, photoIDs []int) error
+}
@@ -0,0 +1,55 @@
+package photo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPhotoMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(sqlxDB)
+
+	closer := func() { sqlxDB.Close() }
+	return repo, mock, closer
+}
+
+func TestCreatePhoto(t *testing.T) {
+	repo, mock, close := setupPhotoMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO gym_photos .* \(SELECT COALESCE\(MAX\(position\) \+ 1, 0\) FROM gym_photos WHERE gym_id = \$1\)\) RETURNING id`).
+		WithArgs(1, "gyms/1/a.jpg", "gyms/1/a_thumb.jpg", "image/jpeg", 800, 600).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "storage_key", "thumbnail_key", "content_type", "width", "height", "position", "created_at"}).
+			AddRow(5, 1, "gyms/1/a.jpg", "gyms/1/a_thumb.jpg", "image/jpeg", 800, 600, 2, time.Now()))
+
+	photo, err := repo.CreatePhoto(context.Background(), 1, NewPhoto{
+		StorageKey:   "gyms/1/a.jpg",
+		ThumbnailKey: "gyms/1/a_thumb.jpg",
+		ContentType:  "image/jpeg",
+		Width:        800,
+		Height:       600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, photo.Position)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderPhotos(t *testing.T) {
+	repo, mock, close := setupPhotoMock(t)
+	defer close()
+
+	mock.ExpectExec(`UPDATE gym_photos p SET position = o.ord - 1 FROM unnest\(\$2::int\[\]\) WITH ORDINALITY AS o\(id, ord\) WHERE p.id = o.id AND p.gym_id = \$1`).
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	require.NoError(t, repo.ReorderPhotos(context.Background(), 1, []int{3, 1, 2}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,248 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"fitslot/internal/gym"
+	"fitslot/internal/logger"
+	"fitslot/internal/storage"
+)
+
+const (
+	// MaxPhotoBytes limits the size of an uploaded photo.
+	MaxPhotoBytes = 5 << 20
+	// MaxPhotoDimension rejects images too large to decode safely.
+	MaxPhotoDimension = 8000
+	// ThumbnailSize is the longest side of a generated thumbnail, in pixels.
+	ThumbnailSize = 320
+)
+
+// photoTypes maps the accepted content types, as sniffed from the file
+// itself, to the extension used for the stored file.
+var photoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+var (
+	ErrPhotoTooLarge     = errors.New("photo too large")
+	ErrUnsupportedType   = errors.New("unsupported photo type")
+	ErrPhotoInvalid      = errors.New("invalid photo")
+	ErrPhotoNotFound     = errors.New("photo not found")
+	ErrPhotoOrderInvalid = errors.New("photo order must list every photo of the gym once")
+)
+
+type Service interface {
+	UploadPhoto(ctx context.Context, gymID int, r io.Reader) (*Photo, error)
+	GetGymProfile(ctx context.Context, gymID int) (*GymProfile, error)
+	ReorderPhotos(ctx context.Context, gymID int, photoIDs []int) ([]Photo, error)
+	DeletePhoto(ctx context.Context, id int) error
+}
+
+type service struct {
+	repo    Repository
+	gymRepo gym.Repository
+	store   storage.Storage
+}
+
+func NewService(repo Repository, gymRepo gym.Repository, store storage.Storage) Service {
+	return &service{
+		repo:    repo,
+		gymRepo: gymRepo,
+		store:   store,
+	}
+}
+
+// UploadPhoto checks that r holds a JPEG or PNG image, stores it along with
+// a JPEG thumbnail and adds it after the gym's existing photos.
+func (s *service) UploadPhoto(ctx context.Context, gymID int, r io.Reader) (*Photo, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxPhotoBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxPhotoBytes {
+		return nil, ErrPhotoTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := photoTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoInvalid
+	}
+	if config.Width > MaxPhotoDimension || config.Height > MaxPhotoDimension {
+		return nil, fmt.Errorf("%w: images can be at most %dx%d pixels", ErrPhotoInvalid, MaxPhotoDimension, MaxPhotoDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoInvalid
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, thumbnail(img, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	newPhoto := NewPhoto{
+		StorageKey:   fmt.Sprintf("gyms/%d/%s%s", gymID, name, ext),
+		ThumbnailKey: fmt.Sprintf("gyms/%d/%s_thumb.jpg", gymID, name),
+		ContentType:  contentType,
+		Width:        config.Width,
+		Height:       config.Height,
+	}
+
+	if err := s.store.Save(ctx, newPhoto.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(ctx, newPhoto.ThumbnailKey, &thumb); err != nil {
+		s.deleteFiles(ctx, newPhoto.StorageKey)
+		return nil, err
+	}
+
+	photo, err := s.repo.CreatePhoto(ctx, gymID, newPhoto)
+	if err != nil {
+		s.deleteFiles(ctx, newPhoto.StorageKey, newPhoto.ThumbnailKey)
+		return nil, err
+	}
+
+	s.withURLs(photo)
+	return photo, nil
+}
+
+func (s *service) GetGymProfile(ctx context.Context, gymID int) (*GymProfile, error) {
+	g, err := s.gymRepo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	photos, err := s.photos(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GymProfile{
+		Gym:    *g,
+		Photos: photos,
+	}, nil
+}
+
+func (s *service) ReorderPhotos(ctx context.Context, gymID int, photoIDs []int) ([]Photo, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	current, err := s.repo.GetPhotosByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+	if !samePhotos(current, photoIDs) {
+		return nil, ErrPhotoOrderInvalid
+	}
+
+	if err := s.repo.ReorderPhotos(ctx, gymID, photoIDs); err != nil {
+		return nil, err
+	}
+
+	return s.photos(ctx, gymID)
+}
+
+// DeletePhoto removes the photo record first so it disappears from the
+// profile even if its files cannot be deleted.
+func (s *service) DeletePhoto(ctx context.Context, id int) error {
+	photo, err := s.repo.GetPhotoByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPhotoNotFound
+		}
+		return err
+	}
+
+	if err := s.repo.DeletePhoto(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPhotoNotFound
+		}
+		return err
+	}
+
+	s.deleteFiles(ctx, photo.StorageKey, photo.ThumbnailKey)
+	return nil
+}
+
+func (s *service) photos(ctx context.Context, gymID int) ([]Photo, error) {
+	photos, err := s.repo.GetPhotosByGym(ctx, gymID)
+	if err != nil {
+		return nil, err
+	}
+	if photos == nil {
+		photos = []Photo{}
+	}
+
+	for i := range photos {
+		s.withURLs(&photos[i])
+	}
+	return photos, nil
+}
+
+func (s *service) withURLs(photo *Photo) {
+	photo.URL = s.store.URL(photo.StorageKey)
+	photo.ThumbnailURL = s.store.URL(photo.ThumbnailKey)
+}
+
+func (s *service) deleteFiles(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logger.Errorf("Failed to delete photo file %s: %v", key, err)
+		}
+	}
+}
+
+// samePhotos reports whether ids lists exactly the given photos, each once.
+func samePhotos(photos []Photo, ids []int) bool {
+	if len(photos) != len(ids) {
+		return false
+	}
+
+	remaining := make(map[int]bool, len(photos))
+	for _, p := range photos {
+		remaining[p.ID] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,206 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"fitslot/internal/gym"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreatePhoto(ctx context.Context, gymID int, photo NewPhoto) (*Photo, error) {
+	args := m.Called(ctx, gymID, photo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Photo), args.Error(1)
+}
+
+func (m *MockRepository) GetPhotosByGym(ctx context.Context, gymID int) ([]Photo, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Photo), args.Error(1)
+}
+
+func (m *MockRepository) GetPhotoByID(ctx context.Context, id int) (*Photo, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Photo), args.Error(1)
+}
+
+func (m *MockRepository) DeletePhoto(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) ReorderPhotos(ctx context.Context, gymID int, photoIDs []int) error {
+	return m.Called(ctx, gymID, photoIDs).Error(0)
+}
+
+// MockGymRepo only stubs the lookups the photo service relies on; calling
+// anything else panics on the nil embedded interface.
+type MockGymRepo struct {
+	mock.Mock
+	gym.Repository
+}
+
+func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Gym), args.Error(1)
+}
+
+// memoryStorage keeps files in a map.
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: map[string][]byte{}}
+}
+
+func (s *memoryStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.files[key] = data
+	return nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func (s *memoryStorage) URL(key string) string {
+	return "/media/" + key
+}
+
+func pngImage(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestService_UploadPhoto(t *testing.T) {
+	t.Run("stores the photo and a thumbnail", func(t *testing.T) {
+		repo, gymRepo, store := new(MockRepository), new(MockGymRepo), newMemoryStorage()
+		created := &Photo{ID: 1, GymID: 1}
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("CreatePhoto", mock.Anything, 1, mock.MatchedBy(func(p NewPhoto) bool {
+			return p.ContentType == "image/png" && p.Width == 640 && p.Height == 480 &&
+				strings.HasSuffix(p.StorageKey, ".png") && strings.HasSuffix(p.ThumbnailKey, "_thumb.jpg")
+		})).Run(func(args mock.Arguments) {
+			p := args.Get(2).(NewPhoto)
+			created.StorageKey, created.ThumbnailKey = p.StorageKey, p.ThumbnailKey
+		}).Return(created, nil)
+
+		service := NewService(repo, gymRepo, store)
+		photo, err := service.UploadPhoto(context.Background(), 1, bytes.NewReader(pngImage(t, 640, 480)))
+
+		require.NoError(t, err)
+		assert.Len(t, store.files, 2)
+		assert.Contains(t, store.files, photo.StorageKey)
+		assert.Equal(t, "/media/"+photo.ThumbnailKey, photo.ThumbnailURL)
+
+		thumb, _, err := image.DecodeConfig(bytes.NewReader(store.files[photo.ThumbnailKey]))
+		require.NoError(t, err)
+		assert.Equal(t, ThumbnailSize, thumb.Width)
+		assert.Equal(t, 240, thumb.Height)
+	})
+
+	t.Run("rejects files that are not images", func(t *testing.T) {
+		repo, gymRepo, store := new(MockRepository), new(MockGymRepo), newMemoryStorage()
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+
+		service := NewService(repo, gymRepo, store)
+		_, err := service.UploadPhoto(context.Background(), 1, strings.NewReader("%PDF-1.4 not a photo"))
+
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+		assert.Empty(t, store.files)
+	})
+
+	t.Run("rejects oversized files", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+
+		service := NewService(repo, gymRepo, newMemoryStorage())
+		_, err := service.UploadPhoto(context.Background(), 1, bytes.NewReader(make([]byte, MaxPhotoBytes+1)))
+
+		assert.ErrorIs(t, err, ErrPhotoTooLarge)
+	})
+
+	t.Run("removes stored files when the record fails", func(t *testing.T) {
+		repo, gymRepo, store := new(MockRepository), new(MockGymRepo), newMemoryStorage()
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("CreatePhoto", mock.Anything, 1, mock.Anything).Return(nil, sql.ErrConnDone)
+
+		service := NewService(repo, gymRepo, store)
+		_, err := service.UploadPhoto(context.Background(), 1, bytes.NewReader(pngImage(t, 10, 10)))
+
+		assert.Error(t, err)
+		assert.Empty(t, store.files)
+	})
+}
+
+func TestService_ReorderPhotos(t *testing.T) {
+	current := []Photo{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	t.Run("reorders every photo", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetPhotosByGym", mock.Anything, 1).Return(current, nil)
+		repo.On("ReorderPhotos", mock.Anything, 1, []int{3, 1, 2}).Return(nil)
+
+		service := NewService(repo, gymRepo, newMemoryStorage())
+		_, err := service.ReorderPhotos(context.Background(), 1, []int{3, 1, 2})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a partial order", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetPhotosByGym", mock.Anything, 1).Return(current, nil)
+
+		service := NewService(repo, gymRepo, newMemoryStorage())
+		_, err := service.ReorderPhotos(context.Background(), 1, []int{3, 3, 1})
+
+		assert.ErrorIs(t, err, ErrPhotoOrderInvalid)
+		repo.AssertNotCalled(t, "ReorderPhotos", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_DeletePhoto(t *testing.T) {
+	repo, store := new(MockRepository), newMemoryStorage()
+	store.files["gyms/1/a.jpg"] = []byte("x")
+	store.files["gyms/1/a_thumb.jpg"] = []byte("x")
+	repo.On("GetPhotoByID", mock.Anything, 5).Return(&Photo{ID: 5, StorageKey: "gyms/1/a.jpg", ThumbnailKey: "gyms/1/a_thumb.jpg"}, nil)
+	repo.On("DeletePhoto", mock.Anything, 5).Return(nil)
+
+	service := NewService(repo, new(MockGymRepo), store)
+	err := service.DeletePhoto(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Empty(t, store.files)
+}
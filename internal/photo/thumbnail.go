@@ -0,0 +1,57 @@
+package photo
+
+import (
+	"image"
+	"image/color"
+)
+
+// thumbnail scales img down so that neither side exceeds maxSize, keeping
+// the aspect ratio. Each target pixel averages the block of source pixels it
+// covers, which is good enough for photos and needs nothing beyond the
+// standard library. Transparent areas are flattened onto white since
+// thumbnails are encoded as JPEG.
+func thumbnail(img image.Image, maxSize int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	tw, th := w, h
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			tw, th = maxSize, max(1, h*maxSize/w)
+		} else {
+			tw, th = max(1, w*maxSize/h), maxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := bounds.Min.Y + y*h/th
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*h/th)
+
+		for x := 0; x < tw; x++ {
+			x0 := bounds.Min.X + x*w/tw
+			x1 := max(x0+1, bounds.Min.X+(x+1)*w/tw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			// Colors are alpha-premultiplied, so adding the missing alpha
+			// composites the pixel over white.
+			white := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + white),
+				G: uint16(g/n + white),
+				B: uint16(b/n + white),
+				A: 0xffff,
+			})
+		}
+	}
+
+	return dst
+}
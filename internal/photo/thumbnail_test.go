@@ -0,0 +1,50 @@
+package photo
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+	t.Run("keeps the aspect ratio", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+
+		thumb := thumbnail(src, 320)
+
+		assert.Equal(t, 320, thumb.Bounds().Dx())
+		assert.Equal(t, 160, thumb.Bounds().Dy())
+	})
+
+	t.Run("does not upscale small images", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 100, 200))
+
+		thumb := thumbnail(src, 320)
+
+		assert.Equal(t, image.Rect(0, 0, 100, 200), thumb.Bounds())
+	})
+
+	t.Run("averages source pixels", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		src.Set(0, 0, color.RGBA{R: 255, A: 255})
+		src.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+		thumb := thumbnail(src, 1)
+
+		r, g, b, a := thumb.At(0, 0).RGBA()
+		assert.InDelta(t, 0x7fff, r, 0x100)
+		assert.Equal(t, uint32(0), g)
+		assert.InDelta(t, 0x7fff, b, 0x100)
+		assert.Equal(t, uint32(0xffff), a)
+	})
+
+	t.Run("flattens transparency onto white", func(t *testing.T) {
+		src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+
+		thumb := thumbnail(src, 320)
+
+		assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thumb.RGBAAt(0, 0))
+	})
+}
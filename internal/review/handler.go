@@ -0,0 +1,170 @@
+package review
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fitslot/internal/api"
+	"fitslot/internal/auth"
+	"fitslot/internal/gym"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// @Summary      Review a gym
+// @Description  Rate a gym from 1 to 5 for one of your past bookings there. Each booking can be reviewed once.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body review.CreateReviewRequest true "Review payload"
+// @Success      201 {object} review.Review
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID}/reviews [post]
+func (h *Handler) CreateReview(c *gin.Context) {
+	userID, exists := auth.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review, err := h.service.CreateReview(c.Request.Context(), userID, gymID, req)
+	if err != nil {
+		switch err {
+		case ErrReviewInvalid:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Rating must be between 1 and 5 and the comment at most 2000 characters"})
+		case ErrVisitNotFinished:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "You can review a visit once it is over"})
+		case ErrNotVisited:
+			c.JSON(http.StatusForbidden, api.ErrorResponse{Error: "You can only review gyms you have booked"})
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case ErrAlreadyReviewed:
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "You have already reviewed this visit"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create review"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// @Summary      List gym reviews
+// @Description  Newest first. Hidden reviews are only listed on the admin route.
+// @Tags         reviews,admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {array} review.Review
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /gyms/{gymID}/reviews [get]
+// @Router       /admin/gyms/{gymID}/reviews [get]
+func (h *Handler) ListReviews(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	includeHidden := strings.Contains(c.Request.URL.Path, "/admin/")
+	reviews, err := h.service.GetReviews(c.Request.Context(), gymID, includeHidden)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch reviews"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// @Summary      Hide a review
+// @Description  Hidden reviews disappear from public listings and no longer count towards the gym's rating.
+// @Tags         admin,reviews
+// @Produce      json
+// @Security     BearerAuth
+// @Param        reviewID path int true "Review ID"
+// @Success      200 {object} review.Review
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/reviews/{reviewID}/hide [post]
+func (h *Handler) HideReview(c *gin.Context) {
+	h.moderate(c, h.service.HideReview)
+}
+
+// @Summary      Unhide a review
+// @Tags         admin,reviews
+// @Produce      json
+// @Security     BearerAuth
+// @Param        reviewID path int true "Review ID"
+// @Success      200 {object} review.Review
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/reviews/{reviewID}/unhide [post]
+func (h *Handler) UnhideReview(c *gin.Context) {
+	h.moderate(c, h.service.UnhideReview)
+}
+
+func (h *Handler) moderate(c *gin.Context, action func(ctx context.Context, id int) (*Review, error)) {
+	reviewID, err := strconv.Atoi(c.Param("reviewID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid review ID"})
+		return
+	}
+
+	review, err := action(c.Request.Context(), reviewID)
+	if err != nil {
+		switch err {
+		case ErrReviewNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Review not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to update review"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
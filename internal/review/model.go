@@ -0,0 +1,33 @@
+package review
+
+import "time"
+
+// Review is a member's rating of a gym, tied to one of their past bookings
+// there so that each visit can be reviewed at most once.
+type Review struct {
+	ID        int        `db:"id" json:"id"`
+	GymID     int        `db:"gym_id" json:"gym_id"`
+	UserID    int        `db:"user_id" json:"user_id"`
+	BookingID int        `db:"booking_id" json:"booking_id"`
+	Rating    int        `db:"rating" json:"rating" example:"5"`
+	Comment   string     `db:"comment" json:"comment" example:"Great equipment, friendly staff"`
+	UserName  string     `db:"user_name" json:"user_name" example:"John Doe"`
+	HiddenAt  *time.Time `db:"hidden_at" json:"hidden_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+type CreateReviewRequest struct {
+	BookingID int    `json:"booking_id" binding:"required" example:"12"`
+	Rating    int    `json:"rating" binding:"required" example:"5"`
+	Comment   string `json:"comment" example:"Great equipment, friendly staff"`
+}
+
+// Visit is the booking a review is written for, with the slot details
+// needed to check that the member actually went.
+type Visit struct {
+	BookingID int       `db:"booking_id"`
+	UserID    int       `db:"user_id"`
+	GymID     int       `db:"gym_id"`
+	Status    string    `db:"status"`
+	EndTime   time.Time `db:"end_time"`
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for a broken unique constraint.
+const uniqueViolation = "23505"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetVisit(ctx context.Context, bookingID int) (*Visit, error) {
+	query := `
+		SELECT b.id AS booking_id, b.user_id, b.status, ts.gym_id, ts.end_time
+		FROM bookings b
+		JOIN time_slots ts ON b.time_slot_id = ts.id
+		WHERE b.id = $1
+	`
+
+	var visit Visit
+	err := r.db.GetContext(ctx, &visit, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &visit, nil
+}
+
+func (r *repository) ReviewExistsForBooking(ctx context.Context, bookingID int) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM gym_reviews WHERE booking_id = $1)`, bookingID)
+	return exists, err
+}
+
+func (r *repository) CreateReview(ctx context.Context, gymID, userID, bookingID, rating int, comment string) (*Review, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO gym_reviews (gym_id, user_id, booking_id, rating, comment)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, gym_id, user_id, booking_id, rating, comment, hidden_at, created_at
+		)
+		SELECT r.*, u.name AS user_name
+		FROM inserted r
+		JOIN users u ON u.id = r.user_id
+	`
+
+	var review Review
+	err := r.db.GetContext(ctx, &review, query, gymID, userID, bookingID, rating, comment)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "unique_review_per_booking" {
+		// Another request reviewed the booking after the service checked.
+		return nil, ErrAlreadyReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetReviewsByGym lists a gym's reviews, newest first. Hidden reviews are
+// only included for moderators.
+func (r *repository) GetReviewsByGym(ctx context.Context, gymID int, includeHidden bool) ([]Review, error) {
+	query := `
+		SELECT r.id, r.gym_id, r.user_id, r.booking_id, r.rating, r.comment, r.hidden_at, r.created_at,
+			u.name AS user_name
+		FROM gym_reviews r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.gym_id = $1 AND ($2 OR r.hidden_at IS NULL)
+		ORDER BY r.created_at DESC
+	`
+
+	var reviews []Review
+	err := r.db.SelectContext(ctx, &reviews, query, gymID, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// SetHidden hides or unhides a review. It returns sql.ErrNoRows when the
+// review does not exist.
+func (r *repository) SetHidden(ctx context.Context, id int, hidden bool) (*Review, error) {
+	query := `
+		WITH updated AS (
+			UPDATE gym_reviews
+			SET hidden_at = CASE WHEN $2 THEN COALESCE(hidden_at, NOW()) END
+			WHERE id = $1
+			RETURNING id, gym_id, user_id, booking_id, rating, comment, hidden_at, created_at
+		)
+		SELECT r.*, u.name AS user_name
+		FROM updated r
+		JOIN users u ON u.id = r.user_id
+	`
+
+	var review Review
+	err := r.db.GetContext(ctx, &review, query, id, hidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
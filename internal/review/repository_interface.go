@@ -0,0 +1,11 @@
+package review
+
+import "context"
+
+type Repository interface {
+	GetVisit(ctx context.Context, bookingID int) (*Visit, error)
+	ReviewExistsForBooking(ctx context.Context, bookingID int) (bool, error)
+	CreateReview(ctx context.Context, gymID, userID, bookingID, rating int, comment string) (*Review, error)
+	GetReviewsByGym(ctx context.Context, gymID int, includeHidden bool) ([]Review, error)
+	SetHidden(ctx context.Context, id int, hidden bool) (*Review, error)
+}
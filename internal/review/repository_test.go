@@ -0,0 +1,83 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReviewMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(sqlxDB)
+
+	closer := func() { sqlxDB.Close() }
+	return repo, mock, closer
+}
+
+func TestGetVisit(t *testing.T) {
+	repo, mock, close := setupReviewMock(t)
+	defer close()
+
+	end := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(`SELECT b.id AS booking_id, b.user_id, b.status, ts.gym_id, ts.end_time FROM bookings b JOIN time_slots ts .* WHERE b.id = \$1`).
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id", "user_id", "status", "gym_id", "end_time"}).
+			AddRow(12, 3, "booked", 1, end))
+
+	visit, err := repo.GetVisit(context.Background(), 12)
+	require.NoError(t, err)
+	require.Equal(t, 3, visit.UserID)
+	require.Equal(t, 1, visit.GymID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReviewsByGym(t *testing.T) {
+	repo, mock, close := setupReviewMock(t)
+	defer close()
+
+	mock.ExpectQuery(`FROM gym_reviews r JOIN users u ON u.id = r.user_id WHERE r.gym_id = \$1 AND \(\$2 OR r.hidden_at IS NULL\)`).
+		WithArgs(1, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "user_id", "booking_id", "rating", "comment", "user_name"}).
+			AddRow(1, 1, 3, 12, 5, "Great", "John"))
+
+	reviews, err := repo.GetReviewsByGym(context.Background(), 1, false)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	require.Equal(t, "John", reviews[0].UserName)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetHidden_NotFound(t *testing.T) {
+	repo, mock, close := setupReviewMock(t)
+	defer close()
+
+	mock.ExpectQuery(`UPDATE gym_reviews SET hidden_at = CASE WHEN \$2 THEN COALESCE\(hidden_at, NOW\(\)\) END WHERE id = \$1`).
+		WithArgs(9, true).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.SetHidden(context.Background(), 9, true)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReview_AlreadyReviewed(t *testing.T) {
+	repo, mock, close := setupReviewMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO gym_reviews \(gym_id, user_id, booking_id, rating, comment\)`).
+		WithArgs(1, 3, 12, 5, "Great").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "unique_review_per_booking"})
+
+	_, err := repo.CreateReview(context.Background(), 1, 3, 12, 5, "Great")
+	require.ErrorIs(t, err, ErrAlreadyReviewed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
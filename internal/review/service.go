@@ -0,0 +1,110 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"fitslot/internal/gym"
+)
+
+// MaxCommentLength caps the length of a review's text, in characters.
+const MaxCommentLength = 2000
+
+var (
+	ErrReviewInvalid    = errors.New("invalid review")
+	ErrNotVisited       = errors.New("no booking at this gym")
+	ErrVisitNotFinished = errors.New("visit has not happened yet")
+	ErrAlreadyReviewed  = errors.New("visit already reviewed")
+	ErrReviewNotFound   = errors.New("review not found")
+)
+
+type Service interface {
+	CreateReview(ctx context.Context, userID, gymID int, req CreateReviewRequest) (*Review, error)
+	GetReviews(ctx context.Context, gymID int, includeHidden bool) ([]Review, error)
+	HideReview(ctx context.Context, id int) (*Review, error)
+	UnhideReview(ctx context.Context, id int) (*Review, error)
+}
+
+type service struct {
+	repo    Repository
+	gymRepo gym.Repository
+}
+
+func NewService(repo Repository, gymRepo gym.Repository) Service {
+	return &service{
+		repo:    repo,
+		gymRepo: gymRepo,
+	}
+}
+
+// CreateReview lets a member rate a gym for one of their past, non-cancelled
+// bookings there. Each booking can be reviewed once.
+func (s *service) CreateReview(ctx context.Context, userID, gymID int, req CreateReviewRequest) (*Review, error) {
+	comment := strings.TrimSpace(req.Comment)
+	if req.Rating < 1 || req.Rating > 5 || len([]rune(comment)) > MaxCommentLength {
+		return nil, ErrReviewInvalid
+	}
+
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	visit, err := s.repo.GetVisit(ctx, req.BookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotVisited
+		}
+		return nil, err
+	}
+	if visit.UserID != userID || visit.GymID != gymID || visit.Status != "booked" {
+		return nil, ErrNotVisited
+	}
+	if visit.EndTime.After(time.Now()) {
+		return nil, ErrVisitNotFinished
+	}
+
+	exists, err := s.repo.ReviewExistsForBooking(ctx, req.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyReviewed
+	}
+
+	return s.repo.CreateReview(ctx, gymID, userID, req.BookingID, req.Rating, comment)
+}
+
+func (s *service) GetReviews(ctx context.Context, gymID int, includeHidden bool) ([]Review, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	reviews, err := s.repo.GetReviewsByGym(ctx, gymID, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []Review{}
+	}
+
+	return reviews, nil
+}
+
+func (s *service) HideReview(ctx context.Context, id int) (*Review, error) {
+	return s.setHidden(ctx, id, true)
+}
+
+func (s *service) UnhideReview(ctx context.Context, id int) (*Review, error) {
+	return s.setHidden(ctx, id, false)
+}
+
+func (s *service) setHidden(ctx context.Context, id int, hidden bool) (*Review, error) {
+	review, err := s.repo.SetHidden(ctx, id, hidden)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReviewNotFound
+	}
+	return review, err
+}
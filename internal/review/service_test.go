@@ -0,0 +1,152 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"fitslot/internal/gym"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetVisit(ctx context.Context, bookingID int) (*Visit, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Visit), args.Error(1)
+}
+
+func (m *MockRepository) ReviewExistsForBooking(ctx context.Context, bookingID int) (bool, error) {
+	args := m.Called(ctx, bookingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateReview(ctx context.Context, gymID, userID, bookingID, rating int, comment string) (*Review, error) {
+	args := m.Called(ctx, gymID, userID, bookingID, rating, comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Review), args.Error(1)
+}
+
+func (m *MockRepository) GetReviewsByGym(ctx context.Context, gymID int, includeHidden bool) ([]Review, error) {
+	args := m.Called(ctx, gymID, includeHidden)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Review), args.Error(1)
+}
+
+func (m *MockRepository) SetHidden(ctx context.Context, id int, hidden bool) (*Review, error) {
+	args := m.Called(ctx, id, hidden)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Review), args.Error(1)
+}
+
+// MockGymRepo only stubs the lookups the review service relies on; calling
+// anything else panics on the nil embedded interface.
+type MockGymRepo struct {
+	mock.Mock
+	gym.Repository
+}
+
+func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Gym), args.Error(1)
+}
+
+func TestService_CreateReview(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	req := CreateReviewRequest{BookingID: 12, Rating: 5, Comment: "  Great equipment  "}
+
+	t.Run("reviews a past visit", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetVisit", mock.Anything, 12).Return(&Visit{BookingID: 12, UserID: 3, GymID: 1, Status: "booked", EndTime: past}, nil)
+		repo.On("ReviewExistsForBooking", mock.Anything, 12).Return(false, nil)
+		repo.On("CreateReview", mock.Anything, 1, 3, 12, 5, "Great equipment").Return(&Review{ID: 1, Rating: 5}, nil)
+
+		service := NewService(repo, gymRepo)
+		review, err := service.CreateReview(context.Background(), 3, 1, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, review.Rating)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects another member's booking", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetVisit", mock.Anything, 12).Return(&Visit{BookingID: 12, UserID: 4, GymID: 1, Status: "booked", EndTime: past}, nil)
+
+		service := NewService(repo, gymRepo)
+		_, err := service.CreateReview(context.Background(), 3, 1, req)
+
+		assert.ErrorIs(t, err, ErrNotVisited)
+		repo.AssertNotCalled(t, "CreateReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an unknown booking", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetVisit", mock.Anything, 12).Return(nil, sql.ErrNoRows)
+
+		service := NewService(repo, gymRepo)
+		_, err := service.CreateReview(context.Background(), 3, 1, req)
+
+		assert.ErrorIs(t, err, ErrNotVisited)
+	})
+
+	t.Run("rejects a visit that has not happened", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetVisit", mock.Anything, 12).Return(&Visit{BookingID: 12, UserID: 3, GymID: 1, Status: "booked", EndTime: time.Now().Add(time.Hour)}, nil)
+
+		service := NewService(repo, gymRepo)
+		_, err := service.CreateReview(context.Background(), 3, 1, req)
+
+		assert.ErrorIs(t, err, ErrVisitNotFinished)
+	})
+
+	t.Run("rejects a second review of the same visit", func(t *testing.T) {
+		repo, gymRepo := new(MockRepository), new(MockGymRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		repo.On("GetVisit", mock.Anything, 12).Return(&Visit{BookingID: 12, UserID: 3, GymID: 1, Status: "booked", EndTime: past}, nil)
+		repo.On("ReviewExistsForBooking", mock.Anything, 12).Return(true, nil)
+
+		service := NewService(repo, gymRepo)
+		_, err := service.CreateReview(context.Background(), 3, 1, req)
+
+		assert.ErrorIs(t, err, ErrAlreadyReviewed)
+	})
+
+	t.Run("rejects an out of range rating", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockGymRepo))
+		_, err := service.CreateReview(context.Background(), 3, 1, CreateReviewRequest{BookingID: 12, Rating: 6})
+
+		assert.ErrorIs(t, err, ErrReviewInvalid)
+	})
+}
+
+func TestService_HideReview_NotFound(t *testing.T) {
+	repo := new(MockRepository)
+	repo.On("SetHidden", mock.Anything, 9, true).Return(nil, sql.ErrNoRows)
+
+	service := NewService(repo, new(MockGymRepo))
+	_, err := service.HideReview(context.Background(), 9)
+
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+}
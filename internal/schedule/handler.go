@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"fitslot/internal/api"
+	"fitslot/internal/gym"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// @Summary      Create a schedule template
+// @Description  Admin-only: add a weekly recurring slot (weekday 0 = Sunday) to a gym's schedule
+// @Tags         admin,schedule
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body schedule.CreateTemplateRequest true "Template payload"
+// @Success      201 {object} schedule.Template
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/schedule-templates [post]
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(c.Request.Context(), gymID, req)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case ErrTemplateInvalid:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid schedule template data"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to create schedule template"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// @Summary      List schedule templates
+// @Tags         admin,schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {array} schedule.Template
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/schedule-templates [get]
+func (h *Handler) ListTemplates(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	templates, err := h.service.GetTemplates(c.Request.Context(), gymID)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch schedule templates"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// @Summary      Delete a schedule template
+// @Description  Admin-only: stop generating slots from a template. Already generated slots are kept.
+// @Tags         admin,schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        templateID path int true "Template ID"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/schedule-templates/{templateID} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("templateID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid template ID"})
+		return
+	}
+
+	if err := h.service.DeleteTemplate(c.Request.Context(), templateID); err != nil {
+		switch err {
+		case ErrTemplateNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Schedule template not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to delete schedule template"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "Schedule template deleted"})
+}
+
+// @Summary      Generate time slots from templates
+// @Description  Admin-only: materialize slots for the next N weeks. Existing slots are skipped, so the call is safe to repeat.
+// @Tags         admin,schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        weeks query int false "Horizon in weeks" default(4)
+// @Success      200 {object} schedule.GenerateResult
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/schedule-templates/generate [post]
+func (h *Handler) GenerateSlots(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(DefaultHorizonWeeks)))
+	if err != nil || weeks <= 0 || weeks > 52 {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "weeks must be between 1 and 52"})
+		return
+	}
+
+	result, err := h.service.GenerateSlots(c.Request.Context(), gymID, time.Now(), weeks)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to generate time slots"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
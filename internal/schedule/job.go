@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"fitslot/internal/gym"
+	"fitslot/internal/job"
+	"fitslot/internal/logger"
+)
+
+// Job periodically keeps every gym's schedule filled for the rolling horizon.
+type Job struct {
+	service  Service
+	gymRepo  gym.Repository
+	interval time.Duration
+	weeks    int
+}
+
+func NewJob(service Service, gymRepo gym.Repository, interval time.Duration, weeks int) *Job {
+	return &Job{
+		service:  service,
+		gymRepo:  gymRepo,
+		interval: interval,
+		weeks:    weeks,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) {
+	job.Run(ctx, "Schedule generator", j.interval, j.runOnce)
+}
+
+func (j *Job) runOnce(ctx context.Context) {
+	gyms, err := j.gymRepo.GetAllGyms(ctx)
+	if err != nil {
+		logger.Errorf("Schedule generator: failed to load gyms: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, g := range gyms {
+		result, err := j.service.GenerateSlots(ctx, g.ID, now, j.weeks)
+		if err != nil {
+			logger.Errorf("Schedule generator: gym %d: %v", g.ID, err)
+			continue
+		}
+		if result.Created > 0 {
+			logger.Infof("Schedule generator: gym %d: created %d slots, skipped %d", g.ID, result.Created, result.Skipped)
+		}
+	}
+}
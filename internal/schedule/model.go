@@ -0,0 +1,33 @@
+package schedule
+
+import "time"
+
+// Template describes a recurring weekly slot that the generator turns into
+// concrete time_slots rows.
+type Template struct {
+	ID              int       `db:"id" json:"id"`
+	GymID           int       `db:"gym_id" json:"gym_id"`
+	Weekday         int       `db:"weekday" json:"weekday" example:"1"`
+	StartTime       string    `db:"start_time" json:"start_time" example:"18:00"`
+	DurationMinutes int       `db:"duration_minutes" json:"duration_minutes" example:"60"`
+	Capacity        int       `db:"capacity" json:"capacity"`
+	PriceCents      int64     `db:"price_cents" json:"price_cents"`
+	Active          bool      `db:"active" json:"active"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+}
+
+type CreateTemplateRequest struct {
+	Weekday         *int   `json:"weekday" binding:"required,min=0,max=6" example:"1"`
+	StartTime       string `json:"start_time" binding:"required" example:"18:00"`
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=1" example:"60"`
+	Capacity        int    `json:"capacity" binding:"required,min=1"`
+	PriceCents      *int64 `json:"price_cents,omitempty"`
+}
+
+type GenerateResult struct {
+	GymID   int       `json:"gym_id"`
+	From    time.Time `json:"from"`
+	Until   time.Time `json:"until"`
+	Created int       `json:"created"`
+	Skipped int       `json:"skipped"`
+}
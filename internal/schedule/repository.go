@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrTemplateNotFound = errors.New("schedule template not found")
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateTemplate(ctx context.Context, t Template) (*Template, error) {
+	query := `
+		INSERT INTO schedule_templates (gym_id, weekday, start_time, duration_minutes, capacity, price_cents)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, gym_id, weekday, to_char(start_time, 'HH24:MI') AS start_time, duration_minutes, capacity, price_cents, active, created_at
+	`
+
+	var created Template
+	err := r.db.GetContext(ctx, &created, query, t.GymID, t.Weekday, t.StartTime, t.DurationMinutes, t.Capacity, t.PriceCents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *repository) GetTemplatesByGym(ctx context.Context, gymID int, onlyActive bool) ([]Template, error) {
+	query := `
+		SELECT id, gym_id, weekday, to_char(start_time, 'HH24:MI') AS start_time, duration_minutes, capacity, price_cents, active, created_at
+		FROM schedule_templates
+		WHERE gym_id = $1
+	`
+	if onlyActive {
+		query += " AND active = TRUE"
+	}
+	query += " ORDER BY weekday ASC, start_time ASC"
+
+	var templates []Template
+	err := r.db.SelectContext(ctx, &templates, query, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (r *repository) DeleteTemplate(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM schedule_templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+
+	return nil
+}
+
+// CreateSlotFromTemplate inserts a slot for the template unless the gym
+// already has a slot starting at the same time or is closed during it. It
+// reports whether a new row was written, which keeps repeated generator
+// runs idempotent.
+func (r *repository) CreateSlotFromTemplate(ctx context.Context, t Template, startTime, endTime time.Time) (bool, error) {
+	query := `
+		INSERT INTO time_slots (gym_id, start_time, end_time, capacity, price_cents, template_id)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE NOT EXISTS (
+			SELECT 1 FROM time_slots
+			WHERE gym_id = $1 AND start_time = $2
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM gym_closures
+			WHERE gym_id = $1 AND starts_at < $3 AND ends_at > $2
+		)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, t.GymID, startTime, endTime, t.Capacity, t.PriceCents, t.ID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
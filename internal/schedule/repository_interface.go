@@ -0,0 +1,13 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	CreateTemplate(ctx context.Context, t Template) (*Template, error)
+	GetTemplatesByGym(ctx context.Context, gymID int, onlyActive bool) ([]Template, error)
+	DeleteTemplate(ctx context.Context, id int) error
+	CreateSlotFromTemplate(ctx context.Context, t Template, startTime, endTime time.Time) (bool, error)
+}
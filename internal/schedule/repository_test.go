@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func setupScheduleMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(sqlxDB)
+
+	closer := func() { sqlxDB.Close() }
+	return repo, mock, closer
+}
+
+func TestCreateTemplate(t *testing.T) {
+	repo, mock, close := setupScheduleMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO schedule_templates.*`).
+		WithArgs(1, 2, "18:00", 60, 15, int64(1200)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "weekday", "start_time", "duration_minutes", "capacity", "price_cents", "active", "created_at"}).
+			AddRow(3, 1, 2, "18:00", 60, 15, 1200, true, time.Now()))
+
+	created, err := repo.CreateTemplate(context.Background(), Template{
+		GymID:           1,
+		Weekday:         2,
+		StartTime:       "18:00",
+		DurationMinutes: 60,
+		Capacity:        15,
+		PriceCents:      1200,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, created.ID)
+	require.Equal(t, "18:00", created.StartTime)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTemplatesByGym_OnlyActive(t *testing.T) {
+	repo, mock, close := setupScheduleMock(t)
+	defer close()
+
+	mock.ExpectQuery(`FROM schedule_templates WHERE gym_id = \$1 AND active = TRUE ORDER BY weekday ASC, start_time ASC`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "gym_id", "weekday", "start_time", "duration_minutes", "capacity", "price_cents", "active", "created_at"}).
+			AddRow(1, 1, 1, "07:00", 60, 10, 1000, true, time.Now()).
+			AddRow(2, 1, 3, "19:30", 45, 12, 1500, true, time.Now()))
+
+	templates, err := repo.GetTemplatesByGym(context.Background(), 1, true)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTemplate_NotFound(t *testing.T) {
+	repo, mock, close := setupScheduleMock(t)
+	defer close()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schedule_templates WHERE id = $1")).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteTemplate(context.Background(), 42)
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestCreateSlotFromTemplate_SkipsExisting(t *testing.T) {
+	repo, mock, close := setupScheduleMock(t)
+	defer close()
+
+	start := time.Date(2025, 3, 3, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tpl := Template{ID: 5, GymID: 1, Capacity: 10, PriceCents: 1000}
+
+	mock.ExpectExec(`INSERT INTO time_slots .* WHERE NOT EXISTS`).
+		WithArgs(1, start, end, 10, int64(1000), 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := repo.CreateSlotFromTemplate(context.Background(), tpl, start, end)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
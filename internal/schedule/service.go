@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fitslot/internal/gym"
+)
+
+// DefaultHorizonWeeks is how far ahead slots are materialized when the
+// caller does not ask for a specific horizon.
+const DefaultHorizonWeeks = 4
+
+var ErrTemplateInvalid = errors.New("invalid schedule template")
+
+type Service interface {
+	CreateTemplate(ctx context.Context, gymID int, req CreateTemplateRequest) (*Template, error)
+	GetTemplates(ctx context.Context, gymID int) ([]Template, error)
+	DeleteTemplate(ctx context.Context, id int) error
+	GenerateSlots(ctx context.Context, gymID int, from time.Time, weeks int) (*GenerateResult, error)
+}
+
+type service struct {
+	repo         Repository
+	gymRepo      gym.Repository
+	availability *gym.AvailabilityCache
+}
+
+func NewService(repo Repository, gymRepo gym.Repository, availability *gym.AvailabilityCache) Service {
+	return &service{
+		repo:         repo,
+		gymRepo:      gymRepo,
+		availability: availability,
+	}
+}
+
+func (s *service) CreateTemplate(ctx context.Context, gymID int, req CreateTemplateRequest) (*Template, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	if req.Weekday == nil || *req.Weekday < 0 || *req.Weekday > 6 {
+		return nil, ErrTemplateInvalid
+	}
+	if _, err := time.Parse("15:04", req.StartTime); err != nil {
+		return nil, ErrTemplateInvalid
+	}
+	if req.DurationMinutes <= 0 || req.Capacity <= 0 {
+		return nil, ErrTemplateInvalid
+	}
+
+	priceCents := gym.DefaultSlotPriceCents
+	if req.PriceCents != nil {
+		if *req.PriceCents < 0 {
+			return nil, ErrTemplateInvalid
+		}
+		priceCents = *req.PriceCents
+	}
+
+	return s.repo.CreateTemplate(ctx, Template{
+		GymID:           gymID,
+		Weekday:         *req.Weekday,
+		StartTime:       req.StartTime,
+		DurationMinutes: req.DurationMinutes,
+		Capacity:        req.Capacity,
+		PriceCents:      priceCents,
+	})
+}
+
+func (s *service) GetTemplates(ctx context.Context, gymID int) ([]Template, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	return s.repo.GetTemplatesByGym(ctx, gymID, false)
+}
+
+func (s *service) DeleteTemplate(ctx context.Context, id int) error {
+	return s.repo.DeleteTemplate(ctx, id)
+}
+
+// GenerateSlots materializes time slots for every active template of the gym
+// from the start of the day of from up to weeks*7 days ahead. Slots that
+// would start before from, that already exist or that fall into a gym
+// closure are skipped.
+//
+// Days and template start times are wall-clock times in the gym's time zone,
+// so a 09:00 class stays at 09:00 across DST changes. A start time that does
+// not exist on a given day, because clocks jump past it, is skipped.
+func (s *service) GenerateSlots(ctx context.Context, gymID int, from time.Time, weeks int) (*GenerateResult, error) {
+	g, err := s.gymRepo.GetGymByID(ctx, gymID)
+	if err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+	from = from.In(g.Zone())
+
+	if weeks <= 0 {
+		weeks = DefaultHorizonWeeks
+	}
+
+	templates, err := s.repo.GetTemplatesByGym(ctx, gymID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	until := day.AddDate(0, 0, weeks*7)
+
+	result := &GenerateResult{
+		GymID: gymID,
+		From:  from,
+		Until: until,
+	}
+
+	// Slots created before a failure are still new, so the cached
+	// availability is invalidated either way.
+	var createdTimes []time.Time
+	defer func() {
+		if len(createdTimes) > 0 {
+			s.availability.Invalidate(ctx, gymID, createdTimes...)
+		}
+	}()
+
+	for ; day.Before(until); day = day.AddDate(0, 0, 1) {
+		for _, t := range templates {
+			if t.Weekday != int(day.Weekday()) {
+				continue
+			}
+
+			clock, err := time.Parse("15:04", t.StartTime)
+			if err != nil {
+				return nil, err
+			}
+
+			wall := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+			startTime, ok := gym.LocalTime(wall, day.Location())
+			if !ok {
+				result.Skipped++
+				continue
+			}
+			if startTime.Before(from) {
+				continue
+			}
+			endTime := startTime.Add(time.Duration(t.DurationMinutes) * time.Minute)
+
+			created, err := s.repo.CreateSlotFromTemplate(ctx, t, startTime, endTime)
+			if err != nil {
+				return nil, err
+			}
+
+			if created {
+				createdTimes = append(createdTimes, startTime)
+				result.Created++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+
+	return result, nil
+}
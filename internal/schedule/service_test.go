@@ -0,0 +1,191 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fitslot/internal/gym"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateTemplate(ctx context.Context, t Template) (*Template, error) {
+	args := m.Called(ctx, t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Template), args.Error(1)
+}
+
+func (m *MockRepository) GetTemplatesByGym(ctx context.Context, gymID int, onlyActive bool) ([]Template, error) {
+	args := m.Called(ctx, gymID, onlyActive)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Template), args.Error(1)
+}
+
+func (m *MockRepository) DeleteTemplate(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) CreateSlotFromTemplate(ctx context.Context, t Template, startTime, endTime time.Time) (bool, error) {
+	args := m.Called(ctx, t, startTime, endTime)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockGymRepo only stubs the gym lookups the schedule service relies on;
+// calling anything else panics on the nil embedded interface.
+type MockGymRepo struct {
+	mock.Mock
+	gym.Repository
+}
+
+func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Gym), args.Error(1)
+}
+
+func TestService_CreateTemplate(t *testing.T) {
+	weekday := 1
+	badWeekday := 7
+
+	tests := []struct {
+		name        string
+		req         CreateTemplateRequest
+		setupMock   func(*MockRepository, *MockGymRepo)
+		expectedErr error
+	}{
+		{
+			name: "successful creation with default price",
+			req:  CreateTemplateRequest{Weekday: &weekday, StartTime: "18:00", DurationMinutes: 60, Capacity: 10},
+			setupMock: func(r *MockRepository, g *MockGymRepo) {
+				g.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+				r.On("CreateTemplate", mock.Anything, Template{
+					GymID:           1,
+					Weekday:         1,
+					StartTime:       "18:00",
+					DurationMinutes: 60,
+					Capacity:        10,
+					PriceCents:      gym.DefaultSlotPriceCents,
+				}).Return(&Template{ID: 1}, nil)
+			},
+		},
+		{
+			name: "gym not found",
+			req:  CreateTemplateRequest{Weekday: &weekday, StartTime: "18:00", DurationMinutes: 60, Capacity: 10},
+			setupMock: func(r *MockRepository, g *MockGymRepo) {
+				g.On("GetGymByID", mock.Anything, 1).Return(nil, errors.New("not found"))
+			},
+			expectedErr: gym.ErrGymNotFound,
+		},
+		{
+			name: "weekday out of range",
+			req:  CreateTemplateRequest{Weekday: &badWeekday, StartTime: "18:00", DurationMinutes: 60, Capacity: 10},
+			setupMock: func(r *MockRepository, g *MockGymRepo) {
+				g.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+			},
+			expectedErr: ErrTemplateInvalid,
+		},
+		{
+			name: "malformed start time",
+			req:  CreateTemplateRequest{Weekday: &weekday, StartTime: "6pm", DurationMinutes: 60, Capacity: 10},
+			setupMock: func(r *MockRepository, g *MockGymRepo) {
+				g.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+			},
+			expectedErr: ErrTemplateInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			gymRepo := new(MockGymRepo)
+			tt.setupMock(repo, gymRepo)
+
+			service := NewService(repo, gymRepo, nil)
+			tpl, err := service.CreateTemplate(context.Background(), 1, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, tpl)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, tpl)
+			}
+			repo.AssertExpectations(t)
+			gymRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GenerateSlots(t *testing.T) {
+	repo := new(MockRepository)
+	gymRepo := new(MockGymRepo)
+
+	// Wednesday 2025-03-05 12:00 UTC.
+	from := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	monday := Template{ID: 1, GymID: 1, Weekday: int(time.Monday), StartTime: "18:00", DurationMinutes: 60, Capacity: 10, PriceCents: 1000}
+	wednesday := Template{ID: 2, GymID: 1, Weekday: int(time.Wednesday), StartTime: "09:00", DurationMinutes: 90, Capacity: 5, PriceCents: 1500}
+
+	gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+	repo.On("GetTemplatesByGym", mock.Anything, 1, true).Return([]Template{monday, wednesday}, nil)
+
+	// Today's Wednesday 09:00 is already in the past and must be skipped.
+	firstMonday := time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)
+	secondMonday := firstMonday.AddDate(0, 0, 7)
+	nextWednesday := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+
+	repo.On("CreateSlotFromTemplate", mock.Anything, monday, firstMonday, firstMonday.Add(time.Hour)).Return(true, nil)
+	repo.On("CreateSlotFromTemplate", mock.Anything, monday, secondMonday, secondMonday.Add(time.Hour)).Return(false, nil)
+	repo.On("CreateSlotFromTemplate", mock.Anything, wednesday, nextWednesday, nextWednesday.Add(90*time.Minute)).Return(true, nil)
+
+	service := NewService(repo, gymRepo, nil)
+	result, err := service.GenerateSlots(context.Background(), 1, from, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, time.Date(2025, 3, 19, 0, 0, 0, 0, time.UTC), result.Until)
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "CreateSlotFromTemplate", 3)
+}
+
+func TestService_GenerateSlots_GymTimezone(t *testing.T) {
+	repo := new(MockRepository)
+	gymRepo := new(MockGymRepo)
+
+	// Berlin switches to summer time on Sunday 2030-03-31 at 02:00.
+	from := time.Date(2030, 3, 29, 12, 0, 0, 0, time.UTC)
+	morning := Template{ID: 1, GymID: 1, Weekday: int(time.Sunday), StartTime: "09:00", DurationMinutes: 60, Capacity: 10}
+	night := Template{ID: 2, GymID: 1, Weekday: int(time.Sunday), StartTime: "02:30", DurationMinutes: 60, Capacity: 10}
+
+	gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1, Timezone: "Europe/Berlin"}, nil)
+	repo.On("GetTemplatesByGym", mock.Anything, 1, true).Return([]Template{morning, night}, nil)
+
+	// 09:00 CEST is 07:00 UTC; 02:30 does not exist that day.
+	start := time.Date(2030, 3, 31, 7, 0, 0, 0, time.UTC)
+	repo.On("CreateSlotFromTemplate", mock.Anything, morning,
+		mock.MatchedBy(start.Equal), mock.MatchedBy(start.Add(time.Hour).Equal),
+	).Return(true, nil)
+
+	service := NewService(repo, gymRepo, nil)
+	result, err := service.GenerateSlots(context.Background(), 1, from, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, "Europe/Berlin", result.Until.Location().String())
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "CreateSlotFromTemplate", 1)
+}
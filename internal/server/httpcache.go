@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cacheWriter holds back the response of a handler so that its ETag can be
+// computed from the body before anything is sent.
+type cacheWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *cacheWriter) WriteHeaderNow() {}
+
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *cacheWriter) Status() int {
+	return w.status
+}
+
+func (w *cacheWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *cacheWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// HTTPCacheMiddleware lets clients and shared caches keep successful GET
+// responses for maxAge. Responses carry an ETag derived from the body, so a
+// revalidating client whose copy is still current gets 304 Not Modified
+// without the body. Other responses are marked as not cacheable.
+func HTTPCacheMiddleware(maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &cacheWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		header := original.Header()
+		if writer.status != http.StatusOK {
+			header.Set("Cache-Control", "no-store")
+			original.WriteHeader(writer.status)
+			_, _ = original.Write(writer.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", cacheControl)
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		original.WriteHeader(http.StatusOK)
+		_, _ = original.Write(writer.body.Bytes())
+	}
+}
+
+// etagMatches applies the weak comparison of If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"fitslot/internal/auth"
 	"fitslot/internal/booking"
+	"fitslot/internal/class"
 	"fitslot/internal/config"
 	"fitslot/internal/email"
 	"fitslot/internal/gym"
+	"fitslot/internal/payment"
+	"fitslot/internal/photo"
+	"fitslot/internal/review"
+	"fitslot/internal/schedule"
+	"fitslot/internal/staff"
+	"fitslot/internal/storage"
 	"fitslot/internal/subscription"
 	"fitslot/internal/user"
 	"fitslot/internal/wallet"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -24,7 +32,7 @@ type Server struct {
 	httpServer *http.Server
 }
 
-func New(db *sqlx.DB, cfg *config.Config, emailService *email.Service) *Server {
+func New(db *sqlx.DB, cfg *config.Config, emailService *email.Service, availability *gym.AvailabilityCache) *Server {
 	router := gin.Default()
 	router.Use(MetricsMiddleware())
 	router.Use(RequestLoggingMiddleware())
@@ -32,29 +40,63 @@ func New(db *sqlx.DB, cfg *config.Config, emailService *email.Service) *Server {
 	router.Use(corsMiddleware())
 
 	userRepo := user.NewRepository(db)
-	gymRepo := gym.NewRepository(db)
+	gymRepo := gym.NewCachedRepository(gym.NewRepository(db), availability)
 	bookingRepo := booking.NewRepository(db)
 	walletRepo := wallet.NewRepository(db)
+	paymentRepo := payment.NewRepository(db)
 	subscriptionRepo := subscription.NewRepository(db)
+	scheduleRepo := schedule.NewRepository(db)
+	classRepo := class.NewRepository(db)
+	staffRepo := staff.NewRepository(db)
+	reviewRepo := review.NewRepository(db)
+	photoRepo := photo.NewRepository(db)
+	mediaStorage := storage.NewLocal(cfg.UploadDir, cfg.MediaURLPrefix)
 
 	userService := user.NewService(userRepo, cfg.JWTSecret)
 	gymService := gym.NewService(gymRepo)
+	scheduleService := schedule.NewService(scheduleRepo, gymRepo, availability)
+	classService := class.NewService(classRepo, gymRepo)
+	staffService := staff.NewService(staffRepo, gymRepo, userRepo)
+	reviewService := review.NewService(reviewRepo, gymRepo)
+	photoService := photo.NewService(photoRepo, gymRepo, mediaStorage)
+	walletService := wallet.NewService(walletRepo, userRepo, cfg.WalletTransferDailyLimitCents)
+	// Without a provider, payments are refused rather than faked.
+	var paymentProvider payment.Provider
+	if cfg.PaymentProvider == payment.FakeProviderName {
+		paymentProvider = payment.NewFakeProvider(cfg.FakePaymentWebhookSecret)
+	}
+	paymentService := payment.NewService(paymentRepo, walletRepo, paymentProvider, emailService)
+	// Charges for bookings and subscriptions collect the auto top-ups they
+	// start.
+	chargingWallets := payment.WithAutoTopUp(walletRepo, paymentService)
 	bookingService := booking.NewService(
 		bookingRepo,
 		gymRepo,
 		subscriptionRepo,
-		walletRepo,
+		chargingWallets,
 		userRepo,
 		emailService,
+		availability,
 	)
 
 	userHandler := user.NewHandler(userService, cfg.JWTSecret)
 	gymHandler := gym.NewHandler(gymService)
+	scheduleHandler := schedule.NewHandler(scheduleService)
+	classHandler := class.NewHandler(classService)
+	staffHandler := staff.NewHandler(staffService)
+	reviewHandler := review.NewHandler(reviewService)
+	photoHandler := photo.NewHandler(photoService)
 	bookingHandler := booking.NewHandler(bookingService)
-	walletHandler := wallet.NewHandler(walletRepo)
-	subscriptionHandler := subscription.NewHandler(subscriptionRepo, walletRepo)
+	walletHandler := wallet.NewHandler(walletRepo, walletService)
+	paymentHandler := payment.NewHandler(paymentService, walletRepo)
+	subscriptionHandler := subscription.NewHandler(subscriptionRepo, chargingWallets)
 	router.GET("/metrics", Metrics())
 
+	// Uploaded media is served by the app unless it lives behind a CDN
+	if strings.HasPrefix(cfg.MediaURLPrefix, "/") {
+		router.Static(cfg.MediaURLPrefix, cfg.UploadDir)
+	}
+
 	public := router.Group("/auth")
 	{
 		public.POST("/register", userHandler.Register)
@@ -62,34 +104,110 @@ func New(db *sqlx.DB, cfg *config.Config, emailService *email.Service) *Server {
 		public.POST("/refresh", userHandler.RefreshToken)
 	}
 
+	// Called by payment providers, authenticated by their signature
+	router.POST("/payments/webhook/:provider", paymentHandler.Webhook)
+
+	// Read-only catalogue for the marketing site. Booking stays behind
+	// authentication.
+	catalogue := router.Group("/public")
+	catalogue.Use(RateLimitMiddleware(float64(cfg.PublicRateLimitRPS), cfg.PublicRateLimitBurst))
+	catalogue.Use(HTTPCacheMiddleware(cfg.PublicCacheMaxAge))
+	{
+		catalogue.GET("/gyms", gymHandler.ListPublicGyms)
+		catalogue.GET("/gyms/:gymID", photoHandler.GetPublicGymProfile)
+		catalogue.GET("/gyms/:gymID/slots", gymHandler.ListPublicTimeSlots)
+	}
+
 	authMiddleware := auth.AuthMiddleware(cfg.JWTSecret)
 	protected := router.Group("/")
 	protected.Use(authMiddleware)
 	{
 		protected.GET("/me", userHandler.GetMe)
 		protected.GET("/gyms", gymHandler.ListGyms)
+		protected.GET("/gyms/:gymID", photoHandler.GetGymProfile)
 		protected.GET("/gyms/:gymID/slots", gymHandler.ListTimeSlots)
+		protected.GET("/gyms/:gymID/closures", gymHandler.ListClosures)
+		protected.GET("/gyms/:gymID/rooms", gymHandler.ListRooms)
+		protected.GET("/gyms/:gymID/occupancy", gymHandler.GetOccupancy)
+		protected.GET("/gyms/:gymID/reviews", reviewHandler.ListReviews)
+		protected.POST("/gyms/:gymID/reviews", reviewHandler.CreateReview)
+		protected.GET("/class-types", classHandler.ListClassTypes)
+		protected.GET("/instructors", classHandler.ListInstructors)
+		protected.GET("/instructors/:instructorID", classHandler.GetInstructor)
+		protected.GET("/slots/search", gymHandler.SearchSlots)
 		protected.POST("/slots/:slotID/book", bookingHandler.BookSlot)
 		protected.POST("/bookings/:bookingID/cancel", bookingHandler.CancelBooking)
 		protected.GET("/bookings", bookingHandler.ListMyBookings)
 		protected.GET("/wallet", walletHandler.GetBalance)
-		protected.POST("/wallet/topup", walletHandler.TopUp)
+		protected.GET("/wallets", walletHandler.ListWallets)
+		protected.POST("/wallet/topup", paymentHandler.TopUp)
+		protected.POST("/wallet/payment-methods", paymentHandler.SavePaymentMethod)
+		protected.GET("/wallet/payment-methods", paymentHandler.ListPaymentMethods)
+		protected.DELETE("/wallet/payment-methods/:methodID", paymentHandler.DeletePaymentMethod)
+		protected.GET("/wallet/auto-topup", paymentHandler.ListAutoTopUps)
+		protected.PUT("/wallet/auto-topup", paymentHandler.SetAutoTopUp)
+		protected.DELETE("/wallet/auto-topup/:currency", paymentHandler.DeleteAutoTopUp)
+		protected.POST("/wallet/transfer", walletHandler.Transfer)
 		protected.GET("/wallet/transactions", walletHandler.ListTransactions)
+		protected.GET("/wallet/statement", walletHandler.GetStatement)
+		protected.GET("/wallet/promo-credits", walletHandler.ListPromoCredits)
+		protected.GET("/exchange-rates", walletHandler.ListExchangeRates)
 		protected.POST("/subscriptions", subscriptionHandler.Create)
 		protected.GET("/subscriptions", subscriptionHandler.ListMy)
 		protected.GET("/subscriptions/plans", subscriptionHandler.ListPlans)
 	}
 
-	adminMiddleware := auth.RequireRole("admin")
+	// Global admins may do everything below. Gym staff are authorized per
+	// route against the gym the addressed gym, slot, closure or template
+	// belongs to.
+	adminMiddleware := auth.RequireRole(auth.SuperuserRole)
+	gymManager := auth.RequireGymRole(staffRepo, staff.RoleManager)
+	gymDesk := auth.RequireGymRole(staffRepo, staff.RoleManager, staff.RoleFrontDesk)
+	gymStaff := auth.RequireGymRole(staffRepo, staff.RoleManager, staff.RoleFrontDesk, staff.RoleInstructor)
+
 	admin := router.Group("/admin")
-	admin.Use(authMiddleware, adminMiddleware)
+	admin.Use(authMiddleware)
 	{
-		admin.POST("/gyms", gymHandler.CreateGym)
-		admin.GET("/gyms", gymHandler.ListGyms)
-		admin.POST("/gyms/:gymID/slots", gymHandler.CreateTimeSlot)
-		admin.GET("/gyms/:gymID/slots", gymHandler.ListTimeSlots)
-		admin.GET("/slots/:slotID/bookings", bookingHandler.ListBookingsBySlot)
-		admin.GET("/gyms/:gymID/bookings", bookingHandler.ListBookingsByGym)
+		admin.POST("/gyms", adminMiddleware, gymHandler.CreateGym)
+		admin.GET("/gyms", adminMiddleware, gymHandler.ListGyms)
+		admin.POST("/class-types", adminMiddleware, classHandler.CreateClassType)
+		admin.POST("/instructors", adminMiddleware, classHandler.CreateInstructor)
+		admin.PUT("/instructors/:instructorID", adminMiddleware, classHandler.UpdateInstructor)
+
+		admin.POST("/gyms/:gymID/slots", gymManager, gymHandler.CreateTimeSlot)
+		admin.POST("/gyms/:gymID/slots/import", gymManager, gymHandler.ImportTimeSlots)
+		admin.GET("/gyms/:gymID/slots", gymStaff, gymHandler.ListTimeSlots)
+		admin.POST("/gyms/:gymID/closures", gymManager, gymHandler.CreateClosure)
+		admin.GET("/gyms/:gymID/closures", gymStaff, gymHandler.ListClosures)
+		admin.DELETE("/closures/:closureID", gymManager, gymHandler.DeleteClosure)
+		admin.POST("/closures/:closureID/cancel-slots", gymManager, bookingHandler.CancelClosureSlots)
+		admin.POST("/gyms/:gymID/rooms", gymManager, gymHandler.CreateRoom)
+		admin.GET("/gyms/:gymID/rooms", gymStaff, gymHandler.ListRooms)
+		admin.POST("/gyms/:gymID/schedule-templates", gymManager, scheduleHandler.CreateTemplate)
+		admin.GET("/gyms/:gymID/schedule-templates", gymStaff, scheduleHandler.ListTemplates)
+		admin.POST("/gyms/:gymID/schedule-templates/generate", gymManager, scheduleHandler.GenerateSlots)
+		admin.DELETE("/schedule-templates/:templateID", gymManager, scheduleHandler.DeleteTemplate)
+		admin.PATCH("/slots/:slotID", gymManager, bookingHandler.UpdateTimeSlot)
+		admin.DELETE("/slots/:slotID", gymManager, bookingHandler.DeleteTimeSlot)
+		admin.GET("/slots/:slotID/bookings", gymStaff, bookingHandler.ListBookingsBySlot)
+		admin.GET("/gyms/:gymID/bookings", gymDesk, bookingHandler.ListBookingsByGym)
+		admin.POST("/gyms/:gymID/staff", gymManager, staffHandler.AssignStaff)
+		admin.GET("/gyms/:gymID/staff", gymManager, staffHandler.ListStaff)
+		admin.DELETE("/gyms/:gymID/staff/:userID", gymManager, staffHandler.RemoveStaff)
+		admin.POST("/gyms/:gymID/photos", gymManager, photoHandler.UploadPhoto)
+		admin.PUT("/gyms/:gymID/photos/order", gymManager, photoHandler.ReorderPhotos)
+		admin.DELETE("/photos/:photoID", gymManager, photoHandler.DeletePhoto)
+		admin.GET("/gyms/:gymID/reviews", adminMiddleware, reviewHandler.ListReviews)
+		admin.POST("/reviews/:reviewID/hide", adminMiddleware, reviewHandler.HideReview)
+		admin.POST("/reviews/:reviewID/unhide", adminMiddleware, reviewHandler.UnhideReview)
+		admin.GET("/ledger/accounts", adminMiddleware, walletHandler.ListAccountBalances)
+		admin.GET("/ledger/check", adminMiddleware, walletHandler.CheckLedger)
+		admin.GET("/wallets/reconciliation", adminMiddleware, walletHandler.Reconcile)
+		admin.POST("/wallets/:walletID/reconcile", adminMiddleware, walletHandler.CorrectWallet)
+		admin.POST("/users/:userID/wallet/adjust", adminMiddleware, walletHandler.AdjustWallet)
+		admin.POST("/users/:userID/wallet/promo-credit", adminMiddleware, walletHandler.GrantPromoCredit)
+		admin.POST("/wallet/transactions/:txID/refund", adminMiddleware, walletHandler.RefundTransaction)
+		admin.PUT("/exchange-rates/:base/:quote", adminMiddleware, walletHandler.SetExchangeRate)
 	}
 
 	SetupSwagger(router)
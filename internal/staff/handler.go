@@ -0,0 +1,150 @@
+package staff
+
+import (
+	"net/http"
+	"strconv"
+
+	"fitslot/internal/api"
+	"fitslot/internal/auth"
+	"fitslot/internal/gym"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func isAdmin(c *gin.Context) bool {
+	role, _ := c.Get("user_role")
+	return role == auth.SuperuserRole
+}
+
+// @Summary      Assign a staff role
+// @Description  Give a user a role at a gym (manager, front_desk or instructor). Gym managers can assign front_desk and instructor; only admins can assign managers.
+// @Tags         admin,staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        request body staff.AssignStaffRequest true "Staff payload"
+// @Success      200 {object} staff.StaffMember
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/staff [post]
+func (h *Handler) AssignStaff(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	var req AssignStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	member, err := h.service.AssignStaff(c.Request.Context(), gymID, req, isAdmin(c))
+	if err != nil {
+		switch err {
+		case ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Role must be manager, front_desk or instructor"})
+		case ErrRoleForbidden:
+			c.JSON(http.StatusForbidden, api.ErrorResponse{Error: "Only admins can assign or change managers"})
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		case ErrUserNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to assign staff role"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// @Summary      List gym staff
+// @Tags         admin,staff
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Success      200 {array} staff.StaffMember
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/staff [get]
+func (h *Handler) ListStaff(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	members, err := h.service.GetStaff(c.Request.Context(), gymID)
+	if err != nil {
+		switch err {
+		case gym.ErrGymNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Gym not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to fetch staff"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// @Summary      Remove a staff member
+// @Description  Revoke a user's role at a gym. Only admins can remove managers.
+// @Tags         admin,staff
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gymID path int true "Gym ID"
+// @Param        userID path int true "User ID"
+// @Success      200 {object} api.MessageResponse
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/gyms/{gymID}/staff/{userID} [delete]
+func (h *Handler) RemoveStaff(c *gin.Context) {
+	gymID, err := strconv.Atoi(c.Param("gymID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid gym ID"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.RemoveStaff(c.Request.Context(), gymID, userID, isAdmin(c)); err != nil {
+		switch err {
+		case ErrRoleForbidden:
+			c.JSON(http.StatusForbidden, api.ErrorResponse{Error: "Only admins can assign or change managers"})
+		case ErrStaffNotFound:
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Staff member not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "Failed to remove staff member"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, api.MessageResponse{Message: "Staff member removed"})
+}
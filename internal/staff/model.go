@@ -0,0 +1,25 @@
+package staff
+
+import "time"
+
+// Gym-scoped staff roles. Global admins are not listed here: they manage
+// every gym without a gym_staff row.
+const (
+	RoleManager    = "manager"
+	RoleFrontDesk  = "front_desk"
+	RoleInstructor = "instructor"
+)
+
+type StaffMember struct {
+	UserID    int       `db:"user_id" json:"user_id"`
+	GymID     int       `db:"gym_id" json:"gym_id"`
+	Role      string    `db:"role" json:"role" example:"front_desk"`
+	UserName  string    `db:"user_name" json:"user_name"`
+	UserEmail string    `db:"user_email" json:"user_email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type AssignStaffRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"required" example:"front_desk"`
+}
@@ -0,0 +1,114 @@
+package staff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrStaffNotFound = errors.New("staff member not found")
+
+// gymLookups maps the route parameters of gym-owned resources to the query
+// that finds their gym.
+var gymLookups = map[string]string{
+	"slotID":     `SELECT gym_id FROM time_slots WHERE id = $1`,
+	"closureID":  `SELECT gym_id FROM gym_closures WHERE id = $1`,
+	"templateID": `SELECT gym_id FROM schedule_templates WHERE id = $1`,
+	"photoID":    `SELECT gym_id FROM gym_photos WHERE id = $1`,
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// AddStaff grants a role at a gym, replacing any role the user already had
+// there.
+func (r *repository) AddStaff(ctx context.Context, gymID, userID int, role string) (*StaffMember, error) {
+	query := `
+		WITH upserted AS (
+			INSERT INTO gym_staff (user_id, gym_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, gym_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING user_id, gym_id, role, created_at
+		)
+		SELECT s.user_id, s.gym_id, s.role, s.created_at, u.name AS user_name, u.email AS user_email
+		FROM upserted s
+		JOIN users u ON u.id = s.user_id
+	`
+
+	var member StaffMember
+	err := r.db.GetContext(ctx, &member, query, userID, gymID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *repository) RemoveStaff(ctx context.Context, gymID, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM gym_staff WHERE gym_id = $1 AND user_id = $2`, gymID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrStaffNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetStaffByGym(ctx context.Context, gymID int) ([]StaffMember, error) {
+	query := `
+		SELECT s.user_id, s.gym_id, s.role, s.created_at, u.name AS user_name, u.email AS user_email
+		FROM gym_staff s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.gym_id = $1
+		ORDER BY s.role ASC, u.name ASC
+	`
+
+	var members []StaffMember
+	err := r.db.SelectContext(ctx, &members, query, gymID)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *repository) GetGymRole(ctx context.Context, userID, gymID int) (string, error) {
+	var role string
+	query := `SELECT role FROM gym_staff WHERE user_id = $1 AND gym_id = $2`
+	err := r.db.GetContext(ctx, &role, query, userID, gymID)
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *repository) ResolveGymID(ctx context.Context, param string, id int) (int, error) {
+	query, ok := gymLookups[param]
+	if !ok {
+		return 0, fmt.Errorf("no gym lookup for parameter %q", param)
+	}
+
+	var gymID int
+	err := r.db.GetContext(ctx, &gymID, query, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return gymID, nil
+}
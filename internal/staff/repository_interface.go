@@ -0,0 +1,11 @@
+package staff
+
+import "context"
+
+type Repository interface {
+	AddStaff(ctx context.Context, gymID, userID int, role string) (*StaffMember, error)
+	RemoveStaff(ctx context.Context, gymID, userID int) error
+	GetStaffByGym(ctx context.Context, gymID int) ([]StaffMember, error)
+	GetGymRole(ctx context.Context, userID, gymID int) (string, error)
+	ResolveGymID(ctx context.Context, param string, id int) (int, error)
+}
@@ -0,0 +1,69 @@
+package staff
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStaffMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewRepository(sqlxDB)
+
+	closer := func() { sqlxDB.Close() }
+	return repo, mock, closer
+}
+
+func TestAddStaff(t *testing.T) {
+	repo, mock, close := setupStaffMock(t)
+	defer close()
+
+	mock.ExpectQuery(`INSERT INTO gym_staff .* ON CONFLICT \(user_id, gym_id\) DO UPDATE SET role = EXCLUDED.role`).
+		WithArgs(7, 1, RoleFrontDesk).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "gym_id", "role", "created_at", "user_name", "user_email"}).
+			AddRow(7, 1, RoleFrontDesk, time.Now(), "Desk", "desk@example.com"))
+
+	member, err := repo.AddStaff(context.Background(), 1, 7, RoleFrontDesk)
+	require.NoError(t, err)
+	require.Equal(t, RoleFrontDesk, member.Role)
+	require.Equal(t, "desk@example.com", member.UserEmail)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveGymID(t *testing.T) {
+	repo, mock, close := setupStaffMock(t)
+	defer close()
+
+	mock.ExpectQuery(`SELECT gym_id FROM time_slots WHERE id = \$1`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"gym_id"}).AddRow(3))
+
+	gymID, err := repo.ResolveGymID(context.Background(), "slotID", 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, gymID)
+
+	_, err = repo.ResolveGymID(context.Background(), "bookingID", 1)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGymRole_NoRole(t *testing.T) {
+	repo, mock, close := setupStaffMock(t)
+	defer close()
+
+	mock.ExpectQuery(`SELECT role FROM gym_staff WHERE user_id = \$1 AND gym_id = \$2`).
+		WithArgs(7, 1).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetGymRole(context.Background(), 7, 1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,84 @@
+package staff
+
+import (
+	"context"
+	"errors"
+
+	"fitslot/internal/gym"
+	"fitslot/internal/user"
+)
+
+var (
+	ErrInvalidRole   = errors.New("invalid staff role")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrRoleForbidden = errors.New("only admins can assign managers")
+)
+
+type Service interface {
+	AssignStaff(ctx context.Context, gymID int, req AssignStaffRequest, byAdmin bool) (*StaffMember, error)
+	RemoveStaff(ctx context.Context, gymID, userID int, byAdmin bool) error
+	GetStaff(ctx context.Context, gymID int) ([]StaffMember, error)
+}
+
+type service struct {
+	repo     Repository
+	gymRepo  gym.Repository
+	userRepo user.Repository
+}
+
+func NewService(repo Repository, gymRepo gym.Repository, userRepo user.Repository) Service {
+	return &service{
+		repo:     repo,
+		gymRepo:  gymRepo,
+		userRepo: userRepo,
+	}
+}
+
+// AssignStaff grants a user a role at the gym. Managers may hire front desk
+// staff and instructors for their gym; only global admins appoint managers.
+func (s *service) AssignStaff(ctx context.Context, gymID int, req AssignStaffRequest, byAdmin bool) (*StaffMember, error) {
+	switch req.Role {
+	case RoleManager:
+		if !byAdmin {
+			return nil, ErrRoleForbidden
+		}
+	case RoleFrontDesk, RoleInstructor:
+	default:
+		return nil, ErrInvalidRole
+	}
+
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, req.UserID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !byAdmin {
+		// A manager must not demote a fellow manager either.
+		if current, err := s.repo.GetGymRole(ctx, req.UserID, gymID); err == nil && current == RoleManager {
+			return nil, ErrRoleForbidden
+		}
+	}
+
+	return s.repo.AddStaff(ctx, gymID, req.UserID, req.Role)
+}
+
+func (s *service) RemoveStaff(ctx context.Context, gymID, userID int, byAdmin bool) error {
+	if !byAdmin {
+		if current, err := s.repo.GetGymRole(ctx, userID, gymID); err == nil && current == RoleManager {
+			return ErrRoleForbidden
+		}
+	}
+
+	return s.repo.RemoveStaff(ctx, gymID, userID)
+}
+
+func (s *service) GetStaff(ctx context.Context, gymID int) ([]StaffMember, error) {
+	if _, err := s.gymRepo.GetGymByID(ctx, gymID); err != nil {
+		return nil, gym.ErrGymNotFound
+	}
+
+	return s.repo.GetStaffByGym(ctx, gymID)
+}
@@ -0,0 +1,119 @@
+package staff
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"fitslot/internal/gym"
+	"fitslot/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) AddStaff(ctx context.Context, gymID, userID int, role string) (*StaffMember, error) {
+	args := m.Called(ctx, gymID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*StaffMember), args.Error(1)
+}
+
+func (m *MockRepository) RemoveStaff(ctx context.Context, gymID, userID int) error {
+	return m.Called(ctx, gymID, userID).Error(0)
+}
+
+func (m *MockRepository) GetStaffByGym(ctx context.Context, gymID int) ([]StaffMember, error) {
+	args := m.Called(ctx, gymID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]StaffMember), args.Error(1)
+}
+
+func (m *MockRepository) GetGymRole(ctx context.Context, userID, gymID int) (string, error) {
+	args := m.Called(ctx, userID, gymID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) ResolveGymID(ctx context.Context, param string, id int) (int, error) {
+	args := m.Called(ctx, param, id)
+	return args.Int(0), args.Error(1)
+}
+
+// MockGymRepo and MockUserRepo only stub the lookups the staff service
+// relies on; calling anything else panics on the nil embedded interface.
+type MockGymRepo struct {
+	mock.Mock
+	gym.Repository
+}
+
+func (m *MockGymRepo) GetGymByID(ctx context.Context, id int) (*gym.Gym, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gym.Gym), args.Error(1)
+}
+
+type MockUserRepo struct {
+	mock.Mock
+	user.Repository
+}
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id int) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func TestService_AssignStaff(t *testing.T) {
+	t.Run("manager hires front desk", func(t *testing.T) {
+		repo, gymRepo, userRepo := new(MockRepository), new(MockGymRepo), new(MockUserRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		userRepo.On("FindByID", mock.Anything, 7).Return(&user.User{ID: 7}, nil)
+		repo.On("GetGymRole", mock.Anything, 7, 1).Return("", sql.ErrNoRows)
+		repo.On("AddStaff", mock.Anything, 1, 7, RoleFrontDesk).Return(&StaffMember{UserID: 7, GymID: 1, Role: RoleFrontDesk}, nil)
+
+		service := NewService(repo, gymRepo, userRepo)
+		member, err := service.AssignStaff(context.Background(), 1, AssignStaffRequest{UserID: 7, Role: RoleFrontDesk}, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, RoleFrontDesk, member.Role)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("only admins appoint managers", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockGymRepo), new(MockUserRepo))
+		_, err := service.AssignStaff(context.Background(), 1, AssignStaffRequest{UserID: 7, Role: RoleManager}, false)
+
+		assert.ErrorIs(t, err, ErrRoleForbidden)
+	})
+
+	t.Run("manager cannot demote another manager", func(t *testing.T) {
+		repo, gymRepo, userRepo := new(MockRepository), new(MockGymRepo), new(MockUserRepo)
+		gymRepo.On("GetGymByID", mock.Anything, 1).Return(&gym.Gym{ID: 1}, nil)
+		userRepo.On("FindByID", mock.Anything, 8).Return(&user.User{ID: 8}, nil)
+		repo.On("GetGymRole", mock.Anything, 8, 1).Return(RoleManager, nil)
+
+		service := NewService(repo, gymRepo, userRepo)
+		_, err := service.AssignStaff(context.Background(), 1, AssignStaffRequest{UserID: 8, Role: RoleInstructor}, false)
+
+		assert.ErrorIs(t, err, ErrRoleForbidden)
+		repo.AssertNotCalled(t, "AddStaff", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockGymRepo), new(MockUserRepo))
+		_, err := service.AssignStaff(context.Background(), 1, AssignStaffRequest{UserID: 7, Role: "janitor"}, true)
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores files on the local filesystem under dir. They are expected to
+// be served as static files under urlPrefix.
+type Local struct {
+	dir       string
+	urlPrefix string
+}
+
+func NewLocal(dir, urlPrefix string) *Local {
+	return &Local{
+		dir:       dir,
+		urlPrefix: strings.TrimRight(urlPrefix, "/"),
+	}
+}
+
+// Save writes to a temporary file first so that a file is never served
+// half-written.
+func (l *Local) Save(ctx context.Context, key string, r io.Reader) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+// Delete removes the file. Deleting a missing file is not an error.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.urlPrefix + "/" + key
+}
+
+// path maps a key to a file inside dir, rejecting keys that would escape it.
+func (l *Local) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
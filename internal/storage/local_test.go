@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_SaveAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocal(dir, "/media/")
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "gyms/1/photo.jpg", strings.NewReader("image data")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "gyms", "1", "photo.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "image data", string(data))
+	assert.Equal(t, "/media/gyms/1/photo.jpg", store.URL("gyms/1/photo.jpg"))
+
+	require.NoError(t, store.Delete(ctx, "gyms/1/photo.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "gyms", "1", "photo.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, store.Delete(ctx, "gyms/1/photo.jpg"), "deleting a missing file is a no-op")
+}
+
+func TestLocal_RejectsKeysOutsideDir(t *testing.T) {
+	store := NewLocal(t.TempDir(), "/media")
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "gyms/../../secret", "gyms//1.jpg"} {
+		err := store.Save(context.Background(), key, strings.NewReader("x"))
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"fitslot/internal/api"
 	"fitslot/internal/auth"
+	"fitslot/internal/money"
 	"fitslot/internal/wallet"
 
 	"fitslot/internal/logger"
@@ -25,6 +26,7 @@ func NewHandler(repo Repository, walletRepo wallet.Repository) *Handler {
 	}
 }
 
+// Plan prices are in the default currency (KZT).
 type Plan struct {
 	Type        string `json:"type"`
 	Name        string `json:"name"`
@@ -75,19 +77,25 @@ func findPlan(planType string) (Plan, error) {
 	return Plan{}, errors.New("unknown plan type")
 }
 
+// CreateSubscriptionRequest buys a plan. The KZT wallet pays unless
+// WalletCurrency names another wallet, in which case the price is converted
+// at the stored exchange rate.
 type CreateSubscriptionRequest struct {
-	Type  string `json:"type" binding:"required"`
-	GymID *int   `json:"gym_id,omitempty"`
+	Type           string `json:"type" binding:"required"`
+	GymID          *int   `json:"gym_id,omitempty"`
+	WalletCurrency string `json:"wallet_currency,omitempty" example:"USD"`
 }
 
 type CreateSubscriptionResponse struct {
-	Subscription *Subscription `json:"subscription"`
-	PaidWith     string        `json:"paid_with"`
-	AmountCents  int64         `json:"amount_cents"`
+	Subscription *Subscription       `json:"subscription"`
+	PaidWith     string              `json:"paid_with"`
+	AmountCents  int64               `json:"amount_cents"`
+	Currency     string              `json:"currency" example:"KZT"`
+	Transaction  *wallet.Transaction `json:"transaction"`
 }
 
 // @Summary      Create subscription
-// @Description  Purchase a subscription plan using wallet balance
+// @Description  Purchase a subscription plan using wallet balance. Plans are priced in KZT; set wallet_currency to pay from a wallet in another currency at the stored exchange rate.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
@@ -97,6 +105,7 @@ type CreateSubscriptionResponse struct {
 // @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      402 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /subscriptions [post]
 func (h *Handler) Create(c *gin.Context) {
@@ -126,14 +135,41 @@ func (h *Handler) Create(c *gin.Context) {
 		req.GymID = nil
 	}
 
+	walletCurrency, err := money.Normalize(req.WalletCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "unsupported wallet currency"})
+		return
+	}
+
 	ctx := c.Request.Context()
 
-	if err := h.walletRepo.AddTransaction(ctx, userID, -plan.PriceCents, "subscription_payment"); err != nil {
-		if err.Error() == "insufficient balance" {
+	metadata := map[string]interface{}{"plan": plan.Type}
+	if req.GymID != nil {
+		metadata["gym_id"] = *req.GymID
+	}
+	if plan.VisitsLimit != nil {
+		metadata["visits_limit"] = *plan.VisitsLimit
+	}
+
+	txn, err := h.walletRepo.Charge(ctx, wallet.Charge{
+		UserID:         userID,
+		Kind:           wallet.EntrySubscriptionCharge,
+		GymID:          req.GymID,
+		PriceCents:     plan.PriceCents,
+		PriceCurrency:  money.DefaultCurrency,
+		WalletCurrency: walletCurrency,
+		Description:    plan.Name + " subscription",
+		Metadata:       metadata,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, wallet.ErrInsufficientBalance):
 			c.JSON(http.StatusPaymentRequired, api.ErrorResponse{Error: "insufficient wallet balance"})
-			return
+		case errors.Is(err, wallet.ErrNoExchangeRate):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: "paying from this wallet currency is not available"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to charge wallet"})
 		}
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to charge wallet"})
 		return
 	}
 
@@ -147,10 +183,18 @@ func (h *Handler) Create(c *gin.Context) {
 	logger.Infof("Subscription created: Type=%s, User=%d", plan.Type, userID)
 	metrics.RecordSubscription(plan.Type)
 
+	if err := h.walletRepo.LinkTransaction(ctx, txn.ID, wallet.TransactionLink{SubscriptionID: &sub.ID}); err != nil {
+		logger.Errorf("Failed to link wallet transaction %d to subscription %d: %v", txn.ID, sub.ID, err)
+	} else {
+		txn.SubscriptionID = &sub.ID
+	}
+
 	c.JSON(http.StatusCreated, CreateSubscriptionResponse{
 		Subscription: sub,
 		PaidWith:     "wallet",
-		AmountCents:  plan.PriceCents,
+		AmountCents:  -txn.AmountCents,
+		Currency:     walletCurrency,
+		Transaction:  txn,
 	})
 }
 
@@ -65,6 +65,27 @@ func (r *repository) IncrementVisits(ctx context.Context, subID int) error {
 	return err
 }
 
+// DecrementVisits gives a visit back, e.g. when a booking paid with the
+// subscription is cancelled by the gym.
+func (r *repository) DecrementVisits(ctx context.Context, subID int) error {
+	return decrementVisits(ctx, r.db, subID)
+}
+
+// DecrementVisitsTx is DecrementVisits within the caller's transaction.
+func DecrementVisitsTx(ctx context.Context, tx *sqlx.Tx, subID int) error {
+	return decrementVisits(ctx, tx, subID)
+}
+
+func decrementVisits(ctx context.Context, e sqlx.ExecerContext, subID int) error {
+	_, err := e.ExecContext(ctx, `
+		UPDATE subscriptions
+		SET visits_used = GREATEST(visits_used - 1, 0),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, subID)
+	return err
+}
+
 func (r *repository) ListActiveByUser(ctx context.Context, userID int) ([]*Subscription, error) {
 	subs := []*Subscription{}
 	err := r.db.SelectContext(ctx, &subs, `
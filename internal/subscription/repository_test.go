@@ -151,6 +151,25 @@ func TestIncrementVisits(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDecrementVisits(t *testing.T) {
+	repo, mock, close := setupSubscriptionMock(t)
+	defer close()
+
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		UPDATE subscriptions
+		SET visits_used = GREATEST(visits_used - 1, 0),
+		    updated_at = NOW()
+		WHERE id = $1
+	`)).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.DecrementVisits(ctx, 3)
+	require.NoError(t, err)
+}
+
 func TestListActiveByUser(t *testing.T) {
 	repo, mock, close := setupSubscriptionMock(t)
 	defer close()
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"fitslot/internal/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AutoTopUpRequired is returned by Charge when the balance falls short of
+// the price but an auto top-up that covers it was started. The charge can be
+// retried once payment PaymentID has been collected. It wraps
+// ErrInsufficientBalance for callers that do not collect auto top-ups.
+type AutoTopUpRequired struct {
+	PaymentID int
+}
+
+func (e *AutoTopUpRequired) Error() string {
+	return fmt.Sprintf("insufficient balance: auto top-up payment %d started", e.PaymentID)
+}
+
+func (e *AutoTopUpRequired) Unwrap() error {
+	return ErrInsufficientBalance
+}
+
+// AutoTopUpPendingTimeout is how long a pending auto top-up holds back the
+// next one. A payment still pending after that is taken to be stuck, for
+// example because its webhook never arrived, and no longer blocks auto
+// top-up. It still counts towards the monthly cap, since it may succeed yet.
+const AutoTopUpPendingTimeout = 30 * time.Minute
+
+// reserveAutoTopUp starts an auto top-up of the member's wallet when a
+// charge leaves its balance at balanceAfter, below the member's threshold. It
+// runs under the wallet's lock in the charge's transaction and records the
+// top-up as a pending payment from the member's saved payment method, so
+// concurrent charges start at most one top-up at a time (see
+// AutoTopUpPendingTimeout) and the monthly cap holds. It returns the
+// payment's ID, or nil when no top-up is due.
+func reserveAutoTopUp(ctx context.Context, tx *sqlx.Tx, userID int, currency string, balanceAfter int64) (*int, error) {
+	var setting struct {
+		ThresholdCents  int64  `db:"threshold_cents"`
+		AmountCents     int64  `db:"amount_cents"`
+		MonthlyCapCents int64  `db:"monthly_cap_cents"`
+		PaymentMethodID int    `db:"payment_method_id"`
+		Provider        string `db:"provider"`
+	}
+	err := tx.GetContext(ctx, &setting,
+		`SELECT a.threshold_cents, a.amount_cents, a.monthly_cap_cents, a.payment_method_id, m.provider
+		 FROM auto_topups a
+		 JOIN payment_methods m ON m.id = a.payment_method_id
+		 WHERE a.user_id = $1 AND a.currency = $2 AND a.enabled
+		 FOR UPDATE OF a`,
+		userID, currency,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A top-up too small to cover the charge would not save it.
+	if balanceAfter >= setting.ThresholdCents || balanceAfter+setting.AmountCents < 0 {
+		return nil, nil
+	}
+
+	var month struct {
+		SpentCents int64 `db:"spent_cents"`
+		Pending    int   `db:"pending"`
+	}
+	err = tx.GetContext(ctx, &month,
+		`SELECT COALESCE(SUM(amount_cents), 0) AS spent_cents,
+			COUNT(*) FILTER (WHERE status = 'pending' AND created_at > NOW() - make_interval(secs => $3)) AS pending
+		 FROM payments
+		 WHERE user_id = $1 AND currency = $2 AND auto_topup
+			AND status IN ('pending', 'succeeded')
+			AND created_at >= date_trunc('month', NOW())`,
+		userID, currency, AutoTopUpPendingTimeout.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if month.Pending > 0 {
+		return nil, nil
+	}
+	if month.SpentCents+setting.AmountCents > setting.MonthlyCapCents {
+		logger.Infof("Auto top-up of user %d's %s wallet skipped: monthly cap of %d reached", userID, currency, setting.MonthlyCapCents)
+		return nil, nil
+	}
+
+	var paymentID int
+	err = tx.GetContext(ctx, &paymentID,
+		`INSERT INTO payments (user_id, amount_cents, currency, status, provider, payment_method_id, auto_topup)
+		 VALUES ($1, $2, $3, 'pending', $4, $5, TRUE)
+		 RETURNING id`,
+		userID, setting.AmountCents, currency, setting.Provider, setting.PaymentMethodID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentID, nil
+}
@@ -1,35 +1,44 @@
 package wallet
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"fitslot/internal/api"
 	"fitslot/internal/auth"
+	"fitslot/internal/logger"
 	"fitslot/internal/metrics"
+	"fitslot/internal/money"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	repo Repository
+	repo    Repository
+	service Service
 }
 
-func NewHandler(repo Repository) *Handler {
+func NewHandler(repo Repository, service Service) *Handler {
 	return &Handler{
-		repo: repo,
+		repo:    repo,
+		service: service,
 	}
 }
 
-type TopUpRequest struct {
-	AmountCents int64 `json:"amount_cents" binding:"required"`
-}
-
 // @Summary      Get wallet balance
+// @Description  Returns the wallet in the currency, KZT by default. A member has one wallet per currency. The balance is split into the member's own money (cash_cents) and promotional credit (promo_cents), with the time the first promotional credit expires.
 // @Tags         wallet
 // @Produce      json
 // @Security     BearerAuth
+// @Param        currency query string false "Currency code" default(KZT)
 // @Success      200 {object} wallet.Wallet
+// @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /wallet [get]
@@ -40,7 +49,13 @@ func (h *Handler) GetBalance(c *gin.Context) {
 		return
 	}
 
-	w, err := h.repo.GetOrCreateWallet(c.Request.Context(), userID)
+	currency, err := money.Normalize(c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "unsupported currency"})
+		return
+	}
+
+	w, err := h.repo.GetOrCreateWallet(c.Request.Context(), userID, currency)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load wallet"})
 		return
@@ -49,55 +64,105 @@ func (h *Handler) GetBalance(c *gin.Context) {
 	c.JSON(http.StatusOK, w)
 }
 
-// @Summary      Top up wallet
+// @Summary      List my wallets
+// @Description  Every wallet of the member, one per currency they have used, with the balance split into cash and promotional credit.
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} wallet.Wallet
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallets [get]
+func (h *Handler) ListWallets(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	wallets, err := h.repo.ListWallets(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load wallets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wallets)
+}
+
+// @Summary      Transfer credit to another member
+// @Description  Moves credit from your wallet to the wallet of the member with the given email, in the same currency (KZT by default). Both wallets list the transfer in their transactions. What a member transfers out of all their wallets within the last 24 hours (a rolling window) is limited; the limit is in KZT and transfers in other currencies count at the stored rate. Promotional credit cannot be transferred, so the amount must be covered by cash.
 // @Tags         wallet
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        request body wallet.TopUpRequest true "Top up payload"
-// @Success      200 {object} wallet.TopUpResponse
+// @Param        request body wallet.TransferRequest true "Transfer payload"
+// @Success      200 {object} wallet.TransferResponse
 // @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
+// @Failure      402 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
-// @Router       /wallet/topup [post]
-func (h *Handler) TopUp(c *gin.Context) {
+// @Router       /wallet/transfer [post]
+func (h *Handler) Transfer(c *gin.Context) {
 	userID, ok := auth.GetUserID(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
 		return
 	}
 
-	var req TopUpRequest
-	if err := c.ShouldBindJSON(&req); err != nil || req.AmountCents <= 0 {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "amount_cents must be positive"})
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "recipient_email and a positive amount_cents are required"})
 		return
 	}
 
-	if err := h.repo.TopUp(c.Request.Context(), userID, req.AmountCents); err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to top up wallet"})
+	txn, err := h.service.Transfer(c.Request.Context(), userID, req)
+	if err != nil {
+		metrics.RecordWalletTransfer("rejected")
+		switch {
+		case errors.Is(err, ErrTransferInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrRecipientNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "No member with this email"})
+		case errors.Is(err, ErrInsufficientBalance):
+			c.JSON(http.StatusPaymentRequired, api.ErrorResponse{Error: "insufficient wallet balance"})
+		case errors.Is(err, ErrTransferLimitExceeded):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: "daily transfer limit exceeded"})
+		case errors.Is(err, ErrNoExchangeRate):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: "transfers in this currency are not available"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to transfer"})
+		}
 		return
 	}
 
-	metrics.RecordWalletTopUp()
-	w, err := h.repo.GetOrCreateWallet(c.Request.Context(), userID)
+	metrics.RecordWalletTransfer("completed")
+	w, err := h.repo.GetOrCreateWallet(c.Request.Context(), userID, txn.Currency)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load wallet after top up"})
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load wallet after transfer"})
 		return
 	}
 
-	c.JSON(http.StatusOK, TopUpResponse{
-		Message: "wallet recharged",
-		Wallet:  *w,
+	c.JSON(http.StatusOK, TransferResponse{
+		Message:     "transfer completed",
+		Wallet:      *w,
+		Transaction: *txn,
 	})
 }
 
 // @Summary      List wallet transactions
+// @Description  Transactions of all the member's wallets, each with its wallet's currency. Transfers appear as transfer_out in the sender's list and transfer_in in the recipient's, with the other member and the note. Payments converted from another currency carry the original amount, currency and rate. Charges link to the booking or subscription they paid for and top-ups to their payment, with a description and details as metadata.
 // @Tags         wallet
 // @Produce      json
 // @Security     BearerAuth
+// @Param        type   query string false "Comma-separated transaction types, e.g. booking_payment,refund"
+// @Param        from   query string false "First day, YYYY-MM-DD (UTC)"
+// @Param        to     query string false "Last day, YYYY-MM-DD (UTC)"
 // @Param        limit  query int false "Limit" default(50)
 // @Param        offset query int false "Offset" default(0)
 // @Success      200 {array} wallet.Transaction
+// @Failure      400 {object} api.ErrorResponse
 // @Failure      401 {object} api.ErrorResponse
 // @Failure      500 {object} api.ErrorResponse
 // @Router       /wallet/transactions [get]
@@ -108,10 +173,39 @@ func (h *Handler) ListTransactions(c *gin.Context) {
 		return
 	}
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter := TransactionFilter{}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if types := c.Query("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(TransactionTypes, t) {
+				c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: fmt.Sprintf("unknown transaction type %q", t)})
+				return
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		day, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "from must be a date like 2024-01-31"})
+			return
+		}
+		filter.From = &day
+	}
+	if to := c.Query("to"); to != "" {
+		day, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "to must be a date like 2024-01-31"})
+			return
+		}
+		until := day.AddDate(0, 0, 1)
+		filter.Until = &until
+	}
 
-	txs, err := h.repo.GetTransactions(c.Request.Context(), userID, limit, offset)
+	txs, err := h.repo.GetTransactions(c.Request.Context(), userID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load transactions"})
 		return
@@ -119,3 +213,429 @@ func (h *Handler) ListTransactions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, txs)
 }
+
+// @Summary      Download a wallet statement
+// @Description  Statement of the wallet in the currency (KZT by default) from one date to another, both inclusive and in UTC: the opening balance, every transaction with a description and the balance after it, and the closing balance. From defaults to the first of the current month, to to today; a statement covers at most 366 days.
+// @Tags         wallet
+// @Produce      text/csv
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        from query string false "First day, YYYY-MM-DD"
+// @Param        to query string false "Last day, YYYY-MM-DD"
+// @Param        currency query string false "Currency code" default(KZT)
+// @Param        format query string false "Output format" Enums(csv, pdf) default(csv)
+// @Success      200 {file} file
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/statement [get]
+func (h *Handler) GetStatement(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	format := c.DefaultQuery("format", StatementCSV)
+	if format != StatementCSV && format != StatementPDF {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "format must be csv or pdf"})
+		return
+	}
+
+	st, err := h.service.Statement(c.Request.Context(), userID, StatementRequest{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Currency: c.Query("currency"),
+	})
+	if err != nil {
+		if errors.Is(err, ErrStatementInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load statement"})
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == StatementPDF {
+		contentType = "application/pdf"
+		err = WriteStatementPDF(&buf, st)
+	} else {
+		err = WriteStatementCSV(&buf, st)
+	}
+	if err != nil {
+		logger.Errorf("Failed to render statement for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to render statement"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, StatementFilename(st, format)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// @Summary      Adjust a member's wallet
+// @Description  Credits or debits the member's wallet in the currency (KZT by default) by hand, recording the reason, the reference (e.g. a support ticket) and the acting admin. A debit cannot take the balance below zero.
+// @Tags         admin,wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userID path int true "User ID"
+// @Param        request body wallet.AdjustmentRequest true "Adjustment"
+// @Success      201 {object} wallet.Transaction
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/users/{userID}/wallet/adjust [post]
+func (h *Handler) AdjustWallet(c *gin.Context) {
+	adminID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var req AdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "type, amount_cents, reason and reference are required"})
+		return
+	}
+
+	txn, err := h.service.Adjust(c.Request.Context(), adminID, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAdjustmentInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrUserNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "User not found"})
+		case errors.Is(err, ErrInsufficientBalance):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: "debit exceeds the wallet balance"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to adjust wallet"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// @Summary      Refund a wallet charge
+// @Description  Returns the amount of a booking or subscription charge to the member's wallet, recording the reason and the acting admin. A charge can be refunded once, including by the refund made when the gym cancels the booking. The part paid with promotional credit goes back into the credit it came from, which keeps its expiry; only the rest comes back as the member's own money.
+// @Tags         admin,wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        txID path int true "Wallet transaction ID"
+// @Param        request body wallet.RefundRequest true "Refund"
+// @Success      201 {object} wallet.Transaction
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/wallet/transactions/{txID}/refund [post]
+func (h *Handler) RefundTransaction(c *gin.Context) {
+	adminID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	txID, err := strconv.Atoi(c.Param("txID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid transaction ID"})
+		return
+	}
+
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "reason is required"})
+		return
+	}
+
+	txn, err := h.service.RefundTransaction(c.Request.Context(), adminID, txID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAdjustmentInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrTransactionNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "Transaction not found"})
+		case errors.Is(err, ErrAlreadyRefunded):
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: "Transaction has already been refunded"})
+		case errors.Is(err, ErrNotRefundable):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to refund transaction"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// @Summary      List my promotional credit
+// @Description  Promotional credit left in the member's wallets, the credit that expires first first. Charges spend it before the member's own money; what is left when it expires is taken back by a credit_expired transaction.
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} wallet.CreditLot
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /wallet/promo-credits [get]
+func (h *Handler) ListPromoCredits(c *gin.Context) {
+	userID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	lots, err := h.repo.ListCreditLots(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load promotional credit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lots)
+}
+
+// @Summary      Grant promotional credit
+// @Description  Credits the member's wallet in the currency (KZT by default) with promotional credit valid for valid_for_days days, at most 366, recording the reason, an optional reference (e.g. a campaign) and the acting admin. Charges spend promotional credit before the member's own money, the credit that expires first first; it cannot be transferred.
+// @Tags         admin,wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userID path int true "User ID"
+// @Param        request body wallet.PromoCreditRequest true "Promotional credit"
+// @Success      201 {object} wallet.Transaction
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/users/{userID}/wallet/promo-credit [post]
+func (h *Handler) GrantPromoCredit(c *gin.Context) {
+	adminID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var req PromoCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "amount_cents, valid_for_days and reason are required"})
+		return
+	}
+
+	txn, err := h.service.GrantPromoCredit(c.Request.Context(), adminID, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAdjustmentInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrUserNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to grant promotional credit"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// @Summary      List ledger account balances
+// @Description  Every ledger account with its balance derived from its postings. Credits are positive.
+// @Tags         admin,ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} wallet.AccountBalance
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/ledger/accounts [get]
+func (h *Handler) ListAccountBalances(c *gin.Context) {
+	balances, err := h.repo.GetAccountBalances(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load ledger accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+// @Summary      Check ledger invariants
+// @Description  Checks that every journal entry, and so the whole ledger, nets to zero in each currency and that every wallet balance matches its ledger account. ok is false when anything is off.
+// @Tags         admin,ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} wallet.LedgerCheck
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/ledger/check [get]
+func (h *Handler) CheckLedger(c *gin.Context) {
+	check, err := h.repo.CheckLedger(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to check ledger"})
+		return
+	}
+
+	if !check.OK {
+		logger.Errorf("Ledger check failed: totals %v, %d unbalanced entries, %d wallet mismatches",
+			check.TotalsCents, len(check.UnbalancedEntries), len(check.WalletMismatches))
+	}
+	metrics.RecordLedgerCheck(check.OK)
+
+	c.JSON(http.StatusOK, check)
+}
+
+// @Summary      Reconcile wallets
+// @Description  Checks every wallet against its transaction history: the balance must equal the sum of the transactions, and each transaction's balance_after must follow from the one before it. Lists the wallets that fail; ok is false when there are any. The check also runs periodically and is exported as metrics.
+// @Tags         admin,wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} wallet.Reconciliation
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/wallets/reconciliation [get]
+func (h *Handler) Reconcile(c *gin.Context) {
+	result, err := h.service.Reconcile(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to reconcile wallets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary      Correct a wallet after reconciliation
+// @Description  Approves the correction of a wallet that failed reconciliation. A reconciliation transaction records the difference between the balance and the transaction history and starts the balance_after chain afresh; the balance and the ledger are unchanged. difference_cents must match the current difference. A wallet whose balance differs from its ledger account needs an adjustment instead (422).
+// @Tags         admin,wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        walletID path int true "Wallet ID"
+// @Param        request body wallet.ReconcileRequest true "Approved correction"
+// @Success      201 {object} wallet.Transaction
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      404 {object} api.ErrorResponse
+// @Failure      409 {object} api.ErrorResponse
+// @Failure      422 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/wallets/{walletID}/reconcile [post]
+func (h *Handler) CorrectWallet(c *gin.Context) {
+	adminID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Param("walletID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "invalid wallet ID"})
+		return
+	}
+
+	var req ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	txn, err := h.service.CorrectWallet(c.Request.Context(), adminID, walletID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAdjustmentInvalid):
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrWalletNotFound):
+			c.JSON(http.StatusNotFound, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrWalletConsistent), errors.Is(err, ErrReconciliationChanged):
+			c.JSON(http.StatusConflict, api.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrWalletLedgerMismatch):
+			c.JSON(http.StatusUnprocessableEntity, api.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to correct wallet"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// @Summary      List exchange rates
+// @Description  The rates payments in another currency are converted at. A rate is the price of one unit of base in quote; the inverse pair uses its reciprocal.
+// @Tags         wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} wallet.ExchangeRate
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /exchange-rates [get]
+func (h *Handler) ListExchangeRates(c *gin.Context) {
+	rates, err := h.repo.ListExchangeRates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to load exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// @Summary      Set an exchange rate
+// @Description  Stores the price of one unit of base in quote, replacing the rate of the pair in either direction. Later conversions use the new rate; past transactions keep the rate they were made at.
+// @Tags         admin,wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        base    path string true "Base currency" example(USD)
+// @Param        quote   path string true "Quote currency" example(KZT)
+// @Param        request body wallet.SetExchangeRateRequest true "Rate"
+// @Success      200 {object} wallet.ExchangeRate
+// @Failure      400 {object} api.ErrorResponse
+// @Failure      401 {object} api.ErrorResponse
+// @Failure      403 {object} api.ErrorResponse
+// @Failure      500 {object} api.ErrorResponse
+// @Router       /admin/exchange-rates/{base}/{quote} [put]
+func (h *Handler) SetExchangeRate(c *gin.Context) {
+	adminID, ok := auth.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, api.ErrorResponse{Error: "user not authenticated"})
+		return
+	}
+
+	var req SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: "rate is required"})
+		return
+	}
+
+	rate, err := h.service.SetExchangeRate(c.Request.Context(), adminID, c.Param("base"), c.Param("quote"), req)
+	if err != nil {
+		if errors.Is(err, ErrExchangeRateInvalid) {
+			c.JSON(http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Error: "failed to set exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"fitslot/internal/job"
+	"fitslot/internal/logger"
+)
+
+// ReconciliationJob periodically reconciles every wallet with its
+// transaction history, keeping the reconciliation metrics current.
+type ReconciliationJob struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewReconciliationJob(service Service, interval time.Duration) *ReconciliationJob {
+	return &ReconciliationJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (j *ReconciliationJob) Start(ctx context.Context) {
+	job.Run(ctx, "Wallet reconciliation", j.interval, j.runOnce)
+}
+
+func (j *ReconciliationJob) runOnce(ctx context.Context) {
+	result, err := j.service.Reconcile(ctx)
+	if err != nil {
+		logger.Errorf("Wallet reconciliation: %v", err)
+		return
+	}
+	logger.Infof("Wallet reconciliation: checked %d wallets, %d discrepancies", result.WalletsChecked, len(result.Discrepancies))
+}
+
+// CreditExpiryJob periodically takes back promotional credit that has
+// expired unspent.
+type CreditExpiryJob struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewCreditExpiryJob(service Service, interval time.Duration) *CreditExpiryJob {
+	return &CreditExpiryJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (j *CreditExpiryJob) Start(ctx context.Context) {
+	job.Run(ctx, "Promotional credit expiry", j.interval, j.runOnce)
+}
+
+func (j *CreditExpiryJob) runOnce(ctx context.Context) {
+	expired, err := j.service.ExpireCredits(ctx)
+	if err != nil {
+		logger.Errorf("Promotional credit expiry: %v", err)
+	}
+	logger.Infof("Promotional credit expiry: expired credit in %d wallets", len(expired))
+}
@@ -1,8 +1,11 @@
 package wallet
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-// Wallet — кошелёк пользователя.
+// Wallet — кошелёк пользователя. A member has one wallet per currency.
 type Wallet struct {
 	ID           int       `db:"id" json:"id"`
 	UserID       int       `db:"user_id" json:"user_id"`
@@ -10,18 +13,415 @@ type Wallet struct {
 	Currency     string    `db:"currency" json:"currency"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+
+	// The balance split into the member's own money and promotional
+	// credit, with the time the first promotional credit expires.
+	CashCents      int64      `db:"cash_cents" json:"cash_cents"`
+	PromoCents     int64      `db:"promo_cents" json:"promo_cents"`
+	PromoExpiresAt *time.Time `db:"promo_expires_at" json:"promo_expires_at,omitempty"`
 }
 
 type Transaction struct {
 	ID           int       `db:"id" json:"id"`
 	WalletID     int       `db:"wallet_id" json:"wallet_id"`
 	AmountCents  int64     `db:"amount_cents" json:"amount_cents"`
-	Type         string    `db:"type" json:"type"` // topup, booking_payment, subscription_payment, refund и т.п.
+	Type         string    `db:"type" json:"type"` // topup, booking_payment, subscription_payment, refund, transfer_out, transfer_in, admin_adjustment, admin_refund и т.п.
 	BalanceAfter int64     `db:"balance_after" json:"balance_after"`
+	Currency     string    `db:"currency" json:"currency" example:"KZT"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+
+	// What the transaction paid for or came from, a readable description
+	// and details such as the slot booked.
+	BookingID      *int             `db:"booking_id" json:"booking_id,omitempty"`
+	SubscriptionID *int             `db:"subscription_id" json:"subscription_id,omitempty"`
+	PaymentID      *int             `db:"payment_id" json:"payment_id,omitempty"`
+	Description    *string          `db:"description" json:"description,omitempty" example:"Class booking at Downtown Gym, 20 Jan 2024 10:00"`
+	Metadata       *json.RawMessage `db:"metadata" json:"metadata,omitempty" swaggertype:"object"`
+
+	// The part of the amount paid with promotional credit or, on a refund,
+	// returned as promotional credit.
+	PromoCents *int64 `db:"promo_cents" json:"promo_cents,omitempty"`
+
+	// Set when the wallet paid a price in another currency: the amount in
+	// that currency, signed like AmountCents, the currency and the rate of
+	// one unit of it in the wallet's currency.
+	OriginalAmountCents *int64  `db:"original_amount_cents" json:"original_amount_cents,omitempty"`
+	OriginalCurrency    *string `db:"original_currency" json:"original_currency,omitempty"`
+	ExchangeRate        *string `db:"exchange_rate" json:"exchange_rate,omitempty" example:"0.0021"`
+
+	// The other member of a transfer and the sender's note.
+	CounterpartyUserID *int    `db:"counterparty_user_id" json:"counterparty_user_id,omitempty"`
+	CounterpartyName   *string `db:"counterparty_name" json:"counterparty_name,omitempty"`
+	Note               *string `db:"note" json:"note,omitempty"`
+
+	// Set on adjustments and refunds made by support staff.
+	Reason     *string `db:"reason" json:"reason,omitempty"`
+	Reference  *string `db:"reference" json:"reference,omitempty"`
+	CreatedBy  *int    `db:"created_by" json:"created_by,omitempty"`
+	RefundOfID *int    `db:"refund_of_id" json:"refund_of_id,omitempty"`
+
+	// Set on a charge that started an auto top-up of the wallet.
+	AutoTopUpPaymentID *int `db:"-" json:"auto_topup_payment_id,omitempty"`
+}
+
+// MaxTransferNoteLength is the longest note, in characters, a transfer can
+// carry.
+const MaxTransferNoteLength = 200
+
+// TransferRequest moves credit to another member, found by email, between
+// the two members' wallets in the currency (KZT when empty).
+type TransferRequest struct {
+	RecipientEmail string `json:"recipient_email" binding:"required,email" example:"friend@example.com"`
+	AmountCents    int64  `json:"amount_cents" binding:"required" example:"2000"`
+	Currency       string `json:"currency" example:"KZT"`
+	Note           string `json:"note" example:"For Saturday's class"`
+}
+
+// Transfer is a validated transfer between two wallets. DailyLimitCents caps
+// what the sender may transfer out of all their wallets within the last 24
+// hours, in the default currency; zero means no limit.
+type Transfer struct {
+	FromUserID      int
+	ToUserID        int
+	AmountCents     int64
+	Currency        string
+	Note            string
+	DailyLimitCents int64
+}
+
+type TransferResponse struct {
+	Message     string      `json:"message" example:"transfer completed"`
+	Wallet      Wallet      `json:"wallet"`
+	Transaction Transaction `json:"transaction"`
+}
+
+// Formats a wallet statement can be exported in.
+const (
+	StatementCSV = "csv"
+	StatementPDF = "pdf"
+)
+
+// MaxStatementDays is the longest period a single statement can cover.
+const MaxStatementDays = 366
+
+// StatementRequest asks for the statement of the wallet in the currency
+// (KZT when empty) from one date to another, both inclusive and written as
+// YYYY-MM-DD in UTC. From defaults to the first of the current month and To
+// to today.
+type StatementRequest struct {
+	From     string
+	To       string
+	Currency string
+}
+
+// Statement is a wallet's account of a period: the balance before it, every
+// transaction in it with the balance after each, and the balance after it.
+// To is the last day covered.
+type Statement struct {
+	HolderName          string
+	HolderEmail         string
+	Currency            string
+	From                time.Time
+	To                  time.Time
+	OpeningBalanceCents int64
+	ClosingBalanceCents int64
+	Transactions        []StatementTransaction
+}
+
+// StatementTransaction is a wallet transaction with the gym it was paid to
+// or refunded by, if any.
+type StatementTransaction struct {
+	Transaction
+	GymName *string `db:"gym_name"`
+}
+
+// Limits of the reason and reference support staff record with adjustments
+// and refunds.
+const (
+	MaxReasonLength    = 500
+	MaxReferenceLength = 100
+)
+
+// Directions of a manual adjustment.
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+)
+
+// AdjustmentRequest credits or debits a member's wallet in the currency
+// (KZT when empty) by hand. The reference points at the support ticket or
+// document behind it.
+type AdjustmentRequest struct {
+	Type        string `json:"type" binding:"required" example:"credit" enums:"credit,debit"`
+	AmountCents int64  `json:"amount_cents" binding:"required" example:"1500"`
+	Currency    string `json:"currency" example:"KZT"`
+	Reason      string `json:"reason" binding:"required" example:"Charged twice for the 12 May class"`
+	Reference   string `json:"reference" binding:"required" example:"SUP-1234"`
+}
+
+type RefundRequest struct {
+	Reason    string `json:"reason" binding:"required" example:"Class cancelled by the gym"`
+	Reference string `json:"reference" example:"SUP-1234"`
+}
+
+// Adjustment is a validated manual adjustment. AmountCents is negative for
+// debits.
+type Adjustment struct {
+	UserID      int
+	AmountCents int64
+	Currency    string
+	Reason      string
+	Reference   string
+	AdminID     int
+}
+
+// TransactionRefund returns the amount of a wallet charge to the member.
+type TransactionRefund struct {
+	TransactionID int
+	Reason        string
+	Reference     string
+	AdminID       int
+}
+
+// MaxPromoCreditDays is the longest promotional credit can be valid for.
+const MaxPromoCreditDays = 366
+
+// PromoCreditRequest grants a member promotional credit in the currency (KZT
+// when empty), valid for a number of days. The reference points at the
+// campaign or support ticket behind it.
+type PromoCreditRequest struct {
+	AmountCents  int64  `json:"amount_cents" binding:"required" example:"200000"`
+	Currency     string `json:"currency" example:"KZT"`
+	ValidForDays int    `json:"valid_for_days" binding:"required" example:"30"`
+	Reason       string `json:"reason" binding:"required" example:"Welcome bonus"`
+	Reference    string `json:"reference" example:"WELCOME-2024"`
+}
+
+// PromoGrant is a validated grant of promotional credit.
+type PromoGrant struct {
+	UserID      int
+	AmountCents int64
+	Currency    string
+	ExpiresAt   time.Time
+	Reason      string
+	Reference   string
+	AdminID     int
+}
+
+// CreditLot is promotional credit granted to a wallet at once. Charges
+// spend RemainingCents of the lot that expires first before the member's
+// own money; what is left at ExpiresAt expires.
+type CreditLot struct {
+	ID             int       `db:"id" json:"id"`
+	WalletID       int       `db:"wallet_id" json:"wallet_id"`
+	Currency       string    `db:"currency" json:"currency" example:"KZT"`
+	AmountCents    int64     `db:"amount_cents" json:"amount_cents"`
+	RemainingCents int64     `db:"remaining_cents" json:"remaining_cents"`
+	ExpiresAt      time.Time `db:"expires_at" json:"expires_at"`
+	Reason         string    `db:"reason" json:"reason" example:"Welcome bonus"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// Charge takes a price from the member's wallet in WalletCurrency. When the
+// price is in another currency it is converted at the stored exchange rate;
+// the member asks for that explicitly by naming the wallet to pay from.
+// GymID is nil for platform-wide charges. Description and Metadata are
+// stored with the transaction. A charge that would leave the balance below
+// the member's auto top-up threshold starts an auto top-up.
+type Charge struct {
+	UserID         int
+	Kind           EntryKind
+	GymID          *int
+	PriceCents     int64
+	PriceCurrency  string
+	WalletCurrency string
+	Description    string
+	Metadata       map[string]interface{}
+}
+
+// TransactionLink points a charge at the booking or subscription it paid
+// for, once that exists.
+type TransactionLink struct {
+	BookingID      *int
+	SubscriptionID *int
+}
+
+// TransactionFilter narrows a member's transaction list to some types and a
+// period [From, Until). Empty fields do not filter.
+type TransactionFilter struct {
+	Types  []string
+	From   *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
+
+// TransactionTypes are the types a wallet transaction can have.
+var TransactionTypes = []string{
+	string(EntryTopUp),
+	string(EntryTopUpRefund),
+	string(EntryBookingCharge),
+	string(EntrySubscriptionCharge),
+	string(EntryRefund),
+	TransactionTransferOut,
+	TransactionTransferIn,
+	string(EntryAdjustment),
+	string(EntryAdminRefund),
+	string(EntryPromoCredit),
+	string(EntryCreditExpired),
+	TransactionReconciliation,
+}
+
+// ExchangeRate is the price of one unit of Base in units of Quote.
+type ExchangeRate struct {
+	Base      string    `db:"base" json:"base" example:"USD"`
+	Quote     string    `db:"quote" json:"quote" example:"KZT"`
+	Rate      string    `db:"rate" json:"rate" example:"470.15"`
+	UpdatedBy *int      `db:"updated_by" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type SetExchangeRateRequest struct {
+	Rate string `json:"rate" binding:"required" example:"470.15"`
+}
+
+// AccountType is what a ledger account holds. Money paid in from outside
+// (top-ups) comes from the cash account, manual corrections by support
+// staff from the adjustments account and promotional credit from the
+// promo liability account, which takes back what expires. Conversions
+// between currencies pass through the fx account of each currency.
+type AccountType string
+
+const (
+	AccountMemberWallet   AccountType = "member_wallet"
+	AccountGymRevenue     AccountType = "gym_revenue"
+	AccountPromoLiability AccountType = "promo_liability"
+	AccountRefunds        AccountType = "refunds"
+	AccountCash           AccountType = "cash"
+	AccountAdjustments    AccountType = "adjustments"
+	AccountFX             AccountType = "fx"
+)
+
+// EntryKind is the business event behind a journal entry. It is also the
+// type of the wallet transaction the entry shows up as, except for
+// transfers, which show up as a transfer_out and a transfer_in.
+type EntryKind string
+
+const (
+	EntryTopUp              EntryKind = "topup"
+	EntryTopUpRefund        EntryKind = "topup_refund"
+	EntryBookingCharge      EntryKind = "booking_payment"
+	EntrySubscriptionCharge EntryKind = "subscription_payment"
+	EntryRefund             EntryKind = "refund"
+	EntryOpeningBalance     EntryKind = "opening_balance"
+	EntryTransfer           EntryKind = "transfer"
+	EntryAdjustment         EntryKind = "admin_adjustment"
+	EntryAdminRefund        EntryKind = "admin_refund"
+	EntryPromoCredit        EntryKind = "promo_credit"
+	EntryCreditExpired      EntryKind = "credit_expired"
+)
+
+// Wallet transaction types of the two sides of a transfer.
+const (
+	TransactionTransferOut = "transfer_out"
+	TransactionTransferIn  = "transfer_in"
+)
+
+// TransactionReconciliation is a correction support staff approved after
+// reconciliation. It records the part of the balance the transaction
+// history is missing; it moves no money and has no ledger entry.
+const TransactionReconciliation = "reconciliation"
+
+// Account is a ledger account in a single currency. Member wallets belong
+// to a user; revenue and refunds belong to a gym, or to the platform when
+// GymID is nil.
+type Account struct {
+	ID        int         `db:"id" json:"id"`
+	Type      AccountType `db:"type" json:"type" example:"gym_revenue"`
+	Currency  string      `db:"currency" json:"currency" example:"KZT"`
+	UserID    *int        `db:"user_id" json:"user_id,omitempty"`
+	GymID     *int        `db:"gym_id" json:"gym_id,omitempty"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+}
+
+// AccountBalance is an account with its balance derived from its postings.
+// Credits are positive, so liabilities and revenue show positive balances
+// and cash, which has paid for them, a negative one.
+type AccountBalance struct {
+	Account
+	BalanceCents int64 `db:"balance_cents" json:"balance_cents"`
+}
+
+// Posting is one line of a journal entry. The postings of an entry net to
+// zero in each currency.
+type Posting struct {
+	AccountID   int    `db:"account_id" json:"account_id"`
+	Currency    string `db:"currency" json:"currency"`
+	AmountCents int64  `db:"amount_cents" json:"amount_cents"`
+}
+
+// LedgerCheck is the result of checking the ledger invariants: every entry
+// and so the whole ledger nets to zero in each currency, and every wallet's
+// balance matches its ledger account.
+type LedgerCheck struct {
+	OK                bool             `json:"ok"`
+	TotalsCents       map[string]int64 `json:"totals_cents"`
+	UnbalancedEntries []int64          `json:"unbalanced_entries"`
+	WalletMismatches  []WalletMismatch `json:"wallet_mismatches"`
+}
+
+type WalletMismatch struct {
+	WalletID     int   `db:"wallet_id" json:"wallet_id"`
+	UserID       int   `db:"user_id" json:"user_id"`
+	BalanceCents int64 `db:"balance_cents" json:"balance_cents"`
+	LedgerCents  int64 `db:"ledger_cents" json:"ledger_cents"`
 }
 
-type TopUpResponse struct {
-	Message string `json:"message" example:"wallet recharged"`
-	Wallet  Wallet `json:"wallet"`
-}
\ No newline at end of file
+// Reconciliation is the result of checking every wallet against its
+// transaction history: the balance must equal the sum of the transaction
+// amounts, and each transaction's balance_after must be the previous one
+// plus its amount, starting from zero. A reconciliation transaction starts
+// the chain afresh, settling the breaks before it.
+type Reconciliation struct {
+	OK             bool                `json:"ok"`
+	CheckedAt      time.Time           `json:"checked_at"`
+	WalletsChecked int                 `json:"wallets_checked"`
+	Discrepancies  []WalletDiscrepancy `json:"discrepancies"`
+}
+
+// WalletDiscrepancy is a wallet that failed reconciliation. DifferenceCents
+// is what the transaction history is missing: the balance minus the sum of
+// the transactions.
+type WalletDiscrepancy struct {
+	WalletID             int          `db:"wallet_id" json:"wallet_id"`
+	UserID               int          `db:"user_id" json:"user_id"`
+	Currency             string       `db:"currency" json:"currency"`
+	BalanceCents         int64        `db:"balance_cents" json:"balance_cents"`
+	TransactionsSumCents int64        `json:"transactions_sum_cents"`
+	DifferenceCents      int64        `json:"difference_cents"`
+	ChainBreaks          []ChainBreak `json:"chain_breaks"`
+}
+
+// ChainBreak is a transaction whose balance_after does not follow from the
+// transaction before it.
+type ChainBreak struct {
+	TransactionID        int   `json:"transaction_id"`
+	ExpectedBalanceCents int64 `json:"expected_balance_cents"`
+	BalanceAfterCents    int64 `json:"balance_after_cents"`
+}
+
+// ReconcileRequest approves the correction of a wallet found by
+// reconciliation. DifferenceCents must match the difference the admin
+// reviewed, so a wallet that changed since is not corrected blindly.
+type ReconcileRequest struct {
+	DifferenceCents *int64 `json:"difference_cents" binding:"required" example:"5000"`
+	Reason          string `json:"reason" binding:"required" example:"Balance carried over from before transactions were recorded"`
+}
+
+// WalletCorrection is an approved reconciliation correction.
+type WalletCorrection struct {
+	WalletID        int
+	DifferenceCents int64
+	Reason          string
+	AdminID         int
+}
@@ -0,0 +1,289 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// lotSpend is the part of a debit taken from one credit lot.
+type lotSpend struct {
+	lotID int
+	cents int64
+}
+
+// promoSpend is how a debit of a wallet is paid from its credit lots.
+// expiredCents is the credit past its expiry, which the debit cannot use.
+type promoSpend struct {
+	lots         []lotSpend
+	cents        int64
+	expiredCents int64
+}
+
+// planPromoSpend takes up to debitCents from the wallet's credit lots, the
+// lot that expires first first. The lots are locked for the rest of the
+// transaction; the wallet must already be.
+func planPromoSpend(ctx context.Context, tx *sqlx.Tx, walletID int, debitCents int64) (promoSpend, error) {
+	var lots []struct {
+		ID             int   `db:"id"`
+		RemainingCents int64 `db:"remaining_cents"`
+		Expired        bool  `db:"expired"`
+	}
+	err := tx.SelectContext(ctx, &lots,
+		`SELECT id, remaining_cents, expires_at <= NOW() AS expired
+		 FROM wallet_credit_lots
+		 WHERE wallet_id = $1 AND remaining_cents > 0
+		 ORDER BY expires_at, id
+		 FOR UPDATE`,
+		walletID,
+	)
+	if err != nil {
+		return promoSpend{}, err
+	}
+
+	var spend promoSpend
+	for _, lot := range lots {
+		if lot.Expired {
+			spend.expiredCents += lot.RemainingCents
+			continue
+		}
+		if take := min(lot.RemainingCents, debitCents-spend.cents); take > 0 {
+			spend.lots = append(spend.lots, lotSpend{lotID: lot.ID, cents: take})
+			spend.cents += take
+		}
+	}
+	return spend, nil
+}
+
+// apply takes the planned amounts from the lots.
+func (s promoSpend) apply(ctx context.Context, tx *sqlx.Tx) error {
+	for _, l := range s.lots {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE wallet_credit_lots SET remaining_cents = remaining_cents - $2 WHERE id = $1`,
+			l.lotID, l.cents,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record keeps what the debit, transaction transactionID, took from each
+// lot, for restorePromoSpend.
+func (s promoSpend) record(ctx context.Context, tx *sqlx.Tx, transactionID int) error {
+	for _, l := range s.lots {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO wallet_credit_lot_spends (transaction_id, lot_id, cents) VALUES ($1, $2, $3)`,
+			transactionID, l.lotID, l.cents,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePromoSpend puts the promotional credit that debit transactionID
+// took back into the lots it came from, for a refund of the debit, and
+// returns how much that was. Credit put back into a lot past its expiry
+// cannot be spent and is taken back with the rest of the lot. The wallet
+// must already be locked.
+func restorePromoSpend(ctx context.Context, tx *sqlx.Tx, transactionID int) (int64, error) {
+	var spends []struct {
+		LotID int   `db:"lot_id"`
+		Cents int64 `db:"cents"`
+	}
+	err := tx.SelectContext(ctx, &spends,
+		`SELECT lot_id, cents FROM wallet_credit_lot_spends WHERE transaction_id = $1 ORDER BY lot_id`,
+		transactionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var restored int64
+	for _, s := range spends {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE wallet_credit_lots SET remaining_cents = remaining_cents + $2 WHERE id = $1`,
+			s.LotID, s.Cents,
+		)
+		if err != nil {
+			return 0, err
+		}
+		restored += s.Cents
+	}
+	return restored, nil
+}
+
+// promoCents is the part of the debit paid with promotional credit, signed
+// like the transaction amount, or nil when there is none.
+func (s promoSpend) promoCents() *int64 {
+	if s.cents == 0 {
+		return nil
+	}
+	cents := -s.cents
+	return &cents
+}
+
+// GrantPromoCredit credits the member's wallet with promotional credit
+// against the promo liability account and records it as a lot that expires
+// at g.ExpiresAt.
+func (r *repository) GrantPromoCredit(ctx context.Context, g PromoGrant) (*Transaction, error) {
+	if g.AmountCents <= 0 {
+		return nil, errors.New("promotional credit must be positive")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, err := addTransaction(ctx, tx, g.UserID, g.AmountCents, g.Currency, EntryPromoCredit, nil, details{
+		reason:    &g.Reason,
+		reference: optional(g.Reference),
+		createdBy: &g.AdminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO wallet_credit_lots (wallet_id, transaction_id, amount_cents, remaining_cents, expires_at, reason, created_by)
+		 VALUES ($1, $2, $3, $3, $4, $5, $6)`,
+		txn.WalletID, txn.ID, g.AmountCents, g.ExpiresAt, g.Reason, g.AdminID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// ListCreditLots returns the member's credit lots with credit left, the
+// one that expires first first.
+func (r *repository) ListCreditLots(ctx context.Context, userID int) ([]CreditLot, error) {
+	lots := []CreditLot{}
+	err := r.db.SelectContext(ctx, &lots,
+		`SELECT l.id, l.wallet_id, w.currency, l.amount_cents, l.remaining_cents, l.expires_at, l.reason, l.created_at
+		 FROM wallet_credit_lots l
+		 JOIN wallets w ON w.id = l.wallet_id
+		 WHERE w.user_id = $1 AND l.remaining_cents > 0
+		 ORDER BY l.expires_at, l.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// ExpireCredits takes back the credit left in lots past their expiry, with
+// one credit_expired transaction per wallet. Each wallet is expired in its
+// own transaction, so a wallet that fails does not hold up the others; the
+// failures are returned together with the transactions made.
+func (r *repository) ExpireCredits(ctx context.Context) ([]Transaction, error) {
+	var due []struct {
+		UserID   int    `db:"user_id"`
+		Currency string `db:"currency"`
+	}
+	err := r.db.SelectContext(ctx, &due,
+		`SELECT DISTINCT w.user_id, w.currency
+		 FROM wallet_credit_lots l
+		 JOIN wallets w ON w.id = l.wallet_id
+		 WHERE l.remaining_cents > 0 AND l.expires_at <= NOW()
+		 ORDER BY w.user_id, w.currency`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []Transaction{}
+	var errs []error
+	for _, w := range due {
+		txn, err := r.expireWalletCredits(ctx, w.UserID, w.Currency)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s wallet of user %d: %w", w.Currency, w.UserID, err))
+			continue
+		}
+		if txn != nil {
+			expired = append(expired, *txn)
+		}
+	}
+	return expired, errors.Join(errs...)
+}
+
+// expireWalletCredits expires the member's lots in the currency that are
+// past their expiry. It returns nil when there are none any more.
+func (r *repository) expireWalletCredits(ctx context.Context, userID int, currency string) (*Transaction, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Charges lock the wallet before its lots; so does expiry, or the two
+	// could deadlock.
+	w, err := lockWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var lots []struct {
+		ID             int   `db:"id"`
+		RemainingCents int64 `db:"remaining_cents"`
+	}
+	err = tx.SelectContext(ctx, &lots,
+		`SELECT id, remaining_cents
+		 FROM wallet_credit_lots
+		 WHERE wallet_id = $1 AND remaining_cents > 0 AND expires_at <= NOW()
+		 ORDER BY expires_at, id
+		 FOR UPDATE`,
+		w.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, nil
+	}
+
+	var cents int64
+	ids := make([]int64, len(lots))
+	for i, lot := range lots {
+		cents += lot.RemainingCents
+		ids[i] = int64(lot.ID)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE wallet_credit_lots SET remaining_cents = 0, expired_at = NOW() WHERE id = ANY($1)`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{"lot_ids": ids})
+	if err != nil {
+		return nil, err
+	}
+	txn, err := addTransaction(ctx, tx, userID, -cents, currency, EntryCreditExpired, nil, details{
+		metadata: optional(string(metadata)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
@@ -0,0 +1,39 @@
+package wallet
+
+// chainRow is the part of a wallet transaction reconciliation looks at.
+type chainRow struct {
+	WalletID     int    `db:"wallet_id"`
+	ID           int    `db:"id"`
+	AmountCents  int64  `db:"amount_cents"`
+	BalanceAfter int64  `db:"balance_after"`
+	Type         string `db:"type"`
+}
+
+// checkWallet reconciles a wallet with its transactions, oldest first. It
+// returns nil when they agree.
+func checkWallet(w WalletDiscrepancy, history []chainRow) *WalletDiscrepancy {
+	var sum, prev int64
+	breaks := []ChainBreak{}
+	for _, row := range history {
+		sum += row.AmountCents
+		if row.Type == TransactionReconciliation {
+			// A correction settles everything before it.
+			breaks = breaks[:0]
+		} else if expected := prev + row.AmountCents; row.BalanceAfter != expected {
+			breaks = append(breaks, ChainBreak{
+				TransactionID:        row.ID,
+				ExpectedBalanceCents: expected,
+				BalanceAfterCents:    row.BalanceAfter,
+			})
+		}
+		prev = row.BalanceAfter
+	}
+
+	w.TransactionsSumCents = sum
+	w.DifferenceCents = w.BalanceCents - sum
+	if w.DifferenceCents == 0 && len(breaks) == 0 {
+		return nil
+	}
+	w.ChainBreaks = breaks
+	return &w
+}
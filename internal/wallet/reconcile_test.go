@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWallet(t *testing.T) {
+	w := WalletDiscrepancy{WalletID: 3, UserID: 5, Currency: "KZT"}
+
+	t.Run("consistent history", func(t *testing.T) {
+		w := w
+		w.BalanceCents = 1500
+		assert.Nil(t, checkWallet(w, []chainRow{
+			{ID: 1, AmountCents: 2000, BalanceAfter: 2000, Type: "topup"},
+			{ID: 2, AmountCents: -500, BalanceAfter: 1500, Type: "booking_payment"},
+		}))
+	})
+
+	t.Run("empty wallet", func(t *testing.T) {
+		assert.Nil(t, checkWallet(w, nil))
+	})
+
+	t.Run("balance carried over without a transaction", func(t *testing.T) {
+		w := w
+		w.BalanceCents = 6000
+		d := checkWallet(w, []chainRow{
+			{ID: 1, AmountCents: 1000, BalanceAfter: 6000, Type: "topup"},
+		})
+		require.NotNil(t, d)
+		assert.Equal(t, int64(1000), d.TransactionsSumCents)
+		assert.Equal(t, int64(5000), d.DifferenceCents)
+		assert.Equal(t, []ChainBreak{{TransactionID: 1, ExpectedBalanceCents: 1000, BalanceAfterCents: 6000}}, d.ChainBreaks)
+	})
+
+	t.Run("one bad link is reported once", func(t *testing.T) {
+		w := w
+		w.BalanceCents = 1700
+		d := checkWallet(w, []chainRow{
+			{ID: 1, AmountCents: 1000, BalanceAfter: 1000, Type: "topup"},
+			{ID: 2, AmountCents: 500, BalanceAfter: 1600, Type: "topup"},
+			{ID: 3, AmountCents: 200, BalanceAfter: 1800, Type: "topup"},
+		})
+		require.NotNil(t, d)
+		assert.Zero(t, d.DifferenceCents)
+		assert.Equal(t, []ChainBreak{{TransactionID: 2, ExpectedBalanceCents: 1500, BalanceAfterCents: 1600}}, d.ChainBreaks)
+	})
+
+	t.Run("a correction settles earlier breaks", func(t *testing.T) {
+		w := w
+		w.BalanceCents = 5500
+		assert.Nil(t, checkWallet(w, []chainRow{
+			{ID: 1, AmountCents: 1000, BalanceAfter: 6000, Type: "topup"},
+			{ID: 2, AmountCents: 5000, BalanceAfter: 6000, Type: TransactionReconciliation},
+			{ID: 3, AmountCents: -500, BalanceAfter: 5500, Type: "booking_payment"},
+		}))
+	})
+
+	t.Run("balance edited behind the history's back", func(t *testing.T) {
+		w := w
+		w.BalanceCents = 900
+		d := checkWallet(w, []chainRow{
+			{ID: 1, AmountCents: 1000, BalanceAfter: 1000, Type: "topup"},
+		})
+		require.NotNil(t, d)
+		assert.Equal(t, int64(-100), d.DifferenceCents)
+		assert.Empty(t, d.ChainBreaks)
+	})
+}
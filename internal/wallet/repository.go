@@ -3,13 +3,33 @@ package wallet
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"fitslot/internal/money"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrUnbalancedEntry     = errors.New("ledger entry does not balance")
+
+	ErrTransferLimitExceeded = errors.New("daily transfer limit exceeded")
+	ErrNoExchangeRate        = errors.New("no exchange rate between the currencies")
+
+	ErrTransactionNotFound = errors.New("wallet transaction not found")
+	ErrNotRefundable       = errors.New("only booking and subscription charges can be refunded")
+	ErrAlreadyRefunded     = errors.New("transaction has already been refunded")
+
+	ErrWalletNotFound        = errors.New("wallet not found")
+	ErrWalletConsistent      = errors.New("wallet is consistent with its transactions")
+	ErrReconciliationChanged = errors.New("wallet changed since it was reviewed")
+	ErrWalletLedgerMismatch  = errors.New("wallet balance differs from its ledger account")
 )
 
 type repository struct {
@@ -20,9 +40,29 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetOrCreateWallet(ctx context.Context, userID int) (*Wallet, error) {
+// walletColumns are the columns of a wallet w with its balance split into
+// cash and the promotional credit left in its lots; see walletPromoJoin.
+const walletColumns = `w.id, w.user_id, w.balance_cents, w.currency, w.created_at, w.updated_at,
+	w.balance_cents - COALESCE(promo.cents, 0) AS cash_cents, COALESCE(promo.cents, 0) AS promo_cents,
+	promo.expires_at AS promo_expires_at`
+
+const walletPromoJoin = `LEFT JOIN LATERAL (
+		SELECT SUM(remaining_cents) AS cents, MIN(expires_at) AS expires_at
+		FROM wallet_credit_lots
+		WHERE wallet_id = w.id AND remaining_cents > 0
+	) promo ON TRUE`
+
+// GetOrCreateWallet returns the member's wallet in the currency, opening it
+// on first use.
+func (r *repository) GetOrCreateWallet(ctx context.Context, userID int, currency string) (*Wallet, error) {
 	w := &Wallet{}
-	err := r.db.GetContext(ctx, w, `SELECT * FROM wallets WHERE user_id = $1`, userID)
+	err := r.db.GetContext(ctx, w,
+		`SELECT `+walletColumns+`
+		 FROM wallets w
+		 `+walletPromoJoin+`
+		 WHERE w.user_id = $1 AND w.currency = $2`,
+		userID, currency,
+	)
 	if err == nil {
 		return w, nil
 	}
@@ -31,53 +71,298 @@ func (r *repository) GetOrCreateWallet(ctx context.Context, userID int) (*Wallet
 	}
 
 	err = r.db.QueryRowxContext(ctx,
-		`INSERT INTO wallets (user_id)
-		 VALUES ($1)
+		`INSERT INTO wallets (user_id, currency)
+		 VALUES ($1, $2)
 		 RETURNING id, user_id, balance_cents, currency, created_at, updated_at`,
-		userID,
+		userID, currency,
 	).StructScan(w)
 
 	if err != nil {
 		return nil, err
 	}
+	w.CashCents = w.BalanceCents
 
 	return w, nil
 }
 
-func (r *repository) AddTransaction(ctx context.Context, userID int, amountCents int64, txType string) error {
+// ListWallets returns the member's wallets, one per currency.
+func (r *repository) ListWallets(ctx context.Context, userID int) ([]Wallet, error) {
+	wallets := []Wallet{}
+	err := r.db.SelectContext(ctx, &wallets,
+		`SELECT `+walletColumns+`
+		 FROM wallets w
+		 `+walletPromoJoin+`
+		 WHERE w.user_id = $1
+		 ORDER BY w.currency`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// AddTransaction moves amountCents into (or, when negative, out of) the
+// member's wallet in the currency and books the other side on the account
+// the kind of transaction implies: cash for top-ups, the gym's revenue for
+// charges and its refunds account for refunds. gymID is nil for
+// platform-wide charges and ignored for top-ups. Money taken out spends
+// the wallet's promotional credit first.
+func (r *repository) AddTransaction(ctx context.Context, userID int, amountCents int64, currency string, kind EntryKind, gymID *int) error {
+	if _, err := counterAccount(kind); err != nil {
+		return err
+	}
+
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	var w Wallet
-	err = tx.QueryRowxContext(ctx,
-		`SELECT id, user_id, balance_cents, currency, created_at, updated_at
-		 FROM wallets
-		 WHERE user_id = $1
-		 FOR UPDATE`,
-		userID,
-	).StructScan(&w)
+	if _, err := addTransaction(ctx, tx, userID, amountCents, currency, kind, gymID, details{}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Charge takes the price from the member's wallet in c.WalletCurrency. A
+// price in another currency is converted at the stored rate within the same
+// transaction, so the rate cannot change between quoting and charging.
+//
+// A charge that leaves the balance below the member's auto top-up threshold
+// starts a top-up, reported in the transaction's AutoTopUpPaymentID. When
+// the balance does not cover the price but the top-up would, the charge
+// fails with *AutoTopUpRequired instead.
+func (r *repository) Charge(ctx context.Context, c Charge) (*Transaction, error) {
+	if c.PriceCents <= 0 {
+		return nil, errors.New("charge amount must be positive")
+	}
+	if _, err := counterAccount(c.Kind); err != nil {
+		return nil, err
+	}
+	d := details{description: optional(c.Description), autoTopUp: true}
+	if len(c.Metadata) > 0 {
+		metadata, err := json.Marshal(c.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		d.metadata = optional(string(metadata))
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = tx.QueryRowxContext(ctx,
-				`INSERT INTO wallets (user_id)
-				 VALUES ($1)
-				 RETURNING id, user_id, balance_cents, currency, created_at, updated_at`,
-				userID,
-			).StructScan(&w)
-			if err != nil {
-				return err
-			}
-		} else {
-			return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	amountCents := c.PriceCents
+	if c.WalletCurrency != c.PriceCurrency {
+		from, err := money.Lookup(c.PriceCurrency)
+		if err != nil {
+			return nil, err
+		}
+		to, err := money.Lookup(c.WalletCurrency)
+		if err != nil {
+			return nil, err
 		}
+		rate, err := exchangeRate(ctx, tx, from.Code, to.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		amountCents = money.Convert(c.PriceCents, from, to, rate)
+		if amountCents <= 0 {
+			return nil, fmt.Errorf("%w: price converts to nothing", ErrNoExchangeRate)
+		}
+		d.conversion = &conversion{
+			amountCents: -c.PriceCents,
+			currency:    from.Code,
+			rate:        rate.FloatString(money.MaxRateDecimals),
+		}
+	}
+
+	txn, err := addTransaction(ctx, tx, c.UserID, -amountCents, c.WalletCurrency, c.Kind, c.GymID, d)
+	var required *AutoTopUpRequired
+	if errors.As(err, &required) {
+		// The charge has not touched the wallet; keep the top-up it started.
+		if commitErr := tx.Commit(); commitErr != nil {
+			return nil, commitErr
+		}
+		return nil, required
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// CreditTopUp credits the wallet with a top-up payment that has succeeded.
+// The pending payment is marked succeeded in the same transaction, so a
+// payment reported as succeeded more than once is credited only the first
+// time; later calls return false.
+func (r *repository) CreditTopUp(ctx context.Context, paymentID int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var payment struct {
+		UserID      int    `db:"user_id"`
+		AmountCents int64  `db:"amount_cents"`
+		Currency    string `db:"currency"`
+	}
+	err = tx.GetContext(ctx, &payment,
+		`UPDATE payments
+		 SET status = 'succeeded', failure_reason = NULL, updated_at = NOW()
+		 WHERE id = $1 AND status = 'pending' AND booking_id IS NULL AND subscription_id IS NULL
+		 RETURNING user_id, amount_cents, currency`,
+		paymentID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = addTransaction(ctx, tx, payment.UserID, payment.AmountCents, payment.Currency, EntryTopUp, nil, details{paymentID: &paymentID})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RefundTopUp takes a top-up the provider refunded back out of the wallet
+// and marks the payment refunded. It reports false when the payment is not
+// a succeeded top-up, so a refund reported twice is taken back once. The
+// money has already left, so the balance may go below zero; the member
+// then cannot spend until they top up again.
+func (r *repository) RefundTopUp(ctx context.Context, paymentID int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var payment struct {
+		UserID      int    `db:"user_id"`
+		AmountCents int64  `db:"amount_cents"`
+		Currency    string `db:"currency"`
+	}
+	err = tx.GetContext(ctx, &payment,
+		`UPDATE payments
+		 SET status = 'refunded', updated_at = NOW()
+		 WHERE id = $1 AND status = 'succeeded' AND booking_id IS NULL AND subscription_id IS NULL
+		 RETURNING user_id, amount_cents, currency`,
+		paymentID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = addTransaction(ctx, tx, payment.UserID, -payment.AmountCents, payment.Currency, EntryTopUpRefund, nil, details{paymentID: &paymentID})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// details are the optional columns of a wallet transaction.
+type details struct {
+	paymentID   *int
+	reason      *string
+	reference   *string
+	createdBy   *int
+	refundOf    *int
+	description *string
+	metadata    *string
+	conversion  *conversion
+	// autoTopUp starts the member's auto top-up when the transaction
+	// leaves the balance below its threshold.
+	autoTopUp bool
+}
+
+// conversion is the other side of a wallet transaction in the currency it
+// was priced in. amountCents has the sign of the wallet amount.
+type conversion struct {
+	amountCents int64
+	currency    string
+	rate        string
+}
+
+func addTransaction(ctx context.Context, tx *sqlx.Tx, userID int, amountCents int64, currency string, kind EntryKind, gymID *int, d details) (*Transaction, error) {
+	counterType, err := counterAccount(kind)
+	if err != nil {
+		return nil, err
+	}
+	if counterType == AccountCash || counterType == AccountPromoLiability {
+		gymID = nil
+	}
+
+	w, err := lockWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return nil, err
 	}
 
 	newBalance := w.BalanceCents + amountCents
-	if newBalance < 0 {
-		return ErrInsufficientBalance
+
+	// Promotional credit past its expiry stays in the balance until it is
+	// taken back, but cannot be spent.
+	// A refunded top-up takes back cash the provider has already returned,
+	// so it spends no promotional credit and may take the balance below
+	// zero.
+	var spend promoSpend
+	if amountCents < 0 && kind != EntryCreditExpired && kind != EntryTopUpRefund {
+		if spend, err = planPromoSpend(ctx, tx, w.ID, -amountCents); err != nil {
+			return nil, err
+		}
+	}
+	spendable := newBalance - spend.expiredCents
+
+	var autoTopUp *int
+	if d.autoTopUp {
+		if autoTopUp, err = reserveAutoTopUp(ctx, tx, userID, currency, spendable); err != nil {
+			return nil, err
+		}
+	}
+	if spendable < 0 && kind != EntryTopUpRefund {
+		if autoTopUp != nil {
+			return nil, &AutoTopUpRequired{PaymentID: *autoTopUp}
+		}
+		return nil, ErrInsufficientBalance
+	}
+	if err := spend.apply(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// A refund gives the part of the charge paid with promotional credit
+	// back as promotional credit, so only the rest becomes cash.
+	promoCents := spend.promoCents()
+	if amountCents > 0 && d.refundOf != nil {
+		restored, err := restorePromoSpend(ctx, tx, *d.refundOf)
+		if err != nil {
+			return nil, err
+		}
+		if restored > 0 {
+			promoCents = &restored
+		}
 	}
 
 	_, err = tx.ExecContext(ctx,
@@ -86,54 +371,1005 @@ func (r *repository) AddTransaction(ctx context.Context, userID int, amountCents
 		 WHERE id = $2`,
 		newBalance, w.ID,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	walletAccount, err := accountID(ctx, tx, AccountMemberWallet, currency, &userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	postings := []Posting{{AccountID: walletAccount, Currency: currency, AmountCents: amountCents}}
+
+	// A converted amount passes through the fx accounts of both currencies
+	// on its way to the counter account.
+	counterCurrency, counterCents := currency, amountCents
+	var original struct {
+		amountCents *int64
+		currency    *string
+		rate        *string
+	}
+	if c := d.conversion; c != nil {
+		fxWallet, err := accountID(ctx, tx, AccountFX, currency, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		fxPrice, err := accountID(ctx, tx, AccountFX, c.currency, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		postings = append(postings,
+			Posting{AccountID: fxWallet, Currency: currency, AmountCents: -amountCents},
+			Posting{AccountID: fxPrice, Currency: c.currency, AmountCents: c.amountCents},
+		)
+		counterCurrency, counterCents = c.currency, c.amountCents
+		original.amountCents, original.currency, original.rate = &c.amountCents, &c.currency, &c.rate
+	}
+
+	counter, err := accountID(ctx, tx, counterType, counterCurrency, nil, gymID)
+	if err != nil {
+		return nil, err
+	}
+	postings = append(postings, Posting{AccountID: counter, Currency: counterCurrency, AmountCents: -counterCents})
+
+	entryID, err := postEntry(ctx, tx, kind, postings)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Transaction
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO wallet_transactions
+			(wallet_id, amount_cents, type, balance_after, ledger_entry_id, payment_id, reason, reference, created_by, refund_of_id,
+			 original_amount_cents, original_currency, exchange_rate, description, metadata, promo_cents)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		 RETURNING id, wallet_id, amount_cents, type, balance_after, created_at, reason, reference, created_by, refund_of_id,
+			original_amount_cents, original_currency, exchange_rate, payment_id, description, metadata, promo_cents`,
+		w.ID, amountCents, kind, newBalance, entryID, d.paymentID, d.reason, d.reference, d.createdBy, d.refundOf,
+		original.amountCents, original.currency, original.rate, d.description, d.metadata, promoCents,
+	).StructScan(&created)
+	if err != nil {
+		return nil, err
+	}
+	if err := spend.record(ctx, tx, created.ID); err != nil {
+		return nil, err
+	}
+	created.Currency = currency
+	created.AutoTopUpPaymentID = autoTopUp
+	return &created, nil
+}
+
+// Adjust credits or, when AmountCents is negative, debits the member's
+// wallet against the adjustments account. A debit cannot take the balance
+// below zero.
+func (r *repository) Adjust(ctx context.Context, a Adjustment) (*Transaction, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, err := addTransaction(ctx, tx, a.UserID, a.AmountCents, a.Currency, EntryAdjustment, nil, details{
+		reason:    &a.Reason,
+		reference: optional(a.Reference),
+		createdBy: &a.AdminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// RefundTransaction returns the amount of a booking or subscription charge to
+// the wallet it was paid from, booked against the refunds account of the gym
+// the charge went to in the wallet's currency. The charge is locked while it
+// is checked, so it is refunded at most once.
+func (r *repository) RefundTransaction(ctx context.Context, refund TransactionRefund) (*Transaction, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, err := refundCharge(ctx, tx, refund.TransactionID, EntryAdminRefund, details{
+		reason:    &refund.Reason,
+		reference: optional(refund.Reference),
+		createdBy: &refund.AdminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// RefundCharge gives a booking or subscription charge back as a refund when
+// what it paid for could not be provided, like RefundTransaction but without
+// an admin.
+func (r *repository) RefundCharge(ctx context.Context, transactionID int) (*Transaction, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, err := refundCharge(ctx, tx, transactionID, EntryRefund, details{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// RefundBookingTx gives back what the member paid from their wallet for a
+// booking, within the caller's transaction. The refund points at the
+// booking's charge, so the part paid with promotional credit goes back into
+// the lots it came from. A charge support has already refunded is not
+// refunded again. Charges that are not linked to their booking are refunded
+// as amountCents of the member's own money.
+func RefundBookingTx(ctx context.Context, tx *sqlx.Tx, bookingID, userID int, amountCents int64, currency string, gymID *int) error {
+	var chargeID int
+	err := tx.GetContext(ctx, &chargeID,
+		`SELECT id FROM wallet_transactions WHERE booking_id = $1 AND type = $2`,
+		bookingID, EntryBookingCharge,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = addTransaction(ctx, tx, userID, amountCents, currency, EntryRefund, gymID, details{})
+		return err
+	}
 	if err != nil {
 		return err
 	}
 
+	_, err = refundCharge(ctx, tx, chargeID, EntryRefund, details{})
+	if errors.Is(err, ErrAlreadyRefunded) {
+		return nil
+	}
+	return err
+}
+
+// refundCharge returns the amount of booking or subscription charge
+// transactionID to the wallet it was paid from as a kind transaction that
+// points at the charge. The charge is locked while it is checked, so it is
+// refunded at most once.
+func refundCharge(ctx context.Context, tx *sqlx.Tx, transactionID int, kind EntryKind, d details) (*Transaction, error) {
+	var charge struct {
+		UserID        int    `db:"user_id"`
+		Currency      string `db:"currency"`
+		AmountCents   int64  `db:"amount_cents"`
+		Type          string `db:"type"`
+		LedgerEntryID *int64 `db:"ledger_entry_id"`
+	}
+	err := tx.GetContext(ctx, &charge,
+		`SELECT w.user_id, w.currency, wt.amount_cents, wt.type, wt.ledger_entry_id
+		 FROM wallet_transactions wt
+		 JOIN wallets w ON w.id = wt.wallet_id
+		 WHERE wt.id = $1
+		 FOR UPDATE OF wt`,
+		transactionID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	switch EntryKind(charge.Type) {
+	case EntryBookingCharge, EntrySubscriptionCharge:
+	default:
+		return nil, ErrNotRefundable
+	}
+	if charge.AmountCents >= 0 {
+		return nil, ErrNotRefundable
+	}
+
+	var refunded bool
+	err = tx.GetContext(ctx, &refunded,
+		`SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE refund_of_id = $1)`,
+		transactionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if refunded {
+		return nil, ErrAlreadyRefunded
+	}
+
+	// Charges made before the ledger existed have no entry and are
+	// refunded from the platform's refunds account.
+	var gymID *int
+	if charge.LedgerEntryID != nil {
+		err = tx.GetContext(ctx, &gymID,
+			`SELECT a.gym_id
+			 FROM ledger_postings p
+			 JOIN ledger_accounts a ON a.id = p.account_id
+			 WHERE p.entry_id = $1 AND a.type = $2`,
+			*charge.LedgerEntryID, AccountGymRevenue,
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	d.refundOf = &transactionID
+	return addTransaction(ctx, tx, charge.UserID, -charge.AmountCents, charge.Currency, kind, gymID, d)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// lockWallet locks the member's wallet in the currency for the rest of the
+// transaction, creating the wallet if the member has none yet.
+func lockWallet(ctx context.Context, tx *sqlx.Tx, userID int, currency string) (*Wallet, error) {
+	var w Wallet
+	err := tx.QueryRowxContext(ctx,
+		`SELECT id, user_id, balance_cents, currency, created_at, updated_at
+		 FROM wallets
+		 WHERE user_id = $1 AND currency = $2
+		 FOR UPDATE`,
+		userID, currency,
+	).StructScan(&w)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRowxContext(ctx,
+			`INSERT INTO wallets (user_id, currency)
+			 VALUES ($1, $2)
+			 RETURNING id, user_id, balance_cents, currency, created_at, updated_at`,
+			userID, currency,
+		).StructScan(&w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Transfer moves credit from one member's wallet to another's in the same
+// currency as a single ledger entry and records it in both wallets. Both
+// wallets are locked in
+// ascending user ID order, so two opposite transfers between the same
+// members wait for each other instead of deadlocking. The daily limit is
+// checked while the sender's wallet is locked, so concurrent transfers
+// cannot exceed it together. Promotional credit cannot be transferred, so
+// the sender's cash must cover the amount. It returns the sender's
+// transaction.
+func (r *repository) Transfer(ctx context.Context, t Transfer) (*Transaction, error) {
+	if t.FromUserID == t.ToUserID {
+		return nil, errors.New("cannot transfer to the same wallet")
+	}
+	if t.AmountCents <= 0 {
+		return nil, errors.New("transfer amount must be positive")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if t.DailyLimitCents > 0 {
+		// The limit spans all of the sender's wallets, which are not all
+		// locked below, so their transfers are made one at a time. The lock
+		// is taken before any wallet so it cannot deadlock with them.
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, transferLockClass, t.FromUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	first, second := t.FromUserID, t.ToUserID
+	if second < first {
+		first, second = second, first
+	}
+	wallets := make(map[int]*Wallet, 2)
+	for _, userID := range []int{first, second} {
+		w, err := lockWallet(ctx, tx, userID, t.Currency)
+		if err != nil {
+			return nil, err
+		}
+		wallets[userID] = w
+	}
+	from, to := wallets[t.FromUserID], wallets[t.ToUserID]
+
+	if t.DailyLimitCents > 0 {
+		sentCents, err := sentInLastDay(ctx, tx, t.FromUserID)
+		if err != nil {
+			return nil, err
+		}
+		amountCents, err := toDefaultCurrency(ctx, tx, t.AmountCents, t.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if sentCents+amountCents > t.DailyLimitCents {
+			return nil, ErrTransferLimitExceeded
+		}
+	}
+
+	var promoCents int64
+	err = tx.GetContext(ctx, &promoCents,
+		`SELECT COALESCE(SUM(remaining_cents), 0)
+		 FROM wallet_credit_lots
+		 WHERE wallet_id = $1 AND remaining_cents > 0`,
+		from.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if from.BalanceCents-promoCents < t.AmountCents {
+		return nil, ErrInsufficientBalance
+	}
+	fromBalance := from.BalanceCents - t.AmountCents
+	toBalance := to.BalanceCents + t.AmountCents
+
+	for _, w := range []struct {
+		id      int
+		balance int64
+	}{{from.ID, fromBalance}, {to.ID, toBalance}} {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE wallets
+			 SET balance_cents = $1, updated_at = NOW()
+			 WHERE id = $2`,
+			w.balance, w.id,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fromAccount, err := accountID(ctx, tx, AccountMemberWallet, t.Currency, &t.FromUserID, nil)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := accountID(ctx, tx, AccountMemberWallet, t.Currency, &t.ToUserID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entryID, err := postEntry(ctx, tx, EntryTransfer, []Posting{
+		{AccountID: fromAccount, Currency: t.Currency, AmountCents: -t.AmountCents},
+		{AccountID: toAccount, Currency: t.Currency, AmountCents: t.AmountCents},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	note := optional(t.Note)
+
+	var sent Transaction
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO wallet_transactions
+			(wallet_id, amount_cents, type, balance_after, ledger_entry_id, counterparty_user_id, note)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, wallet_id, amount_cents, type, balance_after, created_at, counterparty_user_id, note`,
+		from.ID, -t.AmountCents, TransactionTransferOut, fromBalance, entryID, t.ToUserID, note,
+	).StructScan(&sent)
+	if err != nil {
+		return nil, err
+	}
+
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO wallet_transactions (wallet_id, amount_cents, type, balance_after)
-		 VALUES ($1, $2, $3, $4)`,
-		w.ID, amountCents, txType, newBalance,
+		`INSERT INTO wallet_transactions
+			(wallet_id, amount_cents, type, balance_after, ledger_entry_id, counterparty_user_id, note)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		to.ID, t.AmountCents, TransactionTransferIn, toBalance, entryID, t.FromUserID, note,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	sent.Currency = t.Currency
+	return &sent, nil
+}
+
+// transferLockClass namespaces the advisory locks that serialise a
+// member's transfers.
+const transferLockClass = 1
+
+// sentInLastDay is what the member transferred out of all their wallets
+// in the last 24 hours, in the default currency at the stored rates. The
+// window rolls: it is not a calendar day.
+func sentInLastDay(ctx context.Context, tx *sqlx.Tx, userID int) (int64, error) {
+	var sent []struct {
+		Currency string `db:"currency"`
+		Cents    int64  `db:"cents"`
+	}
+	err := tx.SelectContext(ctx, &sent,
+		`SELECT w.currency, SUM(-t.amount_cents) AS cents
+		 FROM wallet_transactions t
+		 JOIN wallets w ON w.id = t.wallet_id
+		 WHERE w.user_id = $1 AND t.type = $2 AND t.created_at > NOW() - INTERVAL '24 hours'
+		 GROUP BY w.currency`,
+		userID, TransactionTransferOut,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, s := range sent {
+		cents, err := toDefaultCurrency(ctx, tx, s.Cents, s.Currency)
+		if err != nil {
+			return 0, err
+		}
+		total += cents
+	}
+	return total, nil
+}
+
+// toDefaultCurrency converts cents in the currency to the default currency
+// at the stored rate.
+func toDefaultCurrency(ctx context.Context, q sqlx.QueryerContext, cents int64, currency string) (int64, error) {
+	if currency == money.DefaultCurrency {
+		return cents, nil
+	}
+	from, err := money.Lookup(currency)
+	if err != nil {
+		return 0, err
+	}
+	to, err := money.Lookup(money.DefaultCurrency)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := exchangeRate(ctx, q, from.Code, to.Code)
+	if err != nil {
+		return 0, err
+	}
+	return money.Convert(cents, from, to, rate), nil
+}
+
+// counterAccount is the account a wallet transaction of the kind books
+// against.
+func counterAccount(kind EntryKind) (AccountType, error) {
+	switch kind {
+	case EntryTopUp, EntryTopUpRefund:
+		return AccountCash, nil
+	case EntryBookingCharge, EntrySubscriptionCharge:
+		return AccountGymRevenue, nil
+	case EntryRefund, EntryAdminRefund:
+		return AccountRefunds, nil
+	case EntryAdjustment:
+		return AccountAdjustments, nil
+	case EntryPromoCredit, EntryCreditExpired:
+		return AccountPromoLiability, nil
+	default:
+		return "", fmt.Errorf("unsupported wallet transaction %q", kind)
+	}
+}
+
+// accountID returns the ledger account of the type, currency and owner,
+// opening it on first use. When another transaction opens the same account
+// concurrently, the statement neither inserts nor sees it, so it is run a
+// second time.
+func accountID(ctx context.Context, tx *sqlx.Tx, accountType AccountType, currency string, userID, gymID *int) (int, error) {
+	id, err := openAccount(ctx, tx, accountType, currency, userID, gymID)
+	if errors.Is(err, sql.ErrNoRows) {
+		id, err = openAccount(ctx, tx, accountType, currency, userID, gymID)
+	}
+	return id, err
+}
+
+func openAccount(ctx context.Context, tx *sqlx.Tx, accountType AccountType, currency string, userID, gymID *int) (int, error) {
+	var id int
+	err := tx.GetContext(ctx, &id,
+		`WITH opened AS (
+			INSERT INTO ledger_accounts (type, currency, user_id, gym_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (type, currency, COALESCE(user_id, 0), COALESCE(gym_id, 0)) DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM opened
+		UNION ALL
+		SELECT id FROM ledger_accounts
+		WHERE type = $1 AND currency = $2 AND user_id IS NOT DISTINCT FROM $3 AND gym_id IS NOT DISTINCT FROM $4
+		LIMIT 1`,
+		accountType, currency, userID, gymID,
+	)
+	return id, err
 }
 
+// postEntry records a journal entry. It refuses postings that do not net to
+// zero in each currency; the database checks the same when the transaction
+// commits.
+func postEntry(ctx context.Context, tx *sqlx.Tx, kind EntryKind, postings []Posting) (int64, error) {
+	totals := make(map[string]int64)
+	for _, p := range postings {
+		if p.AmountCents == 0 {
+			return 0, fmt.Errorf("%w: empty posting", ErrUnbalancedEntry)
+		}
+		totals[p.Currency] += p.AmountCents
+	}
+	if len(postings) < 2 {
+		return 0, ErrUnbalancedEntry
+	}
+	for _, total := range totals {
+		if total != 0 {
+			return 0, ErrUnbalancedEntry
+		}
+	}
+
+	var entryID int64
+	err := tx.GetContext(ctx, &entryID,
+		`INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id`,
+		kind,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range postings {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO ledger_postings (entry_id, account_id, amount_cents)
+			 VALUES ($1, $2, $3)`,
+			entryID, p.AccountID, p.AmountCents,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return entryID, nil
+}
+
+// TopUp credits the member's wallet in the default currency directly,
+// without a payment behind it. Members top up through a payment provider
+// instead, see CreditTopUp.
 func (r *repository) TopUp(ctx context.Context, userID int, amountCents int64) error {
 	if amountCents <= 0 {
 		return errors.New("top up amount must be positive")
 	}
-	return r.AddTransaction(ctx, userID, amountCents, "topup")
+	return r.AddTransaction(ctx, userID, amountCents, money.DefaultCurrency, EntryTopUp, nil)
 }
 
-func (r *repository) GetTransactions(ctx context.Context, userID int, limit, offset int) ([]Transaction, error) {
-	if limit <= 0 {
-		limit = 50
+// transactionColumns are the columns of a wallet transaction as listed to
+// its member, from wallet_transactions wt joined with its wallet w and the
+// transfer counterparty u.
+const transactionColumns = `wt.id, wt.wallet_id, wt.amount_cents, wt.type, wt.balance_after, w.currency, wt.created_at,
+	wt.booking_id, wt.subscription_id, wt.payment_id, wt.description, wt.metadata, wt.promo_cents,
+	wt.original_amount_cents, wt.original_currency, wt.exchange_rate,
+	wt.counterparty_user_id, u.name AS counterparty_name, wt.note,
+	wt.reason, wt.reference, wt.created_by, wt.refund_of_id`
+
+// GetTransactions lists the transactions of all the member's wallets that
+// match the filter, newest first.
+func (r *repository) GetTransactions(ctx context.Context, userID int, filter TransactionFilter) ([]Transaction, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM wallet_transactions wt
+		JOIN wallets w ON w.id = wt.wallet_id
+		LEFT JOIN users u ON u.id = wt.counterparty_user_id
+		WHERE w.user_id = $1
+	`
+	args := []interface{}{userID}
+
+	if len(filter.Types) > 0 {
+		args = append(args, pq.Array(filter.Types))
+		query += fmt.Sprintf(" AND wt.type = ANY($%d)", len(args))
+	}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND wt.created_at >= $%d", len(args))
+	}
+
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		query += fmt.Sprintf(" AND wt.created_at < $%d", len(args))
 	}
 
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY wt.created_at DESC, wt.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	txs := []Transaction{}
+	if err := r.db.SelectContext(ctx, &txs, query, args...); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// LinkTransaction points a charge at the booking or subscription it paid
+// for. Fields of the link left nil are not changed.
+func (r *repository) LinkTransaction(ctx context.Context, transactionID int, link TransactionLink) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE wallet_transactions
+		 SET booking_id = COALESCE($2, booking_id), subscription_id = COALESCE($3, subscription_id)
+		 WHERE id = $1`,
+		transactionID, link.BookingID, link.SubscriptionID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// GetStatement returns the member's wallet in the currency over
+// [from, until): the balance before the period, its transactions oldest
+// first and the balance after it. A member without such a wallet gets an
+// empty statement.
+func (r *repository) GetStatement(ctx context.Context, userID int, currency string, from, until time.Time) (*Statement, error) {
+	st := &Statement{Currency: currency, Transactions: []StatementTransaction{}}
+
 	var walletID int
-	err := r.db.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1`, userID)
+	err := r.db.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1 AND currency = $2`, userID, currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The opening balance is the balance after the last earlier
+	// transaction. Balances carried over from before transactions were
+	// recorded have none, so fall back to the balance before the first
+	// transaction, or the wallet's balance if it has never moved.
+	err = r.db.GetContext(ctx, &st.OpeningBalanceCents, `
+		SELECT COALESCE(
+			(SELECT balance_after FROM wallet_transactions
+			 WHERE wallet_id = $1 AND created_at < $2
+			 ORDER BY created_at DESC, id DESC LIMIT 1),
+			(SELECT balance_after - amount_cents FROM wallet_transactions
+			 WHERE wallet_id = $1 AND created_at >= $2
+			 ORDER BY created_at, id LIMIT 1),
+			(SELECT balance_cents FROM wallets WHERE id = $1))
+	`, walletID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.SelectContext(ctx, &st.Transactions, `
+		SELECT `+transactionColumns+`,
+			(SELECT g.name FROM ledger_postings p
+			 JOIN ledger_accounts a ON a.id = p.account_id
+			 JOIN gyms g ON g.id = a.gym_id
+			 WHERE p.entry_id = wt.ledger_entry_id
+			 LIMIT 1) AS gym_name
+		FROM wallet_transactions wt
+		JOIN wallets w ON w.id = wt.wallet_id
+		LEFT JOIN users u ON u.id = wt.counterparty_user_id
+		WHERE wt.wallet_id = $1 AND wt.created_at >= $2 AND wt.created_at < $3
+		ORDER BY wt.created_at, wt.id
+	`, walletID, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	st.ClosingBalanceCents = st.OpeningBalanceCents
+	if n := len(st.Transactions); n > 0 {
+		st.ClosingBalanceCents = st.Transactions[n-1].BalanceAfter
+	}
+
+	return st, nil
+}
+
+// ListExchangeRates returns the stored exchange rates.
+func (r *repository) ListExchangeRates(ctx context.Context) ([]ExchangeRate, error) {
+	rates := []ExchangeRate{}
+	err := r.db.SelectContext(ctx, &rates,
+		`SELECT base, quote, rate::text AS rate, updated_by, updated_at
+		 FROM exchange_rates
+		 ORDER BY base, quote`)
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// SetExchangeRate stores the rate of base in quote. A pair is kept in one
+// direction only, so a stored inverse of it is replaced.
+func (r *repository) SetExchangeRate(ctx context.Context, base, quote, rate string, adminID int) (*ExchangeRate, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM exchange_rates WHERE base = $1 AND quote = $2`,
+		quote, base,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var er ExchangeRate
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO exchange_rates (base, quote, rate, updated_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (base, quote) DO UPDATE
+		 SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		 RETURNING base, quote, rate::text AS rate, updated_by, updated_at`,
+		base, quote, rate, adminID,
+	).StructScan(&er)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &er, nil
+}
+
+// ExchangeRate returns the price of one unit of from in units of to, from
+// the stored pair in either direction.
+func (r *repository) ExchangeRate(ctx context.Context, from, to string) (*big.Rat, error) {
+	return exchangeRate(ctx, r.db, from, to)
+}
+
+func exchangeRate(ctx context.Context, q sqlx.QueryerContext, from, to string) (*big.Rat, error) {
+	var stored struct {
+		Base string `db:"base"`
+		Rate string `db:"rate"`
+	}
+	err := sqlx.GetContext(ctx, q, &stored,
+		`SELECT base, rate::text AS rate
+		 FROM exchange_rates
+		 WHERE (base = $1 AND quote = $2) OR (base = $2 AND quote = $1)`,
+		from, to,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoExchangeRate
+		}
+		return nil, err
+	}
+
+	rate, ok := new(big.Rat).SetString(stored.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("stored exchange rate %s/%s is invalid: %q", from, to, stored.Rate)
+	}
+	if stored.Base != from {
+		rate.Inv(rate)
+	}
+	return rate, nil
+}
+
+// GetAccountBalances lists every ledger account with its derived balance.
+func (r *repository) GetAccountBalances(ctx context.Context) ([]AccountBalance, error) {
+	var balances []AccountBalance
+	err := r.db.SelectContext(ctx, &balances, `
+		SELECT a.id, a.type, a.currency, a.user_id, a.gym_id, a.created_at,
+			COALESCE(SUM(p.amount_cents), 0) AS balance_cents
+		FROM ledger_accounts a
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		GROUP BY a.id
+		ORDER BY a.type, a.currency, a.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// CheckLedger verifies the ledger invariants on one snapshot of the data.
+func (r *repository) CheckLedger(ctx context.Context) (*LedgerCheck, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	check := &LedgerCheck{
+		TotalsCents:       map[string]int64{},
+		UnbalancedEntries: []int64{},
+		WalletMismatches:  []WalletMismatch{},
+	}
+
+	var totals []struct {
+		Currency   string `db:"currency"`
+		TotalCents int64  `db:"total_cents"`
+	}
+	err = tx.SelectContext(ctx, &totals, `
+		SELECT a.currency, SUM(p.amount_cents) AS total_cents
+		FROM ledger_postings p
+		JOIN ledger_accounts a ON a.id = p.account_id
+		GROUP BY a.currency
+		ORDER BY a.currency
+	`)
+	if err != nil {
+		return nil, err
+	}
+	balanced := true
+	for _, t := range totals {
+		check.TotalsCents[t.Currency] = t.TotalCents
+		if t.TotalCents != 0 {
+			balanced = false
+		}
+	}
+
+	err = tx.SelectContext(ctx, &check.UnbalancedEntries, `
+		SELECT DISTINCT entry_id
+		FROM (
+			SELECT p.entry_id
+			FROM ledger_postings p
+			JOIN ledger_accounts a ON a.id = p.account_id
+			GROUP BY p.entry_id, a.currency
+			HAVING SUM(p.amount_cents) <> 0
+		) unbalanced
+		ORDER BY entry_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.SelectContext(ctx, &check.WalletMismatches, `
+		SELECT w.id AS wallet_id, w.user_id, w.balance_cents,
+			COALESCE(SUM(p.amount_cents), 0) AS ledger_cents
+		FROM wallets w
+		LEFT JOIN ledger_accounts a
+			ON a.type = 'member_wallet' AND a.user_id = w.user_id AND a.currency = w.currency AND a.gym_id IS NULL
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		GROUP BY w.id
+		HAVING w.balance_cents <> COALESCE(SUM(p.amount_cents), 0)
+		ORDER BY w.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	check.OK = balanced && len(check.UnbalancedEntries) == 0 && len(check.WalletMismatches) == 0
+	return check, nil
+}
+
+// ReconcileWallets checks every wallet against its transaction history in
+// one snapshot, streaming the transactions wallet by wallet.
+func (r *repository) ReconcileWallets(ctx context.Context) (*Reconciliation, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &Reconciliation{CheckedAt: time.Now().UTC(), Discrepancies: []WalletDiscrepancy{}}
+
+	var wallets []WalletDiscrepancy
+	err = tx.SelectContext(ctx, &wallets,
+		`SELECT id AS wallet_id, user_id, currency, balance_cents FROM wallets ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryxContext(ctx, `
+		SELECT wallet_id, id, amount_cents, balance_after, type
+		FROM wallet_transactions
+		WHERE wallet_id IS NOT NULL
+		ORDER BY wallet_id, created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var next *chainRow
+	advance := func() error {
+		next = nil
+		if !rows.Next() {
+			return rows.Err()
+		}
+		var row chainRow
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		next = &row
+		return nil
+	}
+	if err := advance(); err != nil {
+		return nil, err
+	}
+
+	// Both are ordered by wallet, so each wallet takes the run of
+	// transactions that belongs to it.
+	for _, w := range wallets {
+		var history []chainRow
+		for next != nil && next.WalletID == w.WalletID {
+			history = append(history, *next)
+			if err := advance(); err != nil {
+				return nil, err
+			}
+		}
+		if d := checkWallet(w, history); d != nil {
+			result.Discrepancies = append(result.Discrepancies, *d)
+		}
+	}
+
+	result.WalletsChecked = len(wallets)
+	result.OK = len(result.Discrepancies) == 0
+	return result, nil
+}
+
+// CorrectWallet records an approved reconciliation correction: a
+// transaction for the difference between the balance and the transaction
+// history, which also starts the balance_after chain afresh. The balance
+// and the ledger stay as they are, so a wallet whose balance disagrees with
+// its ledger account is refused; that needs an adjustment instead.
+func (r *repository) CorrectWallet(ctx context.Context, c WalletCorrection) (*Transaction, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var w WalletDiscrepancy
+	err = tx.GetContext(ctx, &w,
+		`SELECT id AS wallet_id, user_id, currency, balance_cents FROM wallets WHERE id = $1 FOR UPDATE`,
+		c.WalletID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return []Transaction{}, nil
+			return nil, ErrWalletNotFound
 		}
 		return nil, err
 	}
 
-	var txs []Transaction
-	err = r.db.SelectContext(ctx, &txs, `
-		SELECT id, wallet_id, amount_cents, type, balance_after, created_at
+	var history []chainRow
+	err = tx.SelectContext(ctx, &history, `
+		SELECT wallet_id, id, amount_cents, balance_after, type
 		FROM wallet_transactions
 		WHERE wallet_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`, walletID, limit, offset)
+		ORDER BY created_at, id
+	`, w.WalletID)
 	if err != nil {
 		return nil, err
 	}
 
-	return txs, nil
+	d := checkWallet(w, history)
+	if d == nil {
+		return nil, ErrWalletConsistent
+	}
+	if d.DifferenceCents != c.DifferenceCents {
+		return nil, ErrReconciliationChanged
+	}
+
+	var ledgerCents int64
+	err = tx.GetContext(ctx, &ledgerCents, `
+		SELECT COALESCE(SUM(p.amount_cents), 0)
+		FROM ledger_accounts a
+		JOIN ledger_postings p ON p.account_id = a.id
+		WHERE a.type = 'member_wallet' AND a.user_id = $1 AND a.currency = $2 AND a.gym_id IS NULL
+	`, w.UserID, w.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if ledgerCents != w.BalanceCents {
+		return nil, ErrWalletLedgerMismatch
+	}
+
+	var created Transaction
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO wallet_transactions (wallet_id, amount_cents, type, balance_after, reason, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, wallet_id, amount_cents, type, balance_after, created_at, reason, created_by`,
+		w.WalletID, d.DifferenceCents, TransactionReconciliation, w.BalanceCents, c.Reason, c.AdminID,
+	).StructScan(&created)
+	if err != nil {
+		return nil, err
+	}
+	created.Currency = w.Currency
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &created, nil
 }
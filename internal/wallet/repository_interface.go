@@ -1,10 +1,34 @@
 package wallet
 
-import "context"
+import (
+	"context"
+	"math/big"
+	"time"
+)
 
 type Repository interface {
-	GetOrCreateWallet(ctx context.Context, userID int) (*Wallet, error)
-	AddTransaction(ctx context.Context, userID int, amountCents int64, txType string) error
+	GetOrCreateWallet(ctx context.Context, userID int, currency string) (*Wallet, error)
+	ListWallets(ctx context.Context, userID int) ([]Wallet, error)
+	AddTransaction(ctx context.Context, userID int, amountCents int64, currency string, kind EntryKind, gymID *int) error
+	Charge(ctx context.Context, c Charge) (*Transaction, error)
 	TopUp(ctx context.Context, userID int, amountCents int64) error
-	GetTransactions(ctx context.Context, userID int, limit, offset int) ([]Transaction, error)
+	CreditTopUp(ctx context.Context, paymentID int) (bool, error)
+	RefundTopUp(ctx context.Context, paymentID int) (bool, error)
+	GetTransactions(ctx context.Context, userID int, filter TransactionFilter) ([]Transaction, error)
+	LinkTransaction(ctx context.Context, transactionID int, link TransactionLink) error
+	GetStatement(ctx context.Context, userID int, currency string, from, until time.Time) (*Statement, error)
+	GetAccountBalances(ctx context.Context) ([]AccountBalance, error)
+	CheckLedger(ctx context.Context) (*LedgerCheck, error)
+	ReconcileWallets(ctx context.Context) (*Reconciliation, error)
+	CorrectWallet(ctx context.Context, c WalletCorrection) (*Transaction, error)
+	Transfer(ctx context.Context, t Transfer) (*Transaction, error)
+	Adjust(ctx context.Context, a Adjustment) (*Transaction, error)
+	RefundTransaction(ctx context.Context, refund TransactionRefund) (*Transaction, error)
+	RefundCharge(ctx context.Context, transactionID int) (*Transaction, error)
+	GrantPromoCredit(ctx context.Context, g PromoGrant) (*Transaction, error)
+	ListCreditLots(ctx context.Context, userID int) ([]CreditLot, error)
+	ExpireCredits(ctx context.Context) ([]Transaction, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	SetExchangeRate(ctx context.Context, base, quote, rate string, adminID int) (*ExchangeRate, error)
+	ExchangeRate(ctx context.Context, from, to string) (*big.Rat, error)
 }
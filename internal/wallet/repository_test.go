@@ -9,6 +9,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,6 +24,53 @@ func setupWalletMock(t *testing.T) (Repository, sqlmock.Sqlmock, func()) {
 	return repo, mock, closer
 }
 
+const insertTransaction = `INSERT INTO wallet_transactions\s+\(wallet_id, amount_cents, type, balance_after, ledger_entry_id, payment_id, reason, reference, created_by, refund_of_id,\s+original_amount_cents, original_currency, exchange_rate, description, metadata, promo_cents\)`
+
+func transactionRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "wallet_id", "amount_cents", "type", "balance_after", "created_at",
+		"reason", "reference", "created_by", "refund_of_id", "original_amount_cents", "original_currency", "exchange_rate"})
+}
+
+type creditLotRow struct {
+	id        int
+	remaining int64
+	expired   bool
+}
+
+// expectCreditLots expects the open credit lots of the wallet to be locked
+// for a debit, the one that expires first first.
+func expectCreditLots(mock sqlmock.Sqlmock, walletID int, lots ...creditLotRow) {
+	rows := sqlmock.NewRows([]string{"id", "remaining_cents", "expired"})
+	for _, l := range lots {
+		rows.AddRow(l.id, l.remaining, l.expired)
+	}
+	mock.ExpectQuery(`SELECT id, remaining_cents, expires_at <= NOW\(\) AS expired\s+FROM wallet_credit_lots\s+WHERE wallet_id = \$1 AND remaining_cents > 0\s+ORDER BY expires_at, id\s+FOR UPDATE`).
+		WithArgs(walletID).
+		WillReturnRows(rows)
+}
+
+type lotSpendRow struct {
+	lotID int
+	cents int64
+}
+
+// expectLotSpends expects a refund of charge transactionID to look up what
+// the charge took from the credit lots and put it back.
+func expectLotSpends(mock sqlmock.Sqlmock, transactionID int, spends ...lotSpendRow) {
+	rows := sqlmock.NewRows([]string{"lot_id", "cents"})
+	for _, s := range spends {
+		rows.AddRow(s.lotID, s.cents)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT lot_id, cents FROM wallet_credit_lot_spends WHERE transaction_id = $1 ORDER BY lot_id")).
+		WithArgs(transactionID).
+		WillReturnRows(rows)
+	for _, s := range spends {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE wallet_credit_lots SET remaining_cents = remaining_cents + $2 WHERE id = $1")).
+			WithArgs(s.lotID, s.cents).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
 func TestGetOrCreateWallet_WhenNotExists(t *testing.T) {
 	repo, mock, close := setupWalletMock(t)
 	defer close()
@@ -30,18 +78,19 @@ func TestGetOrCreateWallet_WhenNotExists(t *testing.T) {
 	ctx := context.Background()
 
 	// GetContext should return no rows -> insert
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM wallets WHERE user_id = $1")).
-		WithArgs(10).
+	mock.ExpectQuery(`SELECT w.id, w.user_id, w.balance_cents, .* FROM wallets w\s+LEFT JOIN LATERAL .* WHERE w.user_id = \$1 AND w.currency = \$2`).
+		WithArgs(10, "USD").
 		WillReturnError(sql.ErrNoRows)
 
 	// Insert returning
-	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO wallets (user_id) VALUES ($1) RETURNING id, user_id, balance_cents, currency, created_at, updated_at")).
-		WithArgs(10).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(5, 10, 1000, "KZT", time.Now(), time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO wallets (user_id, currency) VALUES ($1, $2) RETURNING id, user_id, balance_cents, currency, created_at, updated_at")).
+		WithArgs(10, "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(5, 10, 0, "USD", time.Now(), time.Now()))
 
-	w, err := repo.GetOrCreateWallet(ctx, 10)
+	w, err := repo.GetOrCreateWallet(ctx, 10, "USD")
 	require.NoError(t, err)
 	require.Equal(t, 5, w.ID)
+	require.Equal(t, "USD", w.Currency)
 }
 
 func TestAddTransaction_Success_UpdateAndInsert(t *testing.T) {
@@ -49,27 +98,1322 @@ func TestAddTransaction_Success_UpdateAndInsert(t *testing.T) {
 	defer close()
 
 	ctx := context.Background()
+	gymID := 3
 
 	// Begin
 	mock.ExpectBegin()
 
 	// SELECT FOR UPDATE returns existing wallet
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 FOR UPDATE")).
-		WithArgs(20).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 2000, "KZT", time.Now(), time.Now()))
+	expectCreditLots(mock, 7)
 
 	// UPDATE wallets
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
 		WithArgs(1500, 7).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
+	// Member wallet and gym revenue accounts
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountGymRevenue, "KZT", nil, gymID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	// Journal entry with balanced postings
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryBookingCharge).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(40, 11, -500).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(40, 2, 500).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
 	// INSERT wallet_transactions
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO wallet_transactions (wallet_id, amount_cents, type, balance_after) VALUES ($1, $2, $3, $4)")).
-		WithArgs(7, -500, "booking_payment", 1500).
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, -500, EntryBookingCharge, 1500, 40, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(1, 7, -500, EntryBookingCharge, 1500, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+
+	mock.ExpectCommit()
+
+	err := repo.AddTransaction(ctx, 20, -500, "KZT", EntryBookingCharge, &gymID)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddTransaction_InsufficientBalance(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 300, "KZT", time.Now(), time.Now()))
+	expectCreditLots(mock, 7)
+	mock.ExpectRollback()
+
+	err := repo.AddTransaction(context.Background(), 20, -500, "KZT", EntrySubscriptionCharge, nil)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreditTopUp(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	ctx := context.Background()
+	claim := `UPDATE payments\s+SET status = 'succeeded', failure_reason = NULL, updated_at = NOW\(\)\s+WHERE id = \$1 AND status = 'pending'`
+
+	// First report: the payment is claimed and credited
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "amount_cents", "currency"}).AddRow(20, 5000, "KZT"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 1000, "KZT", time.Now(), time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(6000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountCash, "KZT", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryTopUp).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(41, 11, 5000).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(41, 1, -5000).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, 5000, EntryTopUp, 6000, 41, 9, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(1, 7, 5000, EntryTopUp, 6000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectCommit()
+
+	// Second report: the payment is no longer pending
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "amount_cents", "currency"}))
+	mock.ExpectRollback()
+
+	credited, err := repo.CreditTopUp(ctx, 9)
+	require.NoError(t, err)
+	require.True(t, credited)
+
+	credited, err = repo.CreditTopUp(ctx, 9)
+	require.NoError(t, err)
+	require.False(t, credited)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundTopUp(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	ctx := context.Background()
+	claim := `UPDATE payments\s+SET status = 'refunded', updated_at = NOW\(\)\s+WHERE id = \$1 AND status = 'succeeded'`
+
+	// The member spent most of the top-up already: the refund is still
+	// taken back in full and leaves the balance below zero, without
+	// touching promotional credit.
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "amount_cents", "currency"}).AddRow(20, 5000, "KZT"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 1000, "KZT", time.Now(), time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(-4000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountCash, "KZT", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryTopUpRefund).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(42, 11, -5000).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(42, 1, 5000).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, -5000, EntryTopUpRefund, -4000, 42, 9, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(2, 7, -5000, EntryTopUpRefund, -4000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectCommit()
+
+	// A repeated report finds the payment refunded already
+	mock.ExpectBegin()
+	mock.ExpectQuery(claim).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "amount_cents", "currency"}))
+	mock.ExpectRollback()
+
+	refunded, err := repo.RefundTopUp(ctx, 9)
+	require.NoError(t, err)
+	require.True(t, refunded)
+
+	refunded, err = repo.RefundTopUp(ctx, 9)
+	require.NoError(t, err)
+	require.False(t, refunded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddTransaction_UnsupportedKind(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	err := repo.AddTransaction(context.Background(), 20, 500, "KZT", EntryOpeningBalance, nil)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectLockWallets expects the wallets of both members to be locked in
+// ascending user ID order.
+func expectLockWallets(mock sqlmock.Sqlmock, balances map[int]int64) {
+	for _, userID := range []int{10, 20} {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+			WithArgs(userID, "KZT").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).
+				AddRow(userID/10, userID, balances[userID], "KZT", time.Now(), time.Now()))
+	}
+}
+
+func TestTransfer_Success(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// User 20 sends to user 10: wallet 1 belongs to user 10, wallet 2 to user 20.
+	mock.ExpectBegin()
+	expectTransferLock(mock, 20)
+	expectLockWallets(mock, map[int]int64{10: 100, 20: 5000})
+
+	expectSentInLastDay(mock, 20, sentRow{"KZT", 1000})
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(remaining_cents\), 0\)\s+FROM wallet_credit_lots`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(3000, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(2100, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(22))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 10, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryTransfer).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(50))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(50, 22, -2000).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(50, 11, 2000).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	note := "for Saturday"
+	mock.ExpectQuery(`INSERT INTO wallet_transactions\s+\(wallet_id, amount_cents, type, balance_after, ledger_entry_id, counterparty_user_id, note\).*RETURNING`).
+		WithArgs(2, -2000, TransactionTransferOut, 3000, 50, 10, &note).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount_cents", "type", "balance_after", "created_at", "counterparty_user_id", "note"}).
+			AddRow(70, 2, -2000, TransactionTransferOut, 3000, time.Now(), 10, note))
+	mock.ExpectExec(`INSERT INTO wallet_transactions\s+\(wallet_id, amount_cents, type, balance_after, ledger_entry_id, counterparty_user_id, note\)`).
+		WithArgs(1, 2000, TransactionTransferIn, 2100, 50, 20, &note).
+		WillReturnResult(sqlmock.NewResult(71, 1))
+
+	mock.ExpectCommit()
+
+	txn, err := repo.Transfer(context.Background(), Transfer{
+		FromUserID:      20,
+		ToUserID:        10,
+		AmountCents:     2000,
+		Currency:        "KZT",
+		Note:            note,
+		DailyLimitCents: 5000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 70, txn.ID)
+	require.Equal(t, int64(-2000), txn.AmountCents)
+	require.Equal(t, 10, *txn.CounterpartyUserID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransfer_DailyLimitExceeded(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	expectTransferLock(mock, 20)
+	expectLockWallets(mock, map[int]int64{10: 100, 20: 5000})
+	expectSentInLastDay(mock, 20, sentRow{"KZT", 4000})
+	mock.ExpectRollback()
+
+	_, err := repo.Transfer(context.Background(), Transfer{
+		FromUserID:      20,
+		ToUserID:        10,
+		AmountCents:     2000,
+		Currency:        "KZT",
+		DailyLimitCents: 5000,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransfer_DailyLimitSpansCurrencies(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// 3 USD sent earlier from the USD wallet count as 1500 KZT.
+	mock.ExpectBegin()
+	expectTransferLock(mock, 20)
+	expectLockWallets(mock, map[int]int64{10: 100, 20: 5000})
+	expectSentInLastDay(mock, 20, sentRow{"KZT", 2000}, sentRow{"USD", 300})
+	mock.ExpectQuery(`SELECT base, rate::text AS rate\s+FROM exchange_rates`).
+		WithArgs("USD", "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"base", "rate"}).AddRow("USD", "500"))
+	mock.ExpectRollback()
+
+	_, err := repo.Transfer(context.Background(), Transfer{
+		FromUserID:      20,
+		ToUserID:        10,
+		AmountCents:     2000,
+		Currency:        "KZT",
+		DailyLimitCents: 5000,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectTransferLock expects the sender's transfers to be serialised.
+func expectTransferLock(mock sqlmock.Sqlmock, userID int) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, $2)")).
+		WithArgs(transferLockClass, userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+type sentRow struct {
+	currency string
+	cents    int64
+}
+
+// expectSentInLastDay expects the member's transfers out of the last 24
+// hours to be totalled per currency.
+func expectSentInLastDay(mock sqlmock.Sqlmock, userID int, sent ...sentRow) {
+	rows := sqlmock.NewRows([]string{"currency", "cents"})
+	for _, s := range sent {
+		rows.AddRow(s.currency, s.cents)
+	}
+	mock.ExpectQuery(`SELECT w.currency, SUM\(-t.amount_cents\) AS cents\s+FROM wallet_transactions t\s+JOIN wallets w`).
+		WithArgs(userID, TransactionTransferOut).
+		WillReturnRows(rows)
+}
+
+func TestTransfer_InsufficientBalance(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// Without a limit the sent total is not queried.
+	mock.ExpectBegin()
+	expectLockWallets(mock, map[int]int64{10: 100, 20: 5000})
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(remaining_cents\), 0\)\s+FROM wallet_credit_lots`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectRollback()
+
+	_, err := repo.Transfer(context.Background(), Transfer{
+		FromUserID:  10,
+		ToUserID:    20,
+		AmountCents: 2000,
+		Currency:    "KZT",
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdjust_Credit(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 1000, "KZT", time.Now(), time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(2500, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountAdjustments, "KZT", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryAdjustment).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(60))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(60, 11, 1500).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(60, 5, -1500).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	reason, reference := "Charged twice", "SUP-1"
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, 1500, EntryAdjustment, 2500, 60, nil, &reason, &reference, 99, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(80, 7, 1500, EntryAdjustment, 2500, time.Now(), reason, reference, 99, nil, nil, nil, nil))
+	mock.ExpectCommit()
+
+	txn, err := repo.Adjust(context.Background(), Adjustment{
+		UserID:      20,
+		AmountCents: 1500,
+		Currency:    "KZT",
+		Reason:      reason,
+		Reference:   reference,
+		AdminID:     99,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 80, txn.ID)
+	require.Equal(t, 99, *txn.CreatedBy)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+const selectCharge = `SELECT w.user_id, w.currency, wt.amount_cents, wt.type, wt.ledger_entry_id\s+FROM wallet_transactions wt`
+
+func TestRefundTransaction_Success(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCharge).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", -500, EntryBookingCharge, 40))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE refund_of_id = $1)")).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT a.gym_id\s+FROM ledger_postings p`).
+		WithArgs(40, AccountGymRevenue).
+		WillReturnRows(sqlmock.NewRows([]string{"gym_id"}).AddRow(3))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, 1000, "KZT", time.Now(), time.Now()))
+	expectLotSpends(mock, 30)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(1500, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountRefunds, "KZT", nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryAdminRefund).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(61))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(61, 11, 500).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(61, 4, -500).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	reason := "Class cancelled"
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, 500, EntryAdminRefund, 1500, 61, nil, &reason, nil, 99, 30, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(81, 7, 500, EntryAdminRefund, 1500, time.Now(), reason, nil, 99, 30, nil, nil, nil))
+	mock.ExpectCommit()
+
+	txn, err := repo.RefundTransaction(context.Background(), TransactionRefund{
+		TransactionID: 30,
+		Reason:        reason,
+		AdminID:       99,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 30, *txn.RefundOfID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundTransaction_RestoresPromoCredit(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// The 1,500.00 charge took 1,000.00 and 200.00 from two credit lots and
+	// 300.00 of the member's own money.
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCharge).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", -150000, EntryBookingCharge, 40))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE refund_of_id = $1)")).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT a.gym_id\s+FROM ledger_postings p`).
+		WithArgs(40, AccountGymRevenue).
+		WillReturnRows(sqlmock.NewRows([]string{"gym_id"}).AddRow(3))
+	expectLockWallet(mock, 50000)
+	expectLotSpends(mock, 30, lotSpendRow{lotID: 2, cents: 100000}, lotSpendRow{lotID: 3, cents: 20000})
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(200000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectPostings(mock, AccountRefunds, 3, EntryAdminRefund, 150000)
+
+	reason := "Class cancelled"
+	promo := int64(120000)
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, 150000, EntryAdminRefund, 200000, 44, nil, &reason, nil, 99, 30, nil, nil, nil, nil, nil, &promo).
+		WillReturnRows(transactionRows().AddRow(81, 7, 150000, EntryAdminRefund, 200000, time.Now(), reason, nil, 99, 30, nil, nil, nil))
+	mock.ExpectCommit()
+
+	_, err := repo.RefundTransaction(context.Background(), TransactionRefund{TransactionID: 30, Reason: reason, AdminID: 99})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundBookingTx(t *testing.T) {
+	ctx := context.Background()
+	gymID := 3
+	selectBookingCharge := regexp.QuoteMeta("SELECT id FROM wallet_transactions WHERE booking_id = $1 AND type = $2")
+	refunded := regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE refund_of_id = $1)")
+
+	begin := func(t *testing.T) (*sqlx.Tx, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectBegin()
+		tx, err := sqlx.NewDb(db, "sqlmock").Beginx()
+		require.NoError(t, err)
+		return tx, mock
+	}
+
+	t.Run("puts the promotional credit back into its lots", func(t *testing.T) {
+		tx, mock := begin(t)
+
+		// The 1,500.00 booking took 1,000.00 from lot 2.
+		mock.ExpectQuery(selectBookingCharge).
+			WithArgs(12, EntryBookingCharge).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+		mock.ExpectQuery(selectCharge).
+			WithArgs(30).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", -150000, EntryBookingCharge, 40))
+		mock.ExpectQuery(refunded).WithArgs(30).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`SELECT a.gym_id\s+FROM ledger_postings p`).
+			WithArgs(40, AccountGymRevenue).
+			WillReturnRows(sqlmock.NewRows([]string{"gym_id"}).AddRow(gymID))
+		expectLockWallet(mock, 0)
+		expectLotSpends(mock, 30, lotSpendRow{lotID: 2, cents: 100000})
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+			WithArgs(150000, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectPostings(mock, AccountRefunds, gymID, EntryRefund, 150000)
+		promo := int64(100000)
+		mock.ExpectQuery(insertTransaction).
+			WithArgs(7, 150000, EntryRefund, 150000, 44, nil, nil, nil, nil, 30, nil, nil, nil, nil, nil, &promo).
+			WillReturnRows(transactionRows().AddRow(82, 7, 150000, EntryRefund, 150000, time.Now(), nil, nil, nil, 30, nil, nil, nil))
+
+		require.NoError(t, RefundBookingTx(ctx, tx, 12, 20, 150000, "KZT", &gymID))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("refunds an unlinked booking as cash", func(t *testing.T) {
+		tx, mock := begin(t)
+
+		mock.ExpectQuery(selectBookingCharge).
+			WithArgs(12, EntryBookingCharge).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		expectLockWallet(mock, 0)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+			WithArgs(1000, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectPostings(mock, AccountRefunds, gymID, EntryRefund, 1000)
+		mock.ExpectQuery(insertTransaction).
+			WithArgs(7, 1000, EntryRefund, 1000, 44, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+			WillReturnRows(transactionRows().AddRow(83, 7, 1000, EntryRefund, 1000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+
+		require.NoError(t, RefundBookingTx(ctx, tx, 12, 20, 1000, "KZT", &gymID))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips a charge support has refunded", func(t *testing.T) {
+		tx, mock := begin(t)
+
+		mock.ExpectQuery(selectBookingCharge).
+			WithArgs(12, EntryBookingCharge).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+		mock.ExpectQuery(selectCharge).
+			WithArgs(30).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", -150000, EntryBookingCharge, 40))
+		mock.ExpectQuery(refunded).WithArgs(30).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		require.NoError(t, RefundBookingTx(ctx, tx, 12, 20, 150000, "KZT", &gymID))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRefundTransaction_AlreadyRefunded(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCharge).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", -500, EntryBookingCharge, 40))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE refund_of_id = $1)")).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err := repo.RefundTransaction(context.Background(), TransactionRefund{TransactionID: 30, Reason: "again", AdminID: 99})
+	require.ErrorIs(t, err, ErrAlreadyRefunded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundTransaction_NotRefundable(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectCharge).
+		WithArgs(31).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount_cents", "type", "ledger_entry_id"}).AddRow(20, "KZT", 5000, EntryTopUp, 41))
+	mock.ExpectRollback()
+
+	_, err := repo.RefundTransaction(context.Background(), TransactionRefund{TransactionID: 31, Reason: "no", AdminID: 99})
+	require.ErrorIs(t, err, ErrNotRefundable)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostEntry_Unbalanced(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "sqlmock")
+
+	mock.ExpectBegin()
+	tx, err := dbx.Beginx()
+	require.NoError(t, err)
+
+	_, err = postEntry(context.Background(), tx, EntryRefund, []Posting{
+		{AccountID: 1, Currency: "KZT", AmountCents: 500},
+		{AccountID: 2, Currency: "KZT", AmountCents: -400},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedEntry)
+
+	// Netting to zero across currencies is not enough.
+	_, err = postEntry(context.Background(), tx, EntryBookingCharge, []Posting{
+		{AccountID: 1, Currency: "USD", AmountCents: -500},
+		{AccountID: 2, Currency: "KZT", AmountCents: 500},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedEntry)
+
+	_, err = postEntry(context.Background(), tx, EntryRefund, []Posting{{AccountID: 1, AmountCents: 0}, {AccountID: 2, AmountCents: 0}})
+	require.ErrorIs(t, err, ErrUnbalancedEntry)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAccountBalances(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectQuery(`SELECT a.id, a.type, a.currency, .* COALESCE\(SUM\(p.amount_cents\), 0\) AS balance_cents FROM ledger_accounts a LEFT JOIN ledger_postings p ON p.account_id = a.id GROUP BY a.id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "currency", "user_id", "gym_id", "created_at", "balance_cents"}).
+			AddRow(1, "cash", "KZT", nil, nil, time.Now(), -2000).
+			AddRow(2, "gym_revenue", "KZT", nil, 3, time.Now(), 500).
+			AddRow(11, "member_wallet", "KZT", 20, nil, time.Now(), 1500))
+
+	balances, err := repo.GetAccountBalances(context.Background())
+	require.NoError(t, err)
+	require.Len(t, balances, 3)
+	require.Equal(t, AccountGymRevenue, balances[1].Type)
+	require.Equal(t, 3, *balances[1].GymID)
+	require.Equal(t, int64(1500), balances[2].BalanceCents)
+}
+
+func TestCheckLedger(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	t.Run("balanced", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT a.currency, SUM\(p.amount_cents\) AS total_cents FROM ledger_postings p .* GROUP BY a.currency`).
+			WillReturnRows(sqlmock.NewRows([]string{"currency", "total_cents"}).AddRow("KZT", 0).AddRow("USD", 0))
+		mock.ExpectQuery(`SELECT DISTINCT entry_id .* GROUP BY p.entry_id, a.currency HAVING SUM\(p.amount_cents\) <> 0`).
+			WillReturnRows(sqlmock.NewRows([]string{"entry_id"}))
+		mock.ExpectQuery(`FROM wallets w .* HAVING w.balance_cents <> COALESCE\(SUM\(p.amount_cents\), 0\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "user_id", "balance_cents", "ledger_cents"}))
+		mock.ExpectRollback()
+
+		check, err := repo.CheckLedger(context.Background())
+		require.NoError(t, err)
+		require.True(t, check.OK)
+		require.Empty(t, check.UnbalancedEntries)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reports imbalances", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT a.currency, SUM\(p.amount_cents\) AS total_cents`).
+			WillReturnRows(sqlmock.NewRows([]string{"currency", "total_cents"}).AddRow("KZT", 0).AddRow("USD", 100))
+		mock.ExpectQuery(`SELECT DISTINCT entry_id`).
+			WillReturnRows(sqlmock.NewRows([]string{"entry_id"}).AddRow(41))
+		mock.ExpectQuery(`FROM wallets w`).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "user_id", "balance_cents", "ledger_cents"}).AddRow(7, 20, 1600, 1500))
+		mock.ExpectRollback()
+
+		check, err := repo.CheckLedger(context.Background())
+		require.NoError(t, err)
+		require.False(t, check.OK)
+		require.Equal(t, map[string]int64{"KZT": 0, "USD": 100}, check.TotalsCents)
+		require.Equal(t, []int64{41}, check.UnbalancedEntries)
+		require.Equal(t, []WalletMismatch{{WalletID: 7, UserID: 20, BalanceCents: 1600, LedgerCents: 1500}}, check.WalletMismatches)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCharge_ConvertsThroughFXAccounts(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	gymID := 3
+
+	// A 2,500.00 KZT slot paid from the USD wallet. Only the USD/KZT rate
+	// is stored, so its inverse is used: 2500.00 / 470 = 5.319 → 5.32 USD.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT base, rate::text AS rate\s+FROM exchange_rates`).
+		WithArgs("KZT", "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"base", "rate"}).AddRow("USD", "470.0000000000"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(8, 20, 1000, "USD", time.Now(), time.Now()))
+	expectCreditLots(mock, 8)
+	mock.ExpectQuery(`FROM auto_topups a`).
+		WithArgs(20, "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"threshold_cents"}))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(468, 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "USD", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountFX, "USD", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountFX, "KZT", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(31))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountGymRevenue, "KZT", nil, gymID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntryBookingCharge).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	for _, p := range []struct {
+		account int
+		amount  int64
+	}{{12, -532}, {30, 532}, {31, -250000}, {2, 250000}} {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+			WithArgs(42, p.account, p.amount).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	original, originalCurrency, rate := int64(-250000), "KZT", "0.0021276596"
+	description, metadata := "Class booking at Downtown Gym, 3 Feb 2024 09:00", `{"slot_id":4}`
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(8, -532, EntryBookingCharge, 468, 42, nil, nil, nil, nil, nil, &original, &originalCurrency, &rate, &description, &metadata, nil).
+		WillReturnRows(transactionRows().AddRow(90, 8, -532, EntryBookingCharge, 468, time.Now(), nil, nil, nil, nil, original, originalCurrency, rate))
+	mock.ExpectCommit()
+
+	txn, err := repo.Charge(context.Background(), Charge{
+		UserID:         20,
+		Kind:           EntryBookingCharge,
+		GymID:          &gymID,
+		PriceCents:     250000,
+		PriceCurrency:  "KZT",
+		WalletCurrency: "USD",
+		Description:    description,
+		Metadata:       map[string]interface{}{"slot_id": 4},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-532), txn.AmountCents)
+	require.Equal(t, "USD", txn.Currency)
+	require.Equal(t, "KZT", *txn.OriginalCurrency)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharge_NoExchangeRate(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT base, rate::text AS rate\s+FROM exchange_rates`).
+		WithArgs("EUR", "KZT").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.Charge(context.Background(), Charge{
+		UserID:         20,
+		Kind:           EntrySubscriptionCharge,
+		PriceCents:     1000,
+		PriceCurrency:  "EUR",
+		WalletCurrency: "KZT",
+	})
+	require.ErrorIs(t, err, ErrNoExchangeRate)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectAutoTopUpCheck expects the auto top-up of user 20's KZT wallet to
+// be looked up: 1,000.00 threshold, 5,000.00 top-ups capped at 20,000.00 a
+// month, with spent already topped up this month and pending in flight
+// for less than AutoTopUpPendingTimeout.
+func expectAutoTopUpCheck(mock sqlmock.Sqlmock, balance int64, spent int64, pending int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, balance, "KZT", time.Now(), time.Now()))
+	expectCreditLots(mock, 7)
+	mock.ExpectQuery(`FROM auto_topups a\s+JOIN payment_methods m ON m.id = a.payment_method_id\s+WHERE a.user_id = \$1 AND a.currency = \$2 AND a.enabled\s+FOR UPDATE OF a`).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"threshold_cents", "amount_cents", "monthly_cap_cents", "payment_method_id", "provider"}).
+			AddRow(100000, 500000, 2000000, 4, "fake"))
+	mock.ExpectQuery(`FILTER \(WHERE status = 'pending' AND created_at > NOW\(\) - make_interval\(secs => \$3\)\) AS pending\s+FROM payments\s+WHERE user_id = \$1 AND currency = \$2 AND auto_topup`).
+		WithArgs(20, "KZT", AutoTopUpPendingTimeout.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"spent_cents", "pending"}).AddRow(spent, pending))
+}
+
+func TestCharge_StartsAutoTopUp(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// 2,000.00 - 1,500.00 leaves 500.00, below the threshold.
+	mock.ExpectBegin()
+	expectAutoTopUpCheck(mock, 200000, 0, 0)
+	mock.ExpectQuery(`INSERT INTO payments \(user_id, amount_cents, currency, status, provider, payment_method_id, auto_topup\)`).
+		WithArgs(20, 500000, "KZT", "fake", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(70))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(50000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountGymRevenue, "KZT", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(EntrySubscriptionCharge).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(43))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(43, 11, -150000).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(43, 2, 150000).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery(insertTransaction).
+		WillReturnRows(transactionRows().AddRow(91, 7, -150000, EntrySubscriptionCharge, 50000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectCommit()
+
+	txn, err := repo.Charge(context.Background(), Charge{
+		UserID: 20, Kind: EntrySubscriptionCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 70, *txn.AutoTopUpPaymentID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharge_AutoTopUpRequired(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// 200.00 does not cover 1,500.00, but a 5,000.00 top-up would: the
+	// top-up is kept and the charge reported as waiting for it.
+	mock.ExpectBegin()
+	expectAutoTopUpCheck(mock, 20000, 500000, 0)
+	mock.ExpectQuery(`INSERT INTO payments`).
+		WithArgs(20, 500000, "KZT", "fake", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(71))
+	mock.ExpectCommit()
+
+	_, err := repo.Charge(context.Background(), Charge{
+		UserID: 20, Kind: EntrySubscriptionCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+	})
+	var required *AutoTopUpRequired
+	require.ErrorAs(t, err, &required)
+	require.Equal(t, 71, required.PaymentID)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharge_AutoTopUpAfterStuckPending(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// An earlier top-up of 5,000.00 has been pending for longer than
+	// AutoTopUpPendingTimeout: it counts towards the cap but no longer
+	// blocks the next top-up.
+	mock.ExpectBegin()
+	expectAutoTopUpCheck(mock, 20000, 500000, 0)
+	mock.ExpectQuery(`INSERT INTO payments`).
+		WithArgs(20, 500000, "KZT", "fake", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(72))
+	mock.ExpectCommit()
+
+	_, err := repo.Charge(context.Background(), Charge{
+		UserID: 20, Kind: EntrySubscriptionCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+	})
+	var required *AutoTopUpRequired
+	require.ErrorAs(t, err, &required)
+	require.Equal(t, 72, required.PaymentID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharge_AutoTopUpSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		spent   int64
+		pending int
+	}{
+		{"monthly cap reached", 1600000, 0},
+		{"top-up already pending", 500000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, close := setupWalletMock(t)
+			defer close()
+
+			mock.ExpectBegin()
+			expectAutoTopUpCheck(mock, 20000, tt.spent, tt.pending)
+			mock.ExpectRollback()
+
+			_, err := repo.Charge(context.Background(), Charge{
+				UserID: 20, Kind: EntrySubscriptionCharge, PriceCents: 150000, PriceCurrency: "KZT", WalletCurrency: "KZT",
+			})
+			require.Equal(t, ErrInsufficientBalance, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetExchangeRate_ReplacesInverse(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM exchange_rates WHERE base = $1 AND quote = $2")).
+		WithArgs("KZT", "USD").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO exchange_rates \(base, quote, rate, updated_by\).*ON CONFLICT \(base, quote\) DO UPDATE`).
+		WithArgs("USD", "KZT", "470.1500000000", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"base", "quote", "rate", "updated_by", "updated_at"}).
+			AddRow("USD", "KZT", "470.1500000000", 1, time.Now()))
+	mock.ExpectCommit()
+
+	rate, err := repo.SetExchangeRate(context.Background(), "USD", "KZT", "470.1500000000", 1)
+	require.NoError(t, err)
+	require.Equal(t, "470.1500000000", rate.Rate)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExchangeRate_Direction(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	query := `SELECT base, rate::text AS rate\s+FROM exchange_rates\s+WHERE \(base = \$1 AND quote = \$2\) OR \(base = \$2 AND quote = \$1\)`
+	mock.ExpectQuery(query).
+		WithArgs("USD", "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"base", "rate"}).AddRow("USD", "500.0000000000"))
+	mock.ExpectQuery(query).
+		WithArgs("KZT", "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"base", "rate"}).AddRow("USD", "500.0000000000"))
+
+	rate, err := repo.ExchangeRate(context.Background(), "USD", "KZT")
+	require.NoError(t, err)
+	require.Equal(t, "500", rate.RatString())
+
+	rate, err = repo.ExchangeRate(context.Background(), "KZT", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "1/500", rate.RatString())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStatement(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 1, 0)
+	at := from.Add(36 * time.Hour)
+
+	mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1 AND currency = \$2`).
+		WithArgs(5, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT COALESCE\(\s*\(SELECT balance_after FROM wallet_transactions`).
+		WithArgs(3, from).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(10000))
+	mock.ExpectQuery(`FROM wallet_transactions wt\s+JOIN wallets w ON w.id = wt.wallet_id\s+LEFT JOIN users u ON u.id = wt.counterparty_user_id\s+WHERE wt.wallet_id = \$1 AND wt.created_at >= \$2 AND wt.created_at < \$3\s+ORDER BY wt.created_at, wt.id`).
+		WithArgs(3, from, until).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount_cents", "type", "balance_after", "currency", "created_at", "gym_name"}).
+			AddRow(20, 3, -1000, "booking_payment", 9000, "KZT", at, "Downtown Gym").
+			AddRow(21, 3, 5000, "topup", 14000, "KZT", at.Add(time.Hour), nil))
+
+	st, err := repo.GetStatement(context.Background(), 5, "KZT", from, until)
+	require.NoError(t, err)
+	require.Len(t, st.Transactions, 2)
+	require.Equal(t, int64(10000), st.OpeningBalanceCents)
+	require.Equal(t, int64(14000), st.ClosingBalanceCents)
+	require.Equal(t, "Downtown Gym", *st.Transactions[0].GymName)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStatement_NoWallet(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1 AND currency = \$2`).
+		WithArgs(5, "USD").
+		WillReturnError(sql.ErrNoRows)
+
+	st, err := repo.GetStatement(context.Background(), 5, "USD", time.Now(), time.Now())
+	require.NoError(t, err)
+	require.Empty(t, st.Transactions)
+	require.Zero(t, st.ClosingBalanceCents)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTransactions_Filters(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 1, 0)
+	bookingID := 12
+
+	mock.ExpectQuery(`WHERE w.user_id = \$1 AND wt.type = ANY\(\$2\) AND wt.created_at >= \$3 AND wt.created_at < \$4\s+ORDER BY wt.created_at DESC, wt.id DESC LIMIT \$5 OFFSET \$6`).
+		WithArgs(5, pq.Array([]string{"booking_payment", "refund"}), from, until, 50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount_cents", "type", "balance_after", "currency", "created_at", "booking_id", "description", "metadata"}).
+			AddRow(20, 3, -1000, "booking_payment", 9000, "KZT", from.Add(time.Hour), bookingID, "Class booking at Downtown Gym, 1 Jan 2024 11:00", []byte(`{"slot_id": 4}`)))
+
+	txs, err := repo.GetTransactions(context.Background(), 5, TransactionFilter{
+		Types: []string{"booking_payment", "refund"},
+		From:  &from,
+		Until: &until,
+	})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, bookingID, *txs[0].BookingID)
+	require.JSONEq(t, `{"slot_id": 4}`, string(*txs[0].Metadata))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTransactions_NoFilters(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectQuery(`WHERE w.user_id = \$1\s+ORDER BY wt.created_at DESC, wt.id DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs(5, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	txs, err := repo.GetTransactions(context.Background(), 5, TransactionFilter{Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLinkTransaction(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	subscriptionID := 8
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallet_transactions SET booking_id = COALESCE($2, booking_id), subscription_id = COALESCE($3, subscription_id) WHERE id = $1")).
+		WithArgs(30, nil, &subscriptionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE wallet_transactions`).
+		WithArgs(31, nil, &subscriptionID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.LinkTransaction(context.Background(), 30, TransactionLink{SubscriptionID: &subscriptionID}))
+	require.ErrorIs(t, repo.LinkTransaction(context.Background(), 31, TransactionLink{SubscriptionID: &subscriptionID}), ErrTransactionNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReconcileWallets(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id AS wallet_id, user_id, currency, balance_cents FROM wallets ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "user_id", "currency", "balance_cents"}).
+			AddRow(1, 10, "KZT", 1500).
+			AddRow(2, 11, "KZT", 6000).
+			AddRow(3, 12, "USD", 700))
+	mock.ExpectQuery(`SELECT wallet_id, id, amount_cents, balance_after, type\s+FROM wallet_transactions\s+WHERE wallet_id IS NOT NULL\s+ORDER BY wallet_id, created_at, id`).
+		WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "id", "amount_cents", "balance_after", "type"}).
+			AddRow(1, 1, 2000, 2000, "topup").
+			AddRow(1, 2, -500, 1500, "booking_payment").
+			AddRow(2, 3, 1000, 6000, "topup"))
+	mock.ExpectRollback()
+
+	result, err := repo.ReconcileWallets(context.Background())
+	require.NoError(t, err)
+	require.False(t, result.OK)
+	require.Equal(t, 3, result.WalletsChecked)
+	require.Len(t, result.Discrepancies, 2)
+
+	require.Equal(t, 2, result.Discrepancies[0].WalletID)
+	require.Equal(t, int64(5000), result.Discrepancies[0].DifferenceCents)
+	require.Len(t, result.Discrepancies[0].ChainBreaks, 1)
+
+	// A wallet with a balance but no transactions at all.
+	require.Equal(t, 3, result.Discrepancies[1].WalletID)
+	require.Equal(t, int64(700), result.Discrepancies[1].DifferenceCents)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func expectCorrectableWallet(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id AS wallet_id, user_id, currency, balance_cents FROM wallets WHERE id = \$1 FOR UPDATE`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "user_id", "currency", "balance_cents"}).AddRow(2, 11, "KZT", 6000))
+	mock.ExpectQuery(`FROM wallet_transactions\s+WHERE wallet_id = \$1\s+ORDER BY created_at, id`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "id", "amount_cents", "balance_after", "type"}).
+			AddRow(2, 3, 1000, 6000, "topup"))
+}
+
+func TestCorrectWallet_Success(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	expectCorrectableWallet(mock)
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(p.amount_cents\), 0\)\s+FROM ledger_accounts a`).
+		WithArgs(11, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(6000))
+	mock.ExpectQuery(`INSERT INTO wallet_transactions \(wallet_id, amount_cents, type, balance_after, reason, created_by\)`).
+		WithArgs(2, int64(5000), TransactionReconciliation, int64(6000), "Carried-over balance", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount_cents", "type", "balance_after", "created_at", "reason", "created_by"}).
+			AddRow(40, 2, 5000, TransactionReconciliation, 6000, time.Now(), "Carried-over balance", 1))
+	mock.ExpectCommit()
+
+	txn, err := repo.CorrectWallet(context.Background(), WalletCorrection{WalletID: 2, DifferenceCents: 5000, Reason: "Carried-over balance", AdminID: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(5000), txn.AmountCents)
+	require.Equal(t, "KZT", txn.Currency)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCorrectWallet_Changed(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	expectCorrectableWallet(mock)
+	mock.ExpectRollback()
+
+	_, err := repo.CorrectWallet(context.Background(), WalletCorrection{WalletID: 2, DifferenceCents: 4000, Reason: "x", AdminID: 1})
+	require.ErrorIs(t, err, ErrReconciliationChanged)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCorrectWallet_LedgerMismatch(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	expectCorrectableWallet(mock)
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(p.amount_cents\), 0\)\s+FROM ledger_accounts a`).
+		WithArgs(11, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(1000))
+	mock.ExpectRollback()
+
+	_, err := repo.CorrectWallet(context.Background(), WalletCorrection{WalletID: 2, DifferenceCents: 5000, Reason: "x", AdminID: 1})
+	require.ErrorIs(t, err, ErrWalletLedgerMismatch)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCorrectWallet_NotFound(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM wallets WHERE id = \$1 FOR UPDATE`).
+		WithArgs(9).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.CorrectWallet(context.Background(), WalletCorrection{WalletID: 9, Reason: "x", AdminID: 1})
+	require.ErrorIs(t, err, ErrWalletNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectPostings expects the member wallet and counter accounts of user 20's
+// KZT wallet and a journal entry moving amount between them.
+func expectPostings(mock sqlmock.Sqlmock, counter AccountType, gymID interface{}, kind EntryKind, amount int64) {
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(AccountMemberWallet, "KZT", 20, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO ledger_accounts .* ON CONFLICT`).
+		WithArgs(counter, "KZT", nil, gymID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO ledger_entries (kind) VALUES ($1) RETURNING id")).
+		WithArgs(kind).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(44))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(44, 11, amount).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ledger_postings (entry_id, account_id, amount_cents) VALUES ($1, $2, $3)")).
+		WithArgs(44, 6, -amount).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+}
+
+func expectLockWallet(mock sqlmock.Sqlmock, balance int64) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance_cents, currency, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE")).
+		WithArgs(20, "KZT").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance_cents", "currency", "created_at", "updated_at"}).AddRow(7, 20, balance, "KZT", time.Now(), time.Now()))
+}
+
+func TestAddTransaction_SpendsPromoCreditFirst(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	gymID := 3
+
+	// 3,000.00 of which 500.00 is expired credit and two lots of 1,000.00
+	// are spendable. A 1,500.00 charge empties the lot that expires first
+	// and takes the rest from the next one.
+	mock.ExpectBegin()
+	expectLockWallet(mock, 300000)
+	expectCreditLots(mock, 7,
+		creditLotRow{id: 1, remaining: 50000, expired: true},
+		creditLotRow{id: 2, remaining: 100000},
+		creditLotRow{id: 3, remaining: 100000},
+	)
+	update := regexp.QuoteMeta("UPDATE wallet_credit_lots SET remaining_cents = remaining_cents - $2 WHERE id = $1")
+	mock.ExpectExec(update).WithArgs(2, 100000).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(update).WithArgs(3, 50000).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(150000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectPostings(mock, AccountGymRevenue, gymID, EntryBookingCharge, -150000)
+	promo := int64(-150000)
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, -150000, EntryBookingCharge, 150000, 44, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &promo).
+		WillReturnRows(transactionRows().AddRow(92, 7, -150000, EntryBookingCharge, 150000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
+	spent := regexp.QuoteMeta("INSERT INTO wallet_credit_lot_spends (transaction_id, lot_id, cents) VALUES ($1, $2, $3)")
+	mock.ExpectExec(spent).WithArgs(92, 2, 100000).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(spent).WithArgs(92, 3, 50000).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
+	err := repo.AddTransaction(context.Background(), 20, -150000, "KZT", EntryBookingCharge, &gymID)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddTransaction_ExpiredPromoCreditNotSpendable(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// 1,000.00 of which 800.00 has expired but not been taken back yet.
+	mock.ExpectBegin()
+	expectLockWallet(mock, 100000)
+	expectCreditLots(mock, 7, creditLotRow{id: 1, remaining: 80000, expired: true})
+	mock.ExpectRollback()
+
+	err := repo.AddTransaction(context.Background(), 20, -50000, "KZT", EntryBookingCharge, nil)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransfer_PromoCreditNotTransferable(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	// User 20 has 5,000 of which 4,000 is promotional credit.
+	mock.ExpectBegin()
+	expectLockWallets(mock, map[int]int64{10: 100, 20: 5000})
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(remaining_cents\), 0\)\s+FROM wallet_credit_lots`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(4000))
+	mock.ExpectRollback()
+
+	_, err := repo.Transfer(context.Background(), Transfer{FromUserID: 20, ToUserID: 10, AmountCents: 2000, Currency: "KZT"})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGrantPromoCredit(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	expiresAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	reason := "Welcome bonus"
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, 1000)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(201000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectPostings(mock, AccountPromoLiability, nil, EntryPromoCredit, 200000)
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, 200000, EntryPromoCredit, 201000, 44, nil, &reason, nil, 99, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(transactionRows().AddRow(93, 7, 200000, EntryPromoCredit, 201000, time.Now(), reason, nil, 99, nil, nil, nil, nil))
+	mock.ExpectExec(`INSERT INTO wallet_credit_lots \(wallet_id, transaction_id, amount_cents, remaining_cents, expires_at, reason, created_by\)`).
+		WithArgs(7, 93, 200000, expiresAt, reason, 99).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	txn, err := repo.GrantPromoCredit(context.Background(), PromoGrant{
+		UserID: 20, AmountCents: 200000, Currency: "KZT", ExpiresAt: expiresAt, Reason: reason, AdminID: 99,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 93, txn.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireCredits(t *testing.T) {
+	repo, mock, close := setupWalletMock(t)
+	defer close()
+
+	mock.ExpectQuery(`SELECT DISTINCT w.user_id, w.currency\s+FROM wallet_credit_lots l`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency"}).AddRow(20, "KZT"))
+
+	// Two lots with 300.00 and 200.00 left are taken back together.
+	mock.ExpectBegin()
+	expectLockWallet(mock, 120000)
+	mock.ExpectQuery(`SELECT id, remaining_cents\s+FROM wallet_credit_lots\s+WHERE wallet_id = \$1 AND remaining_cents > 0 AND expires_at <= NOW\(\)`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "remaining_cents"}).AddRow(3, 30000).AddRow(4, 20000))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallet_credit_lots SET remaining_cents = 0, expired_at = NOW() WHERE id = ANY($1)")).
+		WithArgs(pq.Array([]int64{3, 4})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectLockWallet(mock, 120000)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance_cents = $1, updated_at = NOW() WHERE id = $2")).
+		WithArgs(70000, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectPostings(mock, AccountPromoLiability, nil, EntryCreditExpired, -50000)
+	metadata := `{"lot_ids":[3,4]}`
+	mock.ExpectQuery(insertTransaction).
+		WithArgs(7, -50000, EntryCreditExpired, 70000, 44, nil, nil, nil, nil, nil, nil, nil, nil, nil, &metadata, nil).
+		WillReturnRows(transactionRows().AddRow(94, 7, -50000, EntryCreditExpired, 70000, time.Now(), nil, nil, nil, nil, nil, nil, nil))
 	mock.ExpectCommit()
 
-	err := repo.AddTransaction(ctx, 20, -500, "booking_payment")
+	expired, err := repo.ExpireCredits(context.Background())
 	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, int64(-50000), expired[0].AmountCents)
+	require.Equal(t, "KZT", expired[0].Currency)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
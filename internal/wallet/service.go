@@ -0,0 +1,364 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fitslot/internal/logger"
+	"fitslot/internal/metrics"
+	"fitslot/internal/money"
+	"fitslot/internal/user"
+)
+
+var (
+	ErrTransferInvalid   = errors.New("invalid transfer")
+	ErrRecipientNotFound = errors.New("recipient not found")
+	ErrAdjustmentInvalid = errors.New("invalid adjustment")
+	ErrUserNotFound      = errors.New("user not found")
+
+	ErrExchangeRateInvalid = errors.New("invalid exchange rate")
+	ErrStatementInvalid    = errors.New("invalid statement request")
+)
+
+type Service interface {
+	Transfer(ctx context.Context, fromUserID int, req TransferRequest) (*Transaction, error)
+	Adjust(ctx context.Context, adminID, userID int, req AdjustmentRequest) (*Transaction, error)
+	RefundTransaction(ctx context.Context, adminID, transactionID int, req RefundRequest) (*Transaction, error)
+	GrantPromoCredit(ctx context.Context, adminID, userID int, req PromoCreditRequest) (*Transaction, error)
+	ExpireCredits(ctx context.Context) ([]Transaction, error)
+	SetExchangeRate(ctx context.Context, adminID int, base, quote string, req SetExchangeRateRequest) (*ExchangeRate, error)
+	Statement(ctx context.Context, userID int, req StatementRequest) (*Statement, error)
+	Reconcile(ctx context.Context) (*Reconciliation, error)
+	CorrectWallet(ctx context.Context, adminID, walletID int, req ReconcileRequest) (*Transaction, error)
+}
+
+type service struct {
+	repo                    Repository
+	userRepo                user.Repository
+	dailyTransferLimitCents int64
+}
+
+// NewService returns the wallet service. dailyTransferLimitCents caps what a
+// member can transfer to others out of all their wallets within the last
+// 24 hours, in the default currency; zero disables the limit.
+func NewService(repo Repository, userRepo user.Repository, dailyTransferLimitCents int64) Service {
+	return &service{
+		repo:                    repo,
+		userRepo:                userRepo,
+		dailyTransferLimitCents: dailyTransferLimitCents,
+	}
+}
+
+// Transfer moves credit from the member's wallet to the wallet of the member
+// registered under the recipient email.
+func (s *service) Transfer(ctx context.Context, fromUserID int, req TransferRequest) (*Transaction, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("%w: amount_cents must be positive", ErrTransferInvalid)
+	}
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > MaxTransferNoteLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrTransferInvalid, MaxTransferNoteLength)
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrTransferInvalid, req.Currency)
+	}
+
+	recipient, err := s.userRepo.FindByEmail(ctx, strings.TrimSpace(req.RecipientEmail))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, err
+	}
+	if recipient.ID == fromUserID {
+		return nil, fmt.Errorf("%w: cannot transfer to yourself", ErrTransferInvalid)
+	}
+
+	return s.repo.Transfer(ctx, Transfer{
+		FromUserID:      fromUserID,
+		ToUserID:        recipient.ID,
+		AmountCents:     req.AmountCents,
+		Currency:        currency,
+		Note:            note,
+		DailyLimitCents: s.dailyTransferLimitCents,
+	})
+}
+
+// Adjust credits or debits a member's wallet on behalf of support staff.
+func (s *service) Adjust(ctx context.Context, adminID, userID int, req AdjustmentRequest) (*Transaction, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("%w: amount_cents must be positive", ErrAdjustmentInvalid)
+	}
+	amountCents := req.AmountCents
+	switch req.Type {
+	case AdjustmentCredit:
+	case AdjustmentDebit:
+		amountCents = -amountCents
+	default:
+		return nil, fmt.Errorf("%w: type must be %s or %s", ErrAdjustmentInvalid, AdjustmentCredit, AdjustmentDebit)
+	}
+
+	reason, reference, err := auditFields(req.Reason, req.Reference, true)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrAdjustmentInvalid, req.Currency)
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	txn, err := s.repo.Adjust(ctx, Adjustment{
+		UserID:      userID,
+		AmountCents: amountCents,
+		Currency:    currency,
+		Reason:      reason,
+		Reference:   reference,
+		AdminID:     adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Admin %d adjusted the %s wallet of user %d by %d (%s): %s", adminID, currency, userID, amountCents, reference, reason)
+	return txn, nil
+}
+
+// RefundTransaction refunds a booking or subscription charge on behalf of
+// support staff.
+func (s *service) RefundTransaction(ctx context.Context, adminID, transactionID int, req RefundRequest) (*Transaction, error) {
+	reason, reference, err := auditFields(req.Reason, req.Reference, false)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := s.repo.RefundTransaction(ctx, TransactionRefund{
+		TransactionID: transactionID,
+		Reason:        reason,
+		Reference:     reference,
+		AdminID:       adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Admin %d refunded wallet transaction %d with %d: %s", adminID, transactionID, txn.AmountCents, reason)
+	return txn, nil
+}
+
+// GrantPromoCredit grants a member promotional credit on behalf of support
+// staff or a campaign. It expires ValidForDays days from now.
+func (s *service) GrantPromoCredit(ctx context.Context, adminID, userID int, req PromoCreditRequest) (*Transaction, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("%w: amount_cents must be positive", ErrAdjustmentInvalid)
+	}
+	if req.ValidForDays <= 0 || req.ValidForDays > MaxPromoCreditDays {
+		return nil, fmt.Errorf("%w: valid_for_days must be between 1 and %d", ErrAdjustmentInvalid, MaxPromoCreditDays)
+	}
+	reason, reference, err := auditFields(req.Reason, req.Reference, false)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrAdjustmentInvalid, req.Currency)
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	expiresAt := time.Now().UTC().AddDate(0, 0, req.ValidForDays)
+	txn, err := s.repo.GrantPromoCredit(ctx, PromoGrant{
+		UserID:      userID,
+		AmountCents: req.AmountCents,
+		Currency:    currency,
+		ExpiresAt:   expiresAt,
+		Reason:      reason,
+		Reference:   reference,
+		AdminID:     adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.RecordWalletPromoCredit("granted", currency, req.AmountCents)
+	logger.Infof("Admin %d granted user %d %d %s of promotional credit until %s: %s",
+		adminID, userID, req.AmountCents, currency, expiresAt.Format(time.RFC3339), reason)
+	return txn, nil
+}
+
+// ExpireCredits takes back the promotional credit that has expired unspent.
+func (s *service) ExpireCredits(ctx context.Context) ([]Transaction, error) {
+	expired, err := s.repo.ExpireCredits(ctx)
+	for _, t := range expired {
+		metrics.RecordWalletPromoCredit("expired", t.Currency, -t.AmountCents)
+		logger.Infof("Expired %d %s of promotional credit in wallet %d", -t.AmountCents, t.Currency, t.WalletID)
+	}
+	return expired, err
+}
+
+// SetExchangeRate stores the rate of one unit of base in quote.
+func (s *service) SetExchangeRate(ctx context.Context, adminID int, base, quote string, req SetExchangeRateRequest) (*ExchangeRate, error) {
+	from, err := money.Lookup(base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrExchangeRateInvalid, base)
+	}
+	to, err := money.Lookup(quote)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrExchangeRateInvalid, quote)
+	}
+	if from.Code == to.Code {
+		return nil, fmt.Errorf("%w: base and quote must differ", ErrExchangeRateInvalid)
+	}
+	rate, err := money.ParseRate(req.Rate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: rate must be a positive decimal with at most %d decimal places", ErrExchangeRateInvalid, money.MaxRateDecimals)
+	}
+
+	er, err := s.repo.SetExchangeRate(ctx, from.Code, to.Code, rate.FloatString(money.MaxRateDecimals), adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Admin %d set the %s/%s exchange rate to %s", adminID, from.Code, to.Code, er.Rate)
+	return er, nil
+}
+
+// Statement returns the statement of the member's wallet for the requested
+// period.
+func (s *service) Statement(ctx context.Context, userID int, req StatementRequest) (*Statement, error) {
+	currency, err := money.Normalize(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrStatementInvalid, req.Currency)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, err := statementDate(req.From, time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be a date like 2024-01-31", ErrStatementInvalid)
+	}
+	to, err := statementDate(req.To, today)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be a date like 2024-01-31", ErrStatementInvalid)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrStatementInvalid)
+	}
+	if to.Sub(from) >= MaxStatementDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: a statement covers at most %d days", ErrStatementInvalid, MaxStatementDays)
+	}
+
+	holder, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	st, err := s.repo.GetStatement(ctx, userID, currency, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	st.HolderName = holder.Name
+	st.HolderEmail = holder.Email
+	st.From = from
+	st.To = to
+	return st, nil
+}
+
+// Reconcile checks every wallet against its transaction history and
+// exports the outcome as metrics.
+func (s *service) Reconcile(ctx context.Context) (*Reconciliation, error) {
+	result, err := s.repo.ReconcileWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balanceMismatches, brokenChains int
+	for _, d := range result.Discrepancies {
+		if d.DifferenceCents != 0 {
+			balanceMismatches++
+		}
+		if len(d.ChainBreaks) > 0 {
+			brokenChains++
+		}
+		logger.Errorf("Wallet %d of user %d does not reconcile: balance %d, transactions sum to %d, %d broken balance_after links",
+			d.WalletID, d.UserID, d.BalanceCents, d.TransactionsSumCents, len(d.ChainBreaks))
+	}
+	metrics.RecordWalletReconciliation(balanceMismatches, brokenChains, result.CheckedAt)
+
+	return result, nil
+}
+
+// CorrectWallet records the correction of a wallet that failed
+// reconciliation, once support staff have reviewed and approved it.
+func (s *service) CorrectWallet(ctx context.Context, adminID, walletID int, req ReconcileRequest) (*Transaction, error) {
+	if req.DifferenceCents == nil {
+		return nil, fmt.Errorf("%w: difference_cents is required", ErrAdjustmentInvalid)
+	}
+	reason, _, err := auditFields(req.Reason, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := s.repo.CorrectWallet(ctx, WalletCorrection{
+		WalletID:        walletID,
+		DifferenceCents: *req.DifferenceCents,
+		Reason:          reason,
+		AdminID:         adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Admin %d reconciled wallet %d with %d: %s", adminID, walletID, txn.AmountCents, reason)
+	return txn, nil
+}
+
+// statementDate parses a YYYY-MM-DD date as midnight UTC, or returns def
+// when s is empty.
+func statementDate(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// auditFields trims and checks the reason and reference of an adjustment or
+// refund.
+func auditFields(reason, reference string, referenceRequired bool) (string, string, error) {
+	reason = strings.TrimSpace(reason)
+	reference = strings.TrimSpace(reference)
+
+	if reason == "" {
+		return "", "", fmt.Errorf("%w: reason is required", ErrAdjustmentInvalid)
+	}
+	if utf8.RuneCountInString(reason) > MaxReasonLength {
+		return "", "", fmt.Errorf("%w: reason must be at most %d characters", ErrAdjustmentInvalid, MaxReasonLength)
+	}
+	if reference == "" && referenceRequired {
+		return "", "", fmt.Errorf("%w: reference is required", ErrAdjustmentInvalid)
+	}
+	if utf8.RuneCountInString(reference) > MaxReferenceLength {
+		return "", "", fmt.Errorf("%w: reference must be at most %d characters", ErrAdjustmentInvalid, MaxReferenceLength)
+	}
+
+	return reason, reference, nil
+}
@@ -0,0 +1,592 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"fitslot/internal/metrics"
+	"fitslot/internal/user"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct{ mock.Mock }
+
+func (m *MockRepository) GetOrCreateWallet(ctx context.Context, userID int, currency string) (*Wallet, error) {
+	args := m.Called(ctx, userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Wallet), args.Error(1)
+}
+
+func (m *MockRepository) AddTransaction(ctx context.Context, userID int, amountCents int64, currency string, kind EntryKind, gymID *int) error {
+	args := m.Called(ctx, userID, amountCents, currency, kind, gymID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) TopUp(ctx context.Context, userID int, amountCents int64) error {
+	args := m.Called(ctx, userID, amountCents)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreditTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RefundTopUp(ctx context.Context, paymentID int) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetTransactions(ctx context.Context, userID int, filter TransactionFilter) ([]Transaction, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *MockRepository) LinkTransaction(ctx context.Context, transactionID int, link TransactionLink) error {
+	args := m.Called(ctx, transactionID, link)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetStatement(ctx context.Context, userID int, currency string, from, until time.Time) (*Statement, error) {
+	args := m.Called(ctx, userID, currency, from, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Statement), args.Error(1)
+}
+
+func (m *MockRepository) ReconcileWallets(ctx context.Context) (*Reconciliation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Reconciliation), args.Error(1)
+}
+
+func (m *MockRepository) CorrectWallet(ctx context.Context, c WalletCorrection) (*Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) GetAccountBalances(ctx context.Context) ([]AccountBalance, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]AccountBalance), args.Error(1)
+}
+
+func (m *MockRepository) CheckLedger(ctx context.Context) (*LedgerCheck, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LedgerCheck), args.Error(1)
+}
+
+func (m *MockRepository) Transfer(ctx context.Context, t Transfer) (*Transaction, error) {
+	args := m.Called(ctx, t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) Adjust(ctx context.Context, a Adjustment) (*Transaction, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) RefundTransaction(ctx context.Context, refund TransactionRefund) (*Transaction, error) {
+	args := m.Called(ctx, refund)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) RefundCharge(ctx context.Context, transactionID int) (*Transaction, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) GrantPromoCredit(ctx context.Context, g PromoGrant) (*Transaction, error) {
+	args := m.Called(ctx, g)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) ListCreditLots(ctx context.Context, userID int) ([]CreditLot, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]CreditLot), args.Error(1)
+}
+
+func (m *MockRepository) ExpireCredits(ctx context.Context) ([]Transaction, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *MockRepository) ListWallets(ctx context.Context, userID int) ([]Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Wallet), args.Error(1)
+}
+
+func (m *MockRepository) Charge(ctx context.Context, c Charge) (*Transaction, error) {
+	args := m.Called(ctx, c)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Transaction), args.Error(1)
+}
+
+func (m *MockRepository) ListExchangeRates(ctx context.Context) ([]ExchangeRate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ExchangeRate), args.Error(1)
+}
+
+func (m *MockRepository) SetExchangeRate(ctx context.Context, base, quote, rate string, adminID int) (*ExchangeRate, error) {
+	args := m.Called(ctx, base, quote, rate, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ExchangeRate), args.Error(1)
+}
+
+func (m *MockRepository) ExchangeRate(ctx context.Context, from, to string) (*big.Rat, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Rat), args.Error(1)
+}
+
+type MockUserRepo struct{ mock.Mock }
+
+func (m *MockUserRepo) Create(ctx context.Context, name, email, passwordHash, role string) (*user.User, error) {
+	args := m.Called(ctx, name, email, passwordHash, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepo) FindByEmail(ctx context.Context, email string) (*user.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id int) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepo) EmailExists(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestService_Transfer(t *testing.T) {
+	ctx := context.Background()
+	recipient := &user.User{ID: 2, Name: "Aigerim", Email: "friend@example.com"}
+
+	tests := []struct {
+		name        string
+		req         TransferRequest
+		setupMocks  func(*MockRepository, *MockUserRepo)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			req:  TransferRequest{RecipientEmail: " friend@example.com ", AmountCents: 1500, Note: "  for Saturday "},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "friend@example.com").Return(recipient, nil)
+				r.On("Transfer", ctx, Transfer{
+					FromUserID:      1,
+					ToUserID:        2,
+					AmountCents:     1500,
+					Currency:        "KZT",
+					Note:            "for Saturday",
+					DailyLimitCents: 10000,
+				}).Return(&Transaction{ID: 9, AmountCents: -1500, Type: TransactionTransferOut}, nil)
+			},
+		},
+		{
+			name:        "Non-positive amount",
+			req:         TransferRequest{RecipientEmail: "friend@example.com", AmountCents: -100},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrTransferInvalid,
+		},
+		{
+			name:        "Note too long",
+			req:         TransferRequest{RecipientEmail: "friend@example.com", AmountCents: 100, Note: strings.Repeat("ж", MaxTransferNoteLength+1)},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrTransferInvalid,
+		},
+		{
+			name: "Unknown recipient",
+			req:  TransferRequest{RecipientEmail: "nobody@example.com", AmountCents: 100},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "nobody@example.com").Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrRecipientNotFound,
+		},
+		{
+			name: "Transfer to yourself",
+			req:  TransferRequest{RecipientEmail: "me@example.com", AmountCents: 100},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "me@example.com").Return(&user.User{ID: 1}, nil)
+			},
+			expectedErr: ErrTransferInvalid,
+		},
+		{
+			name: "Limit stays in the default currency",
+			req:  TransferRequest{RecipientEmail: "friend@example.com", AmountCents: 1500, Currency: "usd"},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "friend@example.com").Return(recipient, nil)
+				r.On("Transfer", ctx, Transfer{
+					FromUserID:      1,
+					ToUserID:        2,
+					AmountCents:     1500,
+					Currency:        "USD",
+					DailyLimitCents: 10000,
+				}).Return(&Transaction{ID: 10, AmountCents: -1500, Type: TransactionTransferOut}, nil)
+			},
+		},
+		{
+			name: "No rate to convert the transfer",
+			req:  TransferRequest{RecipientEmail: "friend@example.com", AmountCents: 1500, Currency: "EUR"},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "friend@example.com").Return(recipient, nil)
+				r.On("Transfer", ctx, mock.AnythingOfType("wallet.Transfer")).Return(nil, ErrNoExchangeRate)
+			},
+			expectedErr: ErrNoExchangeRate,
+		},
+		{
+			name:        "Unknown currency",
+			req:         TransferRequest{RecipientEmail: "friend@example.com", AmountCents: 100, Currency: "XYZ"},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrTransferInvalid,
+		},
+		{
+			name: "Limit exceeded",
+			req:  TransferRequest{RecipientEmail: "friend@example.com", AmountCents: 9000},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByEmail", ctx, "friend@example.com").Return(recipient, nil)
+				r.On("Transfer", ctx, mock.AnythingOfType("wallet.Transfer")).Return(nil, ErrTransferLimitExceeded)
+			},
+			expectedErr: ErrTransferLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			userRepo := new(MockUserRepo)
+			tt.setupMocks(repo, userRepo)
+
+			svc := NewService(repo, userRepo, 10000)
+			txn, err := svc.Transfer(ctx, 1, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, txn)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, TransactionTransferOut, txn.Type)
+			}
+
+			repo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_Adjust(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		req         AdjustmentRequest
+		setupMocks  func(*MockRepository, *MockUserRepo)
+		expectedErr error
+	}{
+		{
+			name: "Debit",
+			req:  AdjustmentRequest{Type: AdjustmentDebit, AmountCents: 700, Reason: " Goodwill credit reversed ", Reference: "SUP-2"},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByID", ctx, 5).Return(&user.User{ID: 5}, nil)
+				r.On("Adjust", ctx, Adjustment{
+					UserID:      5,
+					AmountCents: -700,
+					Currency:    "KZT",
+					Reason:      "Goodwill credit reversed",
+					Reference:   "SUP-2",
+					AdminID:     1,
+				}).Return(&Transaction{ID: 3, AmountCents: -700, Type: string(EntryAdjustment)}, nil)
+			},
+		},
+		{
+			name:        "Unknown type",
+			req:         AdjustmentRequest{Type: "bonus", AmountCents: 700, Reason: "r", Reference: "SUP-2"},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrAdjustmentInvalid,
+		},
+		{
+			name:        "Missing reference",
+			req:         AdjustmentRequest{Type: AdjustmentCredit, AmountCents: 700, Reason: "r", Reference: "  "},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrAdjustmentInvalid,
+		},
+		{
+			name: "Unknown user",
+			req:  AdjustmentRequest{Type: AdjustmentCredit, AmountCents: 700, Reason: "r", Reference: "SUP-2"},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByID", ctx, 5).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			userRepo := new(MockUserRepo)
+			tt.setupMocks(repo, userRepo)
+
+			svc := NewService(repo, userRepo, 0)
+			txn, err := svc.Adjust(ctx, 1, 5, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, txn.ID)
+			}
+
+			repo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GrantPromoCredit(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		req         PromoCreditRequest
+		setupMocks  func(*MockRepository, *MockUserRepo)
+		expectedErr error
+	}{
+		{
+			name: "Granted for 30 days",
+			req:  PromoCreditRequest{AmountCents: 200000, ValidForDays: 30, Reason: " Welcome bonus "},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByID", ctx, 5).Return(&user.User{ID: 5}, nil)
+				r.On("GrantPromoCredit", ctx, mock.MatchedBy(func(g PromoGrant) bool {
+					days := time.Until(g.ExpiresAt).Hours() / 24
+					return g.UserID == 5 && g.AmountCents == 200000 && g.Currency == "KZT" &&
+						g.Reason == "Welcome bonus" && g.AdminID == 1 && days > 29.9 && days <= 30
+				})).Return(&Transaction{ID: 3, AmountCents: 200000, Type: string(EntryPromoCredit)}, nil)
+			},
+		},
+		{
+			name:        "Valid too long",
+			req:         PromoCreditRequest{AmountCents: 200000, ValidForDays: MaxPromoCreditDays + 1, Reason: "r"},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrAdjustmentInvalid,
+		},
+		{
+			name:        "Negative amount",
+			req:         PromoCreditRequest{AmountCents: -1, ValidForDays: 30, Reason: "r"},
+			setupMocks:  func(r *MockRepository, u *MockUserRepo) {},
+			expectedErr: ErrAdjustmentInvalid,
+		},
+		{
+			name: "Unknown user",
+			req:  PromoCreditRequest{AmountCents: 200000, ValidForDays: 30, Reason: "r"},
+			setupMocks: func(r *MockRepository, u *MockUserRepo) {
+				u.On("FindByID", ctx, 5).Return(nil, sql.ErrNoRows)
+			},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			userRepo := new(MockUserRepo)
+			tt.setupMocks(repo, userRepo)
+
+			svc := NewService(repo, userRepo, 0)
+			txn, err := svc.GrantPromoCredit(ctx, 1, 5, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, txn.ID)
+			}
+
+			repo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_RefundTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockRepository)
+	repo.On("RefundTransaction", ctx, TransactionRefund{TransactionID: 30, Reason: "Class cancelled", AdminID: 1}).
+		Return(nil, ErrAlreadyRefunded)
+
+	svc := NewService(repo, new(MockUserRepo), 0)
+
+	_, err := svc.RefundTransaction(ctx, 1, 30, RefundRequest{Reason: " "})
+	assert.ErrorIs(t, err, ErrAdjustmentInvalid)
+
+	_, err = svc.RefundTransaction(ctx, 1, 30, RefundRequest{Reason: "Class cancelled"})
+	assert.ErrorIs(t, err, ErrAlreadyRefunded)
+
+	repo.AssertExpectations(t)
+}
+
+func TestService_SetExchangeRate(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockRepository)
+	repo.On("SetExchangeRate", ctx, "USD", "KZT", "470.1500000000", 1).
+		Return(&ExchangeRate{Base: "USD", Quote: "KZT", Rate: "470.1500000000"}, nil)
+
+	svc := NewService(repo, new(MockUserRepo), 0)
+
+	rate, err := svc.SetExchangeRate(ctx, 1, "usd", "KZT", SetExchangeRateRequest{Rate: "470.15"})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rate.Base)
+
+	for _, tt := range []struct{ base, quote, rate string }{
+		{"USD", "USD", "1"},
+		{"XYZ", "KZT", "1"},
+		{"USD", "KZT", "-470"},
+		{"USD", "KZT", "abc"},
+	} {
+		_, err := svc.SetExchangeRate(ctx, 1, tt.base, tt.quote, SetExchangeRateRequest{Rate: tt.rate})
+		assert.ErrorIs(t, err, ErrExchangeRateInvalid, tt)
+	}
+
+	repo.AssertExpectations(t)
+}
+
+func TestService_Statement(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	repo, users := new(MockRepository), new(MockUserRepo)
+	users.On("FindByID", ctx, 5).Return(&user.User{ID: 5, Name: "Anna", Email: "anna@example.com"}, nil)
+	repo.On("GetStatement", ctx, 5, "USD", from, to.AddDate(0, 0, 1)).
+		Return(&Statement{Currency: "USD", OpeningBalanceCents: 1000, ClosingBalanceCents: 500}, nil)
+
+	svc := NewService(repo, users, 0)
+
+	st, err := svc.Statement(ctx, 5, StatementRequest{From: "2024-01-01", To: "2024-01-31", Currency: "usd"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Anna", st.HolderName)
+	assert.Equal(t, "anna@example.com", st.HolderEmail)
+	assert.Equal(t, from, st.From)
+	assert.Equal(t, to, st.To)
+	assert.Equal(t, int64(500), st.ClosingBalanceCents)
+
+	for _, req := range []StatementRequest{
+		{From: "2024-02-01", To: "2024-01-31"},
+		{From: "01/01/2024", To: "2024-01-31"},
+		{From: "2024-01-01", To: "2025-01-01"},
+		{From: "2024-01-01", To: "2024-01-31", Currency: "XYZ"},
+	} {
+		_, err := svc.Statement(ctx, 5, req)
+		assert.ErrorIs(t, err, ErrStatementInvalid, req)
+	}
+
+	repo.AssertExpectations(t)
+}
+
+func TestService_Reconcile(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockRepository)
+	repo.On("ReconcileWallets", ctx).Return(&Reconciliation{
+		CheckedAt:      time.Now(),
+		WalletsChecked: 3,
+		Discrepancies: []WalletDiscrepancy{
+			{WalletID: 2, DifferenceCents: 5000, ChainBreaks: []ChainBreak{{TransactionID: 3}}},
+			{WalletID: 3, DifferenceCents: 700, ChainBreaks: []ChainBreak{}},
+		},
+	}, nil)
+
+	svc := NewService(repo, new(MockUserRepo), 0)
+
+	result, err := svc.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, result.Discrepancies, 2)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.WalletReconciliationDiscrepancies.WithLabelValues("balance")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.WalletReconciliationDiscrepancies.WithLabelValues("chain")))
+}
+
+func TestService_CorrectWallet(t *testing.T) {
+	ctx := context.Background()
+	difference := int64(5000)
+
+	repo := new(MockRepository)
+	repo.On("CorrectWallet", ctx, WalletCorrection{WalletID: 2, DifferenceCents: 5000, Reason: "Carried-over balance", AdminID: 1}).
+		Return(&Transaction{ID: 40, AmountCents: 5000, Type: TransactionReconciliation}, nil)
+
+	svc := NewService(repo, new(MockUserRepo), 0)
+
+	txn, err := svc.CorrectWallet(ctx, 1, 2, ReconcileRequest{DifferenceCents: &difference, Reason: " Carried-over balance "})
+	assert.NoError(t, err)
+	assert.Equal(t, 40, txn.ID)
+
+	_, err = svc.CorrectWallet(ctx, 1, 2, ReconcileRequest{DifferenceCents: &difference, Reason: " "})
+	assert.ErrorIs(t, err, ErrAdjustmentInvalid)
+
+	_, err = svc.CorrectWallet(ctx, 1, 2, ReconcileRequest{Reason: "x"})
+	assert.ErrorIs(t, err, ErrAdjustmentInvalid)
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,243 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fitslot/internal/money"
+	"fitslot/internal/pdf"
+)
+
+// Describe returns a human-readable description of a statement line, such
+// as "Class booking at Downtown Gym" or "Transfer to Anna: For Saturday".
+// The description stored with the transaction wins when it has one.
+func Describe(t StatementTransaction) string {
+	d := describeType(t)
+	if t.Description != nil && *t.Description != "" {
+		d = *t.Description
+	}
+	return withConversion(t, d)
+}
+
+// describeType describes a transaction from its type and counterparts.
+func describeType(t StatementTransaction) string {
+	var d string
+	switch t.Type {
+	case string(EntryTopUp):
+		d = "Wallet top-up"
+	case string(EntryTopUpRefund):
+		d = "Wallet top-up refunded"
+	case string(EntryBookingCharge):
+		d = atGym("Class booking at ", t.GymName, "Class booking")
+	case string(EntrySubscriptionCharge):
+		d = atGym("Subscription at ", t.GymName, "Network subscription")
+	case string(EntryRefund):
+		d = atGym("Refund from ", t.GymName, "Refund")
+	case string(EntryAdminRefund):
+		d = withDetail("Refund by support", t.Reason)
+	case string(EntryAdjustment):
+		d = "Credit by support"
+		if t.AmountCents < 0 {
+			d = "Debit by support"
+		}
+		d = withDetail(d, t.Reason)
+		if t.Reference != nil && *t.Reference != "" {
+			d += " (" + *t.Reference + ")"
+		}
+	case string(EntryPromoCredit):
+		d = withDetail("Promotional credit", t.Reason)
+	case string(EntryCreditExpired):
+		d = "Promotional credit expired"
+	case TransactionReconciliation:
+		d = withDetail("Reconciliation", t.Reason)
+	case TransactionTransferOut:
+		d = withDetail("Transfer to "+counterparty(t), t.Note)
+	case TransactionTransferIn:
+		d = withDetail("Transfer from "+counterparty(t), t.Note)
+	default:
+		d = t.Type
+	}
+	return d
+}
+
+// withConversion appends the price and rate of a converted payment to d.
+func withConversion(t StatementTransaction, d string) string {
+	if t.OriginalAmountCents != nil && t.OriginalCurrency != nil && t.ExchangeRate != nil {
+		if c, err := money.Lookup(*t.OriginalCurrency); err == nil {
+			amount := *t.OriginalAmountCents
+			if amount < 0 {
+				amount = -amount
+			}
+			d += fmt.Sprintf(" (%s %s at %s)", money.Format(amount, c), c.Code, trimRate(*t.ExchangeRate))
+		}
+	}
+	return d
+}
+
+func atGym(prefix string, gymName *string, fallback string) string {
+	if gymName == nil || *gymName == "" {
+		return fallback
+	}
+	return prefix + *gymName
+}
+
+func withDetail(d string, detail *string) string {
+	if detail == nil || *detail == "" {
+		return d
+	}
+	return d + ": " + *detail
+}
+
+func counterparty(t StatementTransaction) string {
+	if t.CounterpartyName != nil && *t.CounterpartyName != "" {
+		return *t.CounterpartyName
+	}
+	if t.CounterpartyUserID != nil {
+		return "member #" + strconv.Itoa(*t.CounterpartyUserID)
+	}
+	return "another member"
+}
+
+// trimRate drops the trailing zeros the database pads rates with.
+func trimRate(rate string) string {
+	if strings.Contains(rate, ".") {
+		rate = strings.TrimRight(strings.TrimRight(rate, "0"), ".")
+	}
+	return rate
+}
+
+// StatementFilename is the name statements are downloaded as.
+func StatementFilename(st *Statement, format string) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s", st.Currency, st.From.Format(time.DateOnly), st.To.Format(time.DateOnly), format)
+}
+
+// WriteStatementCSV writes the statement as CSV: a header, the opening
+// balance, one row per transaction and the closing balance.
+func WriteStatementCSV(w io.Writer, st *Statement) error {
+	c, err := money.Lookup(st.Currency)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "transaction_id", "type", "description", "amount", "balance", "currency"},
+		{st.From.Format(time.DateOnly), "", "opening_balance", "Opening balance", "", money.Format(st.OpeningBalanceCents, c), c.Code},
+	}
+	for _, t := range st.Transactions {
+		rows = append(rows, []string{
+			t.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(t.ID),
+			t.Type,
+			csvText(Describe(t)),
+			money.Format(t.AmountCents, c),
+			money.Format(t.BalanceAfter, c),
+			c.Code,
+		})
+	}
+	rows = append(rows, []string{st.To.Format(time.DateOnly), "", "closing_balance", "Closing balance", "", money.Format(st.ClosingBalanceCents, c), c.Code})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// csvText keeps spreadsheets from running member-written text, such as a
+// transfer note, as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Layout of the PDF statement, in points.
+const (
+	pdfMargin      = 50.0
+	pdfRowHeight   = 14.0
+	pdfFontSize    = 9.0
+	pdfDescX       = 150.0
+	pdfDescRunes   = 52
+	pdfAmountRight = 470.0
+	pdfBalanceX    = pdf.PageWidth - pdfMargin
+)
+
+// WriteStatementPDF writes the statement as an A4 PDF with the opening
+// balance, a table of transactions with the running balance, and the
+// closing balance.
+func WriteStatementPDF(w io.Writer, st *Statement) error {
+	c, err := money.Lookup(st.Currency)
+	if err != nil {
+		return err
+	}
+
+	doc := pdf.New()
+	y := 0.0
+	row := func(date, desc, amount, balance string, font pdf.Font) {
+		if y < pdfMargin+pdfRowHeight {
+			newStatementPage(doc, &y)
+		}
+		doc.Text(pdfMargin, y, pdf.Courier, pdfFontSize, date)
+		doc.Text(pdfDescX, y, font, pdfFontSize, truncate(desc, pdfDescRunes))
+		rightAligned(doc, pdfAmountRight, y, amount)
+		rightAligned(doc, pdfBalanceX, y, balance)
+		y -= pdfRowHeight
+	}
+
+	addStatementPage(doc)
+	top := pdf.PageHeight - pdfMargin
+	doc.Text(pdfMargin, top, pdf.HelveticaBold, 16, "Wallet statement")
+	doc.Text(pdfMargin, top-24, pdf.Helvetica, 10, fmt.Sprintf("%s <%s>", st.HolderName, st.HolderEmail))
+	doc.Text(pdfMargin, top-38, pdf.Helvetica, 10, fmt.Sprintf("Period: %s to %s (UTC)", st.From.Format(time.DateOnly), st.To.Format(time.DateOnly)))
+	doc.Text(pdfMargin, top-52, pdf.Helvetica, 10, "Currency: "+c.Code)
+	y = top - 80
+	statementHeader(doc, &y)
+
+	row(st.From.Format(time.DateOnly), "Opening balance", "", money.Format(st.OpeningBalanceCents, c), pdf.HelveticaBold)
+	for _, t := range st.Transactions {
+		row(t.CreatedAt.UTC().Format("2006-01-02 15:04"), Describe(t), money.Format(t.AmountCents, c), money.Format(t.BalanceAfter, c), pdf.Helvetica)
+	}
+	row(st.To.Format(time.DateOnly), "Closing balance", "", money.Format(st.ClosingBalanceCents, c), pdf.HelveticaBold)
+
+	_, err = doc.WriteTo(w)
+	return err
+}
+
+func newStatementPage(doc *pdf.Document, y *float64) {
+	addStatementPage(doc)
+	*y = pdf.PageHeight - pdfMargin
+	statementHeader(doc, y)
+}
+
+// addStatementPage starts a page with its number in the footer.
+func addStatementPage(doc *pdf.Document) {
+	doc.AddPage()
+	doc.Text(pdfMargin, pdfMargin/2, pdf.Helvetica, 8, fmt.Sprintf("Page %d", doc.PageCount()))
+}
+
+func statementHeader(doc *pdf.Document, y *float64) {
+	doc.Text(pdfMargin, *y, pdf.HelveticaBold, pdfFontSize, "Date")
+	doc.Text(pdfDescX, *y, pdf.HelveticaBold, pdfFontSize, "Description")
+	doc.Text(pdfAmountRight-36, *y, pdf.HelveticaBold, pdfFontSize, "Amount")
+	doc.Text(pdfBalanceX-38, *y, pdf.HelveticaBold, pdfFontSize, "Balance")
+	doc.Line(pdfMargin, *y-4, pdfBalanceX, *y-4)
+	*y -= pdfRowHeight + 4
+}
+
+// rightAligned draws a Courier number ending at x.
+func rightAligned(doc *pdf.Document, x, y float64, s string) {
+	doc.Text(x-float64(len(s))*pdf.CourierWidth*pdfFontSize, y, pdf.Courier, pdfFontSize, s)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
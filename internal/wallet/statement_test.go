@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDescribe(t *testing.T) {
+	original := int64(-470000)
+	counterpartyID := 8
+
+	tests := []struct {
+		name string
+		txn  StatementTransaction
+		want string
+	}{
+		{"top-up", StatementTransaction{Transaction: Transaction{Type: "topup"}}, "Wallet top-up"},
+		{"booking at a gym", StatementTransaction{Transaction: Transaction{Type: "booking_payment"}, GymName: strPtr("Downtown Gym")}, "Class booking at Downtown Gym"},
+		{"network subscription", StatementTransaction{Transaction: Transaction{Type: "subscription_payment"}}, "Network subscription"},
+		{"refund", StatementTransaction{Transaction: Transaction{Type: "refund"}, GymName: strPtr("Downtown Gym")}, "Refund from Downtown Gym"},
+		{"transfer out with note", StatementTransaction{Transaction: Transaction{
+			Type: TransactionTransferOut, CounterpartyName: strPtr("Anna"), Note: strPtr("For Saturday"),
+		}}, "Transfer to Anna: For Saturday"},
+		{"transfer in from a deleted member", StatementTransaction{Transaction: Transaction{
+			Type: TransactionTransferIn, CounterpartyUserID: &counterpartyID,
+		}}, "Transfer from member #8"},
+		{"admin debit", StatementTransaction{Transaction: Transaction{
+			Type: "admin_adjustment", AmountCents: -500, Reason: strPtr("Charged twice"), Reference: strPtr("SUP-1"),
+		}}, "Debit by support: Charged twice (SUP-1)"},
+		{"reconciliation", StatementTransaction{Transaction: Transaction{Type: TransactionReconciliation, Reason: strPtr("Carried-over balance")}}, "Reconciliation: Carried-over balance"},
+		{"promotional credit", StatementTransaction{Transaction: Transaction{Type: "promo_credit", Reason: strPtr("Welcome bonus")}}, "Promotional credit: Welcome bonus"},
+		{"expired promotional credit", StatementTransaction{Transaction: Transaction{Type: "credit_expired", AmountCents: -50000}}, "Promotional credit expired"},
+		{"admin refund", StatementTransaction{Transaction: Transaction{Type: "admin_refund", Reason: strPtr("Class cancelled")}}, "Refund by support: Class cancelled"},
+		{"converted charge", StatementTransaction{Transaction: Transaction{
+			Type: "booking_payment", OriginalAmountCents: &original, OriginalCurrency: strPtr("KZT"), ExchangeRate: strPtr("0.0021000000"),
+		}}, "Class booking (4700.00 KZT at 0.0021)"},
+		{"stored description", StatementTransaction{Transaction: Transaction{
+			Type: "booking_payment", Description: strPtr("Class booking at Downtown Gym, 2 Jan 2024 18:00"), OriginalAmountCents: &original, OriginalCurrency: strPtr("KZT"), ExchangeRate: strPtr("0.0021000000"),
+		}, GymName: strPtr("Downtown Gym")}, "Class booking at Downtown Gym, 2 Jan 2024 18:00 (4700.00 KZT at 0.0021)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Describe(tt.txn))
+		})
+	}
+}
+
+func testStatement() *Statement {
+	at := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	return &Statement{
+		HolderName:          "Anna",
+		HolderEmail:         "anna@example.com",
+		Currency:            "KZT",
+		From:                time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:                  time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalanceCents: 10000,
+		ClosingBalanceCents: 8500,
+		Transactions: []StatementTransaction{
+			{Transaction: Transaction{ID: 20, Type: "booking_payment", AmountCents: -1000, BalanceAfter: 9000, CreatedAt: at}, GymName: strPtr("Downtown Gym")},
+			{Transaction: Transaction{ID: 21, Type: TransactionTransferOut, AmountCents: -500, BalanceAfter: 8500, CreatedAt: at.Add(time.Hour),
+				CounterpartyName: strPtr("Ben"), Note: strPtr("=1+1")}},
+		},
+	}
+}
+
+func TestWriteStatementCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatementCSV(&buf, testStatement()))
+
+	assert.Equal(t, `date,transaction_id,type,description,amount,balance,currency
+2024-01-01,,opening_balance,Opening balance,,100.00,KZT
+2024-01-02T09:30:00Z,20,booking_payment,Class booking at Downtown Gym,-10.00,90.00,KZT
+2024-01-02T10:30:00Z,21,transfer_out,Transfer to Ben: =1+1,-5.00,85.00,KZT
+2024-01-31,,closing_balance,Closing balance,,85.00,KZT
+`, buf.String())
+}
+
+func TestCSVText(t *testing.T) {
+	assert.Equal(t, "'=1+1", csvText("=1+1"))
+	assert.Equal(t, "'@SUM(A1)", csvText("@SUM(A1)"))
+	assert.Equal(t, "Wallet top-up", csvText("Wallet top-up"))
+}
+
+func TestWriteStatementPDF(t *testing.T) {
+	st := testStatement()
+	for i := 0; i < 80; i++ {
+		st.Transactions = append(st.Transactions, st.Transactions[0])
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatementPDF(&buf, st))
+
+	out := buf.String()
+	assert.Contains(t, out, "%PDF-1.4")
+	assert.Contains(t, out, "(Wallet statement) Tj")
+	assert.Contains(t, out, "(Opening balance) Tj")
+	assert.Contains(t, out, "(Class booking at Downtown Gym) Tj")
+	assert.Contains(t, out, "(Closing balance) Tj")
+	assert.Contains(t, out, "(Page 2) Tj")
+	assert.Contains(t, out, "/Count 2")
+}
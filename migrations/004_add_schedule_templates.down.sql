@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_time_slots_template_start;
+
+ALTER TABLE time_slots
+    DROP COLUMN IF EXISTS template_id,
+    DROP COLUMN IF EXISTS price_cents;
+
+DROP INDEX IF EXISTS idx_schedule_templates_gym_id;
+DROP TABLE IF EXISTS schedule_templates;
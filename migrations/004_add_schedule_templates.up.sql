@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS schedule_templates (
+    id SERIAL PRIMARY KEY,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL,
+    start_time TIME NOT NULL,
+    duration_minutes INTEGER NOT NULL,
+    capacity INTEGER NOT NULL,
+    price_cents BIGINT NOT NULL DEFAULT 1000,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_template_weekday CHECK (weekday BETWEEN 0 AND 6),
+    CONSTRAINT check_template_duration_positive CHECK (duration_minutes > 0),
+    CONSTRAINT check_template_capacity_positive CHECK (capacity > 0),
+    CONSTRAINT check_template_price_non_negative CHECK (price_cents >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_templates_gym_id ON schedule_templates(gym_id);
+
+ALTER TABLE time_slots
+    ADD COLUMN IF NOT EXISTS price_cents BIGINT NOT NULL DEFAULT 1000,
+    ADD COLUMN IF NOT EXISTS template_id INTEGER REFERENCES schedule_templates(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_slots_template_start
+    ON time_slots(template_id, start_time)
+    WHERE template_id IS NOT NULL;
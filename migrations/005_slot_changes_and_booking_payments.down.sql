@@ -0,0 +1,7 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS subscription_id,
+    DROP COLUMN IF EXISTS amount_cents,
+    DROP COLUMN IF EXISTS paid_with;
+
+ALTER TABLE time_slots
+    DROP COLUMN IF EXISTS cancelled_at;
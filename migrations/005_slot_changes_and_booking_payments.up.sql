@@ -0,0 +1,7 @@
+ALTER TABLE time_slots
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS paid_with VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS amount_cents BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS subscription_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL;
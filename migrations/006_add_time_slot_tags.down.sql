@@ -0,0 +1,2 @@
+ALTER TABLE time_slots
+    DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,2 @@
+ALTER TABLE time_slots
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
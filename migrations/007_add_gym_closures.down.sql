@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_gym_closures_gym_range;
+DROP TABLE IF EXISTS gym_closures;
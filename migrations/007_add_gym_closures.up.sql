@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS gym_closures (
+    id SERIAL PRIMARY KEY,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_closure_range CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gym_closures_gym_range ON gym_closures(gym_id, starts_at, ends_at);
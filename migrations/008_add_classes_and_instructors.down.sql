@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_time_slots_instructor_id;
+DROP INDEX IF EXISTS idx_time_slots_class_type_id;
+
+ALTER TABLE time_slots
+    DROP COLUMN IF EXISTS instructor_id,
+    DROP COLUMN IF EXISTS class_type_id;
+
+DROP INDEX IF EXISTS idx_instructor_gyms_gym_id;
+DROP TABLE IF EXISTS instructor_gyms;
+DROP TABLE IF EXISTS instructors;
+DROP TABLE IF EXISTS class_types;
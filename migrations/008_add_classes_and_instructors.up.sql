@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS class_types (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS instructors (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    bio TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS instructor_gyms (
+    instructor_id INTEGER NOT NULL REFERENCES instructors(id) ON DELETE CASCADE,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    PRIMARY KEY (instructor_id, gym_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_instructor_gyms_gym_id ON instructor_gyms(gym_id);
+
+ALTER TABLE time_slots
+    ADD COLUMN IF NOT EXISTS class_type_id INTEGER REFERENCES class_types(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS instructor_id INTEGER REFERENCES instructors(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_time_slots_class_type_id ON time_slots(class_type_id);
+CREATE INDEX IF NOT EXISTS idx_time_slots_instructor_id ON time_slots(instructor_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_time_slots_room_time;
+
+ALTER TABLE time_slots
+    DROP COLUMN IF EXISTS room_id;
+
+DROP TABLE IF EXISTS rooms;
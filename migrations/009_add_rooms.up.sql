@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS rooms (
+    id SERIAL PRIMARY KEY,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    capacity INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_room_capacity_positive CHECK (capacity > 0),
+    CONSTRAINT unique_room_name_per_gym UNIQUE (gym_id, name)
+);
+
+ALTER TABLE time_slots
+    ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES rooms(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_time_slots_room_time ON time_slots(room_id, start_time, end_time);
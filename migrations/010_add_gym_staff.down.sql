@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_gym_staff_gym_id;
+DROP TABLE IF EXISTS gym_staff;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS gym_staff (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, gym_id),
+    CONSTRAINT check_gym_staff_role CHECK (role IN ('manager', 'front_desk', 'instructor'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_gym_staff_gym_id ON gym_staff(gym_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS gym_reviews (
+    id SERIAL PRIMARY KEY,
+    gym_id INTEGER NOT NULL REFERENCES gyms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    hidden_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_review_rating CHECK (rating BETWEEN 1 AND 5),
+    CONSTRAINT unique_review_per_booking UNIQUE (booking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gym_reviews_gym_id ON gym_reviews(gym_id);
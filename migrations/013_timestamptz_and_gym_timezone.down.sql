@@ -0,0 +1,63 @@
+-- Times go back to wall-clock time in fitslot.legacy_timezone.
+
+ALTER TABLE gyms
+    DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE users
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gyms
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE time_slots
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN cancelled_at TYPE TIMESTAMP USING cancelled_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE bookings
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE wallets
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE wallet_transactions
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE payments
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE subscriptions
+    ALTER COLUMN valid_from TYPE TIMESTAMP USING valid_from AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN valid_until TYPE TIMESTAMP USING valid_until AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE schedule_templates
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_closures
+    ALTER COLUMN starts_at TYPE TIMESTAMP USING starts_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN ends_at TYPE TIMESTAMP USING ends_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE class_types
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE instructors
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE rooms
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_staff
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_reviews
+    ALTER COLUMN hidden_at TYPE TIMESTAMP USING hidden_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_photos
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
@@ -0,0 +1,66 @@
+-- Existing timestamps were written in the server's local time. They are read
+-- in the zone set as fitslot.legacy_timezone, which the app sets from
+-- LEGACY_TIMEZONE before migrating. When running by hand, set it first:
+--   SET fitslot.legacy_timezone = 'Asia/Almaty';
+
+ALTER TABLE users
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gyms
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE time_slots
+    ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN cancelled_at TYPE TIMESTAMPTZ USING cancelled_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE bookings
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE wallets
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE wallet_transactions
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE payments
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE subscriptions
+    ALTER COLUMN valid_from TYPE TIMESTAMPTZ USING valid_from AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN valid_until TYPE TIMESTAMPTZ USING valid_until AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE schedule_templates
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_closures
+    ALTER COLUMN starts_at TYPE TIMESTAMPTZ USING starts_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN ends_at TYPE TIMESTAMPTZ USING ends_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE class_types
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE instructors
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE rooms
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_staff
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_reviews
+    ALTER COLUMN hidden_at TYPE TIMESTAMPTZ USING hidden_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gym_photos
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE gyms
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,6 @@
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS ledger_entry_id;
+
+DROP TABLE IF EXISTS ledger_postings;
+DROP FUNCTION IF EXISTS ledger_check_entry_balance();
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
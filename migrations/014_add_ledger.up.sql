@@ -0,0 +1,76 @@
+-- Double-entry ledger behind the wallet. Every money movement is a journal
+-- entry whose postings net to zero; a positive amount credits an account and
+-- a negative one debits it. Account balances are the sum of their postings.
+
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(30) NOT NULL
+        CHECK (type IN ('member_wallet', 'gym_revenue', 'promo_liability', 'refunds', 'cash')),
+    user_id INTEGER REFERENCES users(id),
+    gym_id INTEGER REFERENCES gyms(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One account per type and owner. Revenue and refunds without a gym belong
+-- to the platform (network-wide subscriptions).
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_owner
+    ON ledger_accounts (type, COALESCE(user_id, 0), COALESCE(gym_id, 0));
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES ledger_entries(id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account_id ON ledger_postings(account_id);
+
+-- Entries must balance when their transaction commits.
+CREATE OR REPLACE FUNCTION ledger_check_entry_balance() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount_cents), 0) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT OR UPDATE ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_entry_balance();
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS ledger_entry_id BIGINT REFERENCES ledger_entries(id);
+
+-- Carry existing wallet balances over as opening entries against cash.
+INSERT INTO ledger_accounts (type) VALUES ('cash');
+
+INSERT INTO ledger_accounts (type, user_id)
+SELECT 'member_wallet', user_id FROM wallets;
+
+DO $$
+DECLARE
+    w RECORD;
+    entry BIGINT;
+    cash INTEGER := (SELECT id FROM ledger_accounts WHERE type = 'cash' AND gym_id IS NULL);
+BEGIN
+    FOR w IN
+        SELECT a.id AS account_id, wl.balance_cents
+        FROM wallets wl
+        JOIN ledger_accounts a ON a.type = 'member_wallet' AND a.user_id = wl.user_id
+        WHERE wl.balance_cents <> 0
+    LOOP
+        INSERT INTO ledger_entries (kind) VALUES ('opening_balance') RETURNING id INTO entry;
+        INSERT INTO ledger_postings (entry_id, account_id, amount_cents)
+        VALUES (entry, w.account_id, w.balance_cents), (entry, cash, -w.balance_cents);
+    END LOOP;
+END;
+$$;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_wallet_type_created;
+
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS counterparty_user_id;
@@ -0,0 +1,11 @@
+-- Member-to-member transfers. Each transfer is one ledger entry shown as a
+-- transfer_out in the sender's wallet and a transfer_in in the recipient's,
+-- each pointing at the other member.
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS counterparty_user_id INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS note VARCHAR(200);
+
+-- Daily transfer limits sum a wallet's recent transfer_out records.
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_type_created
+    ON wallet_transactions (wallet_id, type, created_at);
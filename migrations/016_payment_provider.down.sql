@@ -0,0 +1,6 @@
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS payment_id;
+
+DROP INDEX IF EXISTS idx_payments_provider_payment;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS failure_reason;
@@ -0,0 +1,17 @@
+-- Top-ups go through a payment provider. A payment starts pending and is
+-- settled by the provider, synchronously or later through a webhook; the
+-- wallet is credited once, when the payment turns succeeded.
+
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+ALTER TABLE payments
+    ADD CONSTRAINT payments_status_check
+        CHECK (status IN ('pending', 'succeeded', 'failed', 'refunded'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_payment
+    ON payments (provider, provider_payment_id)
+    WHERE provider_payment_id IS NOT NULL;
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS payment_id INTEGER REFERENCES payments(id);
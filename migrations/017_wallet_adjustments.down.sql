@@ -0,0 +1,11 @@
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+    CHECK (type IN ('member_wallet', 'gym_revenue', 'promo_liability', 'refunds', 'cash'));
+
+DROP INDEX IF EXISTS idx_wallet_transactions_refund_of;
+
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS refund_of_id,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS reference,
+    DROP COLUMN IF EXISTS reason;
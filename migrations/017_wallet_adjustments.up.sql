@@ -0,0 +1,18 @@
+-- Support staff can adjust wallet balances and refund wallet charges. Both
+-- record the reason and the acting admin; a charge can be refunded once.
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS reason TEXT,
+    ADD COLUMN IF NOT EXISTS reference VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS created_by INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS refund_of_id INTEGER REFERENCES wallet_transactions(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_transactions_refund_of
+    ON wallet_transactions (refund_of_id)
+    WHERE refund_of_id IS NOT NULL;
+
+-- Manual credits and debits are booked against the platform's adjustments
+-- account.
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+    CHECK (type IN ('member_wallet', 'gym_revenue', 'promo_liability', 'refunds', 'cash', 'adjustments'));
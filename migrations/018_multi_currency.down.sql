@@ -0,0 +1,32 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS exchange_rate,
+    DROP COLUMN IF EXISTS original_currency,
+    DROP COLUMN IF EXISTS original_amount_cents;
+
+CREATE OR REPLACE FUNCTION ledger_check_entry_balance() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount_cents), 0) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+    CHECK (type IN ('member_wallet', 'gym_revenue', 'promo_liability', 'refunds', 'cash', 'adjustments'));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_owner;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_owner
+    ON ledger_accounts (type, COALESCE(user_id, 0), COALESCE(gym_id, 0));
+
+ALTER TABLE ledger_accounts DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS exchange_rates;
+
+DROP INDEX IF EXISTS idx_wallets_user_currency;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets(user_id);
+
+ALTER TABLE gyms DROP COLUMN IF EXISTS currency;
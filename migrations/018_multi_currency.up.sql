@@ -0,0 +1,62 @@
+-- Gyms price their slots in their own currency and members hold one wallet
+-- per currency. Paying from a wallet in another currency converts at the
+-- exchange rate admins maintain, booked through the fx accounts so every
+-- entry still balances within each currency.
+
+ALTER TABLE gyms
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'KZT';
+
+DROP INDEX IF EXISTS idx_wallets_user_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_currency ON wallets (user_id, currency);
+
+-- One unit of base costs rate units of quote. A pair is stored in one
+-- direction only; the inverse is derived from it.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base VARCHAR(3) NOT NULL,
+    quote VARCHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_by INTEGER REFERENCES users(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base, quote),
+    CHECK (base <> quote)
+);
+
+-- Ledger accounts hold a single currency.
+ALTER TABLE ledger_accounts
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'KZT';
+
+DROP INDEX IF EXISTS idx_ledger_accounts_owner;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_owner
+    ON ledger_accounts (type, currency, COALESCE(user_id, 0), COALESCE(gym_id, 0));
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+    CHECK (type IN ('member_wallet', 'gym_revenue', 'promo_liability', 'refunds', 'cash', 'adjustments', 'fx'));
+
+-- Entries must balance in every currency they touch.
+CREATE OR REPLACE FUNCTION ledger_check_entry_balance() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM ledger_postings p
+        JOIN ledger_accounts a ON a.id = p.account_id
+        WHERE p.entry_id = NEW.entry_id
+        GROUP BY a.currency
+        HAVING SUM(p.amount_cents) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A converted payment keeps the price it paid for and the rate used.
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS original_amount_cents BIGINT,
+    ADD COLUMN IF NOT EXISTS original_currency VARCHAR(3),
+    ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20, 10);
+
+-- The currency of what the member paid for a booking, so a cancellation
+-- refunds the wallet it came from.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'KZT';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_wallet_created;
+DROP INDEX IF EXISTS idx_wallet_transactions_subscription_id;
+DROP INDEX IF EXISTS idx_wallet_transactions_booking_id;
+
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS subscription_id,
+    DROP COLUMN IF EXISTS booking_id;
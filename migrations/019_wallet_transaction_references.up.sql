@@ -0,0 +1,21 @@
+-- Wallet transactions point at what they paid for: the booking or
+-- subscription a charge bought (payment_id already links top-ups), with a
+-- readable description and details as JSON.
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS subscription_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS description TEXT,
+    ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_booking_id
+    ON wallet_transactions (booking_id)
+    WHERE booking_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_subscription_id
+    ON wallet_transactions (subscription_id)
+    WHERE subscription_id IS NOT NULL;
+
+-- Transaction lists are filtered by date range.
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_created
+    ON wallet_transactions (wallet_id, created_at);
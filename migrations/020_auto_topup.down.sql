@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_payments_auto_topup;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS auto_topup,
+    DROP COLUMN IF EXISTS payment_method_id;
+
+DROP TABLE IF EXISTS auto_topups;
+DROP TABLE IF EXISTS payment_methods;
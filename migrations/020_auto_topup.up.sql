@@ -0,0 +1,39 @@
+-- Members can save a payment method with the provider and have their wallet
+-- topped up from it when a charge would leave the balance below a threshold.
+
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    provider_method_id TEXT NOT NULL,
+    label TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_method_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_methods_user_id ON payment_methods (user_id);
+
+-- One auto top-up per wallet. It is switched off after repeated declines.
+CREATE TABLE IF NOT EXISTS auto_topups (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency VARCHAR(3) NOT NULL,
+    threshold_cents BIGINT NOT NULL CHECK (threshold_cents >= 0),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    monthly_cap_cents BIGINT NOT NULL CHECK (monthly_cap_cents >= amount_cents),
+    payment_method_id INTEGER NOT NULL REFERENCES payment_methods(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency)
+);
+
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS payment_method_id INTEGER REFERENCES payment_methods(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS auto_topup BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The monthly cap sums a member's auto top-ups of the month.
+CREATE INDEX IF NOT EXISTS idx_payments_auto_topup
+    ON payments (user_id, currency, created_at)
+    WHERE auto_topup;
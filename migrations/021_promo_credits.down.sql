@@ -0,0 +1,4 @@
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS promo_cents;
+
+DROP TABLE IF EXISTS wallet_credit_lots;
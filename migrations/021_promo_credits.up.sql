@@ -0,0 +1,31 @@
+-- Promotional credit is granted in lots that expire. A wallet's balance
+-- includes the unspent remainder of its lots; charges spend the lot that
+-- expires first before the member's own money, and what is left of a lot
+-- when it expires is taken back by a credit_expired transaction.
+
+CREATE TABLE IF NOT EXISTS wallet_credit_lots (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    transaction_id INTEGER REFERENCES wallet_transactions(id),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    remaining_cents BIGINT NOT NULL CHECK (remaining_cents >= 0 AND remaining_cents <= amount_cents),
+    expires_at TIMESTAMPTZ NOT NULL,
+    expired_at TIMESTAMPTZ,
+    reason TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only lots with credit left are spent, shown or expired.
+CREATE INDEX IF NOT EXISTS idx_wallet_credit_lots_wallet_open
+    ON wallet_credit_lots (wallet_id, expires_at)
+    WHERE remaining_cents > 0;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_credit_lots_expires_open
+    ON wallet_credit_lots (expires_at)
+    WHERE remaining_cents > 0;
+
+-- The part of a charge paid with promotional credit, negative like the
+-- amount.
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS promo_cents BIGINT;
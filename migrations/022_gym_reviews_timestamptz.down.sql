@@ -0,0 +1,3 @@
+-- Nothing to undo: 013's down migration turns review times back into
+-- TIMESTAMP.
+SELECT 1;
@@ -0,0 +1,14 @@
+-- Databases migrated while 013 left gym_reviews out still store review times
+-- as TIMESTAMP. Convert them the same way 013 converts every other table.
+
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema()
+        AND table_name = 'gym_reviews' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE gym_reviews
+            ALTER COLUMN hidden_at TYPE TIMESTAMPTZ USING hidden_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+            ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+    END IF;
+END
+$$;
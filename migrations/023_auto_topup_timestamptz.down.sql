@@ -0,0 +1,6 @@
+ALTER TABLE auto_topups
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+
+ALTER TABLE payment_methods
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
@@ -0,0 +1,22 @@
+-- 020 stored payment method and auto top-up times as TIMESTAMP. Convert them
+-- like 013 converts every other table, unless the database was migrated
+-- while 020 already created them as TIMESTAMPTZ.
+
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema()
+        AND table_name = 'payment_methods' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE payment_methods
+            ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+    END IF;
+
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema()
+        AND table_name = 'auto_topups' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE auto_topups
+            ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('fitslot.legacy_timezone'),
+            ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('fitslot.legacy_timezone');
+    END IF;
+END
+$$;
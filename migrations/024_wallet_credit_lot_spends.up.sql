@@ -0,0 +1,9 @@
+-- What a debit took from each lot, so that a refund of it puts the credit
+-- back where it came from. A refund's promo_cents is the part returned as
+-- promotional credit.
+CREATE TABLE IF NOT EXISTS wallet_credit_lot_spends (
+    transaction_id INTEGER NOT NULL REFERENCES wallet_transactions(id),
+    lot_id INTEGER NOT NULL REFERENCES wallet_credit_lots(id) ON DELETE CASCADE,
+    cents BIGINT NOT NULL CHECK (cents > 0),
+    PRIMARY KEY (transaction_id, lot_id)
+);